@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/db"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/admin/repository"
+	"gophKeeper/internal/server/services/admin/usecase"
+	audit "gophKeeper/internal/server/services/audit/usecase"
+	"os"
+	"strings"
+)
+
+// Команда создаёт первого администратора: go run ./cmd/admin -username root.
+// Если пользователь с таким именем уже есть, он получает роль администратора,
+// а пароль не меняется. Когда администратор есть, команда ничего не делает:
+// остальные роли назначаются через /api/admin. Пароль читается только из
+// stdin: в аргументах его видно в ps и истории оболочки.
+func main() {
+	username := flag.String("username", "", "Имя администратора (обязательно)")
+	flag.Parse()
+
+	if *username == "" {
+		fmt.Fprintln(os.Stderr, "укажите -username")
+		os.Exit(2)
+	}
+	fmt.Print("Пароль: ")
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	password := strings.TrimRight(line, "\r\n")
+
+	ctx := context.Background()
+	cfg := config.New()
+	database, err := db.Init(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error database:", err)
+		os.Exit(1)
+	}
+
+	userId, err := usecase.NewAdminUsecase(repository.NewAdminRepo(database), audit.Nop).Bootstrap(ctx, *username, password)
+	switch {
+	case errors.Is(err, domain.ErrAdminExists):
+		fmt.Println("Администратор уже есть, назначайте роли через /api/admin")
+		return
+	case errors.Is(err, domain.ErrInvalidInput):
+		fmt.Fprintln(os.Stderr, "имя должно быть не короче 3 символов, пароль — не короче 6")
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "Error bootstrap:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Администратор %s (id %d) создан\n", *username, userId)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	cli2 "gophKeeper/internal/client/services/lockbox/cli"
+	"strconv"
+)
+
+// accountMenu возвращает false, если аккаунт удалён и работу нужно завершить.
+func accountMenu(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader) bool {
+	for {
+		fmt.Println("\nАккаунт:")
+		fmt.Println("1. Посмотреть")
+		fmt.Println("2. Сменить пароль")
+		fmt.Println("3. Сменить имя пользователя")
+		fmt.Println("4. Удалить аккаунт")
+		fmt.Println("5. Назад")
+
+		choice, err := strconv.Atoi(readLine(reader, "Введите номер команды: "))
+		if err != nil {
+			fmt.Println("Ошибка ввода: ожидается число")
+			continue
+		}
+
+		cmd := lockBoxCli.AccountCommand(ctx)
+		switch choice {
+		case 1:
+			cmd.SetArgs([]string{"show"})
+		case 2:
+			cmd.SetArgs([]string{"password",
+				"--old-password", readLine(reader, "Текущий пароль: "),
+				"--new-password", readLine(reader, "Новый пароль: ")})
+		case 3:
+			cmd.SetArgs([]string{"rename",
+				"--password", readLine(reader, "Текущий пароль: "),
+				"--username", readLine(reader, "Новое имя пользователя: ")})
+		case 4:
+			if readLine(reader, "Все данные будут удалены без возможности восстановления. Введите yes: ") != "yes" {
+				continue
+			}
+			cmd.SetArgs([]string{"delete", "--yes", "--password", readLine(reader, "Текущий пароль: ")})
+		case 5:
+			return true
+		default:
+			fmt.Println("Некорректный выбор, попробуйте снова.")
+			continue
+		}
+		if err := cmd.Execute(); err != nil {
+			fmt.Println("❌ Ошибка:", err)
+		}
+		if !lockBoxCli.IsAuthenticated() {
+			return false
+		}
+	}
+}
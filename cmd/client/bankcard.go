@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	cli2 "gophKeeper/internal/client/services/lockbox/cli"
+	"strconv"
+	"strings"
+)
+
+func bankCardMenu(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader) {
+	for {
+		fmt.Println("\nБанковские карты:")
+		fmt.Println("1. Добавить карту")
+		fmt.Println("2. Удалить карту")
+		fmt.Println("3. Посмотреть все")
+		fmt.Println("4. Получить карту")
+		fmt.Println("5. Обновить карту")
+		fmt.Println("6. Назад")
+
+		choice, err := strconv.Atoi(readLine(reader, "Введите номер команды: "))
+		if err != nil {
+			fmt.Println("Ошибка ввода: ожидается число")
+			continue
+		}
+
+		switch choice {
+		case 1:
+			bankCardFormFlow(lockBoxCli, ctx, reader, false)
+		case 2:
+			name := readLine(reader, "Название: ")
+			cmd := lockBoxCli.DeleteCardCommand(ctx)
+			cmd.Flags().Set("name", name)
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка удаления карты:", err)
+			}
+		case 3:
+			cmd := lockBoxCli.GetAllCardsCommand(ctx)
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка получения карт:", err)
+			}
+		case 4:
+			name := readLine(reader, "Название: ")
+			cmd := lockBoxCli.GetCardCommand(ctx)
+			cmd.Flags().Set("name", name)
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка получения карты:", err)
+			}
+		case 5:
+			bankCardFormFlow(lockBoxCli, ctx, reader, true)
+		case 6:
+			return
+		default:
+			fmt.Println("Некорректный выбор, попробуйте снова.")
+		}
+	}
+}
+
+func bankCardFormFlow(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader, update bool) {
+	if update {
+		fmt.Println("\nДанные карты (пустое поле — не менять, «-» — очистить)")
+	} else {
+		fmt.Println("\nДанные карты")
+	}
+	name := readLine(reader, "Название: ")
+	if name == "" {
+		fmt.Println("❌ Ошибка ввода Названия")
+		return
+	}
+
+	cmd := lockBoxCli.CreateCardCommand(ctx)
+	if update {
+		cmd = lockBoxCli.UpdateCardCommand(ctx)
+	}
+	args := []string{"--name", name}
+	var clear []string
+	for _, field := range []struct{ flag, prompt string }{
+		{"number", "Номер карты: "},
+		{"holder", "Держатель: "},
+		{"expiry", "Срок действия (MM/YY): "},
+		{"cvv", "CVV: "},
+		{"pin", "PIN (необязательно): "},
+		{"notes", "Заметки (необязательно): "},
+	} {
+		value := readLine(reader, field.prompt)
+		if update && value == "-" {
+			clear = append(clear, field.flag)
+			continue
+		}
+		args = append(args, "--"+field.flag, value)
+	}
+	if len(clear) > 0 {
+		args = append(args, "--clear", strings.Join(clear, ","))
+	}
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		fmt.Println("❌ Ошибка сохранения карты:", err)
+	}
+}
+
+// readLine читает строку целиком, поэтому значения могут содержать пробелы
+// (например, имя держателя карты).
+func readLine(reader *bufio.Reader, prompt string) string {
+	fmt.Print(prompt)
+	line, _ := reader.ReadString('\n')
+	return strings.TrimRight(line, "\r\n")
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	cli2 "gophKeeper/internal/client/services/lockbox/cli"
+	"strconv"
+)
+
+func binaryMenu(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader) {
+	for {
+		fmt.Println("\nФайлы:")
+		fmt.Println("1. Загрузить файл")
+		fmt.Println("2. Скачать файл")
+		fmt.Println("3. Посмотреть все")
+		fmt.Println("4. Удалить файл")
+		fmt.Println("5. Назад")
+
+		choice, err := strconv.Atoi(readLine(reader, "Введите номер команды: "))
+		if err != nil {
+			fmt.Println("Ошибка ввода: ожидается число")
+			continue
+		}
+
+		switch choice {
+		case 1:
+			cmd := lockBoxCli.UploadCommand(ctx)
+			cmd.SetArgs([]string{
+				"--name", readLine(reader, "Название: "),
+				"--file", readLine(reader, "Путь к файлу: "),
+			})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка загрузки файла:", err)
+			}
+		case 2:
+			cmd := lockBoxCli.DownloadCommand(ctx)
+			cmd.SetArgs([]string{
+				"--name", readLine(reader, "Название: "),
+				"--out", readLine(reader, "Куда сохранить: "),
+			})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка скачивания файла:", err)
+			}
+		case 3:
+			cmd := lockBoxCli.GetFilesCommand(ctx)
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка получения файлов:", err)
+			}
+		case 4:
+			cmd := lockBoxCli.DeleteFileCommand(ctx)
+			cmd.Flags().Set("name", readLine(reader, "Название: "))
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка удаления файла:", err)
+			}
+		case 5:
+			return
+		default:
+			fmt.Println("Некорректный выбор, попробуйте снова.")
+		}
+	}
+}
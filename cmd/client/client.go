@@ -3,17 +3,22 @@ package main
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"fmt"
 	"github.com/spf13/cobra"
 	"gophKeeper/internal/client/config"
 	db "gophKeeper/internal/client/db"
+	"gophKeeper/internal/client/errors"
 	cli2 "gophKeeper/internal/client/services/lockbox/cli"
 	clients2 "gophKeeper/internal/client/services/lockbox/clients"
+	"gophKeeper/internal/client/services/lockbox/models"
 	repos2 "gophKeeper/internal/client/services/lockbox/repository"
 	usecase2 "gophKeeper/internal/client/services/lockbox/usecase"
+	"gophKeeper/pkg/tlsutil"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -36,39 +41,60 @@ func main() {
 	commandLoop(lockBoxCli, ctx)
 }
 
+// newLockBoxService выбирает транспорт по настройке TRANSPORT.
+func newLockBoxService(cfg *config.Config) (clients2.LockBoxService, error) {
+	tlsConfig, err := clientTLS(cfg)
+	if err != nil {
+		return nil, err
+	}
+	switch cfg.Transport {
+	case "grpc":
+		return clients2.NewGRPCLockBoxService(cfg.PgHost, cfg.Port, cfg.GRPCAddr, tlsConfig)
+	case "http", "":
+		return clients2.NewLockBoxServiceTLS(cfg.PgHost, cfg.Port, tlsConfig), nil
+	default:
+		return nil, fmt.Errorf("unknown transport %q", cfg.Transport)
+	}
+}
+
+// clientTLS собирает настройки TLS из конфигурации. Для gRPC без CA и пинов
+// соединение остаётся открытым, как и для http:// в PG_HOST.
+func clientTLS(cfg *config.Config) (*tls.Config, error) {
+	if cfg.TLSCAFile == "" && len(cfg.TLSPins) == 0 && cfg.TLSCertFile == "" {
+		if strings.HasPrefix(cfg.PgHost, "https://") {
+			return tlsutil.ClientConfig(tlsutil.ClientOptions{})
+		}
+		return nil, nil
+	}
+	return tlsutil.ClientConfig(tlsutil.ClientOptions{
+		CAFile:   cfg.TLSCAFile,
+		Pins:     cfg.TLSPins,
+		CertFile: cfg.TLSCertFile,
+		KeyFile:  cfg.TLSKeyFile,
+	})
+}
+
 func initCLI(ctx context.Context, cfg *config.Config, db *sql.DB) *cli2.LockBoxCLI {
 	lockBoxRepository := repos2.NewSQLiteRepository(db)
-	lockBoxService := clients2.NewLockBoxService(cfg.PgHost, cfg.Port)
-	lockBoxUsecase := usecase2.NewLockboxUsecase(lockBoxService, lockBoxRepository)
+	lockBoxService, err := newLockBoxService(cfg)
+	if err != nil {
+		panic(err)
+	}
+	lockBoxService.SetNameEncryption(cfg.EncryptNames)
+	lockBoxService.SetDeviceName(cfg.DeviceName)
+	lockBoxRepository.SetKeyfunc(lockBoxService.Keyfunc())
+	lockBoxUsecase := usecase2.NewLockboxUsecase(lockBoxService, lockBoxRepository, models.ConflictPolicy(cfg.ConflictPolicy))
 	lockBoxCli := cli2.NewLockBoxCLI(lockBoxUsecase)
 
-	go func() {
-		ticker := time.NewTicker(10 * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				lockBoxUsecase.SyncUpdatesToServer(ctx)
-				//if err != nil {
-				//	fmt.Println(err)
-				//}
-				lockBoxUsecase.SyncUpdatesToLocal(ctx)
-				//if err != nil {
-				//	fmt.Println(err)
-				//}
-			case <-ctx.Done():
-				return
-			}
-
-		}
-	}()
+	go lockBoxUsecase.RunOutboxWorker(ctx)
+	go lockBoxUsecase.RunSyncLoop(ctx)
 	go func() {
 		ticker := time.NewTicker(10 * time.Minute)
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				lockBoxRepository.PurgeExpiredLocks()
+				lockBoxUsecase.PurgeTrash(cfg.TrashRetention)
 			case <-ctx.Done():
 				return
 			}
@@ -131,14 +157,60 @@ func authFlow(lockBoxCli *cli2.LockBoxCLI, ctx context.Context) bool {
 			continue
 		}
 
+		if lockBoxCli.MFARequired() {
+			mfaFlow(lockBoxCli, ctx)
+		}
 		if lockBoxCli.IsAuthenticated() {
-			return true
+			return unlockFlow(lockBoxCli, ctx, password)
 		}
 
 		fmt.Println("Не удалось авторизоваться. Попробуйте снова.")
 	}
 }
 
+// unlockFlow запрашивает мастер-пароль, из которого выводится ключ шифрования.
+// Пароль учётной записи уходит на сервер при входе без SRP, поэтому
+// мастер-паролем для нового хранилища он быть не может. Хранилищу, которое
+// раньше создали на пароле учётной записи, предлагается сменить мастер-пароль.
+func unlockFlow(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, accountPassword string) bool {
+	hasVault, err := lockBoxCli.HasVault(ctx)
+	if err != nil {
+		fmt.Println("Ошибка получения параметров хранилища:", err)
+		return false
+	}
+	if !hasVault {
+		fmt.Println("Задайте мастер-пароль: он шифрует данные и не передаётся на сервер.")
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for attempt := 0; attempt < 3; attempt++ {
+		master := readLine(reader, "Мастер-пароль: ")
+		if master == "" {
+			fmt.Println("Ошибка:", errors.ErrMasterPasswordRequired)
+			continue
+		}
+		if master == accountPassword && !hasVault {
+			fmt.Println("Ошибка:", errors.ErrMasterPasswordReused)
+			continue
+		}
+
+		cmd := lockBoxCli.NewUnlockCli(ctx)
+		cmd.SetArgs([]string{"--password", master})
+		if err := cmd.Execute(); err != nil {
+			fmt.Println("Ошибка разблокировки:", err)
+			continue
+		}
+
+		if lockBoxCli.IsUnlocked() {
+			if master == accountPassword {
+				fmt.Println("⚠️  Мастер-пароль совпадает с паролем учётной записи. Смените его: пункт «Сменить мастер-пароль».")
+			}
+			return true
+		}
+	}
+	return false
+}
+
 func commandLoop(lockBoxCli *cli2.LockBoxCLI, ctx context.Context) {
 	reader := bufio.NewReader(os.Stdin)
 
@@ -149,7 +221,19 @@ func commandLoop(lockBoxCli *cli2.LockBoxCLI, ctx context.Context) {
 		fmt.Println("3. Посмотреть все")
 		fmt.Println("4. Получить запись")
 		fmt.Println("5. Обновить запись")
-		fmt.Println("6. Выход")
+		fmt.Println("6. Банковские карты")
+		fmt.Println("7. Заметки")
+		fmt.Println("8. Файлы")
+		fmt.Println("9. Сменить мастер-пароль")
+		fmt.Println("10. Сессии и устройства")
+		fmt.Println("11. Двухфакторная аутентификация")
+		fmt.Println("12. Аккаунт")
+		fmt.Println("13. Журнал безопасности")
+		fmt.Println("14. История записей")
+		fmt.Println("15. Корзина")
+		fmt.Println("16. Конфликты правок")
+		fmt.Println("17. Синхронизация")
+		fmt.Println("18. Выход")
 		fmt.Print("Введите номер команды: ")
 
 		var choice int
@@ -160,7 +244,7 @@ func commandLoop(lockBoxCli *cli2.LockBoxCLI, ctx context.Context) {
 			continue
 		}
 
-		if choice == 6 {
+		if choice == 18 {
 			fmt.Println("Завершение работы.")
 			return
 		}
@@ -176,6 +260,37 @@ func commandLoop(lockBoxCli *cli2.LockBoxCLI, ctx context.Context) {
 			getLockBoxFlow1(lockBoxCli, ctx)
 		case 5:
 			updateLockBoxFlow4(lockBoxCli, ctx)
+		case 6:
+			bankCardMenu(lockBoxCli, ctx, reader)
+		case 7:
+			noteMenu(lockBoxCli, ctx, reader)
+		case 8:
+			binaryMenu(lockBoxCli, ctx, reader)
+		case 9:
+			rotateKeyFlow(lockBoxCli, ctx, reader)
+		case 10:
+			sessionsMenu(lockBoxCli, ctx, reader)
+		case 11:
+			totpMenu(lockBoxCli, ctx, reader)
+		case 12:
+			if !accountMenu(lockBoxCli, ctx, reader) {
+				fmt.Println("Завершение работы.")
+				return
+			}
+		case 13:
+			cmd := lockBoxCli.AuditCommand(ctx)
+			cmd.SetArgs([]string{})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка получения журнала:", err)
+			}
+		case 14:
+			historyMenu(lockBoxCli, ctx, reader)
+		case 15:
+			trashMenu(lockBoxCli, ctx, reader)
+		case 16:
+			conflictsMenu(lockBoxCli, ctx, reader)
+		case 17:
+			syncMenu(lockBoxCli, ctx, reader)
 		default:
 			fmt.Println("Некорректный выбор, попробуйте снова.")
 		}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	cli2 "gophKeeper/internal/client/services/lockbox/cli"
+	"strconv"
+)
+
+func conflictsMenu(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader) {
+	for {
+		fmt.Println("\nКонфликты правок:")
+		fmt.Println("1. Посмотреть конфликты")
+		fmt.Println("2. Разрешить конфликт")
+		fmt.Println("3. Назад")
+
+		choice, err := strconv.Atoi(readLine(reader, "Введите номер команды: "))
+		if err != nil {
+			fmt.Println("Ошибка ввода: ожидается число")
+			continue
+		}
+
+		switch choice {
+		case 1:
+			cmd := lockBoxCli.ConflictsCommand(ctx)
+			cmd.SetArgs([]string{"list"})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка получения конфликтов:", err)
+			}
+		case 2:
+			args, ok := resolveConflictArgs(reader)
+			if !ok {
+				continue
+			}
+			cmd := lockBoxCli.ConflictsCommand(ctx)
+			cmd.SetArgs(args)
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка разрешения конфликта:", err)
+			}
+		case 3:
+			return
+		default:
+			fmt.Println("Некорректный выбор, попробуйте снова.")
+		}
+	}
+}
+
+// resolveConflictArgs спрашивает, какую версию оставить; при слиянии — для
+// каждого поля отдельно.
+func resolveConflictArgs(reader *bufio.Reader) ([]string, bool) {
+	args := []string{"resolve", "--name", readLine(reader, "Название записи: ")}
+	fmt.Println("1. Оставить локальную версию")
+	fmt.Println("2. Оставить версию с сервера")
+	fmt.Println("3. Слить по полям")
+	switch readLine(reader, "Ваш выбор: ") {
+	case "1":
+		return append(args, "--keep", "local"), true
+	case "2":
+		return append(args, "--keep", "remote"), true
+	case "3":
+		args = append(args, "--keep", "remote")
+		for _, field := range []struct{ flag, title string }{
+			{"url", "URL"}, {"login", "Логин"}, {"password", "Пароль"}, {"description", "Описание"},
+		} {
+			side, ok := conflictSide(readLine(reader, field.title+" (1 — локальный, 2 — с сервера): "))
+			if !ok {
+				fmt.Println("Некорректный выбор, конфликт не изменён.")
+				return nil, false
+			}
+			args = append(args, "--"+field.flag, side)
+		}
+		return args, true
+	default:
+		fmt.Println("Некорректный выбор, попробуйте снова.")
+		return nil, false
+	}
+}
+
+func conflictSide(choice string) (string, bool) {
+	switch choice {
+	case "1":
+		return "local", true
+	case "2":
+		return "remote", true
+	default:
+		return "", false
+	}
+}
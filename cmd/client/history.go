@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	cli2 "gophKeeper/internal/client/services/lockbox/cli"
+	"strconv"
+)
+
+func historyMenu(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader) {
+	for {
+		fmt.Println("\nИстория записей:")
+		fmt.Println("1. Посмотреть версии записи")
+		fmt.Println("2. Восстановить версию")
+		fmt.Println("3. Назад")
+
+		choice, err := strconv.Atoi(readLine(reader, "Введите номер команды: "))
+		if err != nil {
+			fmt.Println("Ошибка ввода: ожидается число")
+			continue
+		}
+
+		switch choice {
+		case 1:
+			cmd := lockBoxCli.HistoryCommand(ctx)
+			cmd.SetArgs([]string{"--name", readLine(reader, "Название: ")})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка получения истории:", err)
+			}
+		case 2:
+			name := readLine(reader, "Название: ")
+			revision := readLine(reader, "Номер версии: ")
+			cmd := lockBoxCli.RestoreCommand(ctx)
+			cmd.SetArgs([]string{"--name", name, "--revision", revision})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка восстановления:", err)
+			}
+		case 3:
+			return
+		default:
+			fmt.Println("Некорректный выбор, попробуйте снова.")
+		}
+	}
+}
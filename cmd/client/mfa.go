@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	cli2 "gophKeeper/internal/client/services/lockbox/cli"
+	"os"
+	"strconv"
+)
+
+// mfaFlow запрашивает код второго фактора после принятого пароля.
+func mfaFlow(lockBoxCli *cli2.LockBoxCLI, ctx context.Context) {
+	reader := bufio.NewReader(os.Stdin)
+	for attempt := 0; attempt < 3 && lockBoxCli.MFARequired(); attempt++ {
+		cmd := lockBoxCli.NewMFACli(ctx)
+		cmd.SetArgs([]string{"--code", readLine(reader, "Код из приложения или код восстановления: ")})
+		if err := cmd.Execute(); err != nil {
+			fmt.Println("Ошибка аутентификации:", err)
+		}
+	}
+}
+
+func totpMenu(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader) {
+	for {
+		fmt.Println("\nДвухфакторная аутентификация:")
+		fmt.Println("1. Подключить приложение")
+		fmt.Println("2. Подтвердить включение")
+		fmt.Println("3. Отключить")
+		fmt.Println("4. Назад")
+
+		choice, err := strconv.Atoi(readLine(reader, "Введите номер команды: "))
+		if err != nil {
+			fmt.Println("Ошибка ввода: ожидается число")
+			continue
+		}
+
+		cmd := lockBoxCli.TOTPCommand(ctx)
+		switch choice {
+		case 1:
+			cmd.SetArgs([]string{"enable"})
+		case 2:
+			cmd.SetArgs([]string{"confirm", "--code", readLine(reader, "Код из приложения: ")})
+		case 3:
+			cmd.SetArgs([]string{"disable", "--code", readLine(reader, "Код из приложения или код восстановления: ")})
+		case 4:
+			return
+		default:
+			fmt.Println("Некорректный выбор, попробуйте снова.")
+			continue
+		}
+		if err := cmd.Execute(); err != nil {
+			fmt.Println("❌ Ошибка:", err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	cli2 "gophKeeper/internal/client/services/lockbox/cli"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+func noteMenu(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader) {
+	for {
+		fmt.Println("\nЗаметки:")
+		fmt.Println("1. Создать заметку")
+		fmt.Println("2. Удалить заметку")
+		fmt.Println("3. Посмотреть все")
+		fmt.Println("4. Получить заметку")
+		fmt.Println("5. Обновить заметку")
+		fmt.Println("6. Назад")
+
+		choice, err := strconv.Atoi(readLine(reader, "Введите номер команды: "))
+		if err != nil {
+			fmt.Println("Ошибка ввода: ожидается число")
+			continue
+		}
+
+		switch choice {
+		case 1:
+			noteFormFlow(lockBoxCli, ctx, reader, false)
+		case 2:
+			cmd := lockBoxCli.DeleteNoteCommand(ctx)
+			cmd.Flags().Set("name", readLine(reader, "Название: "))
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка удаления заметки:", err)
+			}
+		case 3:
+			cmd := lockBoxCli.GetAllNotesCommand(ctx)
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка получения заметок:", err)
+			}
+		case 4:
+			cmd := lockBoxCli.GetNoteCommand(ctx)
+			cmd.Flags().Set("name", readLine(reader, "Название: "))
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка получения заметки:", err)
+			}
+		case 5:
+			noteFormFlow(lockBoxCli, ctx, reader, true)
+		case 6:
+			return
+		default:
+			fmt.Println("Некорректный выбор, попробуйте снова.")
+		}
+	}
+}
+
+func noteFormFlow(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader, update bool) {
+	name := readLine(reader, "Название: ")
+	if name == "" {
+		fmt.Println("❌ Ошибка ввода Названия")
+		return
+	}
+
+	body, err := readMultiline(reader)
+	if err != nil {
+		fmt.Println("❌ Ошибка ввода текста заметки:", err)
+		return
+	}
+
+	cmd := lockBoxCli.CreateNoteCommand(ctx)
+	if update {
+		cmd = lockBoxCli.UpdateNoteCommand(ctx)
+	}
+	cmd.SetArgs([]string{"--name", name, "--body", body})
+	if err := cmd.Execute(); err != nil {
+		fmt.Println("❌ Ошибка сохранения заметки:", err)
+	}
+}
+
+// readMultiline открывает $EDITOR, если он задан, иначе читает строки
+// со стандартного ввода до строки, состоящей из одной точки.
+func readMultiline(reader *bufio.Reader) (string, error) {
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return readFromEditor(editor)
+	}
+
+	fmt.Println("Введите текст. Для завершения введите строку с одной точкой \".\":")
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." {
+			break
+		}
+		lines = append(lines, line)
+		if err != nil {
+			break
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func readFromEditor(editor string) (string, error) {
+	file, err := os.CreateTemp("", "gophkeeper-note-*.txt")
+	if err != nil {
+		return "", err
+	}
+	// Временный файл содержит открытый текст, поэтому удаляем его сразу после чтения.
+	defer os.Remove(file.Name())
+	file.Close()
+
+	cmd := exec.Command(editor, file.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	cli2 "gophKeeper/internal/client/services/lockbox/cli"
+	"strconv"
+)
+
+func sessionsMenu(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader) {
+	for {
+		fmt.Println("\nСессии и устройства:")
+		fmt.Println("1. Посмотреть все")
+		fmt.Println("2. Завершить сессию")
+		fmt.Println("3. Завершить все, кроме текущей")
+		fmt.Println("4. Назад")
+
+		choice, err := strconv.Atoi(readLine(reader, "Введите номер команды: "))
+		if err != nil {
+			fmt.Println("Ошибка ввода: ожидается число")
+			continue
+		}
+
+		switch choice {
+		case 1:
+			cmd := lockBoxCli.SessionsCommand(ctx)
+			cmd.SetArgs([]string{"list"})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка получения сессий:", err)
+			}
+		case 2:
+			cmd := lockBoxCli.SessionsCommand(ctx)
+			cmd.SetArgs([]string{"revoke", "--id", readLine(reader, "Id сессии: ")})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка завершения сессии:", err)
+			}
+		case 3:
+			cmd := lockBoxCli.SessionsCommand(ctx)
+			cmd.SetArgs([]string{"revoke", "--others"})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка завершения сессий:", err)
+			}
+		case 4:
+			return
+		default:
+			fmt.Println("Некорректный выбор, попробуйте снова.")
+		}
+	}
+}
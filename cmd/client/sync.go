@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	cli2 "gophKeeper/internal/client/services/lockbox/cli"
+	"strconv"
+)
+
+func syncMenu(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader) {
+	for {
+		fmt.Println("\nСинхронизация:")
+		fmt.Println("1. Состояние очереди отправки")
+		fmt.Println("2. Синхронизировать сейчас")
+		fmt.Println("3. Назад")
+
+		choice, err := strconv.Atoi(readLine(reader, "Введите номер команды: "))
+		if err != nil {
+			fmt.Println("Ошибка ввода: ожидается число")
+			continue
+		}
+
+		switch choice {
+		case 1:
+			cmd := lockBoxCli.SyncCommand(ctx)
+			cmd.SetArgs([]string{"status"})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка получения состояния синхронизации:", err)
+			}
+		case 2:
+			cmd := lockBoxCli.SyncCommand(ctx)
+			cmd.SetArgs([]string{"now"})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка синхронизации:", err)
+			}
+		case 3:
+			return
+		default:
+			fmt.Println("Некорректный выбор, попробуйте снова.")
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	cli2 "gophKeeper/internal/client/services/lockbox/cli"
+	"strconv"
+)
+
+func trashMenu(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader) {
+	for {
+		fmt.Println("\nКорзина:")
+		fmt.Println("1. Посмотреть удалённые")
+		fmt.Println("2. Восстановить запись")
+		fmt.Println("3. Очистить корзину")
+		fmt.Println("4. Назад")
+
+		choice, err := strconv.Atoi(readLine(reader, "Введите номер команды: "))
+		if err != nil {
+			fmt.Println("Ошибка ввода: ожидается число")
+			continue
+		}
+
+		switch choice {
+		case 1:
+			cmd := lockBoxCli.TrashCommand(ctx)
+			cmd.SetArgs([]string{"list"})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка получения корзины:", err)
+			}
+		case 2:
+			cmd := lockBoxCli.TrashCommand(ctx)
+			cmd.SetArgs([]string{"restore", "--id", readLine(reader, "Id записи: ")})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка восстановления:", err)
+			}
+		case 3:
+			if readLine(reader, "Записи будут удалены без возможности восстановления. Введите yes: ") != "yes" {
+				continue
+			}
+			cmd := lockBoxCli.TrashCommand(ctx)
+			cmd.SetArgs([]string{"empty", "--yes"})
+			if err := cmd.Execute(); err != nil {
+				fmt.Println("❌ Ошибка очистки корзины:", err)
+			}
+		case 4:
+			return
+		default:
+			fmt.Println("Некорректный выбор, попробуйте снова.")
+		}
+	}
+}
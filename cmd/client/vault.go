@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	cli2 "gophKeeper/internal/client/services/lockbox/cli"
+)
+
+func rotateKeyFlow(lockBoxCli *cli2.LockBoxCLI, ctx context.Context, reader *bufio.Reader) {
+	oldPassword := readLine(reader, "Текущий мастер-пароль: ")
+	newPassword := readLine(reader, "Новый мастер-пароль: ")
+	if newPassword != readLine(reader, "Повторите новый мастер-пароль: ") {
+		fmt.Println("❌ Пароли не совпадают")
+		return
+	}
+
+	cmd := lockBoxCli.RotateKeyCommand(ctx)
+	cmd.SetArgs([]string{"--old-password", oldPassword, "--new-password", newPassword})
+	if err := cmd.Execute(); err != nil {
+		fmt.Println("❌ Ошибка смены ключа:", err)
+	}
+}
@@ -2,20 +2,41 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"gophKeeper/internal/server/config"
+	grpc1 "gophKeeper/internal/server/controller/grpc/v1"
 	v2 "gophKeeper/internal/server/controller/http/v1"
 	"gophKeeper/internal/server/db"
 	"gophKeeper/internal/server/middleware"
+	repository8 "gophKeeper/internal/server/services/admin/repository"
+	usecase8 "gophKeeper/internal/server/services/admin/usecase"
+	repository9 "gophKeeper/internal/server/services/audit/repository"
+	usecase9 "gophKeeper/internal/server/services/audit/usecase"
+	"gophKeeper/internal/server/services/auth/attempts"
 	repository1 "gophKeeper/internal/server/services/auth/repository"
 	usecase1 "gophKeeper/internal/server/services/auth/usecase"
+	repository4 "gophKeeper/internal/server/services/bankcard/repository"
+	usecase4 "gophKeeper/internal/server/services/bankcard/usecase"
+	repository6 "gophKeeper/internal/server/services/binary/repository"
+	"gophKeeper/internal/server/services/binary/storage"
+	usecase6 "gophKeeper/internal/server/services/binary/usecase"
+	"gophKeeper/internal/server/services/events"
 	repository3 "gophKeeper/internal/server/services/lockbox/repository"
 	usecase3 "gophKeeper/internal/server/services/lockbox/usecase"
+	repository5 "gophKeeper/internal/server/services/note/repository"
+	usecase5 "gophKeeper/internal/server/services/note/usecase"
 	repository2 "gophKeeper/internal/server/services/users/repository"
 	usecase2 "gophKeeper/internal/server/services/users/usecase"
+	repository7 "gophKeeper/internal/server/services/vault/repository"
+	usecase7 "gophKeeper/internal/server/services/vault/usecase"
+	"gophKeeper/pkg/tlsutil"
+	"net"
 	"net/http"
 
 	"os"
@@ -47,14 +68,35 @@ func main() {
 
 	mware := middleware.NewMiddlewareService(cfg, database)
 
+	auditRepos := repository9.NewAuditRepo(database)
+	auditUsecase := usecase9.NewAuditUsecase(auditRepos)
+
 	authRepos := repository1.NewAuthRepository(database)
-	authUsecase := usecase1.NewAuthUsecase(authRepos)
+	loginGuard := attempts.NewGuard(attempts.NewStore(cfg.App.Login, database), attempts.NewPolicy(cfg.App.Login))
+	authUsecase := usecase1.NewAuthUsecase(authRepos, loginGuard, auditUsecase, cfg.App.Login.LegacyPassword)
 
-	userRepos := repository2.NewUserRepository(database)
-	userUsecase := usecase2.NewUserUsecase(userRepos)
+	lockBoxRepos := repository3.NewLockBoxRepo(database, cfg.App.History, cfg.App.Trash)
+	lockBoxUsecase := usecase3.NewLockBoxUsecase(lockBoxRepos, auditUsecase)
+
+	bankCardRepos := repository4.NewBankCardRepo(database)
+	bankCardUsecase := usecase4.NewBankCardUsecase(bankCardRepos)
+
+	noteRepos := repository5.NewNoteRepo(database)
+	noteUsecase := usecase5.NewNoteUsecase(noteRepos)
 
-	lockBoxRepos := repository3.NewLockBoxRepo(database)
-	lockBoxUsecase := usecase3.NewLockBoxUsecase(lockBoxRepos)
+	blobStore, err := storage.NewLocalStore(cfg.App.BlobDir)
+	if err != nil {
+		logger.Error("Error blob storage: " + err.Error())
+		return
+	}
+	userRepos := repository2.NewUserRepository(database)
+	userUsecase := usecase2.NewUserUsecase(userRepos, authUsecase, blobStore)
+	binaryRepos := repository6.NewBinaryRepo(database)
+	binaryUsecase := usecase6.NewBinaryUsecase(binaryRepos, blobStore)
+	vaultRepos := repository7.NewVaultRepo(database)
+	vaultUsecase := usecase7.NewVaultUsecase(vaultRepos, blobStore)
+	adminRepos := repository8.NewAdminRepo(database)
+	adminUsecase := usecase8.NewAdminUsecase(adminRepos, auditUsecase)
 
 	router := gin.Default()
 
@@ -72,6 +114,9 @@ func main() {
 			select {
 			case <-ticker.C:
 				lockBoxRepos.PurgeExpiredLocks(ctx)
+				lockBoxRepos.PurgeRevisions(ctx)
+				lockBoxRepos.PurgeTombstones(ctx)
+				lockBoxRepos.PurgeIdempotencyKeys(ctx)
 			case <-ctx.Done():
 				return
 			}
@@ -79,12 +124,33 @@ func main() {
 		}
 	}()
 
+	eventsHub := events.NewHub()
+	go events.Listen(ctx, database.GetDB(), eventsHub)
+
+	middleware.StartLimiterJanitor(ctx)
+	go loginGuard.RunJanitor(ctx, time.Hour)
+	go authUsecase.RunJanitor(ctx, time.Minute)
+
 	router.Use(cors.New(corsConfig))
 	api := router.Group("/api")
 	{
 		v2.NewAuthHandler(cfg, api, authUsecase, mware)
 		v2.NewLockBoxHandlerHandler(cfg, api, lockBoxUsecase, mware)
+		v2.NewSyncHandler(cfg, api, lockBoxUsecase, mware)
+		v2.NewEventsHandler(cfg, api, eventsHub, mware)
+		v2.NewBankCardHandler(cfg, api, bankCardUsecase, mware)
+		v2.NewNoteHandler(cfg, api, noteUsecase, mware)
+		v2.NewBinaryHandler(cfg, api, binaryUsecase, mware)
+		v2.NewVaultHandler(cfg, api, vaultUsecase, mware)
 		v2.NewUserHandler(cfg, api, userUsecase, mware)
+		v2.NewAdminHandler(cfg, api, adminUsecase, mware)
+		v2.NewAuditHandler(cfg, api, auditUsecase, mware)
+	}
+
+	tlsConfig, err := serverTLS(cfg, logger)
+	if err != nil {
+		logger.Error("Error TLS: " + err.Error())
+		return
 	}
 
 	srv := &http.Server{
@@ -93,12 +159,36 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 		Addr:         cfg.App.Host + ":" + cfg.App.Port,
 		Handler:      router,
+		TLSConfig:    tlsConfig,
 	}
+	srv.RegisterOnShutdown(eventsHub.Close)
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsConfig != nil {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Fatal("Server error", zap.Error(err))
 		}
 	}()
+
+	var grpcOpts []grpc.ServerOption
+	if tlsConfig != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpc1.NewServer(mware, authUsecase, userUsecase, lockBoxUsecase, grpcOpts...)
+	go func() {
+		listener, err := net.Listen("tcp", cfg.App.Host+":"+cfg.App.GRPCPort)
+		if err != nil {
+			logger.Fatal("gRPC listen error", zap.Error(err))
+		}
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.Fatal("gRPC server error", zap.Error(err))
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -107,5 +197,28 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Error("Server shutdown error", zap.Error(err))
 	}
+	grpcServer.GracefulStop()
+
+}
 
+// serverTLS возвращает конфигурацию TLS или nil, если сертификат не задан.
+// Для самоподписанного сертификата в лог пишется пин, который нужно указать
+// клиенту в TLS_PINS.
+func serverTLS(cfg *config.Config, logger *zap.Logger) (*tls.Config, error) {
+	conf := cfg.App.TLS
+	if !conf.Enabled() {
+		logger.Warn("TLS is disabled, serving plaintext")
+		return nil, nil
+	}
+	if conf.SelfSigned {
+		if err := tlsutil.EnsureSelfSigned(conf.CertFile, conf.KeyFile, []string{"localhost", "127.0.0.1", cfg.App.Host}); err != nil {
+			return nil, err
+		}
+		pin, err := tlsutil.CertFilePin(conf.CertFile)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Self-signed certificate", zap.String("cert", conf.CertFile), zap.String("pin", pin))
+	}
+	return tlsutil.ServerConfig(conf.CertFile, conf.KeyFile, conf.ClientCA)
 }
@@ -3,11 +3,58 @@ package config
 import (
 	"github.com/joho/godotenv"
 	"os"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Port   string
 	PgHost string
+	// EncryptNames скрывает от сервера имена записей (ENCRYPT_NAMES=true).
+	EncryptNames bool
+	// Transport — протокол обмена с сервером: http или grpc.
+	Transport string
+	GRPCAddr  string
+	// TLS — доверие к серверу: свой CA, пины ключа сервера (base64 SHA-256
+	// SubjectPublicKeyInfo через запятую) и сертификат клиента для mTLS.
+	TLSCAFile   string
+	TLSPins     []string
+	TLSCertFile string
+	TLSKeyFile  string
+	// DeviceName — имя устройства в списке сессий аккаунта, по умолчанию имя хоста.
+	DeviceName string
+	// TrashRetention — сколько удалённые записи хранятся в локальной базе.
+	// Нулевое значение оставляет их до явной очистки корзины.
+	TrashRetention time.Duration
+	// ConflictPolicy — как разрешать конфликты правок с разных устройств:
+	// lww, keep-both или manual.
+	ConflictPolicy string
+}
+
+func getList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// deviceName возвращает DEVICE_NAME, а если оно не задано — имя хоста.
+func deviceName() string {
+	if name := getEnv("DEVICE_NAME", ""); name != "" {
+		return name
+	}
+	name, _ := os.Hostname()
+	return name
+}
+
+func getDuration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(getEnv(key, "")); err == nil {
+		return d
+	}
+	return def
 }
 
 func getEnv(key, def string) string {
@@ -19,7 +66,17 @@ func getEnv(key, def string) string {
 func New() *Config {
 	_ = godotenv.Load(".env.client")
 	return &Config{
-		Port:   getEnv("HTTP_PORT", "8090"),
-		PgHost: getEnv("PG_HOST", "http://localhost"),
+		Port:           getEnv("HTTP_PORT", "8090"),
+		PgHost:         getEnv("PG_HOST", "http://localhost"),
+		EncryptNames:   getEnv("ENCRYPT_NAMES", "false") == "true",
+		Transport:      getEnv("TRANSPORT", "http"),
+		GRPCAddr:       getEnv("GRPC_ADDR", "localhost:9090"),
+		TLSCAFile:      getEnv("TLS_CA_FILE", ""),
+		TLSPins:        getList("TLS_PINS"),
+		TLSCertFile:    getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:     getEnv("TLS_KEY_FILE", ""),
+		DeviceName:     deviceName(),
+		TrashRetention: getDuration("LOCKBOX_TRASH_RETENTION", 30*24*time.Hour),
+		ConflictPolicy: getEnv("LOCKBOX_CONFLICT_POLICY", "keep-both"),
 	}
 }
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS key_rotation (
+    user_id INTEGER PRIMARY KEY,
+    old_kdf TEXT NOT NULL,
+    new_kdf TEXT NOT NULL,
+    committed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS key_rotation;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- dirty — число локальных правок записи, ещё не отправленных на сервер.
+-- Записи, сохранённые до разностной синхронизации, отправляются один раз.
+ALTER TABLE lockbox ADD COLUMN dirty INTEGER NOT NULL DEFAULT 1;
+
+-- Курсор ленты изменений сервера: номер последнего полученного изменения.
+CREATE TABLE IF NOT EXISTS sync_cursor (
+    user_id INTEGER PRIMARY KEY,
+    cursor INTEGER NOT NULL DEFAULT 0
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS sync_cursor;
+ALTER TABLE lockbox DROP COLUMN dirty;
+-- +goose StatementEnd
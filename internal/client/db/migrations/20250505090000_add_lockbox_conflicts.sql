@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- revision — версия записи на сервере, от которой начата локальная правка.
+ALTER TABLE lockbox ADD COLUMN revision INTEGER NOT NULL DEFAULT 0;
+
+-- Отложенные конфликты: версия сервера, которую не учла локальная правка.
+-- Поля зашифрованы так же, как в lockbox.
+CREATE TABLE IF NOT EXISTS lockbox_conflicts (
+    name TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    deleted INTEGER NOT NULL DEFAULT 0,
+    revision INTEGER NOT NULL DEFAULT 0,
+    username TEXT,
+    url TEXT,
+    password TEXT,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (name, user_id)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS lockbox_conflicts;
+ALTER TABLE lockbox DROP COLUMN revision;
+-- +goose StatementEnd
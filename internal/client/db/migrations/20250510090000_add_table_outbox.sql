@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Очередь операций над записями, ещё не принятых сервером. Содержимое
+-- берётся из lockbox при отправке; ключ идемпотентности не даёт серверу
+-- применить повтор операции дважды. next_attempt_at пустое — отправлять
+-- сразу, иначе не раньше этого времени.
+CREATE TABLE IF NOT EXISTS outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    op TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL UNIQUE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE INDEX IF NOT EXISTS idx_outbox_user_name ON outbox (user_id, name);
+
+-- dirty теперь — число операций записи в очереди. Неотправленные правки
+-- становятся одной операцией.
+INSERT INTO outbox (user_id, name, op, idempotency_key)
+SELECT user_id, name, CASE WHEN deleted_at IS NULL THEN 'update' ELSE 'delete' END, lower(hex(randomblob(16)))
+FROM lockbox WHERE dirty > 0;
+
+UPDATE lockbox SET dirty = 1 WHERE dirty > 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
@@ -20,4 +20,30 @@ var (
 	ErrInvalidTokenClaims          = errors.New("invalid token claims")
 	ErrUserIdNotFoundInToken       = errors.New("user id not found in token")
 	ErrLockboxNameTakenByUser      = errors.New("unique_lockbox_name_per_user")
+	ErrCardNumberRequired          = errors.New("card number is required")
+	ErrInvalidCardNumber           = errors.New("invalid card number")
+	ErrInvalidCardExpiry           = errors.New("card expiry must be in MM/YY format")
+	ErrCardExpired                 = errors.New("card is expired")
+	ErrInvalidCVV                  = errors.New("cvv must be 3 or 4 digits")
+	ErrInvalidPIN                  = errors.New("pin must be 4 to 12 digits")
+	ErrCardExists                  = errors.New("bank card with this name already exists")
+	ErrUnknownCardField            = errors.New("unknown bank card field")
+	ErrNoteBodyRequired            = errors.New("note body is required")
+	ErrNoteExists                  = errors.New("note with this name already exists")
+	ErrFilePathRequired            = errors.New("file path is required")
+	ErrBinaryCorrupted             = errors.New("downloaded file does not match its metadata")
+	ErrMasterPasswordRequired      = errors.New("master password is required")
+	ErrWrongMasterPassword         = errors.New("wrong master password")
+	ErrMasterPasswordReused        = errors.New("master password must differ from the account password")
+	ErrKDFAlreadySet               = errors.New("key derivation parameters are already set")
+	ErrRotationConflict            = errors.New("vault changed during key rotation, run rotate-key again")
+	ErrRotationForeign             = errors.New("vault key was changed by another device, abort the pending rotation")
+	ErrSRPNotSet                   = errors.New("account has no srp verifier yet")
+	ErrServerProof                 = errors.New("server failed to prove knowledge of the srp verifier")
+	ErrMFARequired                 = errors.New("two-factor authentication code is required")
+	ErrInvalidMFACode              = errors.New("invalid or expired two-factor authentication code")
+	ErrUsernameTaken               = errors.New("username is already taken")
+	ErrAccountDisabled             = errors.New("account is disabled by an administrator")
+	ErrInvalidConflictSide         = errors.New("conflict side must be local or remote")
+	ErrLockBoxChanged              = errors.New("lockbox was changed on another device since it was read")
 )
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// AccountCommand объединяет команды управления аккаунтом: account show,
+// account password, account rename и account delete.
+func (cli *LockBoxCLI) AccountCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "account",
+		Short: "Manage the signed in account",
+	}
+	cmd.AddCommand(cli.ShowAccountCommand(ctx), cli.ChangePasswordCommand(ctx),
+		cli.RenameAccountCommand(ctx), cli.DeleteAccountCommand(ctx))
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) ShowAccountCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the signed in account",
+		Run: func(cmd *cobra.Command, args []string) {
+			account, err := cli.lockBoxUC.GetAccount(ctx)
+			if err != nil {
+				fmt.Println("❌ Ошибка получения аккаунта:", err)
+				return
+			}
+			fmt.Printf("👤 Пользователь: %s (id %d, %s)\n", account.Username, account.UserID, account.UserType)
+		},
+	}
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) ChangePasswordCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "password",
+		Short: "Change the account password",
+		Run: func(cmd *cobra.Command, args []string) {
+			oldPassword, _ := cmd.Flags().GetString("old-password")
+			newPassword, _ := cmd.Flags().GetString("new-password")
+			if oldPassword == "" || newPassword == "" {
+				fmt.Println("❌ Ошибка: укажите old-password и new-password")
+				return
+			}
+
+			err := cli.lockBoxUC.ChangePassword(ctx, oldPassword, newPassword, func(done, total int, item string) {
+				fmt.Printf("🔄 [%d/%d] %s\n", done, total, item)
+			})
+			if err != nil {
+				fmt.Println("❌ Ошибка смены пароля:", err)
+				return
+			}
+			fmt.Println("✅ Пароль изменён, вход на других устройствах завершён")
+		},
+	}
+
+	cmd.Flags().String("old-password", "", "Текущий пароль (обязательно)")
+	cmd.Flags().String("new-password", "", "Новый пароль (обязательно)")
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) RenameAccountCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename",
+		Short: "Change the account username",
+		Run: func(cmd *cobra.Command, args []string) {
+			password, _ := cmd.Flags().GetString("password")
+			username, _ := cmd.Flags().GetString("username")
+			if password == "" || username == "" {
+				fmt.Println("❌ Ошибка: укажите password и username")
+				return
+			}
+
+			if err := cli.lockBoxUC.RenameAccount(ctx, password, username); err != nil {
+				fmt.Println("❌ Ошибка смены имени:", err)
+				return
+			}
+			fmt.Printf("✅ Имя изменено, входите как %s\n", username)
+		},
+	}
+
+	cmd.Flags().String("password", "", "Текущий пароль (обязательно)")
+	cmd.Flags().String("username", "", "Новое имя пользователя (обязательно)")
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) DeleteAccountCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete the account with all its data",
+		Run: func(cmd *cobra.Command, args []string) {
+			password, _ := cmd.Flags().GetString("password")
+			yes, _ := cmd.Flags().GetBool("yes")
+			if password == "" {
+				fmt.Println("❌ Ошибка: укажите password")
+				return
+			}
+			if !yes {
+				fmt.Println("❌ Удаление необратимо: подтвердите его флагом --yes")
+				return
+			}
+
+			if err := cli.lockBoxUC.DeleteAccount(ctx, password); err != nil {
+				fmt.Println("❌ Ошибка удаления аккаунта:", err)
+				return
+			}
+			fmt.Println("✅ Аккаунт и все его данные удалены")
+		},
+	}
+
+	cmd.Flags().String("password", "", "Текущий пароль (обязательно)")
+	cmd.Flags().Bool("yes", false, "Подтвердить удаление")
+
+	return cmd
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// AuditCommand показывает журнал аудита аккаунта: входы, выдачу и отзыв
+// токенов, обращения к записям и действия администратора.
+func (cli *LockBoxCLI) AuditCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show security events of the account",
+		Run: func(cmd *cobra.Command, args []string) {
+			limit, _ := cmd.Flags().GetInt("limit")
+			events, err := cli.lockBoxUC.GetAuditEvents(ctx, limit)
+			if err != nil {
+				fmt.Println("❌ Ошибка получения журнала:", err)
+				return
+			}
+
+			if len(events) == 0 {
+				fmt.Println("🔍 Журнал пуст.")
+				return
+			}
+
+			fmt.Println("\n🛡  Журнал безопасности:")
+			fmt.Println("──────────────────────────────────────────────────────────────────────")
+			for _, event := range events {
+				result := "✅"
+				if !event.Success {
+					result = "❌"
+				}
+				line := fmt.Sprintf("%s %s %-18s", event.CreatedAt.Format("2006-01-02 15:04:05"), result, event.Action)
+				if event.Target != "" {
+					line += " " + event.Target
+				}
+				if event.IP != "" {
+					line += " с " + event.IP
+				}
+				if event.ActorId != 0 {
+					line += fmt.Sprintf(" (администратор id %d)", event.ActorId)
+				}
+				fmt.Println(line)
+			}
+		},
+	}
+
+	cmd.Flags().Int("limit", 50, "Сколько последних событий показать")
+
+	return cmd
+}
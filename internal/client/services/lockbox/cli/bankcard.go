@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"gophKeeper/internal/client/services/lockbox/models"
+
+	"github.com/spf13/cobra"
+)
+
+func (cli *LockBoxCLI) CreateCardCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "createCard",
+		Short: "Create a new bank card",
+		Run: func(cmd *cobra.Command, args []string) {
+			input := bankCardInputFromFlags(cmd)
+
+			if _, err := cli.lockBoxUC.CreateBankCard(ctx, &input); err != nil {
+				fmt.Println("❌ Ошибка создания карты:", err)
+				return
+			}
+
+			fmt.Println("✅ Карта успешно сохранена!")
+		},
+	}
+	addBankCardFlags(cmd)
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) GetCardCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "getCard",
+		Short: "Get a bank card by name",
+		Run: func(cmd *cobra.Command, args []string) {
+			name, err := cmd.Flags().GetString("name")
+			if err != nil || name == "" {
+				fmt.Println("❌ Ошибка: не указан параметр name")
+				return
+			}
+
+			card, err := cli.lockBoxUC.GetBankCard(ctx, name)
+			if err != nil || card == nil {
+				fmt.Println("❌ Ошибка, карта не найдена")
+				return
+			}
+			fmt.Println("\n✅ Карта найдена!")
+			fmt.Println("──────────────────────────────────────────────")
+			fmt.Printf("🔹 Название:     %s\n", card.Name)
+			fmt.Printf("💳 Номер:        %s\n", card.Number)
+			fmt.Printf("👤 Держатель:    %s\n", card.Holder)
+			fmt.Printf("📆 Срок:         %s\n", card.Expiry)
+			fmt.Printf("🔒 CVV:          %s\n", card.CVV)
+			fmt.Printf("🔑 PIN:          %s\n", card.PIN)
+			fmt.Printf("📝 Заметки:      %s\n", card.Notes)
+			fmt.Printf("📅 Дата создания:%s\n", card.CreatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Printf("♻️  Обновлено:   %s\n", card.UpdatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Println("──────────────────────────────────────────────")
+		},
+	}
+
+	cmd.Flags().String("name", "", "Card name (обязательно)")
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) GetAllCardsCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "getCards",
+		Short: "Get all bank cards",
+		Run: func(cmd *cobra.Command, args []string) {
+			cards, err := cli.lockBoxUC.GetBankCards(ctx)
+			if err != nil {
+				fmt.Println("❌ Ошибка в получении карт:", err)
+				return
+			}
+
+			if len(*cards) == 0 {
+				fmt.Println("🔍 Нет сохранённых карт.")
+				return
+			}
+
+			fmt.Println("\n💳 Список карт:")
+			fmt.Println("──────────────────────────────────────────────────────────────────────")
+			for i, card := range *cards {
+				fmt.Printf("[%d] 🔹 Название:  %s\n", i+1, card.Name)
+				fmt.Printf("    💳 Номер:     %s\n", maskCardNumber(card.Number))
+				fmt.Printf("    👤 Держатель: %s\n", card.Holder)
+				fmt.Printf("    📆 Срок:      %s\n", card.Expiry)
+				fmt.Println("──────────────────────────────────────────────────────────────────────")
+			}
+		},
+	}
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) UpdateCardCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "updateCard",
+		Short: "Update a bank card",
+		Run: func(cmd *cobra.Command, args []string) {
+			input := bankCardInputFromFlags(cmd)
+			clear, _ := cmd.Flags().GetStringSlice("clear")
+
+			if err := cli.lockBoxUC.UpdateBankCard(ctx, &input, clear); err != nil {
+				fmt.Println("❌ Ошибка в обновлении карты:", err)
+				return
+			}
+
+			fmt.Println("✅ Карта успешно обновлена!")
+		},
+	}
+	addBankCardFlags(cmd)
+	cmd.Flags().StringSlice("clear", nil, "Очистить поля: holder, expiry, cvv, pin, notes")
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) DeleteCardCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deleteCard",
+		Short: "Delete a bank card",
+		Run: func(cmd *cobra.Command, args []string) {
+			name, _ := cmd.Flags().GetString("name")
+			if name == "" {
+				fmt.Println("❌ Ошибка: имя карты обязательно")
+				return
+			}
+
+			if err := cli.lockBoxUC.DeleteBankCard(ctx, name); err != nil {
+				fmt.Println("❌ Ошибка удаления карты")
+				return
+			}
+			fmt.Println("✅ Карта успешно удалена!")
+		},
+	}
+
+	cmd.Flags().String("name", "", "Название карты (обязательно)")
+
+	return cmd
+}
+
+func addBankCardFlags(cmd *cobra.Command) {
+	cmd.Flags().String("name", "", "Название карты (обязательно)")
+	cmd.Flags().String("number", "", "Card number")
+	cmd.Flags().String("holder", "", "Card holder")
+	cmd.Flags().String("expiry", "", "Expiry date MM/YY")
+	cmd.Flags().String("cvv", "", "CVV")
+	cmd.Flags().String("pin", "", "PIN (необязательно)")
+	cmd.Flags().String("notes", "", "Billing notes (необязательно)")
+}
+
+func bankCardInputFromFlags(cmd *cobra.Command) models.BankCardInput {
+	name, _ := cmd.Flags().GetString("name")
+	number, _ := cmd.Flags().GetString("number")
+	holder, _ := cmd.Flags().GetString("holder")
+	expiry, _ := cmd.Flags().GetString("expiry")
+	cvv, _ := cmd.Flags().GetString("cvv")
+	pin, _ := cmd.Flags().GetString("pin")
+	notes, _ := cmd.Flags().GetString("notes")
+
+	return models.BankCardInput{
+		Name:   name,
+		Number: number,
+		Holder: holder,
+		Expiry: expiry,
+		CVV:    cvv,
+		PIN:    pin,
+		Notes:  notes,
+	}
+}
+
+// maskCardNumber оставляет видимыми только последние четыре цифры.
+func maskCardNumber(number string) string {
+	if len(number) <= 4 {
+		return number
+	}
+	return "**** " + number[len(number)-4:]
+}
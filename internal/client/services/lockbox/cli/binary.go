@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func (cli *LockBoxCLI) UploadCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upload",
+		Short: "Upload a binary file",
+		Run: func(cmd *cobra.Command, args []string) {
+			name, _ := cmd.Flags().GetString("name")
+			file, _ := cmd.Flags().GetString("file")
+			if name == "" || file == "" {
+				fmt.Println("❌ Ошибка: укажите name и file")
+				return
+			}
+
+			if err := cli.lockBoxUC.UploadFile(ctx, name, file); err != nil {
+				fmt.Println("❌ Ошибка загрузки файла:", err)
+				return
+			}
+			fmt.Println("✅ Файл успешно загружен!")
+		},
+	}
+
+	cmd.Flags().String("name", "", "Название файла в хранилище (обязательно)")
+	cmd.Flags().String("file", "", "Путь к файлу (обязательно)")
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) DownloadCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "download",
+		Short: "Download a binary file",
+		Run: func(cmd *cobra.Command, args []string) {
+			name, _ := cmd.Flags().GetString("name")
+			out, _ := cmd.Flags().GetString("out")
+			if name == "" || out == "" {
+				fmt.Println("❌ Ошибка: укажите name и out")
+				return
+			}
+
+			if err := cli.lockBoxUC.DownloadFile(ctx, name, out); err != nil {
+				fmt.Println("❌ Ошибка скачивания файла:", err)
+				return
+			}
+			fmt.Println("✅ Файл сохранён в", out)
+		},
+	}
+
+	cmd.Flags().String("name", "", "Название файла в хранилище (обязательно)")
+	cmd.Flags().String("out", "", "Куда сохранить файл (обязательно)")
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) GetFilesCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "getFiles",
+		Short: "List binary files",
+		Run: func(cmd *cobra.Command, args []string) {
+			files, err := cli.lockBoxUC.GetFiles(ctx)
+			if err != nil {
+				fmt.Println("❌ Ошибка в получении файлов:", err)
+				return
+			}
+
+			if len(*files) == 0 {
+				fmt.Println("🔍 Нет сохранённых файлов.")
+				return
+			}
+
+			fmt.Println("\n📁 Список файлов:")
+			fmt.Println("──────────────────────────────────────────────────────────────────────")
+			for i, file := range *files {
+				fmt.Printf("[%d] 🔹 Название:  %s\n", i+1, file.Name)
+				fmt.Printf("    📦 Размер:    %d байт\n", file.Size)
+				fmt.Printf("    ♻️  Обновлено: %s\n", file.UpdatedAt.Format("2006-01-02 15:04:05"))
+				fmt.Println("──────────────────────────────────────────────────────────────────────")
+			}
+		},
+	}
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) DeleteFileCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deleteFile",
+		Short: "Delete a binary file",
+		Run: func(cmd *cobra.Command, args []string) {
+			name, _ := cmd.Flags().GetString("name")
+			if name == "" {
+				fmt.Println("❌ Ошибка: имя файла обязательно")
+				return
+			}
+
+			if err := cli.lockBoxUC.DeleteFile(ctx, name); err != nil {
+				fmt.Println("❌ Ошибка удаления файла")
+				return
+			}
+			fmt.Println("✅ Файл успешно удалён!")
+		},
+	}
+
+	cmd.Flags().String("name", "", "Название файла (обязательно)")
+
+	return cmd
+}
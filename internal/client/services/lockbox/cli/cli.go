@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	errors "gophKeeper/internal/client/errors"
 	"gophKeeper/internal/client/services/lockbox/models"
 	"gophKeeper/internal/client/services/lockbox/usecase"
 
@@ -11,6 +12,8 @@ import (
 
 type LockBoxCLI struct {
 	lockBoxUC usecase.ILockBoxUsecase
+	// mfaRequired — пароль принят, вход ждёт код второго фактора.
+	mfaRequired bool
 }
 
 func NewLockBoxCLI(lockBoxUC usecase.ILockBoxUsecase) *LockBoxCLI {
@@ -191,6 +194,23 @@ func (cli *LockBoxCLI) RegisterCommands(ctx context.Context, root *cobra.Command
 		cli.GetCommand(ctx),
 		cli.UpdateCommand(ctx),
 		cli.GetAllCommand(ctx),
+		cli.HistoryCommand(ctx),
+		cli.RestoreCommand(ctx),
+		cli.CreateCardCommand(ctx),
+		cli.GetCardCommand(ctx),
+		cli.GetAllCardsCommand(ctx),
+		cli.UpdateCardCommand(ctx),
+		cli.DeleteCardCommand(ctx),
+		cli.CreateNoteCommand(ctx),
+		cli.GetNoteCommand(ctx),
+		cli.GetAllNotesCommand(ctx),
+		cli.UpdateNoteCommand(ctx),
+		cli.DeleteNoteCommand(ctx),
+		cli.UploadCommand(ctx),
+		cli.DownloadCommand(ctx),
+		cli.GetFilesCommand(ctx),
+		cli.DeleteFileCommand(ctx),
+		cli.RotateKeyCommand(ctx),
 	)
 }
 func (cli *LockBoxCLI) NewRegisterCli(ctx context.Context) *cobra.Command {
@@ -244,7 +264,13 @@ func (cli *LockBoxCLI) NewAuthCli(ctx context.Context) *cobra.Command {
 				return
 			}
 
-			if err := cli.lockBoxUC.Authenticate(ctx, username, password); err != nil {
+			err = cli.lockBoxUC.Authenticate(ctx, username, password)
+			cli.mfaRequired = err == errors.ErrMFARequired
+			if cli.mfaRequired {
+				fmt.Println("🔐 Пароль принят, введите код двухфакторной аутентификации")
+				return
+			}
+			if err != nil {
 				fmt.Println("❌ Ошибка:", err)
 				return
 			}
@@ -264,3 +290,43 @@ func (cli *LockBoxCLI) NewAuthCli(ctx context.Context) *cobra.Command {
 func (cli *LockBoxCLI) IsAuthenticated() bool {
 	return cli.lockBoxUC.IsAuthenticated()
 }
+
+// MFARequired сообщает, что вход ждёт код второго фактора (команда mfa).
+func (cli *LockBoxCLI) MFARequired() bool {
+	return cli.mfaRequired
+}
+
+func (cli *LockBoxCLI) NewUnlockCli(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Unlock the vault with the master password",
+		Run: func(cmd *cobra.Command, args []string) {
+			password, err := cmd.Flags().GetString("password")
+			if err != nil || password == "" {
+				fmt.Println("❌ Ошибка: Укажите мастер-пароль")
+				return
+			}
+
+			if err := cli.lockBoxUC.Unlock(ctx, password); err != nil {
+				fmt.Println("❌ Ошибка:", err)
+				return
+			}
+
+			fmt.Println("✅ Хранилище разблокировано")
+		},
+	}
+
+	cmd.Flags().String("password", "", "Master password (обязательно)")
+	cmd.MarkFlagRequired("password")
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) IsUnlocked() bool {
+	return cli.lockBoxUC.IsUnlocked()
+}
+
+// HasVault — задан ли уже мастер-пароль аккаунта.
+func (cli *LockBoxCLI) HasVault(ctx context.Context) (bool, error) {
+	return cli.lockBoxUC.HasVault(ctx)
+}
@@ -39,7 +39,7 @@ func TestCreateCommand(t *testing.T) {
 	output := captureOutput(func() {
 		cmd.Run(cmd, []string{})
 	})
-	if !strings.Contains(output, "✅ LockBox успешно создан с ID:") {
+	if !strings.Contains(output, "✅ LockBox успешно создан:") {
 		t.Errorf("Ожидался успешный вывод, получено: %s", output)
 	}
 }
@@ -176,3 +176,418 @@ func TestIsAuthenticated(t *testing.T) {
 		t.Errorf("Ожидалось, что IsAuthenticated вернёт true")
 	}
 }
+
+func TestNewUnlockCli(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+	cmd := cliObj.NewUnlockCli(ctx)
+
+	output := captureOutput(func() {
+		cmd.Run(cmd, []string{})
+	})
+	if !strings.Contains(output, "❌ Ошибка: Укажите мастер-пароль") {
+		t.Errorf("Ожидалась ошибка отсутствия мастер-пароля, получено: %s", output)
+	}
+
+	cmd.Flags().Set("password", "master")
+	output = captureOutput(func() {
+		cmd.Run(cmd, []string{})
+	})
+	if !strings.Contains(output, "✅ Хранилище разблокировано") {
+		t.Errorf("Ожидался вывод успешной разблокировки, получено: %s", output)
+	}
+}
+
+func TestRotateKeyCommand(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+	cmd := cliObj.RotateKeyCommand(ctx)
+
+	output := captureOutput(func() {
+		cmd.Run(cmd, []string{})
+	})
+	if !strings.Contains(output, "❌ Ошибка: укажите old-password и new-password") {
+		t.Errorf("Ожидалась ошибка отсутствия паролей, получено: %s", output)
+	}
+
+	cmd.Flags().Set("old-password", "old")
+	cmd.Flags().Set("new-password", "new")
+	output = captureOutput(func() {
+		cmd.Run(cmd, []string{})
+	})
+	if !strings.Contains(output, "[1/1] box1") || !strings.Contains(output, "✅ Хранилище перешифровано новым ключом") {
+		t.Errorf("Ожидался прогресс и успешное завершение, получено: %s", output)
+	}
+}
+
+func TestCardCommands(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+
+	create := cliObj.CreateCardCommand(ctx)
+	create.Flags().Set("name", "visa")
+	create.Flags().Set("number", "4111111111111111")
+	output := captureOutput(func() {
+		create.Run(create, []string{})
+	})
+	if !strings.Contains(output, "✅ Карта успешно сохранена!") {
+		t.Errorf("Ожидался вывод успешного создания карты, получено: %s", output)
+	}
+
+	list := cliObj.GetAllCardsCommand(ctx)
+	output = captureOutput(func() {
+		list.Run(list, []string{})
+	})
+	if !strings.Contains(output, "**** 1111") || strings.Contains(output, "4111111111111111") {
+		t.Errorf("Ожидался замаскированный номер карты, получено: %s", output)
+	}
+
+	del := cliObj.DeleteCardCommand(ctx)
+	output = captureOutput(func() {
+		del.Run(del, []string{})
+	})
+	if !strings.Contains(output, "❌ Ошибка: имя карты обязательно") {
+		t.Errorf("Ожидалась ошибка отсутствия имени, получено: %s", output)
+	}
+}
+
+func TestNoteCommands(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+
+	file, err := os.CreateTemp(t.TempDir(), "note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("code-1\ncode-2\n")
+	file.Close()
+
+	create := cliObj.CreateNoteCommand(ctx)
+	create.Flags().Set("name", "recovery")
+	create.Flags().Set("file", file.Name())
+	output := captureOutput(func() {
+		create.Run(create, []string{})
+	})
+	if !strings.Contains(output, "✅ Заметка успешно сохранена!") {
+		t.Errorf("Ожидался вывод успешного создания заметки, получено: %s", output)
+	}
+
+	get := cliObj.GetNoteCommand(ctx)
+	get.Flags().Set("name", "recovery")
+	output = captureOutput(func() {
+		get.Run(get, []string{})
+	})
+	if !strings.Contains(output, "line 1\nline 2") {
+		t.Errorf("Ожидался многострочный текст заметки, получено: %s", output)
+	}
+}
+
+func TestUploadDownloadCommands(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+
+	upload := cliObj.UploadCommand(ctx)
+	output := captureOutput(func() {
+		upload.Run(upload, []string{})
+	})
+	if !strings.Contains(output, "❌ Ошибка: укажите name и file") {
+		t.Errorf("Ожидалась ошибка отсутствия параметров, получено: %s", output)
+	}
+
+	upload.Flags().Set("name", "id_rsa")
+	upload.Flags().Set("file", "/tmp/id_rsa")
+	output = captureOutput(func() {
+		upload.Run(upload, []string{})
+	})
+	if !strings.Contains(output, "✅ Файл успешно загружен!") {
+		t.Errorf("Ожидался вывод успешной загрузки, получено: %s", output)
+	}
+
+	download := cliObj.DownloadCommand(ctx)
+	download.Flags().Set("name", "notfound")
+	download.Flags().Set("out", "/tmp/out")
+	output = captureOutput(func() {
+		download.Run(download, []string{})
+	})
+	if !strings.Contains(output, "❌ Ошибка скачивания файла") {
+		t.Errorf("Ожидалась ошибка скачивания, получено: %s", output)
+	}
+}
+
+func TestSessionsCommand(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+
+	list := cliObj.ListSessionsCommand(ctx)
+	output := captureOutput(func() {
+		list.Run(list, []string{})
+	})
+	if !strings.Contains(output, "laptop (это устройство)") || !strings.Contains(output, "ci-runner") {
+		t.Errorf("Ожидался список устройств, получено: %s", output)
+	}
+
+	revoke := cliObj.RevokeSessionCommand(ctx)
+	output = captureOutput(func() {
+		revoke.Run(revoke, []string{})
+	})
+	if !strings.Contains(output, "❌ Ошибка: укажите id сессии или --others") {
+		t.Errorf("Ожидалась ошибка отсутствия id, получено: %s", output)
+	}
+
+	revoke.Flags().Set("id", "2")
+	output = captureOutput(func() {
+		revoke.Run(revoke, []string{})
+	})
+	if !strings.Contains(output, "✅ Сессия завершена") {
+		t.Errorf("Ожидалось завершение сессии, получено: %s", output)
+	}
+
+	others := cliObj.RevokeSessionCommand(ctx)
+	others.Flags().Set("others", "true")
+	output = captureOutput(func() {
+		others.Run(others, []string{})
+	})
+	if !strings.Contains(output, "✅ Завершено сессий на других устройствах: 1") {
+		t.Errorf("Ожидалось завершение других сессий, получено: %s", output)
+	}
+}
+
+func TestHistoryCommands(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+
+	history := cliObj.HistoryCommand(ctx)
+	history.Flags().Set("name", "mail")
+	output := captureOutput(func() {
+		history.Run(history, []string{})
+	})
+	if !strings.Contains(output, "[5]") || !strings.Contains(output, "old-secret") {
+		t.Errorf("Ожидалась прежняя версия, получено: %s", output)
+	}
+
+	restore := cliObj.RestoreCommand(ctx)
+	restore.Flags().Set("name", "mail")
+	output = captureOutput(func() {
+		restore.Run(restore, []string{})
+	})
+	if !strings.Contains(output, "❌ Ошибка: укажите name и номер версии") {
+		t.Errorf("Ожидалась ошибка отсутствия версии, получено: %s", output)
+	}
+
+	restore.Flags().Set("revision", "5")
+	output = captureOutput(func() {
+		restore.Run(restore, []string{})
+	})
+	if !strings.Contains(output, "✅ Версия восстановлена") {
+		t.Errorf("Ожидалось восстановление, получено: %s", output)
+	}
+}
+
+func TestAuditCommand(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+
+	audit := cliObj.AuditCommand(ctx)
+	output := captureOutput(func() {
+		audit.Run(audit, []string{})
+	})
+	if !strings.Contains(output, "lockbox.read") || !strings.Contains(output, "❌ login.failure") {
+		t.Errorf("Ожидался список событий, получено: %s", output)
+	}
+
+	audit.Flags().Set("limit", "-1")
+	output = captureOutput(func() {
+		audit.Run(audit, []string{})
+	})
+	if !strings.Contains(output, "❌ Ошибка получения журнала") {
+		t.Errorf("Ожидалась ошибка, получено: %s", output)
+	}
+}
+
+func TestTOTPCommands(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+
+	enable := cliObj.EnableTOTPCommand(ctx)
+	output := captureOutput(func() {
+		enable.Run(enable, []string{})
+	})
+	if !strings.Contains(output, "otpauth://totp/gophKeeper:user") {
+		t.Errorf("Ожидалась ссылка otpauth, получено: %s", output)
+	}
+
+	confirm := cliObj.ConfirmTOTPCommand(ctx)
+	confirm.Flags().Set("code", "123456")
+	output = captureOutput(func() {
+		confirm.Run(confirm, []string{})
+	})
+	if !strings.Contains(output, "aaaa-bbbb-cccc-dddd") {
+		t.Errorf("Ожидались коды восстановления, получено: %s", output)
+	}
+
+	mfa := cliObj.NewMFACli(ctx)
+	mfa.Flags().Set("code", "000000")
+	output = captureOutput(func() {
+		mfa.Run(mfa, []string{})
+	})
+	if !strings.Contains(output, "❌ Ошибка") {
+		t.Errorf("Ожидалась ошибка неверного кода, получено: %s", output)
+	}
+}
+
+func TestAccountCommands(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+
+	show := cliObj.ShowAccountCommand(ctx)
+	output := captureOutput(func() {
+		show.Run(show, []string{})
+	})
+	if !strings.Contains(output, "👤 Пользователь: user") {
+		t.Errorf("Ожидались данные аккаунта, получено: %s", output)
+	}
+
+	password := cliObj.ChangePasswordCommand(ctx)
+	password.Flags().Set("old-password", "wrong")
+	password.Flags().Set("new-password", "new password")
+	output = captureOutput(func() {
+		password.Run(password, []string{})
+	})
+	if !strings.Contains(output, "❌ Ошибка смены пароля") {
+		t.Errorf("Ожидалась ошибка неверного пароля, получено: %s", output)
+	}
+
+	password.Flags().Set("old-password", "password")
+	output = captureOutput(func() {
+		password.Run(password, []string{})
+	})
+	if !strings.Contains(output, "✅ Пароль изменён") {
+		t.Errorf("Ожидалась смена пароля, получено: %s", output)
+	}
+
+	rename := cliObj.RenameAccountCommand(ctx)
+	rename.Flags().Set("password", "password")
+	rename.Flags().Set("username", "alice")
+	output = captureOutput(func() {
+		rename.Run(rename, []string{})
+	})
+	if !strings.Contains(output, "✅ Имя изменено, входите как alice") {
+		t.Errorf("Ожидалась смена имени, получено: %s", output)
+	}
+
+	del := cliObj.DeleteAccountCommand(ctx)
+	del.Flags().Set("password", "password")
+	output = captureOutput(func() {
+		del.Run(del, []string{})
+	})
+	if !strings.Contains(output, "--yes") {
+		t.Errorf("Ожидался запрос подтверждения, получено: %s", output)
+	}
+
+	del.Flags().Set("yes", "true")
+	output = captureOutput(func() {
+		del.Run(del, []string{})
+	})
+	if !strings.Contains(output, "✅ Аккаунт и все его данные удалены") {
+		t.Errorf("Ожидалось удаление аккаунта, получено: %s", output)
+	}
+}
+
+func TestTrashCommands(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+
+	list := cliObj.ListTrashCommand(ctx)
+	output := captureOutput(func() {
+		list.Run(list, []string{})
+	})
+	if !strings.Contains(output, "[7]") || !strings.Contains(output, "old-mail") {
+		t.Errorf("Ожидалась удалённая запись, получено: %s", output)
+	}
+
+	restore := cliObj.RestoreTrashCommand(ctx)
+	restore.Flags().Set("id", "7")
+	output = captureOutput(func() {
+		restore.Run(restore, []string{})
+	})
+	if !strings.Contains(output, "✅ Запись восстановлена") {
+		t.Errorf("Ожидалось восстановление, получено: %s", output)
+	}
+
+	empty := cliObj.EmptyTrashCommand(ctx)
+	output = captureOutput(func() {
+		empty.Run(empty, []string{})
+	})
+	if !strings.Contains(output, "--yes") {
+		t.Errorf("Ожидался запрос подтверждения, получено: %s", output)
+	}
+
+	empty.Flags().Set("yes", "true")
+	output = captureOutput(func() {
+		empty.Run(empty, []string{})
+	})
+	if !strings.Contains(output, "✅ Удалено записей: 1") {
+		t.Errorf("Ожидалась очистка корзины, получено: %s", output)
+	}
+}
+
+func TestConflictsCommands(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+
+	list := cliObj.ListConflictsCommand(ctx)
+	output := captureOutput(func() {
+		list.Run(list, []string{})
+	})
+	if !strings.Contains(output, "mail") || !strings.Contains(output, "mine") || !strings.Contains(output, "theirs") {
+		t.Errorf("Ожидались обе версии записи, получено: %s", output)
+	}
+
+	resolve := cliObj.ResolveConflictCommand(ctx)
+	resolve.Flags().Set("name", "mail")
+	resolve.Flags().Set("keep", "remote")
+	resolve.Flags().Set("password", "local")
+	output = captureOutput(func() {
+		resolve.Run(resolve, []string{})
+	})
+	if !strings.Contains(output, "✅ Конфликт разрешён") {
+		t.Errorf("Ожидалось разрешение конфликта, получено: %s", output)
+	}
+}
+
+func TestSyncCommands(t *testing.T) {
+	mockUC := usecase.NewLockBoxUsecaseMock()
+	cliObj := NewLockBoxCLI(mockUC)
+	ctx := context.Background()
+
+	status := cliObj.SyncStatusCommand(ctx)
+	output := captureOutput(func() {
+		status.Run(status, []string{})
+	})
+	if !strings.Contains(output, "update: mail") || !strings.Contains(output, "connection refused") {
+		t.Errorf("Ожидалась операция в очереди с ошибкой, получено: %s", output)
+	}
+	if !strings.Contains(output, "Конфликтов ждут решения: 1") {
+		t.Errorf("Ожидалось число конфликтов, получено: %s", output)
+	}
+
+	now := cliObj.SyncNowCommand(ctx)
+	output = captureOutput(func() {
+		now.Run(now, []string{})
+	})
+	if !strings.Contains(output, "✅ Синхронизация завершена") {
+		t.Errorf("Ожидалась успешная синхронизация, получено: %s", output)
+	}
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"gophKeeper/internal/client/services/lockbox/models"
+
+	"github.com/spf13/cobra"
+)
+
+// ConflictsCommand объединяет команды отложенных конфликтов правок с разных
+// устройств: conflicts list и conflicts resolve.
+func (cli *LockBoxCLI) ConflictsCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conflicts",
+		Short: "Resolve conflicting edits from other devices",
+	}
+	cmd.AddCommand(cli.ListConflictsCommand(ctx), cli.ResolveConflictCommand(ctx))
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) ListConflictsCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List conflicts waiting for a decision",
+		Run: func(cmd *cobra.Command, args []string) {
+			conflicts, err := cli.lockBoxUC.ListConflicts(ctx)
+			if err != nil {
+				fmt.Println("❌ Ошибка получения конфликтов:", err)
+				return
+			}
+			if len(conflicts) == 0 {
+				fmt.Println("🔍 Конфликтов нет.")
+				return
+			}
+
+			fmt.Println("\n⚠️  Конфликты:")
+			fmt.Println("──────────────────────────────────────────────")
+			for _, conflict := range conflicts {
+				fmt.Printf("🔹 Название: %s (%s)\n", conflict.Name, conflict.CreatedAt.Format("2006-01-02 15:04:05"))
+				printConflictSide("Локальная", &conflict.Local, conflict.LocalDeleted)
+				printConflictSide("С сервера", &conflict.Remote, conflict.RemoteDeleted)
+				fmt.Println("──────────────────────────────────────────────")
+			}
+		},
+	}
+
+	return cmd
+}
+
+func printConflictSide(title string, box *models.LockBox, deleted bool) {
+	if deleted {
+		fmt.Printf("    %s: удалена\n", title)
+		return
+	}
+	fmt.Printf("    %s:\n", title)
+	fmt.Printf("      🌍 URL:      %s\n", box.URL)
+	fmt.Printf("      👤 Логин:    %s\n", box.Login)
+	fmt.Printf("      🔑 Пароль:   %s\n", box.Password)
+	fmt.Printf("      📝 Описание: %s\n", box.Description)
+}
+
+func (cli *LockBoxCLI) ResolveConflictCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resolve",
+		Short: "Keep the local or the server version, or merge them field by field",
+		Run: func(cmd *cobra.Command, args []string) {
+			name, err := cmd.Flags().GetString("name")
+			if err != nil || name == "" {
+				fmt.Println("❌ Ошибка: укажите название записи из conflicts list")
+				return
+			}
+			resolution := models.ConflictResolution{}
+			for flag, side := range map[string]*models.ConflictSide{
+				"keep":        &resolution.Keep,
+				"url":         &resolution.URL,
+				"login":       &resolution.Login,
+				"password":    &resolution.Password,
+				"description": &resolution.Description,
+			} {
+				value, _ := cmd.Flags().GetString(flag)
+				*side = models.ConflictSide(value)
+			}
+
+			if err := cli.lockBoxUC.ResolveConflict(ctx, name, resolution); err != nil {
+				fmt.Println("❌ Ошибка разрешения конфликта:", err)
+				return
+			}
+			fmt.Println("✅ Конфликт разрешён")
+		},
+	}
+
+	cmd.Flags().String("name", "", "Название записи")
+	cmd.Flags().String("keep", "", "Какую версию оставить: local или remote")
+	cmd.Flags().String("url", "", "Откуда взять URL: local или remote")
+	cmd.Flags().String("login", "", "Откуда взять логин: local или remote")
+	cmd.Flags().String("password", "", "Откуда взять пароль: local или remote")
+	cmd.Flags().String("description", "", "Откуда взять описание: local или remote")
+
+	return cmd
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func (cli *LockBoxCLI) HistoryCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show previous versions of a lockbox",
+		Run: func(cmd *cobra.Command, args []string) {
+			name, err := cmd.Flags().GetString("name")
+			if err != nil || name == "" {
+				fmt.Println("❌ Ошибка: не указан параметр name")
+				return
+			}
+
+			revisions, err := cli.lockBoxUC.GetLockBoxHistory(ctx, name)
+			if err != nil {
+				fmt.Println("❌ Ошибка получения истории:", err)
+				return
+			}
+			if len(revisions) == 0 {
+				fmt.Println("🔍 Прежних версий нет.")
+				return
+			}
+
+			fmt.Printf("\n🕘 История %s:\n", name)
+			fmt.Println("──────────────────────────────────────────────")
+			for _, revision := range revisions {
+				fmt.Printf("[%d] 📅 Версия от %s, заменена %s\n", revision.Revision,
+					revision.UpdatedAt.Format("2006-01-02 15:04:05"), revision.ReplacedAt.Format("2006-01-02 15:04:05"))
+				fmt.Printf("    🔹 Название: %s\n", revision.Name)
+				fmt.Printf("    🔗 URL:      %s\n", revision.URL)
+				fmt.Printf("    👤 Логин:    %s\n", revision.Login)
+				fmt.Printf("    🔑 Пароль:   %s\n", revision.Password)
+				fmt.Printf("    📝 Описание: %s\n", revision.Description)
+				fmt.Println("──────────────────────────────────────────────")
+			}
+		},
+	}
+
+	cmd.Flags().String("name", "", "Lockbox name (обязательно)")
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) RestoreCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a lockbox to a previous version",
+		Run: func(cmd *cobra.Command, args []string) {
+			name, _ := cmd.Flags().GetString("name")
+			revision, _ := cmd.Flags().GetInt("revision")
+			if name == "" || revision <= 0 {
+				fmt.Println("❌ Ошибка: укажите name и номер версии из history")
+				return
+			}
+
+			if err := cli.lockBoxUC.RestoreLockBox(ctx, name, revision); err != nil {
+				fmt.Println("❌ Ошибка восстановления:", err)
+				return
+			}
+			fmt.Println("✅ Версия восстановлена, текущее содержимое сохранено в истории")
+		},
+	}
+
+	cmd.Flags().String("name", "", "Lockbox name (обязательно)")
+	cmd.Flags().Int("revision", 0, "Номер версии из history (обязательно)")
+
+	return cmd
+}
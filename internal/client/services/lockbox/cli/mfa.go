@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// NewMFACli завершает вход кодом из приложения-аутентификатора или кодом
+// восстановления, если auth сообщил, что он нужен.
+func (cli *LockBoxCLI) NewMFACli(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mfa",
+		Short: "Finish login with a two-factor code",
+		Run: func(cmd *cobra.Command, args []string) {
+			code, _ := cmd.Flags().GetString("code")
+			if code == "" {
+				fmt.Println("❌ Ошибка: укажите код")
+				return
+			}
+
+			if err := cli.lockBoxUC.VerifyMFA(ctx, code); err != nil {
+				fmt.Println("❌ Ошибка:", err)
+				return
+			}
+			cli.mfaRequired = false
+
+			fmt.Println("✅ Аутентификация успешна!")
+		},
+	}
+
+	cmd.Flags().String("code", "", "Код из приложения или код восстановления (обязательно)")
+
+	return cmd
+}
+
+// TOTPCommand объединяет команды настройки двухфакторной аутентификации:
+// totp enable, totp confirm и totp disable.
+func (cli *LockBoxCLI) TOTPCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "totp",
+		Short: "Manage two-factor authentication",
+	}
+	cmd.AddCommand(cli.EnableTOTPCommand(ctx), cli.ConfirmTOTPCommand(ctx), cli.DisableTOTPCommand(ctx))
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) EnableTOTPCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable",
+		Short: "Get a secret for an authenticator app",
+		Run: func(cmd *cobra.Command, args []string) {
+			enrollment, err := cli.lockBoxUC.EnrollTOTP(ctx)
+			if err != nil {
+				fmt.Println("❌ Ошибка подключения 2FA:", err)
+				return
+			}
+
+			fmt.Println("\n🔐 Добавьте аккаунт в приложение-аутентификатор:")
+			fmt.Println("──────────────────────────────────────────────")
+			fmt.Printf("🔹 Ссылка: %s\n", enrollment.URI)
+			fmt.Printf("🔑 Секрет: %s\n", enrollment.Secret)
+			fmt.Println("──────────────────────────────────────────────")
+			fmt.Println("Затем подтвердите включение кодом из приложения: totp confirm --code")
+		},
+	}
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) ConfirmTOTPCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "confirm",
+		Short: "Turn on two-factor authentication with a code from the app",
+		Run: func(cmd *cobra.Command, args []string) {
+			code, _ := cmd.Flags().GetString("code")
+			if code == "" {
+				fmt.Println("❌ Ошибка: укажите код")
+				return
+			}
+
+			codes, err := cli.lockBoxUC.ConfirmTOTP(ctx, code)
+			if err != nil {
+				fmt.Println("❌ Ошибка включения 2FA:", err)
+				return
+			}
+
+			fmt.Println("✅ Двухфакторная аутентификация включена!")
+			fmt.Println("Сохраните коды восстановления, каждый действует один раз и больше показан не будет:")
+			fmt.Println("──────────────────────────────────────────────")
+			for _, code := range codes {
+				fmt.Println("  " + code)
+			}
+			fmt.Println("──────────────────────────────────────────────")
+		},
+	}
+
+	cmd.Flags().String("code", "", "Код из приложения (обязательно)")
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) DisableTOTPCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disable",
+		Short: "Turn off two-factor authentication",
+		Run: func(cmd *cobra.Command, args []string) {
+			code, _ := cmd.Flags().GetString("code")
+			if code == "" {
+				fmt.Println("❌ Ошибка: укажите код")
+				return
+			}
+
+			if err := cli.lockBoxUC.DisableTOTP(ctx, code); err != nil {
+				fmt.Println("❌ Ошибка отключения 2FA:", err)
+				return
+			}
+			fmt.Println("✅ Двухфакторная аутентификация отключена")
+		},
+	}
+
+	cmd.Flags().String("code", "", "Код из приложения или код восстановления (обязательно)")
+
+	return cmd
+}
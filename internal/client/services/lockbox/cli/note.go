@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func (cli *LockBoxCLI) CreateNoteCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "createNote",
+		Short: "Create a new text note",
+		Run: func(cmd *cobra.Command, args []string) {
+			input, err := noteInputFromFlags(cmd)
+			if err != nil {
+				fmt.Println("❌ Ошибка чтения заметки:", err)
+				return
+			}
+
+			if _, err := cli.lockBoxUC.CreateNote(ctx, input); err != nil {
+				fmt.Println("❌ Ошибка создания заметки:", err)
+				return
+			}
+
+			fmt.Println("✅ Заметка успешно сохранена!")
+		},
+	}
+	addNoteFlags(cmd)
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) GetNoteCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "getNote",
+		Short: "Get a text note by name",
+		Run: func(cmd *cobra.Command, args []string) {
+			name, err := cmd.Flags().GetString("name")
+			if err != nil || name == "" {
+				fmt.Println("❌ Ошибка: не указан параметр name")
+				return
+			}
+
+			note, err := cli.lockBoxUC.GetNote(ctx, name)
+			if err != nil || note == nil {
+				fmt.Println("❌ Ошибка, заметка не найдена")
+				return
+			}
+			fmt.Println("\n✅ Заметка найдена!")
+			fmt.Println("──────────────────────────────────────────────")
+			fmt.Printf("🔹 Название:     %s\n", note.Name)
+			fmt.Printf("📅 Дата создания:%s\n", note.CreatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Printf("♻️  Обновлено:   %s\n", note.UpdatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Println("──────────────────────────────────────────────")
+			fmt.Println(note.Body)
+			fmt.Println("──────────────────────────────────────────────")
+		},
+	}
+
+	cmd.Flags().String("name", "", "Note name (обязательно)")
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) GetAllNotesCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "getNotes",
+		Short: "Get all text notes",
+		Run: func(cmd *cobra.Command, args []string) {
+			notes, err := cli.lockBoxUC.GetNotes(ctx)
+			if err != nil {
+				fmt.Println("❌ Ошибка в получении заметок:", err)
+				return
+			}
+
+			if len(*notes) == 0 {
+				fmt.Println("🔍 Нет сохранённых заметок.")
+				return
+			}
+
+			fmt.Println("\n📝 Список заметок:")
+			fmt.Println("──────────────────────────────────────────────────────────────────────")
+			for i, note := range *notes {
+				fmt.Printf("[%d] 🔹 Название:  %s\n", i+1, note.Name)
+				fmt.Printf("    📄 Строк:     %d\n", strings.Count(note.Body, "\n")+1)
+				fmt.Printf("    ♻️  Обновлено: %s\n", note.UpdatedAt.Format("2006-01-02 15:04:05"))
+				fmt.Println("──────────────────────────────────────────────────────────────────────")
+			}
+		},
+	}
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) UpdateNoteCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "updateNote",
+		Short: "Replace the body of a text note",
+		Run: func(cmd *cobra.Command, args []string) {
+			input, err := noteInputFromFlags(cmd)
+			if err != nil {
+				fmt.Println("❌ Ошибка чтения заметки:", err)
+				return
+			}
+
+			if err := cli.lockBoxUC.UpdateNote(ctx, input); err != nil {
+				fmt.Println("❌ Ошибка в обновлении заметки:", err)
+				return
+			}
+
+			fmt.Println("✅ Заметка успешно обновлена!")
+		},
+	}
+	addNoteFlags(cmd)
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) DeleteNoteCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deleteNote",
+		Short: "Delete a text note",
+		Run: func(cmd *cobra.Command, args []string) {
+			name, _ := cmd.Flags().GetString("name")
+			if name == "" {
+				fmt.Println("❌ Ошибка: имя заметки обязательно")
+				return
+			}
+
+			if err := cli.lockBoxUC.DeleteNote(ctx, name); err != nil {
+				fmt.Println("❌ Ошибка удаления заметки")
+				return
+			}
+			fmt.Println("✅ Заметка успешно удалена!")
+		},
+	}
+
+	cmd.Flags().String("name", "", "Название заметки (обязательно)")
+
+	return cmd
+}
+
+func addNoteFlags(cmd *cobra.Command) {
+	cmd.Flags().String("name", "", "Название заметки (обязательно)")
+	cmd.Flags().String("body", "", "Note text")
+	cmd.Flags().String("file", "", "Read note text from file, \"-\" for stdin")
+}
+
+// noteInputFromFlags берёт текст заметки из --body либо из --file,
+// что позволяет сохранять многострочный текст без ограничений на размер.
+func noteInputFromFlags(cmd *cobra.Command) (*models.NoteInput, error) {
+	name, _ := cmd.Flags().GetString("name")
+	body, _ := cmd.Flags().GetString("body")
+	file, _ := cmd.Flags().GetString("file")
+
+	if file != "" {
+		var reader io.Reader = cmd.InOrStdin()
+		if file != "-" {
+			f, err := os.Open(file)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			reader = f
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		body = string(data)
+	}
+
+	return &models.NoteInput{Name: name, Body: body}, nil
+}
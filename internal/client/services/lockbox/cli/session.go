@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// SessionsCommand объединяет команды управления устройствами, с которых
+// выполнен вход: sessions list и sessions revoke.
+func (cli *LockBoxCLI) SessionsCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Manage devices signed in to the account",
+	}
+	cmd.AddCommand(cli.ListSessionsCommand(ctx), cli.RevokeSessionCommand(ctx))
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) ListSessionsCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List devices signed in to the account",
+		Run: func(cmd *cobra.Command, args []string) {
+			sessions, err := cli.lockBoxUC.GetSessions(ctx)
+			if err != nil {
+				fmt.Println("❌ Ошибка получения сессий:", err)
+				return
+			}
+
+			if len(*sessions) == 0 {
+				fmt.Println("🔍 Нет активных сессий.")
+				return
+			}
+
+			fmt.Println("\n💻 Активные сессии:")
+			fmt.Println("──────────────────────────────────────────────────────────────────────")
+			for _, session := range *sessions {
+				device := session.DeviceName
+				if device == "" {
+					device = session.UserAgent
+				}
+				current := ""
+				if session.Current {
+					current = " (это устройство)"
+				}
+				fmt.Printf("[%d] 🔹 Устройство: %s%s\n", session.ID, device, current)
+				fmt.Printf("    🖥  ОС:         %s\n", session.OS)
+				fmt.Printf("    🌐 IP:         %s\n", session.IP)
+				fmt.Printf("    📅 Первый вход: %s\n", session.CreatedAt.Format("2006-01-02 15:04:05"))
+				fmt.Printf("    ♻️  Активность: %s\n", session.LastUsedAt.Format("2006-01-02 15:04:05"))
+				fmt.Println("──────────────────────────────────────────────────────────────────────")
+			}
+		},
+	}
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) RevokeSessionCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Sign out a device by session id, or all other devices",
+		Run: func(cmd *cobra.Command, args []string) {
+			others, _ := cmd.Flags().GetBool("others")
+			if others {
+				revoked, err := cli.lockBoxUC.RevokeOtherSessions(ctx)
+				if err != nil {
+					fmt.Println("❌ Ошибка завершения сессий:", err)
+					return
+				}
+				fmt.Printf("✅ Завершено сессий на других устройствах: %d\n", revoked)
+				return
+			}
+
+			id, err := cmd.Flags().GetInt("id")
+			if err != nil || id <= 0 {
+				fmt.Println("❌ Ошибка: укажите id сессии или --others")
+				return
+			}
+			if err := cli.lockBoxUC.RevokeSession(ctx, id); err != nil {
+				fmt.Println("❌ Ошибка завершения сессии:", err)
+				return
+			}
+			fmt.Println("✅ Сессия завершена")
+		},
+	}
+
+	cmd.Flags().Int("id", 0, "Id сессии из sessions list")
+	cmd.Flags().Bool("others", false, "Завершить все сессии, кроме текущей")
+
+	return cmd
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// SyncCommand объединяет команды синхронизации с сервером: sync status и
+// sync now.
+func (cli *LockBoxCLI) SyncCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Show or run synchronization with the server",
+	}
+	cmd.AddCommand(cli.SyncStatusCommand(ctx), cli.SyncNowCommand(ctx))
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) SyncStatusCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "List operations waiting to be sent to the server",
+		Run: func(cmd *cobra.Command, args []string) {
+			status, err := cli.lockBoxUC.GetSyncStatus(ctx)
+			if err != nil {
+				fmt.Println("❌ Ошибка получения состояния синхронизации:", err)
+				return
+			}
+			fmt.Printf("🔄 Курсор ленты изменений: %d\n", status.Cursor)
+			if status.Conflicts > 0 {
+				fmt.Printf("⚠️  Конфликтов ждут решения: %d\n", status.Conflicts)
+			}
+			if len(status.Pending) == 0 {
+				fmt.Println("✅ Все изменения отправлены.")
+				return
+			}
+
+			fmt.Printf("\n📤 Ожидают отправки (%d):\n", len(status.Pending))
+			fmt.Println("──────────────────────────────────────────────")
+			for _, entry := range status.Pending {
+				fmt.Printf("🔹 %s: %s (%s)\n", entry.Op, entry.Name, entry.CreatedAt.Format("2006-01-02 15:04:05"))
+				if entry.Attempts > 0 {
+					fmt.Printf("    Попыток: %d\n", entry.Attempts)
+				}
+				if !entry.NextAttemptAt.IsZero() && entry.NextAttemptAt.After(time.Now()) {
+					fmt.Printf("    Следующая попытка: %s\n", entry.NextAttemptAt.Local().Format("2006-01-02 15:04:05"))
+				}
+				if entry.LastError != "" {
+					fmt.Printf("    Ошибка: %s\n", entry.LastError)
+				}
+			}
+			fmt.Println("──────────────────────────────────────────────")
+		},
+	}
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) SyncNowCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "now",
+		Short: "Send pending changes and fetch updates right away",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cli.lockBoxUC.SyncUpdatesToServer(ctx); err != nil {
+				fmt.Println("❌ Ошибка отправки изменений:", err)
+				return
+			}
+			if err := cli.lockBoxUC.SyncUpdatesToLocal(ctx); err != nil {
+				fmt.Println("❌ Ошибка получения изменений:", err)
+				return
+			}
+			fmt.Println("✅ Синхронизация завершена")
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// TrashCommand объединяет команды корзины удалённых записей: trash list,
+// trash restore и trash empty.
+func (cli *LockBoxCLI) TrashCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "Manage deleted lockboxes",
+	}
+	cmd.AddCommand(cli.ListTrashCommand(ctx), cli.RestoreTrashCommand(ctx), cli.EmptyTrashCommand(ctx))
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) ListTrashCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List deleted lockboxes that can be restored",
+		Run: func(cmd *cobra.Command, args []string) {
+			lockBoxes, err := cli.lockBoxUC.ListTrash(ctx)
+			if err != nil {
+				fmt.Println("❌ Ошибка получения корзины:", err)
+				return
+			}
+			if len(lockBoxes) == 0 {
+				fmt.Println("🔍 Корзина пуста.")
+				return
+			}
+
+			fmt.Println("\n🗑  Корзина:")
+			fmt.Println("──────────────────────────────────────────────")
+			for _, lockBox := range lockBoxes {
+				fmt.Printf("[%d] 🔹 Название: %s\n", lockBox.ID, lockBox.Name)
+				fmt.Printf("    👤 Логин:    %s\n", lockBox.Login)
+				fmt.Printf("    🗑  Удалена:  %s\n", lockBox.DeletedAt.Format("2006-01-02 15:04:05"))
+				fmt.Println("──────────────────────────────────────────────")
+			}
+		},
+	}
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) RestoreTrashCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a deleted lockbox by id",
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetInt("id")
+			if err != nil || id <= 0 {
+				fmt.Println("❌ Ошибка: укажите id записи из trash list")
+				return
+			}
+			if err := cli.lockBoxUC.RestoreTrash(ctx, id); err != nil {
+				fmt.Println("❌ Ошибка восстановления:", err)
+				return
+			}
+			fmt.Println("✅ Запись восстановлена из корзины")
+		},
+	}
+
+	cmd.Flags().Int("id", 0, "Id записи из trash list")
+
+	return cmd
+}
+
+func (cli *LockBoxCLI) EmptyTrashCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Permanently delete all lockboxes in the trash",
+		Run: func(cmd *cobra.Command, args []string) {
+			yes, _ := cmd.Flags().GetBool("yes")
+			if !yes {
+				fmt.Println("❌ Очистка необратима: подтвердите её флагом --yes")
+				return
+			}
+			purged, err := cli.lockBoxUC.EmptyTrash(ctx)
+			if err != nil {
+				fmt.Println("❌ Ошибка очистки корзины:", err)
+				return
+			}
+			fmt.Printf("✅ Удалено записей: %d\n", purged)
+		},
+	}
+
+	cmd.Flags().Bool("yes", false, "Подтвердить очистку")
+
+	return cmd
+}
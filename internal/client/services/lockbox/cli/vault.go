@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func (cli *LockBoxCLI) RotateKeyCommand(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Re-encrypt the whole vault with a new master password",
+		Run: func(cmd *cobra.Command, args []string) {
+			abort, _ := cmd.Flags().GetBool("abort")
+			if abort {
+				if err := cli.lockBoxUC.AbortKeyRotation(); err != nil {
+					fmt.Println("❌ Ошибка отмены смены ключа:", err)
+					return
+				}
+				fmt.Println("✅ Незавершённая смена ключа отменена")
+				return
+			}
+
+			oldPassword, _ := cmd.Flags().GetString("old-password")
+			newPassword, _ := cmd.Flags().GetString("new-password")
+			if oldPassword == "" || newPassword == "" {
+				fmt.Println("❌ Ошибка: укажите old-password и new-password")
+				return
+			}
+
+			err := cli.lockBoxUC.RotateKey(ctx, oldPassword, newPassword, func(done, total int, item string) {
+				fmt.Printf("🔄 [%d/%d] %s\n", done, total, item)
+			})
+			if err != nil {
+				fmt.Println("❌ Ошибка смены ключа:", err)
+				fmt.Println("Повторите команду, чтобы продолжить, или выполните её с --abort")
+				return
+			}
+			fmt.Println("✅ Хранилище перешифровано новым ключом")
+		},
+	}
+
+	cmd.Flags().String("old-password", "", "Текущий мастер-пароль (обязательно)")
+	cmd.Flags().String("new-password", "", "Новый мастер-пароль (обязательно)")
+	cmd.Flags().Bool("abort", false, "Отменить незавершённую смену ключа")
+
+	return cmd
+}
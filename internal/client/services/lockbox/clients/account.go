@@ -0,0 +1,123 @@
+package clients
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/srp"
+	"net/http"
+)
+
+// passwordProof подтверждает текущий пароль при изменении аккаунта.
+type passwordProof struct {
+	Session  string `json:"session,omitempty"`
+	M1       string `json:"m1,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// proofFor проводит первый шаг SRP и считает доказательство M1 текущего
+// пароля, не передавая сам пароль. Аккаунт без верификатора подтверждает
+// пароль как при прежнем входе.
+func (s *lockBoxService) proofFor(ctx context.Context, username, password string) (*passwordProof, error) {
+	client, err := srp.NewClient(username, password)
+	if err != nil {
+		return nil, err
+	}
+	var challenge struct {
+		Session string `json:"session"`
+		Salt    string `json:"salt"`
+		B       string `json:"b"`
+	}
+	req := map[string]string{"username": username, "a": hex.EncodeToString(client.A)}
+	err = s.postSRP(ctx, "/api/auth/srp/init", req, &challenge)
+	if err == errors.ErrSRPNotSet {
+		return &passwordProof{Password: password}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err1 := hex.DecodeString(challenge.Salt)
+	serverB, err2 := hex.DecodeString(challenge.B)
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("failed to parse response: invalid srp challenge")
+	}
+	m1, err := client.Proof(salt, serverB)
+	if err != nil {
+		return nil, err
+	}
+	return &passwordProof{Session: challenge.Session, M1: hex.EncodeToString(m1)}, nil
+}
+
+// accountStatus переводит ответ на изменение аккаунта в ошибку клиента.
+func accountStatus(status int, err error) error {
+	switch status {
+	case http.StatusForbidden:
+		return errors.ErrInvalidCredentials
+	case http.StatusConflict:
+		return errors.ErrUsernameTaken
+	}
+	return err
+}
+
+// GetAccount возвращает данные аккаунта вошедшего пользователя.
+func (s *lockBoxService) GetAccount(ctx context.Context) (*models.Account, error) {
+	var account models.Account
+	if _, err := s.authRequest(ctx, http.MethodGet, "/api/users/me", nil, &account, true); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ChangePassword заменяет пароль аккаунта. Сервер получает доказательство
+// старого пароля и верификатор нового, но не сами пароли.
+func (s *lockBoxService) ChangePassword(ctx context.Context, username, oldPassword, newPassword string) error {
+	proof, err := s.proofFor(ctx, username, oldPassword)
+	if err != nil {
+		return err
+	}
+	verifier, err := newSRPVerifier(username, newPassword)
+	if err != nil {
+		return err
+	}
+	req := struct {
+		Proof *passwordProof `json:"proof"`
+		srpVerifier
+	}{Proof: proof, srpVerifier: *verifier}
+	return accountStatus(s.authRequest(ctx, http.MethodPut, "/api/users/me/password", req, nil, true))
+}
+
+// RenameAccount меняет имя для входа. Верификатор зависит от имени, поэтому
+// он пересчитывается для нового имени с прежним паролем.
+func (s *lockBoxService) RenameAccount(ctx context.Context, username, password, newUsername string) error {
+	proof, err := s.proofFor(ctx, username, password)
+	if err != nil {
+		return err
+	}
+	verifier, err := newSRPVerifier(newUsername, password)
+	if err != nil {
+		return err
+	}
+	req := struct {
+		Proof    *passwordProof `json:"proof"`
+		Username string         `json:"username"`
+		srpVerifier
+	}{Proof: proof, Username: newUsername, srpVerifier: *verifier}
+	return accountStatus(s.authRequest(ctx, http.MethodPut, "/api/users/me/username", req, nil, true))
+}
+
+// DeleteAccount удаляет аккаунт со всеми данными на сервере и забывает токены.
+func (s *lockBoxService) DeleteAccount(ctx context.Context, username, password string) error {
+	proof, err := s.proofFor(ctx, username, password)
+	if err != nil {
+		return err
+	}
+	req := map[string]*passwordProof{"proof": proof}
+	if err := accountStatus(s.authRequest(ctx, http.MethodDelete, "/api/users/me", req, nil, true)); err != nil {
+		return err
+	}
+	s.tokens.set(&authTokens{})
+	return nil
+}
@@ -0,0 +1,19 @@
+package clients
+
+import (
+	"context"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"net/http"
+	"strconv"
+)
+
+// GetAuditEvents возвращает последние limit событий журнала аудита аккаунта,
+// новые первыми.
+func (s *lockBoxService) GetAuditEvents(ctx context.Context, limit int) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+	path := "/api/audit?limit=" + strconv.Itoa(limit)
+	if _, err := s.authRequest(ctx, http.MethodGet, path, nil, &events, true); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
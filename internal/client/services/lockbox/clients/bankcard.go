@@ -0,0 +1,234 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	"io"
+	"net/http"
+	neturl "net/url"
+)
+
+// SealBankCard шифрует реквизиты карты и, при включённом шифровании имён, её
+// название.
+func (s *lockBoxService) SealBankCard(data *models.BankCardInput) (*models.BankCardInput, error) {
+	dataEncrypt, err := crypt.EncryptBankCard(data, s.encryptor)
+	if err != nil {
+		return nil, err
+	}
+	dataEncrypt.Name, dataEncrypt.NameIndex, err = s.sealName(data.Name)
+	if err != nil {
+		return nil, err
+	}
+	return dataEncrypt, nil
+}
+
+// openCard расшифровывает карту, пришедшую с сервера, вместе с названием.
+func (s *lockBoxService) openCard(card *models.BankCard) (*models.BankCard, error) {
+	card, err := crypt.DecryptBankCard(card, s.encryptor)
+	if err != nil {
+		return nil, err
+	}
+	if card.NameIndex != "" {
+		if card.Name, err = s.encryptor.Decrypt(card.Name); err != nil {
+			return nil, err
+		}
+	}
+	return card, nil
+}
+
+// cardPath — путь чтения карты: по слепому индексу названия, если включено
+// шифрование имён, иначе по самому названию.
+func (s *lockBoxService) cardPath(name string) (string, error) {
+	if !s.encryptNames {
+		return "/api/bank_cards/" + neturl.PathEscape(name), nil
+	}
+	index, err := crypt.BlindIndex(name, s.encryptor)
+	if err != nil {
+		return "", err
+	}
+	return "/api/bank_cards/index/" + index, nil
+}
+
+// findCard находит карту по названию, не расшифровывая её.
+func (s *lockBoxService) findCard(ctx context.Context, name string) (*models.BankCard, error) {
+	path, err := s.cardPath(name)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+":"+s.port+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get bank card (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var card models.BankCard
+	if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &card, nil
+}
+
+func (s *lockBoxService) CreateCard(ctx context.Context, data *models.BankCardInput) (int, error) {
+	dataEncrypt, err := s.SealBankCard(data)
+	if err != nil {
+		return 0, err
+	}
+	jsonData, err := json.Marshal(dataEncrypt)
+	if err != nil {
+		return 0, err
+	}
+
+	url := s.baseURL + ":" + s.port + "/api/bank_cards/create"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return 0, errors.ErrCardExists
+	}
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("failed to create bank card (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return response.ID, nil
+}
+
+func (s *lockBoxService) GetCard(ctx context.Context, name string) (*models.BankCard, error) {
+	card, err := s.findCard(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.openCard(card)
+}
+
+func (s *lockBoxService) GetCards(ctx context.Context) (*[]models.BankCard, error) {
+	url := fmt.Sprintf("%s:%s/api/bank_cards/", s.baseURL, s.port)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get bank cards (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var cards []models.BankCard
+	if err := json.NewDecoder(resp.Body).Decode(&cards); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	cardsDecrypt := make([]models.BankCard, len(cards))
+	for i := range cards {
+		card, err := s.openCard(&cards[i])
+		if err != nil {
+			return nil, err
+		}
+		cardsDecrypt[i] = *card
+	}
+	return &cardsDecrypt, nil
+}
+
+// UpdateCard заменяет карту id целиком: пустые поля data очищаются на сервере.
+func (s *lockBoxService) UpdateCard(ctx context.Context, id int, data *models.BankCardInput) error {
+	dataEncrypt, err := s.SealBankCard(data)
+	if err != nil {
+		return err
+	}
+	jsonData, err := json.Marshal(dataEncrypt)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s:%s/api/bank_cards/id/%d", s.baseURL, s.port, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errors.ErrNotFound
+	}
+	if resp.StatusCode == http.StatusConflict {
+		return errors.ErrCardExists
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update bank card (code %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// DeleteCard удаляет карту по id, найденному по названию: так же работает
+// и при зашифрованных названиях.
+func (s *lockBoxService) DeleteCard(ctx context.Context, name string) error {
+	card, err := s.findCard(ctx, name)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s:%s/api/bank_cards/id/%d", s.baseURL, s.port, card.ID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errors.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete bank card (code %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	"io"
+	"net/http"
+	neturl "net/url"
+)
+
+func (s *lockBoxService) StartUpload(ctx context.Context, file *models.BinaryFile) (*models.UploadSession, error) {
+	jsonData, err := json.Marshal(file)
+	if err != nil {
+		return nil, err
+	}
+
+	url := s.baseURL + ":" + s.port + "/api/binaries/uploads"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to start upload (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var session models.UploadSession
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &session, nil
+}
+
+// UploadChunk шифрует кусок файла и отправляет его телом запроса без JSON-обёртки.
+func (s *lockBoxService) UploadChunk(ctx context.Context, uploadID, index int, chunk []byte) error {
+	encrypted, err := crypt.EncryptChunk(chunk, s.encryptor)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s:%s/api/binaries/uploads/%d/chunks/%d", s.baseURL, s.port, uploadID, index)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(encrypted))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to upload chunk %d (code %d): %s", index, resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (s *lockBoxService) CompleteUpload(ctx context.Context, uploadID int) error {
+	url := fmt.Sprintf("%s:%s/api/binaries/uploads/%d/complete", s.baseURL, s.port, uploadID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to complete upload (code %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (s *lockBoxService) GetBinary(ctx context.Context, name string) (*models.BinaryFile, error) {
+	url := fmt.Sprintf("%s:%s/api/binaries/%s", s.baseURL, s.port, neturl.PathEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get file (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var file models.BinaryFile
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &file, nil
+}
+
+func (s *lockBoxService) GetBinaries(ctx context.Context) (*[]models.BinaryFile, error) {
+	url := fmt.Sprintf("%s:%s/api/binaries/", s.baseURL, s.port)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get files (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var files []models.BinaryFile
+	if err := json.NewDecoder(resp.Body).Decode(&files); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &files, nil
+}
+
+func (s *lockBoxService) DownloadChunk(ctx context.Context, name string, index int) ([]byte, error) {
+	url := fmt.Sprintf("%s:%s/api/binaries/%s/chunks/%d", s.baseURL, s.port, neturl.PathEscape(name), index)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to download chunk %d (code %d): %s", index, resp.StatusCode, string(body))
+	}
+
+	encrypted, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return crypt.DecryptChunk(encrypted, s.encryptor)
+}
+
+func (s *lockBoxService) DeleteBinary(ctx context.Context, name string) error {
+	url := fmt.Sprintf("%s:%s/api/binaries/%s", s.baseURL, s.port, neturl.PathEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errors.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete file (code %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -3,8 +3,10 @@ package clients
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"github.com/golang-jwt/jwt/v4"
 	errors "gophKeeper/internal/client/errors"
 	"gophKeeper/internal/client/services/lockbox/models"
 	"gophKeeper/pkg/crypt"
@@ -23,25 +25,191 @@ type LockBoxService interface {
 	AuthUser(ctx context.Context, username, password string) (string, error)
 	Authenticated() bool
 	UpdateOrCreate(ctx context.Context, data *models.LockBox) error
+	SyncLockBoxes(ctx context.Context, items []models.LockBox) (*[]models.LockBox, error)
+	CreateCard(ctx context.Context, data *models.BankCardInput) (int, error)
+	GetCard(ctx context.Context, name string) (*models.BankCard, error)
+	GetCards(ctx context.Context) (*[]models.BankCard, error)
+	UpdateCard(ctx context.Context, id int, data *models.BankCardInput) error
+	DeleteCard(ctx context.Context, name string) error
+	CreateNote(ctx context.Context, data *models.NoteInput) (int, error)
+	GetNote(ctx context.Context, name string) (*models.Note, error)
+	GetNotes(ctx context.Context) (*[]models.Note, error)
+	UpdateNote(ctx context.Context, data *models.NoteInput) error
+	UpdateOrCreateNote(ctx context.Context, data *models.Note) error
+	DeleteNote(ctx context.Context, name string) error
+	StartUpload(ctx context.Context, file *models.BinaryFile) (*models.UploadSession, error)
+	UploadChunk(ctx context.Context, uploadID, index int, chunk []byte) error
+	CompleteUpload(ctx context.Context, uploadID int) error
+	GetBinary(ctx context.Context, name string) (*models.BinaryFile, error)
+	GetBinaries(ctx context.Context) (*[]models.BinaryFile, error)
+	DownloadChunk(ctx context.Context, name string, index int) ([]byte, error)
+	DeleteBinary(ctx context.Context, name string) error
+	GetKDFParams(ctx context.Context) (*models.KDFParams, error)
+	SetKDFParams(ctx context.Context, params *models.KDFParams) error
+	SetEncryptor(encryptor crypt.Encryptor)
+	SetNameEncryption(enabled bool)
+	SetDeviceName(name string)
+	SealLockBox(data *models.LockBox) (*models.LockBox, error)
+	SealBankCard(data *models.BankCardInput) (*models.BankCardInput, error)
+	WithEncryptor(encryptor crypt.Encryptor) LockBoxService
+	RotateKey(ctx context.Context, batch *models.RotationBatch) error
+	Keyfunc() jwt.Keyfunc
+	VerifyMFA(ctx context.Context, code string) (string, error)
+	EnrollTOTP(ctx context.Context) (*models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, code string) error
+	GetSessions(ctx context.Context) (*[]models.Session, error)
+	RevokeSession(ctx context.Context, id int) error
+	RevokeOtherSessions(ctx context.Context) (int, error)
+	GetAccount(ctx context.Context) (*models.Account, error)
+	ChangePassword(ctx context.Context, username, oldPassword, newPassword string) error
+	RenameAccount(ctx context.Context, username, password, newUsername string) error
+	DeleteAccount(ctx context.Context, username, password string) error
+	GetAuditEvents(ctx context.Context, limit int) ([]models.AuditEvent, error)
+	GetLockBoxHistory(ctx context.Context, name string) ([]models.LockBoxRevision, error)
+	RestoreLockBox(ctx context.Context, name string, revision int) error
+	GetTrash(ctx context.Context) ([]models.LockBox, error)
+	RestoreFromTrash(ctx context.Context, id int) error
+	EmptyTrash(ctx context.Context) (int, error)
+	GetChanges(ctx context.Context, since int64, after int) (*models.ChangeSet, error)
+	PushChanges(ctx context.Context, changes []models.LockBoxChange) (*models.PushResult, error)
+	WatchChanges(ctx context.Context, changed func(seq int64)) error
 }
 
-var key string = "superSecretKey19"
-
 type lockBoxService struct {
 	baseURL   string
 	port      string
-	authToken string
+	tokens    *tokenStore
+	keys      *keyCache
 	client    *http.Client
 	encryptor crypt.Encryptor
+	// refresh обменивает refresh-токен на новую пару; gRPC-клиент подменяет
+	// его своим вызовом.
+	refresh func(ctx context.Context, refreshToken string) (*authTokens, error)
+	// encryptNames включает режим, в котором сервер не видит имён записей:
+	// имя шифруется, а запись адресуется по id и слепому индексу имени.
+	encryptNames bool
+	// deviceName — имя устройства в списке сессий аккаунта.
+	deviceName string
+	// validators — ответы на чтение записей с их ETag и Last-Modified.
+	validators *validatorCache
 }
 
 func NewLockBoxService(baseURL string, port string) LockBoxService {
-	return &lockBoxService{
-		baseURL:   baseURL,
-		port:      port,
-		client:    &http.Client{},
-		encryptor: crypt.New(key),
+	return NewLockBoxServiceTLS(baseURL, port, nil)
+}
+
+// NewLockBoxServiceTLS создаёт клиент, который проверяет сервер по tlsConfig:
+// свой CA, пины ключа, сертификат клиента. nil — настройки net/http по умолчанию.
+func NewLockBoxServiceTLS(baseURL string, port string, tlsConfig *tls.Config) LockBoxService {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	s := &lockBoxService{
+		baseURL:    baseURL,
+		port:       port,
+		tokens:     &tokenStore{},
+		encryptor:  crypt.Locked(),
+		validators: &validatorCache{},
+	}
+	s.client = &http.Client{Transport: &refreshTransport{base: transport, service: s}}
+	s.refresh = s.refreshREST
+	s.keys = &keyCache{fetch: s.fetchJWKS}
+	return s
+}
+
+// SetEncryptor подменяет шифратор после разблокировки хранилища мастер-паролем.
+func (s *lockBoxService) SetEncryptor(encryptor crypt.Encryptor) {
+	s.encryptor = encryptor
+}
+
+// SetNameEncryption включает или выключает шифрование имён записей.
+func (s *lockBoxService) SetNameEncryption(enabled bool) {
+	s.encryptNames = enabled
+}
+
+// SetDeviceName задаёт имя, под которым вход виден в списке сессий.
+func (s *lockBoxService) SetDeviceName(name string) {
+	s.deviceName = name
+}
+
+// sealName возвращает имя в том виде, в каком оно уходит на сервер, и его
+// слепой индекс. В обычном режиме имя передаётся как есть, индекс пустой.
+func (s *lockBoxService) sealName(name string) (string, string, error) {
+	if !s.encryptNames {
+		return name, "", nil
+	}
+	return crypt.EncryptName(name, s.encryptor)
+}
+
+// openName расшифровывает имя записи, пришедшей с сервера. Записи без индекса
+// созданы до включения режима и хранят имя открыто.
+func (s *lockBoxService) openName(box *models.LockBox) error {
+	if box.NameIndex == "" {
+		return nil
+	}
+	name, err := s.encryptor.Decrypt(box.Name)
+	if err != nil {
+		return err
+	}
+	box.Name = name
+	return nil
+}
+
+// SealLockBox шифрует запись для отправки на сервер вместе с именем, если
+// включено шифрование имён.
+func (s *lockBoxService) SealLockBox(data *models.LockBox) (*models.LockBox, error) {
+	dataEncrypt, err := crypt.EncryptLockBox(data, s.encryptor)
+	if err != nil {
+		return nil, err
+	}
+	dataEncrypt.Name, dataEncrypt.NameIndex, err = s.sealName(data.Name)
+	if err != nil {
+		return nil, err
+	}
+	return dataEncrypt, nil
+}
+
+// getByIndex находит запись по слепому индексу имени, не расшифровывая её.
+func (s *lockBoxService) getByIndex(ctx context.Context, name string) (*models.LockBox, error) {
+	path, err := s.indexPath(name)
+	if err != nil {
+		return nil, err
+	}
+	status, body, err := s.getValidated(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, errors.ErrNotFound
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("failed to get lockbox (code %d): %s", status, string(body))
+	}
+
+	var lockBox models.LockBox
+	if err := json.Unmarshal(body, &lockBox); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &lockBox, nil
+}
+
+// indexPath — путь чтения записи по слепому индексу её имени.
+func (s *lockBoxService) indexPath(name string) (string, error) {
+	index, err := crypt.BlindIndex(name, s.encryptor)
+	if err != nil {
+		return "", err
 	}
+	return "/api/lock_boxes/index/" + index, nil
+}
+
+// WithEncryptor возвращает копию сервиса с тем же токеном, но другим ключом.
+// Используется при смене ключа, когда одновременно нужны старый и новый.
+func (s *lockBoxService) WithEncryptor(encryptor crypt.Encryptor) LockBoxService {
+	clone := *s
+	clone.encryptor = encryptor
+	return &clone
 }
 
 func (s *lockBoxService) Create(ctx context.Context, data *models.LockBoxInput) (int, error) {
@@ -49,6 +217,10 @@ func (s *lockBoxService) Create(ctx context.Context, data *models.LockBoxInput)
 	if err != nil {
 		return 0, err
 	}
+	dataEncrypt.Name, dataEncrypt.NameIndex, err = s.sealName(data.Name)
+	if err != nil {
+		return 0, err
+	}
 	jsonData, err := json.Marshal(dataEncrypt)
 	if err != nil {
 		return 0, err
@@ -61,7 +233,7 @@ func (s *lockBoxService) Create(ctx context.Context, data *models.LockBoxInput)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", s.authToken)
+	req.Header.Set("Authorization", s.tokens.access())
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -84,63 +256,59 @@ func (s *lockBoxService) Create(ctx context.Context, data *models.LockBoxInput)
 }
 
 func (s *lockBoxService) Get(ctx context.Context, name string) (*models.LockBox, error) {
-	url := fmt.Sprintf("%s:%s/api/lock_boxes/%s", s.baseURL, s.port, name)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
+	if s.encryptNames {
+		lockBox, err := s.getByIndex(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.openName(lockBox); err != nil {
+			return nil, err
+		}
+		return crypt.DecryptLockBox(lockBox, s.encryptor)
 	}
-	req.Header.Set("Authorization", s.authToken)
 
-	resp, err := s.client.Do(req)
+	status, body, err := s.getValidated(ctx, "/api/lock_boxes/"+name)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get lockbox (code %d): %s", resp.StatusCode, string(body))
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("failed to get lockbox (code %d): %s", status, string(body))
 	}
 
 	var lockBox models.LockBox
-	if err := json.NewDecoder(resp.Body).Decode(&lockBox); err != nil {
+	if err := json.Unmarshal(body, &lockBox); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
-	log.Println(lockBox)
 	dataDecrypt, err := crypt.DecryptLockBox(&lockBox, s.encryptor)
 	if err != nil {
 		return nil, err
 	}
-	log.Println(dataDecrypt)
 	return dataDecrypt, nil
 }
 
 func (s *lockBoxService) GetAll(ctx context.Context) (*[]models.LockBox, error) {
-	url := fmt.Sprintf("%s:%s/api/lock_boxes/", s.baseURL, s.port)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	status, body, err := s.getValidated(ctx, "/api/lock_boxes/")
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", s.authToken)
 
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return nil, err
+	// Сервер отвечает 404, если у пользователя нет ни одного lockbox.
+	if status == http.StatusNotFound {
+		return nil, errors.ErrNotFound
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get lockboxes (code %d): %s", resp.StatusCode, string(body))
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("failed to get lockboxes (code %d): %s", status, string(body))
 	}
 
 	var lockBoxes []models.LockBox
-	if err := json.NewDecoder(resp.Body).Decode(&lockBoxes); err != nil {
+	if err := json.Unmarshal(body, &lockBoxes); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 	datesDecrypt := make([]models.LockBox, len(lockBoxes))
 	for i := range lockBoxes {
+		if err := s.openName(&lockBoxes[i]); err != nil {
+			return nil, err
+		}
 		dataDecrypt, err := crypt.DecryptLockBox(&lockBoxes[i], s.encryptor)
 		if err != nil {
 			return nil, err
@@ -151,13 +319,35 @@ func (s *lockBoxService) GetAll(ctx context.Context) (*[]models.LockBox, error)
 	return &datesDecrypt, nil
 }
 
+// Update меняет запись. Правка уходит с ETag записи — сохранённым при чтении
+// через этот сервис или полученным прямо перед правкой, — и изменённая с тех
+// пор на другом устройстве запись не перезаписывается: ErrLockBoxChanged.
 func (s *lockBoxService) Update(ctx context.Context, data *models.LockBoxInput) error {
 
 	url := fmt.Sprintf("%s:%s/api/lock_boxes/", s.baseURL, s.port)
+	readPath := "/api/lock_boxes/" + data.Name
 	dataEncrypt, err := crypt.EncryptStruct(data, s.encryptor)
 	if err != nil {
 		return err
 	}
+	if s.encryptNames {
+		lockBox, err := s.getByIndex(ctx, data.Name)
+		if err != nil {
+			return err
+		}
+		url = fmt.Sprintf("%s:%s/api/lock_boxes/id/%d", s.baseURL, s.port, lockBox.ID)
+		if readPath, err = s.indexPath(data.Name); err != nil {
+			return err
+		}
+		dataEncrypt.Name, dataEncrypt.NameIndex, err = s.sealName(data.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.readValidator(ctx, readPath); err != nil {
+		return err
+	}
 
 	jsonData, err := json.Marshal(dataEncrypt)
 	if err != nil {
@@ -169,7 +359,8 @@ func (s *lockBoxService) Update(ctx context.Context, data *models.LockBoxInput)
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", s.authToken)
+	req.Header.Set("Authorization", s.tokens.access())
+	s.setIfMatch(req, readPath)
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -177,6 +368,11 @@ func (s *lockBoxService) Update(ctx context.Context, data *models.LockBoxInput)
 	}
 	defer resp.Body.Close()
 
+	// Сохранённое тело устарело в любом случае: запись изменили здесь или там.
+	s.validators.drop(readPath)
+	if resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusConflict {
+		return errors.ErrLockBoxChanged
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("failed to update lockbox (code %d): %s", resp.StatusCode, string(body))
@@ -185,14 +381,31 @@ func (s *lockBoxService) Update(ctx context.Context, data *models.LockBoxInput)
 	return nil
 }
 
+// Delete удаляет запись в корзину; с ETag прочитанной записи, как Update.
 func (s *lockBoxService) Delete(ctx context.Context, name string) error {
 	url := fmt.Sprintf("%s:%s/api/lock_boxes/%s", s.baseURL, s.port, name)
+	readPath := "/api/lock_boxes/" + name
+	if s.encryptNames {
+		lockBox, err := s.getByIndex(ctx, name)
+		if err != nil {
+			return err
+		}
+		url = fmt.Sprintf("%s:%s/api/lock_boxes/id/%d", s.baseURL, s.port, lockBox.ID)
+		if readPath, err = s.indexPath(name); err != nil {
+			return err
+		}
+	}
 
+	// Удаление всегда условное: без ETag в кэше он читается заранее.
+	if err := s.readValidator(ctx, readPath); err != nil {
+		return err
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Authorization", s.authToken)
+	req.Header.Set("Authorization", s.tokens.access())
+	s.setIfMatch(req, readPath)
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -200,6 +413,10 @@ func (s *lockBoxService) Delete(ctx context.Context, name string) error {
 	}
 	defer resp.Body.Close()
 
+	s.validators.drop(readPath)
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return errors.ErrLockBoxChanged
+	}
 	if resp.StatusCode != http.StatusNoContent {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("failed to delete lockbox (code %d): %s", resp.StatusCode, string(body))
@@ -213,7 +430,12 @@ func (s *lockBoxService) RegisterUser(ctx context.Context, username, password st
 		return errors.ErrUsernameAndPasswordRequired
 	}
 
-	data := map[string]string{"username": username, "password": password}
+	// Сервер получает только соль и верификатор SRP, пароль остаётся у клиента.
+	verifier, err := newSRPVerifier(username, password)
+	if err != nil {
+		return err
+	}
+	data := map[string]string{"username": username, "srp_salt": verifier.Salt, "srp_verifier": verifier.Verifier}
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request body: %w", err)
@@ -249,51 +471,76 @@ func (s *lockBoxService) AuthUser(ctx context.Context, username, password string
 		return "", errors.ErrUsernameAndPasswordRequired
 	}
 
+	tokens, err := s.authSRP(ctx, username, password)
+	if err == errors.ErrSRPNotSet {
+		// Аккаунт создан до перехода на SRP: входим по паролю последний раз
+		// и сразу сохраняем верификатор. С 2FA верификатор сохранится при
+		// входе, на котором сервер не спросит код.
+		tokens, err = s.authPassword(ctx, username, password)
+		if err != nil {
+			return "", err
+		}
+		token, err := s.tokens.login(tokens)
+		if err != nil {
+			return "", err
+		}
+		if err := s.setSRPVerifier(ctx, username, password); err != nil {
+			log.Println("failed to switch account to srp:", err)
+		}
+		return token, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return s.tokens.login(tokens)
+}
+
+// authPassword — прежний вход, при котором пароль передаётся серверу.
+func (s *lockBoxService) authPassword(ctx context.Context, username, password string) (*authTokens, error) {
 	data := map[string]string{"username": username, "password": password}
 	jsonData, err := json.Marshal(data)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request body: %w", err)
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
 	url := s.baseURL + ":" + s.port + "/api/auth/login"
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	s.setDevice(req)
 
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return "", fmt.Errorf("authentication failed (code %d), and response body could not be read", resp.StatusCode)
+			return nil, fmt.Errorf("authentication failed (code %d), and response body could not be read", resp.StatusCode)
 		}
-		return "", fmt.Errorf("authentication failed (code %d): %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("authentication failed (code %d): %s", resp.StatusCode, string(body))
 	}
 
-	var response struct {
-		Token string `json:"token"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+	var tokens authTokens
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	s.authToken = response.Token
-	return response.Token, nil
+	return &tokens, nil
 }
 
 func (s *lockBoxService) Authenticated() bool {
-	return s.authToken != ""
+	return s.tokens.access() != ""
 }
 
 func (s *lockBoxService) UpdateOrCreate(ctx context.Context, data *models.LockBox) error {
-	dataEncrypt, err := crypt.EncryptLockBox(data, s.encryptor)
+	dataEncrypt, err := s.SealLockBox(data)
 	if err != nil {
 		return err
 	}
@@ -309,13 +556,17 @@ func (s *lockBoxService) UpdateOrCreate(ctx context.Context, data *models.LockBo
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", s.authToken)
+	req.Header.Set("Authorization", s.tokens.access())
 
 	resp, err := s.client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	// Сервер не перезаписывает запись, изменённую после data.Revision.
+	if resp.StatusCode == http.StatusConflict {
+		return errors.ErrLockBoxChanged
+	}
 	if (resp.StatusCode != http.StatusCreated) && (resp.StatusCode != http.StatusOK) {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("failed to create or update lockbox (code %d): %s", resp.StatusCode, string(body))
@@ -329,3 +580,18 @@ func (s *lockBoxService) UpdateOrCreate(ctx context.Context, data *models.LockBo
 
 	return nil
 }
+
+// SyncLockBoxes отправляет локальные записи и возвращает все записи с сервера.
+// В REST API для этого нет отдельного метода: записи отправляются по одной.
+func (s *lockBoxService) SyncLockBoxes(ctx context.Context, items []models.LockBox) (*[]models.LockBox, error) {
+	for i := range items {
+		if err := s.UpdateOrCreate(ctx, &items[i]); err != nil {
+			return nil, err
+		}
+	}
+	remote, err := s.GetAll(ctx)
+	if err == errors.ErrNotFound {
+		return &[]models.LockBox{}, nil
+	}
+	return remote, err
+}
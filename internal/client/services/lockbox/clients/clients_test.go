@@ -2,6 +2,8 @@ package clients
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/hex"
 	"encoding/json"
 	"net"
 	"net/http"
@@ -9,10 +11,18 @@ import (
 	"strings"
 	"testing"
 
+	errors "gophKeeper/internal/client/errors"
 	"gophKeeper/internal/client/services/lockbox/models"
 	"gophKeeper/pkg/crypt"
+	"gophKeeper/pkg/jwks"
+	"gophKeeper/pkg/srp"
+
+	"github.com/golang-jwt/jwt/v4"
 )
 
+// testKey — ключ, которым тесты разблокируют сервис вместо мастер-пароля.
+const testKey = "superSecretKey19"
+
 // extractHostPort получает базовый URL и порт из адреса тестового сервера.
 func extractHostPort(url string) (baseURL, port string) {
 	trimmed := strings.TrimPrefix(url, "http://")
@@ -41,7 +51,8 @@ func TestCreate(t *testing.T) {
 
 	baseURL, port := extractHostPort(ts.URL)
 	svc := NewLockBoxService(baseURL, port)
-	svc.(*lockBoxService).authToken = "dummy"
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
 
 	input := &models.LockBoxInput{
 		Name: "test",
@@ -70,7 +81,7 @@ func TestGet(t *testing.T) {
 		if !strings.HasPrefix(r.URL.Path, "/api/lock_boxes/") {
 			t.Errorf("Неверный путь запроса: %s", r.URL.Path)
 		}
-		encryptor := crypt.New("superSecretKey19")
+		encryptor := crypt.New(testKey)
 		encrypted := expectedLockBox
 		dataEncrypted, err := crypt.EncryptLockBox(&encrypted, encryptor)
 		if err != nil {
@@ -84,7 +95,8 @@ func TestGet(t *testing.T) {
 
 	baseURL, port := extractHostPort(ts.URL)
 	svc := NewLockBoxService(baseURL, port)
-	svc.(*lockBoxService).authToken = "dummy"
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
 
 	lb, err := svc.Get(context.Background(), "test")
 	if err != nil {
@@ -108,7 +120,7 @@ func TestGetAll(t *testing.T) {
 		if !strings.HasSuffix(r.URL.Path, "/api/lock_boxes/") {
 			t.Errorf("Неверный путь запроса: %s", r.URL.Path)
 		}
-		encryptor := crypt.New("superSecretKey19")
+		encryptor := crypt.New(testKey)
 		encryptedLockBoxes := make([]models.LockBox, len(expectedLockBoxes))
 		copy(encryptedLockBoxes, expectedLockBoxes)
 		var datesEncrypted []models.LockBox
@@ -122,13 +134,14 @@ func TestGetAll(t *testing.T) {
 		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(encryptedLockBoxes)
+		json.NewEncoder(w).Encode(datesEncrypted)
 	}))
 	defer ts.Close()
 
 	baseURL, port := extractHostPort(ts.URL)
 	svc := NewLockBoxService(baseURL, port)
-	svc.(*lockBoxService).authToken = "dummy"
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
 
 	lockBoxes, err := svc.GetAll(context.Background())
 	if err != nil {
@@ -140,20 +153,33 @@ func TestGetAll(t *testing.T) {
 }
 
 func TestUpdate(t *testing.T) {
+	const etag = `"7-3"`
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Без сохранённого ETag запись читается перед правкой.
+		if r.Method == http.MethodGet {
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(models.LockBox{ID: 7, Name: "test", Revision: 3})
+			return
+		}
 		if r.Method != http.MethodPut {
 			t.Errorf("Ожидался PUT, получили %s", r.Method)
 		}
 		if !strings.HasSuffix(r.URL.Path, "/api/lock_boxes/") {
 			t.Errorf("Неверный путь запроса: %s", r.URL.Path)
 		}
+		if r.Header.Get("If-Match") != etag {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer ts.Close()
 
 	baseURL, port := extractHostPort(ts.URL)
 	svc := NewLockBoxService(baseURL, port)
-	svc.(*lockBoxService).authToken = "dummy"
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
 
 	input := &models.LockBoxInput{
 		Name: "test",
@@ -165,26 +191,124 @@ func TestUpdate(t *testing.T) {
 }
 
 func TestDelete(t *testing.T) {
+	const etag = `"7-3"`
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Без сохранённого ETag запись читается перед удалением.
+		if r.Method == http.MethodGet {
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(models.LockBox{ID: 7, Name: "test", Revision: 3})
+			return
+		}
 		if r.Method != http.MethodDelete {
 			t.Errorf("Ожидался DELETE, получили %s", r.Method)
 		}
 		if !strings.HasPrefix(r.URL.Path, "/api/lock_boxes/") {
 			t.Errorf("Неверный путь запроса: %s", r.URL.Path)
 		}
+		if r.Header.Get("If-Match") != etag {
+			t.Errorf("Ожидался If-Match %s, получили %q", etag, r.Header.Get("If-Match"))
+		}
 		w.WriteHeader(http.StatusNoContent)
 	}))
 	defer ts.Close()
 
 	baseURL, port := extractHostPort(ts.URL)
 	svc := NewLockBoxService(baseURL, port)
-	svc.(*lockBoxService).authToken = "dummy"
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
 
 	if err := svc.Delete(context.Background(), "test"); err != nil {
 		t.Fatalf("Неожиданная ошибка: %v", err)
 	}
 }
 
+func TestConditionalRequests(t *testing.T) {
+	const etag = `"7-3"`
+	var gets, notModified int
+	var ifMatch string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			gets++
+			if r.Header.Get("If-None-Match") == etag {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			box := models.LockBox{ID: 7, Name: "test", URL: "example"}
+			dataEncrypted, err := crypt.EncryptLockBox(&box, crypt.New(testKey))
+			if err != nil {
+				t.Fatalf("Ошибка шифрования в тесте: %v", err)
+			}
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(*dataEncrypted)
+		case http.MethodPut:
+			ifMatch = r.Header.Get("If-Match")
+			w.WriteHeader(http.StatusPreconditionFailed)
+		}
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
+
+	for i := 0; i < 2; i++ {
+		lb, err := svc.Get(context.Background(), "test")
+		if err != nil {
+			t.Fatalf("Неожиданная ошибка: %v", err)
+		}
+		if lb.URL != "example" {
+			t.Errorf("Ожидался URL example, получили %q", lb.URL)
+		}
+	}
+	if gets != 2 || notModified != 1 {
+		t.Errorf("Ожидался повторный GET с ответом 304, получили %d GET и %d 304", gets, notModified)
+	}
+
+	err := svc.Update(context.Background(), &models.LockBoxInput{Name: "test", URL: "updated"})
+	if err != errors.ErrLockBoxChanged {
+		t.Fatalf("Ожидалась ErrLockBoxChanged, получили %v", err)
+	}
+	if ifMatch != etag {
+		t.Errorf("Ожидался If-Match %s, получили %q", etag, ifMatch)
+	}
+	if _, ok := svc.(*lockBoxService).validators.get("/api/lock_boxes/test"); ok {
+		t.Error("Сохранённый ответ должен сбрасываться после правки")
+	}
+}
+
+func TestWatchChanges(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/events" || r.Header.Get("Authorization") != "dummy" {
+			t.Errorf("Неверный запрос: %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event:ready\ndata:{}\n\n: ping\n\nevent:lockbox\ndata:{\"seq\":7}\n\n"))
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
+
+	var seqs []int64
+	err := svc.WatchChanges(context.Background(), func(seq int64) {
+		seqs = append(seqs, seq)
+	})
+	if err == nil {
+		t.Error("Закрытый сервером поток должен возвращать ошибку")
+	}
+	if len(seqs) != 2 || seqs[0] != 0 || seqs[1] != 7 {
+		t.Errorf("Ожидались события [0 7], получили %v", seqs)
+	}
+}
+
 func TestRegisterUser(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -193,12 +317,18 @@ func TestRegisterUser(t *testing.T) {
 		if !strings.HasSuffix(r.URL.Path, "/api/users/") {
 			t.Errorf("Неверный путь запроса: %s", r.URL.Path)
 		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["password"]; ok || body["srp_verifier"] == "" {
+			t.Errorf("Ожидался верификатор SRP вместо пароля: %v", body)
+		}
 		w.WriteHeader(http.StatusCreated)
 	}))
 	defer ts.Close()
 
 	baseURL, port := extractHostPort(ts.URL)
 	svc := NewLockBoxService(baseURL, port)
+	svc.SetEncryptor(crypt.New(testKey))
 
 	if err := svc.RegisterUser(context.Background(), "user", "pass"); err != nil {
 		t.Fatalf("Неожиданная ошибка: %v", err)
@@ -206,17 +336,79 @@ func TestRegisterUser(t *testing.T) {
 }
 
 func TestAuthUser(t *testing.T) {
-	expectedToken := "testtoken"
+	salt, _ := srp.NewSalt()
+	verifier := srp.Verifier("user", "pass", salt)
+	var server *srp.Server
+
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			t.Errorf("Ожидался POST, получили %s", r.Method)
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["password"]; ok {
+			t.Errorf("Пароль не должен отправляться на сервер")
 		}
-		if !strings.HasSuffix(r.URL.Path, "/api/auth/login") {
-			t.Errorf("Неверный путь запроса: %s", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/auth/srp/init":
+			A, _ := hex.DecodeString(body["a"])
+			server, _ = srp.NewServer(body["username"], salt, verifier, A)
+			json.NewEncoder(w).Encode(map[string]string{
+				"session": "s1", "salt": hex.EncodeToString(salt), "b": hex.EncodeToString(server.B),
+			})
+		case "/api/auth/srp/verify":
+			m1, _ := hex.DecodeString(body["m1"])
+			m2, err := server.Verify(m1)
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"token": "testtoken", "m2": hex.EncodeToString(m2)})
+		default:
+			t.Errorf("Неожиданный запрос: %s %s", r.Method, r.URL.Path)
 		}
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+
+	if _, err := svc.AuthUser(context.Background(), "user", "wrong"); err != errors.ErrInvalidCredentials {
+		t.Fatalf("Ожидалась ошибка ErrInvalidCredentials, получено: %v", err)
+	}
+
+	token, err := svc.AuthUser(context.Background(), "user", "pass")
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if token != "testtoken" {
+		t.Errorf("Ожидался токен testtoken, получили %s", token)
+	}
+	if !svc.Authenticated() {
+		t.Errorf("Ожидалось, что сервис будет аутентифицирован")
+	}
+}
+
+func TestAuthUserLegacy(t *testing.T) {
+	expectedToken := "testtoken"
+	upgraded := false
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"token": expectedToken})
+		switch r.URL.Path {
+		case "/api/auth/srp/init":
+			w.WriteHeader(http.StatusConflict)
+		case "/api/auth/login":
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]string{"token": expectedToken})
+		case "/api/auth/srp":
+			if r.Header.Get("Authorization") != expectedToken {
+				t.Errorf("Верификатор должен сохраняться с токеном")
+			}
+			upgraded = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Неожиданный запрос: %s %s", r.Method, r.URL.Path)
+		}
 	}))
 	defer ts.Close()
 
@@ -230,8 +422,158 @@ func TestAuthUser(t *testing.T) {
 	if token != expectedToken {
 		t.Errorf("Ожидался токен %s, получили %s", expectedToken, token)
 	}
-	if !svc.Authenticated() {
-		t.Errorf("Ожидалось, что сервис будет аутентифицирован")
+	if !upgraded {
+		t.Errorf("После входа по паролю аккаунт должен перейти на SRP")
+	}
+}
+
+func TestRefreshOnUnauthorized(t *testing.T) {
+	refreshes := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/auth/refresh" {
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["refresh_token"] != "r1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			refreshes++
+			json.NewEncoder(w).Encode(map[string]string{"token": "fresh", "refresh_token": "r2"})
+			return
+		}
+		if r.Header.Get("Authorization") != "fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var box models.LockBox
+		if err := json.NewDecoder(r.Body).Decode(&box); err != nil || box.Name != "test" {
+			t.Errorf("Тело запроса должно повторяться без изменений, получили %+v (%v)", box, err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]int{"id": 1})
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "expired", RefreshToken: "r1"})
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Create(context.Background(), &models.LockBoxInput{Name: "test"}); err != nil {
+			t.Fatalf("Неожиданная ошибка: %v", err)
+		}
+	}
+	if refreshes != 1 {
+		t.Errorf("Ожидалось одно обновление токена, получили %d", refreshes)
+	}
+	if got := svc.(*lockBoxService).tokens.refreshToken; got != "r2" {
+		t.Errorf("Ожидался новый refresh-токен r2, получили %s", got)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "dummy" {
+			t.Errorf("Запрос %s должен идти с токеном", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/auth/sessions":
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"session_id": 5, "device_name": "laptop", "os": "linux", "current": true},
+				{"session_id": 6, "device_name": "ci-runner", "os": "linux"},
+			})
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/auth/sessions/6":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/auth/sessions/7":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/auth/sessions":
+			json.NewEncoder(w).Encode(map[string]int{"revoked": 1})
+		default:
+			t.Errorf("Неожиданный запрос: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
+	ctx := context.Background()
+
+	sessions, err := svc.GetSessions(ctx)
+	if err != nil {
+		t.Fatalf("GetSessions: %v", err)
+	}
+	if len(*sessions) != 2 || !(*sessions)[0].Current || (*sessions)[1].DeviceName != "ci-runner" {
+		t.Errorf("Неверный список сессий: %+v", *sessions)
+	}
+	if err := svc.RevokeSession(ctx, 6); err != nil {
+		t.Errorf("RevokeSession: %v", err)
+	}
+	if err := svc.RevokeSession(ctx, 7); err != errors.ErrNotFound {
+		t.Errorf("Для чужой сессии ожидалась ErrNotFound, получили %v", err)
+	}
+	if revoked, err := svc.RevokeOtherSessions(ctx); err != nil || revoked != 1 {
+		t.Errorf("RevokeOtherSessions: ожидалась 1 сессия, получили %d (%v)", revoked, err)
+	}
+}
+
+func TestLoginSendsDevice(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Device-Name") != "ci-runner" || r.Header.Get("X-Device-OS") == "" {
+			t.Errorf("Вход должен передавать устройство, получили %q %q",
+				r.Header.Get("X-Device-Name"), r.Header.Get("X-Device-OS"))
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.SetDeviceName("ci-runner")
+
+	if _, err := svc.AuthUser(context.Background(), "user", "pass"); err == nil {
+		t.Fatal("Ожидалась ошибка входа")
+	}
+}
+
+func TestVerifyMFA(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/auth/mfa/verify" {
+			t.Errorf("Неожиданный запрос: %s %s", r.Method, r.URL.Path)
+		}
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["mfa_token"] != "partial" || req["code"] != "123456" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"token": "full", "refresh_token": "refresh"})
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	store := svc.(*lockBoxService).tokens
+
+	if _, err := store.login(&authTokens{MFARequired: true, MFAToken: "partial"}); err != errors.ErrMFARequired {
+		t.Fatalf("Ожидалась ErrMFARequired, получили %v", err)
+	}
+	if svc.Authenticated() {
+		t.Fatal("До ввода кода пользователь не должен считаться вошедшим")
+	}
+	if _, err := svc.VerifyMFA(context.Background(), "000000"); err != errors.ErrInvalidMFACode {
+		t.Errorf("Для неверного кода ожидалась ErrInvalidMFACode, получили %v", err)
+	}
+	token, err := svc.VerifyMFA(context.Background(), "123456")
+	if err != nil || token != "full" {
+		t.Fatalf("VerifyMFA: получили %q (%v)", token, err)
+	}
+	if !svc.Authenticated() || store.pendingMFA() != "" {
+		t.Error("После кода пользователь должен войти, а токен входа — сброситься")
 	}
 }
 
@@ -252,7 +594,8 @@ func TestUpdateOrCreate(t *testing.T) {
 
 	baseURL, port := extractHostPort(ts.URL)
 	svc := NewLockBoxService(baseURL, port)
-	svc.(*lockBoxService).authToken = "dummy"
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
 
 	lockBox := &models.LockBox{
 		Name: "test",
@@ -262,3 +605,377 @@ func TestUpdateOrCreate(t *testing.T) {
 		t.Fatalf("Неожиданная ошибка: %v", err)
 	}
 }
+
+func TestCardRoundTrip(t *testing.T) {
+	var stored models.BankCardInput
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/api/bank_cards/create"):
+			json.NewDecoder(r.Body).Decode(&stored)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]int{"id": 1})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/api/bank_cards/visa"):
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(stored)
+		default:
+			t.Errorf("Неожиданный запрос: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
+
+	input := &models.BankCardInput{Name: "visa", Number: "4111111111111111", CVV: "123"}
+	if _, err := svc.CreateCard(context.Background(), input); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if stored.Number == input.Number || stored.CVV == input.CVV {
+		t.Errorf("Реквизиты карты отправлены на сервер в открытом виде: %+v", stored)
+	}
+
+	card, err := svc.GetCard(context.Background(), "visa")
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if card.Number != input.Number || card.CVV != input.CVV {
+		t.Errorf("Ожидалась расшифрованная карта %+v, получили %+v", input, card)
+	}
+}
+
+func TestEncryptedCardNames(t *testing.T) {
+	var stored models.BankCard
+	var updated models.BankCardInput
+	deleted := false
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/api/bank_cards/create"):
+			json.NewDecoder(r.Body).Decode(&stored)
+			stored.ID = 3
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]int{"id": stored.ID})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/bank_cards/index/"+stored.NameIndex:
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(stored)
+		case r.Method == http.MethodPut && r.URL.Path == "/api/bank_cards/id/3":
+			json.NewDecoder(r.Body).Decode(&updated)
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/bank_cards/id/3":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Неожиданный запрос: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.SetNameEncryption(true)
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
+
+	if _, err := svc.CreateCard(context.Background(), &models.BankCardInput{Name: "visa", Number: "4111111111111111", PIN: "1234"}); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if stored.Name == "visa" || stored.NameIndex == "" {
+		t.Fatalf("Название карты отправлено на сервер в открытом виде: %+v", stored)
+	}
+
+	card, err := svc.GetCard(context.Background(), "Visa ")
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if card.Name != "visa" || card.PIN != "1234" {
+		t.Errorf("Ожидалась расшифрованная карта visa, получили %+v", card)
+	}
+
+	// Карта уходит целиком: пустой PIN очищает поле на сервере.
+	if err := svc.UpdateCard(context.Background(), card.ID, &models.BankCardInput{Name: "visa", Number: card.Number}); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if updated.Name == "visa" || updated.NameIndex != stored.NameIndex || updated.PIN != "" {
+		t.Errorf("Ожидалась карта с зашифрованным названием и пустым PIN, получили %+v", updated)
+	}
+
+	if err := svc.DeleteCard(context.Background(), "visa"); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if !deleted {
+		t.Error("Карта должна удаляться по id")
+	}
+}
+
+func TestLockedService(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Запрос не должен отправляться до разблокировки: %s %s", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
+
+	_, err := svc.Create(context.Background(), &models.LockBoxInput{Name: "test", Password: "secret"})
+	if err != crypt.ErrLocked {
+		t.Fatalf("Ожидалась ошибка ErrLocked, получено: %v", err)
+	}
+}
+
+func TestEncryptedNames(t *testing.T) {
+	var stored models.LockBox
+	deleted := false
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/api/lock_boxes/create"):
+			json.NewDecoder(r.Body).Decode(&stored)
+			stored.ID = 7
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]int{"id": stored.ID})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/lock_boxes/index/"):
+			if strings.TrimPrefix(r.URL.Path, "/api/lock_boxes/index/") != stored.NameIndex {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(stored)
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/lock_boxes/id/7":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Неожиданный запрос: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.SetNameEncryption(true)
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
+
+	if _, err := svc.Create(context.Background(), &models.LockBoxInput{Name: "github", Password: "secret"}); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if stored.Name == "github" || stored.NameIndex == "" {
+		t.Fatalf("Имя отправлено на сервер в открытом виде: %+v", stored)
+	}
+
+	// Поиск по индексу не зависит от регистра и пробелов вокруг имени.
+	box, err := svc.Get(context.Background(), " GitHub")
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if box.Name != "github" || box.Password != "secret" {
+		t.Errorf("Ожидалась расшифрованная запись github, получили %+v", box)
+	}
+
+	if err := svc.Delete(context.Background(), "github"); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if !deleted {
+		t.Error("Запись должна удаляться по id")
+	}
+}
+
+func TestKeyfunc(t *testing.T) {
+	_, signer, _ := ed25519.GenerateKey(nil)
+	jwk, _ := jwks.NewKey(signer.Public())
+	fetches := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/auth/jwks" {
+			t.Errorf("Неожиданный запрос: %s %s", r.Method, r.URL.Path)
+		}
+		fetches++
+		json.NewEncoder(w).Encode(jwks.Set{Keys: []jwks.Key{jwk}})
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{"user_id": 7})
+		token.Header["kid"] = kid
+		signed, _ := token.SignedString(key)
+		return signed
+	}
+
+	for i := 0; i < 2; i++ {
+		token, err := jwt.Parse(sign(jwt.SigningMethodEdDSA, jwk.Kid, signer), svc.Keyfunc())
+		if err != nil || !token.Valid {
+			t.Fatalf("Токен сервера не прошёл проверку: %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Ключи должны кешироваться, запросов JWKS: %d", fetches)
+	}
+
+	if _, err := jwt.Parse(sign(jwt.SigningMethodHS256, jwk.Kid, []byte("your-secret-key")), svc.Keyfunc()); err == nil {
+		t.Error("Токен на общем секрете должен отклоняться")
+	}
+	if _, err := jwt.Parse(sign(jwt.SigningMethodEdDSA, "unknown", signer), svc.Keyfunc()); err == nil {
+		t.Error("Токен с неизвестным kid должен отклоняться")
+	}
+}
+
+func TestLockBoxHistory(t *testing.T) {
+	encryptor := crypt.New(testKey)
+	old, err := crypt.EncryptLockBox(&models.LockBox{Name: "mail", Password: "old-secret"}, encryptor)
+	if err != nil {
+		t.Fatalf("Ошибка шифрования в тесте: %v", err)
+	}
+
+	var restored string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/lock_boxes/mail":
+			json.NewEncoder(w).Encode(models.LockBox{ID: 7, Name: "mail"})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/lock_boxes/7/history":
+			json.NewEncoder(w).Encode([]models.LockBoxRevision{{Revision: 3, LockBoxID: 7, LockBox: *old}})
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/lock_boxes/7/history/"):
+			restored = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.SetEncryptor(encryptor)
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
+	ctx := context.Background()
+
+	revisions, err := svc.GetLockBoxHistory(ctx, "mail")
+	if err != nil {
+		t.Fatalf("GetLockBoxHistory: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Revision != 3 || revisions[0].Password != "old-secret" {
+		t.Fatalf("Версия не расшифрована: %+v", revisions)
+	}
+
+	if err := svc.RestoreLockBox(ctx, "mail", 3); err != nil {
+		t.Fatalf("RestoreLockBox: %v", err)
+	}
+	if restored != "/api/lock_boxes/7/history/3/restore" {
+		t.Errorf("Неверный путь восстановления: %s", restored)
+	}
+	if err := svc.RestoreLockBox(ctx, "missing", 3); err != errors.ErrNotFound {
+		t.Errorf("Ожидалась ErrNotFound, получено: %v", err)
+	}
+}
+
+func TestLockBoxTrash(t *testing.T) {
+	encryptor := crypt.New(testKey)
+	deleted, err := crypt.EncryptLockBox(&models.LockBox{ID: 7, Name: "mail", Password: "secret"}, encryptor)
+	if err != nil {
+		t.Fatalf("Ошибка шифрования в тесте: %v", err)
+	}
+
+	var restored string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/trash/":
+			json.NewEncoder(w).Encode([]models.LockBox{*deleted})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/trash/7/restore":
+			restored = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/trash/":
+			json.NewEncoder(w).Encode(map[string]int{"purged": 1})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.SetEncryptor(encryptor)
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
+	ctx := context.Background()
+
+	trash, err := svc.GetTrash(ctx)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != 7 || trash[0].Password != "secret" {
+		t.Fatalf("Запись из корзины не расшифрована: %+v", trash)
+	}
+
+	if err := svc.RestoreFromTrash(ctx, 7); err != nil {
+		t.Fatalf("RestoreFromTrash: %v", err)
+	}
+	if restored == "" {
+		t.Error("Запрос на восстановление не отправлен")
+	}
+	if err := svc.RestoreFromTrash(ctx, 8); err != errors.ErrNotFound {
+		t.Errorf("Ожидалась ErrNotFound, получено: %v", err)
+	}
+
+	purged, err := svc.EmptyTrash(ctx)
+	if err != nil || purged != 1 {
+		t.Errorf("EmptyTrash: %d, %v", purged, err)
+	}
+}
+
+func TestPushChangesConflict(t *testing.T) {
+	encryptor := crypt.New(testKey)
+	current, err := crypt.EncryptLockBox(&models.LockBox{ID: 3, Name: "mail", Password: "theirs", Revision: 5}, encryptor)
+	if err != nil {
+		t.Fatalf("Ошибка шифрования в тесте: %v", err)
+	}
+
+	var sent []models.LockBoxChange
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Changes []models.LockBoxChange `json:"changes"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		sent = req.Changes
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(models.PushResult{
+			Applied:   1,
+			Revisions: []int{0, 0},
+			Conflicts: []models.PushConflict{{Index: 0, LockBoxChange: models.LockBoxChange{LockBox: *current}}},
+		})
+	}))
+	defer ts.Close()
+
+	baseURL, port := extractHostPort(ts.URL)
+	svc := NewLockBoxService(baseURL, port)
+	svc.SetEncryptor(encryptor)
+	svc.(*lockBoxService).tokens.set(&authTokens{Token: "dummy"})
+
+	result, err := svc.PushChanges(context.Background(), []models.LockBoxChange{
+		{LockBox: models.LockBox{Name: "mail", Password: "mine", Revision: 4}},
+		{Deleted: true, LockBox: models.LockBox{Name: "old", Revision: 2}},
+	})
+	if err != nil {
+		t.Fatalf("PushChanges: %v", err)
+	}
+	if len(sent) != 2 || sent[0].LockBox.Revision != 4 || sent[1].LockBox.Revision != 2 {
+		t.Errorf("Версии правок не отправлены: %+v", sent)
+	}
+	if sent[0].LockBox.Password == "mine" {
+		t.Error("Пароль отправлен в открытом виде")
+	}
+	if len(result.Conflicts) != 1 {
+		t.Fatalf("Ожидался один конфликт: %+v", result)
+	}
+	got := result.Conflicts[0].LockBox
+	if got.Name != "mail" || got.Password != "theirs" || got.Revision != 5 {
+		t.Errorf("Версия сервера не расшифрована: %+v", got)
+	}
+}
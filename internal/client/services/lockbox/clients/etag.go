@@ -0,0 +1,115 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"gophKeeper/internal/client/errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// cachedResponse — тело ответа на чтение и его валидаторы.
+type cachedResponse struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// validatorCache хранит последние ответы на чтение записей по пути запроса.
+// Повторное чтение отправляет их валидаторы, и без изменений на сервере
+// ответ 304 приходит без тела. ETag записи уходит потом в If-Match её правки.
+type validatorCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+func (c *validatorCache) get(path string) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	return entry, ok
+}
+
+func (c *validatorCache) put(path string, entry cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]cachedResponse{}
+	}
+	c.entries[path] = entry
+}
+
+func (c *validatorCache) drop(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, path)
+}
+
+// getValidated выполняет GET path с валидаторами сохранённого ответа. На 304
+// возвращается сохранённое тело со статусом 200, на 200 — новое, и оно
+// запоминается. Тело с другим статусом возвращается как есть.
+func (s *lockBoxService) getValidated(ctx context.Context, path string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+":"+s.port+path, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+	cached, ok := s.validators.get(path)
+	switch {
+	case ok && cached.etag != "":
+		req.Header.Set("If-None-Match", cached.etag)
+	case ok && cached.lastModified != "":
+		req.Header.Set("If-Modified-Since", cached.lastModified)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && ok {
+		return http.StatusOK, cached.body, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	switch etag, modified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"); {
+	case resp.StatusCode == http.StatusOK && (etag != "" || modified != ""):
+		s.validators.put(path, cachedResponse{etag: etag, lastModified: modified, body: body})
+	default:
+		s.validators.drop(path)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// readValidator читает запись по пути readPath, если её ETag ещё не
+// сохранён: сервер не меняет существующую запись без версии.
+func (s *lockBoxService) readValidator(ctx context.Context, readPath string) error {
+	if cached, ok := s.validators.get(readPath); ok && cached.etag != "" {
+		return nil
+	}
+	status, body, err := s.getValidated(ctx, readPath)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return errors.ErrNotFound
+	default:
+		return fmt.Errorf("failed to get lockbox (code %d): %s", status, string(body))
+	}
+}
+
+// setIfMatch добавляет к правке записи ETag, с которым её читали по пути
+// readPath: если запись с тех пор изменили, сервер ответит 412 и правка не
+// затрёт чужую. Без сохранённого ETag правка уходит без условия.
+func (s *lockBoxService) setIfMatch(req *http.Request, readPath string) {
+	if cached, ok := s.validators.get(readPath); ok && cached.etag != "" {
+		req.Header.Set("If-Match", cached.etag)
+	}
+}
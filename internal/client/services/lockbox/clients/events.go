@@ -0,0 +1,86 @@
+package clients
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// eventsIdleTimeout — сколько поток событий может молчать. Сервер шлёт
+// комментарий каждые 15 секунд; дольше тишина значит, что соединение
+// оборвалось без закрытия.
+const eventsIdleTimeout = 45 * time.Second
+
+// WatchChanges держит поток событий сервера и вызывает changed на каждое
+// изменение записей пользователя с номером в ленте изменений. После
+// подключения changed вызывается с 0: пока потока не было, события могли
+// пропасть. Возвращается, когда поток оборвался или отменён ctx.
+func (s *lockBoxService) WatchChanges(ctx context.Context, changed func(seq int64)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+":"+s.port+"/api/events", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to open event stream (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	idle := time.AfterFunc(eventsIdleTimeout, cancel)
+	defer idle.Stop()
+
+	var event, data string
+	lines := bufio.NewScanner(resp.Body)
+	for lines.Scan() {
+		idle.Reset(eventsIdleTimeout)
+		line := lines.Text()
+		switch {
+		case line == "":
+			dispatchEvent(event, data, changed)
+			event, data = "", ""
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+	if err := lines.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.New("event stream closed by server")
+}
+
+// dispatchEvent передаёт changed событие потока. Непонятные данные события
+// lockbox всё равно будят синхронизацию — как событие без номера.
+func dispatchEvent(event, data string, changed func(seq int64)) {
+	switch event {
+	case "ready":
+		changed(0)
+	case "lockbox":
+		var payload struct {
+			Seq int64 `json:"seq"`
+		}
+		_ = json.Unmarshal([]byte(data), &payload)
+		changed(payload.Seq)
+	}
+}
@@ -0,0 +1,446 @@
+package clients
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	pb "gophKeeper/pkg/pb/gophkeeper/v1"
+	"gophKeeper/pkg/srp"
+	"io"
+	"log"
+	"runtime"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// grpcLockBoxService — реализация LockBoxService поверх gRPC. Карт, заметок,
+// файлов, смены ключа, настройки 2FA, списка сессий, журнала аудита,
+// истории записей, корзины, ленты и потока изменений в gRPC API нет, эти методы
+// идут через REST встроенного lockBoxService с тем же токеном и шифратором.
+type grpcLockBoxService struct {
+	*lockBoxService
+	conn  *grpc.ClientConn
+	auth  pb.AuthServiceClient
+	users pb.UserServiceClient
+	locks pb.LockBoxServiceClient
+}
+
+// NewGRPCLockBoxService подключается к gRPC-серверу по адресу host:port.
+// tlsConfig действует и на gRPC, и на REST-запросы; без него и без опций
+// соединение не шифруется.
+func NewGRPCLockBoxService(baseURL, port, grpcAddr string, tlsConfig *tls.Config, opts ...grpc.DialOption) (LockBoxService, error) {
+	if len(opts) == 0 {
+		creds := insecure.NewCredentials()
+		if tlsConfig != nil {
+			creds = credentials.NewTLS(tlsConfig)
+		}
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	}
+	rest := NewLockBoxServiceTLS(baseURL, port, tlsConfig).(*lockBoxService)
+	opts = append(opts, grpc.WithChainUnaryInterceptor(refreshInterceptor(rest)))
+	conn, err := grpc.NewClient(grpcAddr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newGRPCLockBoxService(rest, conn), nil
+}
+
+// refreshInterceptor повторяет вызов с новым токеном, если сервер отклонил
+// истёкший access-токен. Поток Sync не повторяется: его токен обновит первый
+// же унарный вызов, а синхронизация запускается по таймеру.
+func refreshInterceptor(rest *lockBoxService) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if status.Code(err) != codes.Unauthenticated {
+			return err
+		}
+		md, _ := metadata.FromOutgoingContext(ctx)
+		stale := md.Get("authorization")
+		if len(stale) == 0 || stale[0] == "" {
+			return err
+		}
+		token, refreshErr := rest.tokens.renew(ctx, stale[0], rest.refresh)
+		if refreshErr != nil {
+			return err
+		}
+		md = md.Copy()
+		md.Set("authorization", token)
+		return invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
+	}
+}
+
+func newGRPCLockBoxService(rest *lockBoxService, conn *grpc.ClientConn) *grpcLockBoxService {
+	s := &grpcLockBoxService{
+		lockBoxService: rest,
+		conn:           conn,
+		auth:           pb.NewAuthServiceClient(conn),
+		users:          pb.NewUserServiceClient(conn),
+		locks:          pb.NewLockBoxServiceClient(conn),
+	}
+	rest.refresh = s.refreshGRPC
+	return s
+}
+
+// refreshGRPC обновляет сессию вызовом AuthService.Refresh; его же
+// использует REST-часть клиента.
+func (s *grpcLockBoxService) refreshGRPC(ctx context.Context, refreshToken string) (*authTokens, error) {
+	resp, err := s.auth.Refresh(ctx, &pb.RefreshRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return &authTokens{Token: resp.Token, RefreshToken: resp.RefreshToken}, nil
+}
+
+func (s *grpcLockBoxService) WithEncryptor(encryptor crypt.Encryptor) LockBoxService {
+	clone := *s.lockBoxService
+	clone.encryptor = encryptor
+	return newGRPCLockBoxService(&clone, s.conn)
+}
+
+// withToken передаёт токен в метаданных, как заголовок Authorization в REST.
+func (s *grpcLockBoxService) withToken(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", s.tokens.access())
+}
+
+// withDevice передаёт серверу имя устройства и ОС для списка сессий.
+func (s *grpcLockBoxService) withDevice(ctx context.Context) context.Context {
+	if s.deviceName != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-device-name", s.deviceName)
+	}
+	return metadata.AppendToOutgoingContext(ctx, "x-device-os", runtime.GOOS)
+}
+
+// fromStatus переводит коды gRPC в ошибки клиента, которые возвращает и REST-реализация.
+func fromStatus(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return errors.ErrNotFound
+	case codes.Unauthenticated:
+		return errors.ErrInvalidCredentials
+	case codes.FailedPrecondition:
+		return errors.ErrSRPNotSet
+	case codes.AlreadyExists:
+		return errors.ErrKDFAlreadySet
+	case codes.Aborted:
+		return errors.ErrLockBoxChanged
+	default:
+		return err
+	}
+}
+
+func toProtoLockBox(box *models.LockBox) *pb.LockBox {
+	return &pb.LockBox{
+		Id:          int64(box.ID),
+		Name:        box.Name,
+		NameIndex:   box.NameIndex,
+		Url:         box.URL,
+		Login:       box.Login,
+		Password:    box.Password,
+		Description: box.Description,
+		Revision:    int64(box.Revision),
+	}
+}
+
+func fromProtoLockBox(box *pb.LockBox) *models.LockBox {
+	lockBox := &models.LockBox{
+		ID:          int(box.Id),
+		Name:        box.Name,
+		NameIndex:   box.NameIndex,
+		URL:         box.Url,
+		Login:       box.Login,
+		Password:    box.Password,
+		Description: box.Description,
+		CreatedAt:   box.CreatedAt.AsTime(),
+		UpdatedAt:   box.UpdatedAt.AsTime(),
+		Revision:    int(box.Revision),
+	}
+	if box.DeletedAt != nil {
+		lockBox.DeletedAt = box.DeletedAt.AsTime()
+	}
+	return lockBox
+}
+
+// open расшифровывает запись, полученную с сервера.
+func (s *grpcLockBoxService) open(box *pb.LockBox) (*models.LockBox, error) {
+	lockBox := fromProtoLockBox(box)
+	if err := s.openName(lockBox); err != nil {
+		return nil, err
+	}
+	return crypt.DecryptLockBox(lockBox, s.encryptor)
+}
+
+// key адресует запись по слепому индексу, если имена шифруются, иначе по имени.
+func (s *grpcLockBoxService) key(name string) (*pb.LockBoxKey, error) {
+	if !s.encryptNames {
+		return &pb.LockBoxKey{Key: &pb.LockBoxKey_Name{Name: name}}, nil
+	}
+	index, err := crypt.BlindIndex(name, s.encryptor)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LockBoxKey{Key: &pb.LockBoxKey_NameIndex{NameIndex: index}}, nil
+}
+
+func (s *grpcLockBoxService) Create(ctx context.Context, data *models.LockBoxInput) (int, error) {
+	box, err := s.SealLockBox(&models.LockBox{
+		Name: data.Name, URL: data.URL, Login: data.Login, Password: data.Password, Description: data.Description,
+	})
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.locks.Create(s.withToken(ctx), toProtoLockBox(box))
+	if err != nil {
+		return 0, fromStatus(err)
+	}
+	return int(resp.Id), nil
+}
+
+func (s *grpcLockBoxService) Get(ctx context.Context, name string) (*models.LockBox, error) {
+	key, err := s.key(name)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.locks.Get(s.withToken(ctx), key)
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return s.open(resp)
+}
+
+func (s *grpcLockBoxService) GetAll(ctx context.Context) (*[]models.LockBox, error) {
+	resp, err := s.locks.List(s.withToken(ctx), &emptypb.Empty{})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	lockBoxes := make([]models.LockBox, 0, len(resp.LockBoxes))
+	for _, box := range resp.LockBoxes {
+		lockBox, err := s.open(box)
+		if err != nil {
+			return nil, err
+		}
+		lockBoxes = append(lockBoxes, *lockBox)
+	}
+	return &lockBoxes, nil
+}
+
+// Update меняет запись от версии, которую сервер отдаёт прямо перед правкой.
+// Если запись успели изменить между чтением и записью — ErrLockBoxChanged.
+func (s *grpcLockBoxService) Update(ctx context.Context, data *models.LockBoxInput) error {
+	box, err := s.SealLockBox(&models.LockBox{
+		Name: data.Name, URL: data.URL, Login: data.Login, Password: data.Password, Description: data.Description,
+	})
+	if err != nil {
+		return err
+	}
+	key, err := s.key(data.Name)
+	if err != nil {
+		return err
+	}
+	existing, err := s.locks.Get(s.withToken(ctx), key)
+	if err != nil {
+		return fromStatus(err)
+	}
+	box.Revision = int(existing.Revision)
+	if s.encryptNames {
+		box.ID = int(existing.Id)
+	}
+	if _, err := s.locks.Update(s.withToken(ctx), toProtoLockBox(box)); err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+func (s *grpcLockBoxService) Delete(ctx context.Context, name string) error {
+	key, err := s.key(name)
+	if err != nil {
+		return err
+	}
+	if _, err := s.locks.Delete(s.withToken(ctx), key); err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+func (s *grpcLockBoxService) UpdateOrCreate(ctx context.Context, data *models.LockBox) error {
+	box, err := s.SealLockBox(data)
+	if err != nil {
+		return err
+	}
+	if _, err := s.locks.CreateOrUpdate(s.withToken(ctx), toProtoLockBox(box)); err != nil {
+		return fromStatus(err)
+	}
+	return nil
+}
+
+// SyncLockBoxes передаёт записи одним потоком и получает в нём же все записи пользователя.
+func (s *grpcLockBoxService) SyncLockBoxes(ctx context.Context, items []models.LockBox) (*[]models.LockBox, error) {
+	stream, err := s.locks.Sync(s.withToken(ctx))
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	for i := range items {
+		box, err := s.SealLockBox(&items[i])
+		if err != nil {
+			return nil, err
+		}
+		if err := stream.Send(&pb.SyncRequest{LockBox: toProtoLockBox(box)}); err != nil {
+			return nil, fromStatus(err)
+		}
+		if _, err := stream.Recv(); err != nil {
+			return nil, fromStatus(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	lockBoxes := []models.LockBox{}
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fromStatus(err)
+		}
+		lockBox, err := s.open(event.GetLockBox())
+		if err != nil {
+			return nil, err
+		}
+		lockBoxes = append(lockBoxes, *lockBox)
+	}
+	return &lockBoxes, nil
+}
+
+func (s *grpcLockBoxService) RegisterUser(ctx context.Context, username, password string) error {
+	if username == "" || password == "" {
+		return errors.ErrUsernameAndPasswordRequired
+	}
+	salt, err := srp.NewSalt()
+	if err != nil {
+		return err
+	}
+	_, err = s.users.Register(ctx, &pb.RegisterRequest{
+		Username: username,
+		Srp:      &pb.SRPVerifier{Salt: salt, Verifier: srp.Verifier(username, password, salt)},
+	})
+	return fromStatus(err)
+}
+
+func (s *grpcLockBoxService) AuthUser(ctx context.Context, username, password string) (string, error) {
+	if username == "" || password == "" {
+		return "", errors.ErrUsernameAndPasswordRequired
+	}
+
+	ctx = s.withDevice(ctx)
+	init := func(A []byte) (string, []byte, []byte, error) {
+		resp, err := s.auth.SRPInit(ctx, &pb.SRPInitRequest{Username: username, A: A})
+		if err != nil {
+			return "", nil, nil, fromStatus(err)
+		}
+		return resp.Session, resp.Salt, resp.B, nil
+	}
+	verify := func(session string, m1 []byte) (*authTokens, []byte, error) {
+		resp, err := s.auth.SRPVerify(ctx, &pb.SRPVerifyRequest{Session: session, M1: m1})
+		if err != nil {
+			return nil, nil, fromStatus(err)
+		}
+		return &authTokens{
+			Token:        resp.Token,
+			RefreshToken: resp.RefreshToken,
+			MFARequired:  resp.MfaRequired,
+			MFAToken:     resp.MfaToken,
+		}, resp.M2, nil
+	}
+	tokens, err := srpLogin(username, password, init, verify)
+	if err == errors.ErrSRPNotSet {
+		// Как и в REST: последний вход по паролю и переход на SRP.
+		resp, err := s.auth.Login(ctx, &pb.LoginRequest{Username: username, Password: password})
+		if err != nil {
+			return "", fromStatus(err)
+		}
+		token, err := s.tokens.login(fromLoginResponse(resp))
+		if err != nil {
+			return "", err
+		}
+		if err := s.setVerifier(ctx, username, password); err != nil {
+			log.Println("failed to switch account to srp:", err)
+		}
+		return token, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return s.tokens.login(tokens)
+}
+
+func fromLoginResponse(resp *pb.LoginResponse) *authTokens {
+	return &authTokens{
+		Token:        resp.Token,
+		RefreshToken: resp.RefreshToken,
+		MFARequired:  resp.MfaRequired,
+		MFAToken:     resp.MfaToken,
+	}
+}
+
+// VerifyMFA завершает вход кодом второго фактора.
+func (s *grpcLockBoxService) VerifyMFA(ctx context.Context, code string) (string, error) {
+	resp, err := s.auth.VerifyMFA(s.withDevice(ctx), &pb.VerifyMFARequest{MfaToken: s.tokens.pendingMFA(), Code: code})
+	if err != nil {
+		if status.Code(err) == codes.Unauthenticated {
+			return "", errors.ErrInvalidMFACode
+		}
+		return "", fromStatus(err)
+	}
+	return s.tokens.login(fromLoginResponse(resp))
+}
+
+func (s *grpcLockBoxService) setVerifier(ctx context.Context, username, password string) error {
+	salt, err := srp.NewSalt()
+	if err != nil {
+		return err
+	}
+	_, err = s.auth.SetSRPVerifier(s.withToken(ctx), &pb.SRPVerifier{Salt: salt, Verifier: srp.Verifier(username, password, salt)})
+	return fromStatus(err)
+}
+
+func (s *grpcLockBoxService) GetKDFParams(ctx context.Context) (*models.KDFParams, error) {
+	resp, err := s.users.GetKDFParams(s.withToken(ctx), &emptypb.Empty{})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	// Соль передаётся строкой base64, как её сериализует REST API.
+	salt, err := base64.StdEncoding.DecodeString(resp.Salt)
+	if err != nil {
+		return nil, err
+	}
+	return &models.KDFParams{
+		Salt:    salt,
+		Time:    resp.Time,
+		Memory:  resp.Memory,
+		Threads: uint8(resp.Threads),
+		Check:   resp.Check,
+	}, nil
+}
+
+func (s *grpcLockBoxService) SetKDFParams(ctx context.Context, params *models.KDFParams) error {
+	_, err := s.users.SetKDFParams(s.withToken(ctx), &pb.KDFParams{
+		Salt:    base64.StdEncoding.EncodeToString(params.Salt),
+		Time:    params.Time,
+		Memory:  params.Memory,
+		Threads: uint32(params.Threads),
+		Check:   params.Check,
+	})
+	return fromStatus(err)
+}
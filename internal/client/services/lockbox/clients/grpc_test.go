@@ -0,0 +1,141 @@
+package clients
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	pb "gophKeeper/pkg/pb/gophkeeper/v1"
+	"gophKeeper/pkg/srp"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeGRPCServer хранит записи в памяти и принимает один пароль по SRP.
+type fakeGRPCServer struct {
+	pb.UnimplementedAuthServiceServer
+	pb.UnimplementedLockBoxServiceServer
+	t        *testing.T
+	salt     []byte
+	verifier []byte
+	srp      *srp.Server
+	boxes    []*pb.LockBox
+}
+
+func (f *fakeGRPCServer) SRPInit(ctx context.Context, req *pb.SRPInitRequest) (*pb.SRPInitResponse, error) {
+	server, err := srp.NewServer(req.Username, f.salt, f.verifier, req.A)
+	if err != nil {
+		return nil, err
+	}
+	f.srp = server
+	return &pb.SRPInitResponse{Session: "s1", Salt: f.salt, B: server.B}, nil
+}
+
+func (f *fakeGRPCServer) SRPVerify(ctx context.Context, req *pb.SRPVerifyRequest) (*pb.SRPVerifyResponse, error) {
+	m2, err := f.srp.Verify(req.M1)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SRPVerifyResponse{Token: "grpc-token", M2: m2}, nil
+}
+
+func (f *fakeGRPCServer) checkToken(ctx context.Context) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if got := md.Get("authorization"); len(got) == 0 || got[0] != "grpc-token" {
+		f.t.Errorf("Ожидался токен в метаданных, получили %v", got)
+	}
+}
+
+func (f *fakeGRPCServer) Create(ctx context.Context, req *pb.LockBox) (*pb.LockBoxID, error) {
+	f.checkToken(ctx)
+	req.Id = int64(len(f.boxes) + 1)
+	f.boxes = append(f.boxes, req)
+	return &pb.LockBoxID{Id: req.Id}, nil
+}
+
+func (f *fakeGRPCServer) Get(ctx context.Context, req *pb.LockBoxKey) (*pb.LockBox, error) {
+	f.checkToken(ctx)
+	for _, box := range f.boxes {
+		if box.NameIndex == req.GetNameIndex() {
+			return box, nil
+		}
+	}
+	return nil, io.EOF
+}
+
+func (f *fakeGRPCServer) Sync(stream grpc.BidiStreamingServer[pb.SyncRequest, pb.SyncResponse]) error {
+	f.checkToken(stream.Context())
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		id, _ := f.Create(stream.Context(), req.LockBox)
+		stream.Send(&pb.SyncResponse{Event: &pb.SyncResponse_Ack{Ack: id}})
+	}
+	for _, box := range f.boxes {
+		stream.Send(&pb.SyncResponse{Event: &pb.SyncResponse_LockBox{LockBox: box}})
+	}
+	return nil
+}
+
+func TestGRPCLockBoxService(t *testing.T) {
+	salt, _ := srp.NewSalt()
+	fake := &fakeGRPCServer{t: t, salt: salt, verifier: srp.Verifier("user", "pass", salt)}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterAuthServiceServer(server, fake)
+	pb.RegisterLockBoxServiceServer(server, fake)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	defer conn.Close()
+
+	svc := newGRPCLockBoxService(NewLockBoxService("http://localhost", "0").(*lockBoxService), conn)
+	svc.SetEncryptor(crypt.New(testKey))
+	svc.SetNameEncryption(true)
+
+	ctx := context.Background()
+	if _, err := svc.AuthUser(ctx, "user", "pass"); err != nil {
+		t.Fatalf("Вход по SRP не удался: %v", err)
+	}
+
+	if _, err := svc.Create(ctx, &models.LockBoxInput{Name: "github", Password: "secret"}); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if fake.boxes[0].Name == "github" || fake.boxes[0].Password == "secret" {
+		t.Fatalf("Запись отправлена в открытом виде: %v", fake.boxes[0])
+	}
+
+	box, err := svc.Get(ctx, "github")
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if box.Name != "github" || box.Password != "secret" {
+		t.Errorf("Ожидалась расшифрованная запись github, получили %+v", box)
+	}
+
+	remote, err := svc.SyncLockBoxes(ctx, []models.LockBox{{Name: "mail", Password: "p"}})
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if len(*remote) != 2 || (*remote)[1].Name != "mail" || (*remote)[1].Password != "p" {
+		t.Errorf("Ожидались две расшифрованные записи, получили %+v", *remote)
+	}
+}
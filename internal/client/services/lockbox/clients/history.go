@@ -0,0 +1,75 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	"net/http"
+	"net/url"
+)
+
+// lockBoxID находит id записи на сервере по имени, не расшифровывая её.
+func (s *lockBoxService) lockBoxID(ctx context.Context, name string) (int, error) {
+	if s.encryptNames {
+		lockBox, err := s.getByIndex(ctx, name)
+		if err != nil {
+			return 0, err
+		}
+		return lockBox.ID, nil
+	}
+
+	var lockBox models.LockBox
+	status, err := s.authRequest(ctx, http.MethodGet, "/api/lock_boxes/"+url.PathEscape(name), nil, &lockBox, true)
+	if status == http.StatusNotFound {
+		return 0, errors.ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return lockBox.ID, nil
+}
+
+// GetLockBoxHistory возвращает прежние версии записи name, новые первыми.
+func (s *lockBoxService) GetLockBoxHistory(ctx context.Context, name string) ([]models.LockBoxRevision, error) {
+	id, err := s.lockBoxID(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	var revisions []models.LockBoxRevision
+	path := fmt.Sprintf("/api/lock_boxes/%d/history", id)
+	if _, err := s.authRequest(ctx, http.MethodGet, path, nil, &revisions, true); err != nil {
+		return nil, err
+	}
+
+	for i := range revisions {
+		lockBox := revisions[i].LockBox
+		if err := s.openName(&lockBox); err != nil {
+			return nil, err
+		}
+		opened, err := crypt.DecryptLockBox(&lockBox, s.encryptor)
+		if err != nil {
+			return nil, err
+		}
+		revisions[i].LockBox = *opened
+	}
+	return revisions, nil
+}
+
+// RestoreLockBox возвращает записи name содержимое версии revision.
+func (s *lockBoxService) RestoreLockBox(ctx context.Context, name string, revision int) error {
+	id, err := s.lockBoxID(ctx, name)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/api/lock_boxes/%d/history/%d/restore", id, revision)
+	status, err := s.authRequest(ctx, http.MethodPost, path, nil, nil, true)
+	switch status {
+	case http.StatusNotFound:
+		return errors.ErrNotFound
+	case http.StatusConflict:
+		return errors.ErrExists
+	}
+	return err
+}
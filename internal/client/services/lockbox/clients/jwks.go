@@ -0,0 +1,100 @@
+package clients
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"gophKeeper/pkg/jwks"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// keyCacheTTL — как долго ключи сервера считаются свежими.
+	keyCacheTTL = time.Hour
+	// keyRefetchInterval ограничивает запросы JWKS при токенах с неизвестным kid.
+	keyRefetchInterval = time.Minute
+	keyFetchTimeout    = 10 * time.Second
+)
+
+// keyCache хранит открытые ключи подписи сервера. Неизвестный kid означает
+// ротацию ключей на сервере: кеш перечитывается, но не чаще keyRefetchInterval.
+type keyCache struct {
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+	fetch   func(ctx context.Context) (*jwks.Set, error)
+}
+
+func (c *keyCache) lookup(kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key, ok := c.keys[kid]
+	age := time.Since(c.fetched)
+	if (ok && age < keyCacheTTL) || (!ok && age < keyRefetchInterval) {
+		if !ok {
+			return nil, jwks.ErrUnknownKey
+		}
+		return key, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyFetchTimeout)
+	defer cancel()
+	set, err := c.fetch(ctx)
+	if err != nil {
+		// Сервер недоступен: доверяем уже известному ключу.
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	c.keys = make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if public, err := jwk.PublicKey(); err == nil {
+			c.keys[jwk.Kid] = public
+		}
+	}
+	c.fetched = time.Now()
+
+	if key, ok = c.keys[kid]; !ok {
+		return nil, jwks.ErrUnknownKey
+	}
+	return key, nil
+}
+
+// fetchJWKS запрашивает открытые ключи сервера.
+func (s *lockBoxService) fetchJWKS(ctx context.Context) (*jwks.Set, error) {
+	url := s.baseURL + ":" + s.port + "/api/auth/jwks"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get jwks (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var set jwks.Set
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &set, nil
+}
+
+// Keyfunc проверяет подпись токенов по открытым ключам сервера.
+func (s *lockBoxService) Keyfunc() jwt.Keyfunc {
+	return jwks.Keyfunc(s.keys.lookup)
+}
@@ -0,0 +1,69 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"io"
+	"net/http"
+)
+
+func (s *lockBoxService) GetKDFParams(ctx context.Context) (*models.KDFParams, error) {
+	url := s.baseURL + ":" + s.port + "/api/users/kdf"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get kdf params (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var params models.KDFParams
+	if err := json.NewDecoder(resp.Body).Decode(&params); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &params, nil
+}
+
+func (s *lockBoxService) SetKDFParams(ctx context.Context, params *models.KDFParams) error {
+	jsonData, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	url := s.baseURL + ":" + s.port + "/api/users/kdf"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return errors.ErrKDFAlreadySet
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to set kdf params (code %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"io"
+	"net/http"
+)
+
+// authRequest отправляет запрос к /api/auth и разбирает ответ в out. withToken —
+// запрос от имени вошедшего пользователя.
+func (s *lockBoxService) authRequest(ctx context.Context, method, path string, payload, out any, withToken bool) (int, error) {
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	url := s.baseURL + ":" + s.port + path
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if withToken {
+		req.Header.Set("Authorization", s.tokens.access())
+	}
+	s.setDevice(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, fmt.Errorf("request failed (code %d): %s", resp.StatusCode, string(data))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// VerifyMFA завершает вход, начатый AuthUser, кодом из приложения-аутентификатора
+// или кодом восстановления.
+func (s *lockBoxService) VerifyMFA(ctx context.Context, code string) (string, error) {
+	req := map[string]string{"mfa_token": s.tokens.pendingMFA(), "code": code}
+	var tokens authTokens
+	status, err := s.authRequest(ctx, http.MethodPost, "/api/auth/mfa/verify", req, &tokens, false)
+	if status == http.StatusUnauthorized {
+		return "", errors.ErrInvalidMFACode
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.tokens.login(&tokens)
+}
+
+// EnrollTOTP получает секрет для приложения-аутентификатора. 2FA включится
+// после ConfirmTOTP с кодом из приложения.
+func (s *lockBoxService) EnrollTOTP(ctx context.Context) (*models.TOTPEnrollment, error) {
+	var enrollment models.TOTPEnrollment
+	if _, err := s.authRequest(ctx, http.MethodPost, "/api/auth/totp", nil, &enrollment, true); err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// ConfirmTOTP включает 2FA и возвращает одноразовые коды восстановления.
+func (s *lockBoxService) ConfirmTOTP(ctx context.Context, code string) ([]string, error) {
+	var response struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	status, err := s.authRequest(ctx, http.MethodPost, "/api/auth/totp/confirm", map[string]string{"code": code}, &response, true)
+	if status == http.StatusForbidden {
+		return nil, errors.ErrInvalidMFACode
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response.RecoveryCodes, nil
+}
+
+// DisableTOTP отключает 2FA; подходит и код восстановления.
+func (s *lockBoxService) DisableTOTP(ctx context.Context, code string) error {
+	status, err := s.authRequest(ctx, http.MethodDelete, "/api/auth/totp", map[string]string{"code": code}, nil, true)
+	if status == http.StatusForbidden {
+		return errors.ErrInvalidMFACode
+	}
+	return err
+}
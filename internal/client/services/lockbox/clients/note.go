@@ -0,0 +1,201 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	"io"
+	"net/http"
+	neturl "net/url"
+)
+
+func (s *lockBoxService) CreateNote(ctx context.Context, data *models.NoteInput) (int, error) {
+	noteEncrypt, err := crypt.EncryptNote(&models.Note{Name: data.Name, Body: data.Body}, s.encryptor)
+	if err != nil {
+		return 0, err
+	}
+	jsonData, err := json.Marshal(models.NoteInput{Name: noteEncrypt.Name, Body: noteEncrypt.Body})
+	if err != nil {
+		return 0, err
+	}
+
+	url := s.baseURL + ":" + s.port + "/api/notes/create"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return 0, errors.ErrNoteExists
+	}
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("failed to create note (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return response.ID, nil
+}
+
+func (s *lockBoxService) GetNote(ctx context.Context, name string) (*models.Note, error) {
+	url := fmt.Sprintf("%s:%s/api/notes/%s", s.baseURL, s.port, neturl.PathEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get note (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var note models.Note
+	if err := json.NewDecoder(resp.Body).Decode(&note); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return crypt.DecryptNote(&note, s.encryptor)
+}
+
+func (s *lockBoxService) GetNotes(ctx context.Context) (*[]models.Note, error) {
+	url := fmt.Sprintf("%s:%s/api/notes/", s.baseURL, s.port)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get notes (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var notes []models.Note
+	if err := json.NewDecoder(resp.Body).Decode(&notes); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	notesDecrypt := make([]models.Note, len(notes))
+	for i := range notes {
+		note, err := crypt.DecryptNote(&notes[i], s.encryptor)
+		if err != nil {
+			return nil, err
+		}
+		notesDecrypt[i] = *note
+	}
+	return &notesDecrypt, nil
+}
+
+func (s *lockBoxService) UpdateNote(ctx context.Context, data *models.NoteInput) error {
+	noteEncrypt, err := crypt.EncryptNote(&models.Note{Name: data.Name, Body: data.Body}, s.encryptor)
+	if err != nil {
+		return err
+	}
+	jsonData, err := json.Marshal(models.NoteInput{Name: noteEncrypt.Name, Body: noteEncrypt.Body})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s:%s/api/notes/", s.baseURL, s.port)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errors.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update note (code %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (s *lockBoxService) UpdateOrCreateNote(ctx context.Context, data *models.Note) error {
+	noteEncrypt, err := crypt.EncryptNote(data, s.encryptor)
+	if err != nil {
+		return err
+	}
+	jsonData, err := json.Marshal(models.NoteInput{Name: noteEncrypt.Name, Body: noteEncrypt.Body})
+	if err != nil {
+		return err
+	}
+
+	url := s.baseURL + ":" + s.port + "/api/notes/create/update"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to create or update note (code %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (s *lockBoxService) DeleteNote(ctx context.Context, name string) error {
+	url := fmt.Sprintf("%s:%s/api/notes/%s", s.baseURL, s.port, neturl.PathEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errors.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete note (code %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"io"
+	"net/http"
+	"runtime"
+	"sync"
+)
+
+// authTokens — ответ сервера на вход и обновление сессии. При включённой 2FA
+// вход возвращает только MFAToken: токены выдаются после кода.
+type authTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	MFARequired  bool   `json:"mfa_required"`
+	MFAToken     string `json:"mfa_token"`
+}
+
+// tokenStore хранит токены вошедшего пользователя. Один экземпляр разделяют
+// копии сервиса из WithEncryptor, поэтому обновлённый токен видят все.
+type tokenStore struct {
+	mu           sync.Mutex
+	token        string
+	refreshToken string
+	// mfaToken — вход, ожидающий код второго фактора.
+	mfaToken string
+}
+
+func (t *tokenStore) access() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.token
+}
+
+func (t *tokenStore) set(tokens *authTokens) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = tokens.Token
+	t.refreshToken = tokens.RefreshToken
+	t.mfaToken = ""
+}
+
+// login сохраняет результат входа. Если сервер ждёт второй фактор,
+// запоминает токен незавершённого входа и возвращает ErrMFARequired.
+func (t *tokenStore) login(tokens *authTokens) (string, error) {
+	if tokens.MFARequired {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.mfaToken = tokens.MFAToken
+		return "", errors.ErrMFARequired
+	}
+	t.set(tokens)
+	return tokens.Token, nil
+}
+
+func (t *tokenStore) pendingMFA() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mfaToken
+}
+
+// renew обменивает refresh-токен на новую пару, если stale всё ещё текущий
+// токен. Если его уже обновил другой запрос, возвращает свежий без обращения
+// к серверу: refresh-токен одноразовый, и повторный обмен отозвал бы сессию.
+func (t *tokenStore) renew(ctx context.Context, stale string,
+	refresh func(ctx context.Context, refreshToken string) (*authTokens, error)) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != stale {
+		return t.token, nil
+	}
+	if t.refreshToken == "" {
+		return "", errors.ErrInvalidCredentials
+	}
+	tokens, err := refresh(ctx, t.refreshToken)
+	if err != nil {
+		return "", err
+	}
+	t.token = tokens.Token
+	t.refreshToken = tokens.RefreshToken
+	return t.token, nil
+}
+
+// refreshREST обновляет сессию через /api/auth/refresh.
+func (s *lockBoxService) refreshREST(ctx context.Context, refreshToken string) (*authTokens, error) {
+	jsonData, err := json.Marshal(map[string]string{"refresh_token": refreshToken})
+	if err != nil {
+		return nil, err
+	}
+
+	url := s.baseURL + ":" + s.port + "/api/auth/refresh"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, errors.ErrInvalidCredentials
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to refresh session (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var tokens authTokens
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &tokens, nil
+}
+
+// refreshTransport повторяет запрос с новым токеном, если сервер ответил 401
+// на запрос с истёкшим access-токеном. Запросы без токена проходят как есть.
+type refreshTransport struct {
+	base    http.RoundTripper
+	service *lockBoxService
+}
+
+func (t *refreshTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	stale := req.Header.Get("Authorization")
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || stale == "" {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	token, err := t.service.tokens.renew(req.Context(), stale, t.service.refresh)
+	if err != nil {
+		return resp, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	retry.Header.Set("Authorization", token)
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return t.base.RoundTrip(retry)
+}
+
+// setDevice передаёт серверу имя устройства и ОС, чтобы вход можно было
+// узнать в списке сессий.
+func (s *lockBoxService) setDevice(req *http.Request) {
+	if s.deviceName != "" {
+		req.Header.Set("X-Device-Name", s.deviceName)
+	}
+	req.Header.Set("X-Device-OS", runtime.GOOS)
+}
+
+// GetSessions возвращает устройства, с которых выполнен вход в аккаунт.
+func (s *lockBoxService) GetSessions(ctx context.Context) (*[]models.Session, error) {
+	url := s.baseURL + ":" + s.port + "/api/auth/sessions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, errors.ErrInvalidCredentials
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get sessions (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var sessions []models.Session
+	if err := json.NewDecoder(resp.Body).Decode(&sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &sessions, nil
+}
+
+// RevokeSession завершает вход на устройстве id. Отзыв текущей сессии
+// равносилен выходу.
+func (s *lockBoxService) RevokeSession(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%s:%s/api/auth/sessions/%d", s.baseURL, s.port, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errors.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to revoke session (code %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// RevokeOtherSessions завершает вход на всех устройствах, кроме текущего,
+// и возвращает число отозванных сессий.
+func (s *lockBoxService) RevokeOtherSessions(ctx context.Context) (int, error) {
+	url := s.baseURL + ":" + s.port + "/api/auth/sessions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("failed to revoke sessions (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Revoked int `json:"revoked"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result.Revoked, nil
+}
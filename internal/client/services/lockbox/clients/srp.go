@@ -0,0 +1,163 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/pkg/srp"
+	"io"
+	"net/http"
+)
+
+type srpVerifier struct {
+	Salt     string `json:"srp_salt"`
+	Verifier string `json:"srp_verifier"`
+}
+
+// newSRPVerifier вычисляет верификатор, который сервер хранит вместо пароля.
+func newSRPVerifier(username, password string) (*srpVerifier, error) {
+	salt, err := srp.NewSalt()
+	if err != nil {
+		return nil, err
+	}
+	return &srpVerifier{
+		Salt:     hex.EncodeToString(salt),
+		Verifier: hex.EncodeToString(srp.Verifier(username, password, salt)),
+	}, nil
+}
+
+// postSRP отправляет шаг обмена SRP и разбирает ответ в out.
+func (s *lockBoxService) postSRP(ctx context.Context, path string, payload, out any) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	url := s.baseURL + ":" + s.port + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.setDevice(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusConflict {
+		return errors.ErrSRPNotSet
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return errors.ErrInvalidCredentials
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		return errors.ErrAccountDisabled
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("authentication failed (code %d): %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// srpLogin проводит обмен SRP-6a поверх любого транспорта: пароль не покидает
+// клиента, а сервер в ответ доказывает, что знает верификатор пользователя.
+func srpLogin(username, password string,
+	init func(A []byte) (session string, salt, B []byte, err error),
+	verify func(session string, m1 []byte) (tokens *authTokens, m2 []byte, err error)) (*authTokens, error) {
+	client, err := srp.NewClient(username, password)
+	if err != nil {
+		return nil, err
+	}
+	session, salt, serverB, err := init(client.A)
+	if err != nil {
+		return nil, err
+	}
+	m1, err := client.Proof(salt, serverB)
+	if err != nil {
+		return nil, err
+	}
+	tokens, m2, err := verify(session, m1)
+	if err != nil {
+		return nil, err
+	}
+	if client.VerifyServer(m2) != nil {
+		return nil, errors.ErrServerProof
+	}
+	return tokens, nil
+}
+
+func (s *lockBoxService) authSRP(ctx context.Context, username, password string) (*authTokens, error) {
+	init := func(A []byte) (string, []byte, []byte, error) {
+		var challenge struct {
+			Session string `json:"session"`
+			Salt    string `json:"salt"`
+			B       string `json:"b"`
+		}
+		req := map[string]string{"username": username, "a": hex.EncodeToString(A)}
+		if err := s.postSRP(ctx, "/api/auth/srp/init", req, &challenge); err != nil {
+			return "", nil, nil, err
+		}
+		salt, err1 := hex.DecodeString(challenge.Salt)
+		serverB, err2 := hex.DecodeString(challenge.B)
+		if err1 != nil || err2 != nil {
+			return "", nil, nil, fmt.Errorf("failed to parse response: invalid srp challenge")
+		}
+		return challenge.Session, salt, serverB, nil
+	}
+	verify := func(session string, m1 []byte) (*authTokens, []byte, error) {
+		var response struct {
+			authTokens
+			M2 string `json:"m2"`
+		}
+		req := map[string]string{"session": session, "m1": hex.EncodeToString(m1)}
+		if err := s.postSRP(ctx, "/api/auth/srp/verify", req, &response); err != nil {
+			return nil, nil, err
+		}
+		m2, _ := hex.DecodeString(response.M2)
+		return &response.authTokens, m2, nil
+	}
+	return srpLogin(username, password, init, verify)
+}
+
+// setSRPVerifier переводит уже вошедшего пользователя на SRP; после этого
+// сервер удаляет хеш пароля.
+func (s *lockBoxService) setSRPVerifier(ctx context.Context, username, password string) error {
+	verifier, err := newSRPVerifier(username, password)
+	if err != nil {
+		return err
+	}
+	jsonData, err := json.Marshal(verifier)
+	if err != nil {
+		return err
+	}
+
+	url := s.baseURL + ":" + s.port + "/api/auth/srp"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to set srp verifier (code %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
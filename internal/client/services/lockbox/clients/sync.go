@@ -0,0 +1,111 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	"io"
+	"net/http"
+)
+
+// GetChanges забирает страницу ленты изменений после курсора since и
+// расшифровывает записи. after — ключ следующей страницы полного списка,
+// 0 для первой.
+func (s *lockBoxService) GetChanges(ctx context.Context, since int64, after int) (*models.ChangeSet, error) {
+	var set models.ChangeSet
+	path := fmt.Sprintf("/api/sync/changes?since=%d&after=%d", since, after)
+	if _, err := s.authRequest(ctx, http.MethodGet, path, nil, &set, true); err != nil {
+		return nil, err
+	}
+
+	for i := range set.Changes {
+		change := &set.Changes[i]
+		if err := s.openName(&change.LockBox); err != nil {
+			return nil, err
+		}
+		// Для удаления достаточно имени.
+		if change.Deleted {
+			continue
+		}
+		opened, err := crypt.DecryptLockBox(&change.LockBox, s.encryptor)
+		if err != nil {
+			return nil, err
+		}
+		change.LockBox = *opened
+	}
+	return &set, nil
+}
+
+// PushChanges отправляет пакет локальных изменений. От удалённой записи
+// серверу нужны только имя или его слепой индекс и версия. Изменения, которые
+// сервер отклонил из-за правок с другого устройства, возвращаются в
+// Conflicts вместе с текущей версией сервера. Ключ идемпотентности
+// передаётся как есть: повтор пакета после обрыва связи безопасен.
+func (s *lockBoxService) PushChanges(ctx context.Context, changes []models.LockBoxChange) (*models.PushResult, error) {
+	sealed := make([]models.LockBoxChange, len(changes))
+	for i, change := range changes {
+		sealed[i].Deleted, sealed[i].IdempotencyKey = change.Deleted, change.IdempotencyKey
+		if change.Deleted {
+			name, index, err := s.sealName(change.LockBox.Name)
+			if err != nil {
+				return nil, err
+			}
+			sealed[i].LockBox = models.LockBox{Name: name, NameIndex: index, Revision: change.LockBox.Revision}
+			continue
+		}
+		box, err := s.SealLockBox(&change.LockBox)
+		if err != nil {
+			return nil, err
+		}
+		sealed[i].LockBox = *box
+	}
+
+	jsonData, err := json.Marshal(map[string][]models.LockBoxChange{"changes": sealed})
+	if err != nil {
+		return nil, err
+	}
+	url := s.baseURL + ":" + s.port + "/api/sync/push"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", s.tokens.access())
+	s.setDevice(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	// 409 — часть пакета применена, остальное в конфликтах.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusConflict {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to push changes (code %d): %s", resp.StatusCode, string(body))
+	}
+	var result models.PushResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	for i := range result.Conflicts {
+		conflict := &result.Conflicts[i]
+		if conflict.Index < 0 || conflict.Index >= len(changes) {
+			return nil, fmt.Errorf("conflict for unknown change %d", conflict.Index)
+		}
+		// Имя берётся из отправленного изменения: расшифровывать его незачем.
+		conflict.LockBox.Name = changes[conflict.Index].LockBox.Name
+		if conflict.Deleted {
+			continue
+		}
+		opened, err := crypt.DecryptLockBox(&conflict.LockBox, s.encryptor)
+		if err != nil {
+			return nil, err
+		}
+		conflict.LockBox = *opened
+	}
+	return &result, nil
+}
@@ -0,0 +1,54 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	"net/http"
+)
+
+// GetTrash возвращает удалённые записи, которые сервер ещё хранит.
+func (s *lockBoxService) GetTrash(ctx context.Context) ([]models.LockBox, error) {
+	var lockBoxes []models.LockBox
+	if _, err := s.authRequest(ctx, http.MethodGet, "/api/trash/", nil, &lockBoxes, true); err != nil {
+		return nil, err
+	}
+
+	for i := range lockBoxes {
+		if err := s.openName(&lockBoxes[i]); err != nil {
+			return nil, err
+		}
+		opened, err := crypt.DecryptLockBox(&lockBoxes[i], s.encryptor)
+		if err != nil {
+			return nil, err
+		}
+		lockBoxes[i] = *opened
+	}
+	return lockBoxes, nil
+}
+
+// RestoreFromTrash возвращает запись id из корзины.
+func (s *lockBoxService) RestoreFromTrash(ctx context.Context, id int) error {
+	path := fmt.Sprintf("/api/trash/%d/restore", id)
+	status, err := s.authRequest(ctx, http.MethodPost, path, nil, nil, true)
+	switch status {
+	case http.StatusNotFound:
+		return errors.ErrNotFound
+	case http.StatusConflict:
+		return errors.ErrExists
+	}
+	return err
+}
+
+// EmptyTrash окончательно удаляет записи из корзины и возвращает их число.
+func (s *lockBoxService) EmptyTrash(ctx context.Context) (int, error) {
+	var resp struct {
+		Purged int `json:"purged"`
+	}
+	if _, err := s.authRequest(ctx, http.MethodDelete, "/api/trash/", nil, &resp, true); err != nil {
+		return 0, err
+	}
+	return resp.Purged, nil
+}
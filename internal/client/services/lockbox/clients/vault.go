@@ -0,0 +1,42 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	errors "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"io"
+	"net/http"
+)
+
+// RotateKey отправляет уже перешифрованный пакет; шифратор сервиса не используется.
+func (s *lockBoxService) RotateKey(ctx context.Context, batch *models.RotationBatch) error {
+	jsonData, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	url := s.baseURL + ":" + s.port + "/api/vault/rotate"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", s.tokens.access())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return errors.ErrRotationConflict
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to rotate key (code %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import "time"
 
 type LockBoxInput struct {
 	Name        string `json:"name"`
+	NameIndex   string `json:"name_index,omitempty"`
 	URL         string `json:"url"`
 	Login       string `json:"login"`
 	Password    string `json:"password"`
@@ -13,6 +14,7 @@ type LockBoxInput struct {
 type LockBox struct {
 	ID          int       `json:"id"`
 	Name        string    `json:"name"`
+	NameIndex   string    `json:"name_index,omitempty"`
 	URL         string    `json:"url"`
 	Login       string    `json:"login"`
 	Password    string    `json:"password"`
@@ -21,6 +23,133 @@ type LockBox struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 	SyncedAt    time.Time `json:"synced_at"`
 	DeletedAt   time.Time `json:"deleted_at"`
+	// Revision — версия записи на сервере, от которой начата локальная
+	// правка; 0 — запись на сервер ещё не попадала.
+	Revision int `json:"revision"`
+}
+
+// LockBoxRevision — прежняя версия записи. Revision — номер версии для
+// восстановления, ReplacedAt — когда её заменила следующая.
+type LockBoxRevision struct {
+	Revision   int       `json:"revision_id"`
+	LockBoxID  int       `json:"lockbox_id"`
+	ReplacedAt time.Time `json:"replaced_at"`
+	LockBox
+}
+
+// LockBoxChange — изменение записи в ленте синхронизации. Запись из корзины
+// приходит с Deleted, от окончательно удалённой остаётся только имя.
+// Отправляемое изменение несёт ключ идемпотентности своей операции из
+// очереди: повтор того же ключа сервер не применяет второй раз. OutboxID и
+// Attempts — номер операции в очереди и число прошлых попыток отправки.
+type LockBoxChange struct {
+	Seq            int64   `json:"seq"`
+	Deleted        bool    `json:"deleted"`
+	LockBox        LockBox `json:"data"`
+	IdempotencyKey string  `json:"idempotency_key,omitempty"`
+	OutboxID       int64   `json:"-"`
+	Attempts       int     `json:"-"`
+}
+
+// Операции очереди отправки.
+const (
+	OutboxCreate = "create"
+	OutboxUpdate = "update"
+	OutboxDelete = "delete"
+)
+
+// OutboxEntry — операция над записью, ещё не принятая сервером.
+// NextAttemptAt пустое, если операцию можно отправлять сразу.
+type OutboxEntry struct {
+	ID             int64
+	Name           string
+	Op             string
+	IdempotencyKey string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	CreatedAt      time.Time
+}
+
+// SyncStatus — состояние синхронизации: очередь неотправленных операций,
+// число отложенных конфликтов и курсор ленты изменений сервера.
+type SyncStatus struct {
+	Pending   []OutboxEntry
+	Conflicts int
+	Cursor    int64
+}
+
+// ChangeSet — страница ленты изменений сервера. Cursor сохраняется для
+// следующего запроса, More — есть ли изменения после этой страницы. Reset
+// означает, что курсор устарел: Changes содержит все записи, остальные
+// нужно удалить локально, когда придёт последняя страница. After — ключ
+// следующей страницы полного списка.
+type ChangeSet struct {
+	Cursor  int64           `json:"cursor"`
+	Reset   bool            `json:"reset"`
+	More    bool            `json:"more"`
+	After   int             `json:"after,omitempty"`
+	Changes []LockBoxChange `json:"changes"`
+}
+
+// PushResult — ответ сервера на пакет изменений. Revisions — новая версия
+// каждого изменения пакета по порядку, 0 для удалений и конфликтов.
+type PushResult struct {
+	Applied   int            `json:"applied"`
+	Revisions []int          `json:"revisions"`
+	Conflicts []PushConflict `json:"conflicts"`
+}
+
+// PushConflict — изменение пакета под номером Index, отклонённое сервером:
+// запись успели изменить с другого устройства. LockBoxChange — текущая
+// версия на сервере.
+type PushConflict struct {
+	Index int `json:"index"`
+	LockBoxChange
+}
+
+// ConflictPolicy — как клиент разрешает конфликты правок с разных устройств.
+type ConflictPolicy string
+
+const (
+	// ConflictLastWriterWins — локальная правка перезаписывает серверную.
+	ConflictLastWriterWins ConflictPolicy = "lww"
+	// ConflictKeepBoth — серверная версия остаётся под своим именем, а
+	// локальная сохраняется копией под новым.
+	ConflictKeepBoth ConflictPolicy = "keep-both"
+	// ConflictManual — конфликт откладывается до решения пользователя в
+	// команде conflicts; до тех пор запись не отправляется.
+	ConflictManual ConflictPolicy = "manual"
+)
+
+// LockBoxConflict — отложенный конфликт: локальная версия записи и версия
+// сервера, которую она не учла.
+type LockBoxConflict struct {
+	Name          string
+	Local         LockBox
+	LocalDeleted  bool
+	Remote        LockBox
+	RemoteDeleted bool
+	CreatedAt     time.Time
+}
+
+// ConflictSide — какую версию оставить при разрешении конфликта.
+type ConflictSide string
+
+const (
+	KeepLocal  ConflictSide = "local"
+	KeepRemote ConflictSide = "remote"
+)
+
+// ConflictResolution — решение по отложенному конфликту: Keep для записи
+// целиком и, при слиянии по полям, своя сторона для отдельных полей. Пустое
+// поле берётся со стороны Keep.
+type ConflictResolution struct {
+	Keep        ConflictSide
+	URL         ConflictSide
+	Login       ConflictSide
+	Password    ConflictSide
+	Description ConflictSide
 }
 
 type DeleteLockBox struct {
@@ -32,3 +161,132 @@ type UpdatedItem struct {
 	ID   int
 	Name string
 }
+
+// BankCardInput — содержимое карты. NameIndex заполняется при отправке,
+// если включено шифрование имён: тогда Name уходит на сервер зашифрованным.
+type BankCardInput struct {
+	Name      string `json:"name"`
+	NameIndex string `json:"name_index,omitempty"`
+	Number    string `json:"number"`
+	Holder    string `json:"holder"`
+	Expiry    string `json:"expiry"` // MM/YY
+	CVV       string `json:"cvv"`
+	PIN       string `json:"pin"`
+	Notes     string `json:"notes"`
+}
+
+type BankCard struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	NameIndex string    `json:"name_index,omitempty"`
+	Number    string    `json:"number"`
+	Holder    string    `json:"holder"`
+	Expiry    string    `json:"expiry"`
+	CVV       string    `json:"cvv"`
+	PIN       string    `json:"pin"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NoteInput struct {
+	Name string `json:"name"`
+	Body string `json:"body"`
+}
+
+type Note struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	SyncedAt  time.Time `json:"synced_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type BinaryFile struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	ChunkSize  int       `json:"chunk_size"`
+	ChunkCount int       `json:"chunk_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Session — устройство, с которого выполнен вход в аккаунт.
+type Session struct {
+	ID         int       `json:"session_id"`
+	DeviceName string    `json:"device_name"`
+	OS         string    `json:"os"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
+
+// TOTPEnrollment — секрет для приложения-аутентификатора и ссылка otpauth://.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type UploadSession struct {
+	BinaryFile
+	Received []int `json:"received"`
+}
+
+// KDFParams — параметры Argon2id, по которым из мастер-пароля выводится ключ
+// шифрования. Хранятся на сервере, чтобы любой клиент пользователя выводил тот же ключ.
+type KDFParams struct {
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"` // KiB
+	Threads uint8  `json:"threads"`
+	Check   string `json:"check"`
+}
+
+// RotationBatch — всё хранилище, перешифрованное новым ключом. Сервер применяет
+// пакет одной транзакцией; OldCheck подтверждает, что ключ не сменили параллельно.
+type RotationBatch struct {
+	OldCheck  string          `json:"old_check"`
+	KDF       KDFParams       `json:"kdf"`
+	LockBoxes []LockBox       `json:"lock_boxes"`
+	BankCards []BankCard      `json:"bank_cards"`
+	Notes     []Note          `json:"notes"`
+	Binaries  []RotatedBinary `json:"binaries"`
+}
+
+// RotatedBinary — загрузка с перешифрованным содержимым файла Name.
+type RotatedBinary struct {
+	Name     string `json:"name"`
+	UploadID int    `json:"upload_id"`
+}
+
+// KeyRotation — журнал незавершённой смены ключа. Сохраняется локально до
+// отправки пакета, чтобы прерванную смену можно было продолжить с теми же параметрами.
+type KeyRotation struct {
+	OldKDF    KDFParams
+	NewKDF    KDFParams
+	Committed bool
+}
+
+// AuditEvent — запись журнала аудита аккаунта. ActorId заполнен, если
+// действие выполнил администратор; Target — id записи, сессии или имя.
+type AuditEvent struct {
+	ID        int64     `json:"id"`
+	ActorId   int       `json:"actor_id,omitempty"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target,omitempty"`
+	Success   bool      `json:"success"`
+	IP        string    `json:"ip,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Account — данные аккаунта вошедшего пользователя.
+type Account struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	UserType string `json:"user_type"`
+}
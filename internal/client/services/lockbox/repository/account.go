@@ -0,0 +1,29 @@
+package repository
+
+// DeleteUserData удаляет из локальной базы все данные текущего пользователя:
+// после удаления аккаунта на устройстве не должно остаться его записей.
+func (r *SQLiteRepository) DeleteUserData() error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM lockbox WHERE user_id = ?`,
+		`DELETE FROM note WHERE user_id = ?`,
+		`DELETE FROM key_rotation WHERE user_id = ?`,
+		`DELETE FROM sync_cursor WHERE user_id = ?`,
+		`DELETE FROM lockbox_conflicts WHERE user_id = ?`,
+		`DELETE FROM outbox WHERE user_id = ?`,
+	} {
+		if _, err := tx.Exec(query, userID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
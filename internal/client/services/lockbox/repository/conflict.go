@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	"time"
+)
+
+// SetRevision переносит неотправленную правку на версию сервера revision:
+// следующая отправка перезапишет её.
+func (r *SQLiteRepository) SetRevision(name string, revision int) error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`UPDATE lockbox SET revision = ? WHERE name = ? AND user_id = ?`, revision, name, userID)
+	return err
+}
+
+// SaveConflict откладывает конфликт до решения пользователя. Более новая
+// версия сервера заменяет сохранённую ранее.
+func (r *SQLiteRepository) SaveConflict(conflict *models.LockBoxConflict) error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	remote := &conflict.Remote
+	if !conflict.RemoteDeleted {
+		if remote, err = crypt.EncryptLockBox(&conflict.Remote, r.encryptor); err != nil {
+			return err
+		}
+	}
+	_, err = r.db.Exec(
+		`INSERT INTO lockbox_conflicts (name, user_id, deleted, revision, username, url, password, description)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (name, user_id) DO UPDATE
+		 SET deleted = excluded.deleted, revision = excluded.revision, username = excluded.username,
+		     url = excluded.url, password = excluded.password, description = excluded.description`,
+		conflict.Name, userID, conflict.RemoteDeleted, remote.Revision, remote.Login, remote.URL, remote.Password,
+		remote.Description,
+	)
+	return err
+}
+
+// GetConflicts возвращает отложенные конфликты вместе с локальными версиями
+// записей, старые первыми.
+func (r *SQLiteRepository) GetConflicts() ([]models.LockBoxConflict, error) {
+	userID, err := r.getUserID()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(
+		`SELECT c.name, c.deleted, c.revision, COALESCE(c.username, ''), COALESCE(c.url, ''),
+		        COALESCE(c.password, ''), COALESCE(c.description, ''), c.created_at,
+		        l.deleted_at IS NOT NULL, l.revision, COALESCE(l.username, ''), COALESCE(l.url, ''),
+		        COALESCE(l.password, ''), COALESCE(l.description, '')
+		 FROM lockbox_conflicts c
+		 JOIN lockbox l ON l.name = c.name AND l.user_id = c.user_id
+		 WHERE c.user_id = ?
+		 ORDER BY c.created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conflicts []models.LockBoxConflict
+	for rows.Next() {
+		var c models.LockBoxConflict
+		remote, local := &c.Remote, &c.Local
+		if err := rows.Scan(&c.Name, &c.RemoteDeleted, &remote.Revision, &remote.Login, &remote.URL,
+			&remote.Password, &remote.Description, &c.CreatedAt,
+			&c.LocalDeleted, &local.Revision, &local.Login, &local.URL, &local.Password, &local.Description); err != nil {
+			return nil, err
+		}
+		remote.Name, local.Name = c.Name, c.Name
+		if !c.RemoteDeleted {
+			if remote, err = crypt.DecryptLockBox(remote, r.encryptor); err != nil {
+				return nil, err
+			}
+			c.Remote = *remote
+		}
+		if local, err = crypt.DecryptLockBox(local, r.encryptor); err != nil {
+			return nil, err
+		}
+		c.Local = *local
+		conflicts = append(conflicts, c)
+	}
+	return conflicts, rows.Err()
+}
+
+// ResolveConflict снимает отложенный конфликт. Локальная правка переносится
+// на версию сервера revision и уйдёт следующей отправкой: её операция всё
+// ещё в очереди. Если передан box, он заменяет содержимое записи.
+func (r *SQLiteRepository) ResolveConflict(name string, box *models.LockBox, revision int) error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if box != nil {
+		dataEncrypt, err := crypt.EncryptLockBox(box, r.encryptor)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`UPDATE lockbox SET username = ?, url = ?, password = ?, description = ?, updated_at = ?,
+			                    deleted_at = NULL
+			 WHERE name = ? AND user_id = ?`,
+			dataEncrypt.Login, dataEncrypt.URL, dataEncrypt.Password, dataEncrypt.Description, time.Now(), name, userID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE lockbox SET revision = ? WHERE name = ? AND user_id = ?`, revision, name, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM lockbox_conflicts WHERE name = ? AND user_id = ?`, name, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteConflict снимает отложенный конфликт, ничего не меняя в записи.
+func (r *SQLiteRepository) DeleteConflict(name string) error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`DELETE FROM lockbox_conflicts WHERE name = ? AND user_id = ?`, name, userID)
+	return err
+}
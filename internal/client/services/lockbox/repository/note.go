@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"database/sql"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	"time"
+)
+
+// SaveNote создаёт заметку или перезаписывает существующую с тем же именем.
+// Нулевой SyncedAt означает, что заметка ещё не отправлена на сервер.
+func (r *SQLiteRepository) SaveNote(note *models.Note) error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	noteEncrypt, err := crypt.EncryptNote(note, r.encryptor)
+	if err != nil {
+		return err
+	}
+	var syncedAt interface{}
+	if !note.SyncedAt.IsZero() {
+		syncedAt = note.SyncedAt
+	}
+	_, err = r.db.Exec(
+		`INSERT INTO note (name, body, user_id, synced_at)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT (name, user_id) DO UPDATE
+		 SET body = excluded.body, synced_at = excluded.synced_at,
+		     updated_at = CURRENT_TIMESTAMP, deleted_at = NULL`,
+		noteEncrypt.Name, noteEncrypt.Body, userID, syncedAt,
+	)
+	return err
+}
+
+func (r *SQLiteRepository) GetNote(name string) (*models.Note, error) {
+	userID, err := r.getUserID()
+	if err != nil {
+		return nil, err
+	}
+	var note models.Note
+	err = r.db.QueryRow(
+		`SELECT id, name, body, created_at, updated_at
+		 FROM note WHERE name = ? AND user_id = ? AND deleted_at IS NULL`, name, userID,
+	).Scan(&note.ID, &note.Name, &note.Body, &note.CreatedAt, &note.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return crypt.DecryptNote(&note, r.encryptor)
+}
+
+func (r *SQLiteRepository) GetNotes() (*[]models.Note, error) {
+	userID, err := r.getUserID()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(
+		`SELECT id, name, body, created_at, updated_at, synced_at
+		 FROM note WHERE user_id = ? AND deleted_at IS NULL`, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []models.Note{}
+	for rows.Next() {
+		var note models.Note
+		var syncedAt sql.NullTime
+		if err := rows.Scan(&note.ID, &note.Name, &note.Body, &note.CreatedAt, &note.UpdatedAt, &syncedAt); err != nil {
+			return nil, err
+		}
+		if syncedAt.Valid {
+			note.SyncedAt = syncedAt.Time
+		}
+		noteDecrypt, err := crypt.DecryptNote(&note, r.encryptor)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, *noteDecrypt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &notes, nil
+}
+
+func (r *SQLiteRepository) DeletedNote(name string) error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(
+		`UPDATE note SET deleted_at = ? WHERE name = ? AND user_id = ?`,
+		time.Now(), name, userID,
+	)
+	return err
+}
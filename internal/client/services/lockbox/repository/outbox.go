@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"time"
+)
+
+// enqueue ставит операцию op над записью name в очередь отправки в той же
+// транзакции, что и саму правку. Операция сливается с последней ещё не
+// отправлявшейся операцией записи: содержимое всё равно берётся при
+// отправке. Ушедшая хоть раз операция не меняется, чтобы повтор с её ключом
+// означал то же самое. dirty записи — число её операций в очереди.
+func enqueue(tx *sql.Tx, userID int, name, op string) error {
+	var id int64
+	var last string
+	var attempts int
+	err := tx.QueryRow(
+		`SELECT id, op, attempts FROM outbox WHERE user_id = ? AND name = ? ORDER BY id DESC LIMIT 1`,
+		userID, name,
+	).Scan(&id, &last, &attempts)
+	switch {
+	case err == nil && attempts == 0:
+		// Сервер ещё не видел создания: правка после него остаётся созданием.
+		if last == models.OutboxCreate && op == models.OutboxUpdate {
+			op = last
+		}
+		if _, err := tx.Exec(`UPDATE outbox SET op = ? WHERE id = ?`, op, id); err != nil {
+			return err
+		}
+	case err == nil || err == sql.ErrNoRows:
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`INSERT INTO outbox (user_id, name, op, idempotency_key) VALUES (?, ?, ?, ?)`,
+			userID, name, op, key,
+		)
+		if err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+	_, err = tx.Exec(
+		`UPDATE lockbox SET dirty = (SELECT COUNT(*) FROM outbox WHERE user_id = ? AND name = ?)
+		 WHERE name = ? AND user_id = ?`,
+		userID, name, name, userID,
+	)
+	return err
+}
+
+// newIdempotencyKey — случайный ключ операции, 32 шестнадцатеричных символа.
+func newIdempotencyKey() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// MarkAttempted отмечает операции, уходящие на сервер. Дальнейшие правки
+// записей встают в очередь новыми операциями.
+func (r *SQLiteRepository) MarkAttempted(outboxIDs []int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range outboxIDs {
+		if _, err := tx.Exec(`UPDATE outbox SET attempts = attempts + 1 WHERE id = ?`, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RetryLater откладывает неудавшуюся операцию до at и запоминает ошибку.
+func (r *SQLiteRepository) RetryLater(outboxID int64, at time.Time, lastError string) error {
+	_, err := r.db.Exec(
+		`UPDATE outbox SET next_attempt_at = ?, last_error = ? WHERE id = ?`,
+		at.UTC(), lastError, outboxID,
+	)
+	return err
+}
+
+// GetOutbox возвращает очередь отправки пользователя в порядке постановки.
+func (r *SQLiteRepository) GetOutbox() ([]models.OutboxEntry, error) {
+	userID, err := r.getUserID()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(
+		`SELECT id, name, op, idempotency_key, attempts, next_attempt_at, COALESCE(last_error, ''), created_at
+		 FROM outbox WHERE user_id = ? ORDER BY id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.OutboxEntry
+	for rows.Next() {
+		var entry models.OutboxEntry
+		var next sql.NullTime
+		if err := rows.Scan(&entry.ID, &entry.Name, &entry.Op, &entry.IdempotencyKey, &entry.Attempts, &next,
+			&entry.LastError, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entry.NextAttemptAt = next.Time
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -13,8 +13,6 @@ import (
 	"time"
 )
 
-var jwtSecret = []byte("your-secret-key")
-
 type Repository interface {
 	SaveLockBox(box *models.LockBox) error
 	GetLockBox(name string) (*models.LockBox, error)
@@ -23,28 +21,65 @@ type Repository interface {
 	GetLockBoxes() (*[]models.LockBox, error)
 	Exists(name string) (bool, error)
 	SaveToken(token string)
-	PurgeExpiredLocks() error
+	SetKeyfunc(keyfunc jwt.Keyfunc)
+	PurgeExpiredLocks(before time.Time) error
+	GetPendingLockBoxes() ([]models.LockBoxChange, error)
+	MarkAttempted(outboxIDs []int64) error
+	RetryLater(outboxID int64, at time.Time, lastError string) error
+	MarkSynced(outboxID int64, revision int) error
+	GetOutbox() ([]models.OutboxEntry, error)
+	ApplyChange(change *models.LockBoxChange) error
+	AcceptChange(change *models.LockBoxChange) error
+	SetRevision(name string, revision int) error
+	SaveConflict(conflict *models.LockBoxConflict) error
+	GetConflicts() ([]models.LockBoxConflict, error)
+	ResolveConflict(name string, box *models.LockBox, revision int) error
+	DeleteConflict(name string) error
+	GetSyncCursor() (int64, error)
+	SaveSyncCursor(cursor int64) error
+	SaveNote(note *models.Note) error
+	GetNote(name string) (*models.Note, error)
+	GetNotes() (*[]models.Note, error)
+	DeletedNote(name string) error
+	SetEncryptor(encryptor crypt.Encryptor)
+	SaveRotation(rotation *models.KeyRotation) error
+	GetRotation() (*models.KeyRotation, error)
+	DeleteRotation() error
+	ReEncrypt(oldEncryptor, newEncryptor crypt.Encryptor) error
+	DeleteUserData() error
 }
 
-var key string = "superSecretKey19"
-
 type SQLiteRepository struct {
 	db        *sql.DB
 	authToken string
 	encryptor crypt.Encryptor
+	// keyfunc проверяет подпись токена открытыми ключами сервера; userID
+	// запоминается после первой успешной проверки текущего токена.
+	keyfunc jwt.Keyfunc
+	userID  int
 }
 
 func NewSQLiteRepository(db *sql.DB) Repository {
 	return &SQLiteRepository{
 		db:        db,
-		encryptor: crypt.New(key),
+		encryptor: crypt.Locked(),
 	}
 }
 
-func GetUserIDFromToken(tokenString string) (int, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+// SetEncryptor подменяет шифратор после разблокировки хранилища мастер-паролем.
+func (r *SQLiteRepository) SetEncryptor(encryptor crypt.Encryptor) {
+	r.encryptor = encryptor
+}
+
+// GetUserIDFromToken проверяет подпись токена и возвращает id пользователя.
+// Срок действия не проверяется: локальной базе нужен только владелец данных,
+// а истёкший токен сервис обновляет сам.
+func GetUserIDFromToken(tokenString string, keyfunc jwt.Keyfunc) (int, error) {
+	if keyfunc == nil {
+		return 0, errors.ErrInvalidToken
+	}
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	token, err := parser.Parse(tokenString, keyfunc)
 
 	if err != nil || !token.Valid {
 		return 0, errors.ErrInvalidToken
@@ -62,8 +97,20 @@ func GetUserIDFromToken(tokenString string) (int, error) {
 	return int(userIdFloat), nil
 }
 func (r *SQLiteRepository) getUserID() (int, error) {
-	return GetUserIDFromToken(r.authToken)
+	if r.userID != 0 {
+		return r.userID, nil
+	}
+	userID, err := GetUserIDFromToken(r.authToken, r.keyfunc)
+	if err != nil {
+		return 0, err
+	}
+	r.userID = userID
+	return userID, nil
 }
+
+// SaveLockBox сохраняет новую запись и ставит её создание в очередь
+// отправки. Имя записи из корзины занимается заново: она возвращается с
+// новым содержимым. Занятое живой записью имя — ErrExists.
 func (r *SQLiteRepository) SaveLockBox(box *models.LockBox) error {
 	userID, err := r.getUserID()
 	if err != nil {
@@ -73,12 +120,32 @@ func (r *SQLiteRepository) SaveLockBox(box *models.LockBox) error {
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec(
-		`INSERT INTO lockbox (name,username, url, password, description, user_id) 
-	 VALUES (?, ?, ?, ?, ?, ?)`,
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		`INSERT INTO lockbox (name, username, url, password, description, user_id, dirty)
+		 VALUES (?, ?, ?, ?, ?, ?, 0)
+		 ON CONFLICT (name, user_id) DO UPDATE
+		 SET username = excluded.username, url = excluded.url, password = excluded.password,
+		     description = excluded.description, updated_at = CURRENT_TIMESTAMP, deleted_at = NULL
+		 WHERE lockbox.deleted_at IS NOT NULL
+		 RETURNING id`,
 		dataEncrypt.Name, dataEncrypt.Login, dataEncrypt.URL, dataEncrypt.Password, dataEncrypt.Description, userID,
-	)
-	return err
+	).Scan(&box.ID)
+	if err == sql.ErrNoRows {
+		return errors.ErrExists
+	}
+	if err != nil {
+		return err
+	}
+	if err := enqueue(tx, userID, box.Name, models.OutboxCreate); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *SQLiteRepository) GetLockBoxes() (*[]models.LockBox, error) {
@@ -88,7 +155,7 @@ func (r *SQLiteRepository) GetLockBoxes() (*[]models.LockBox, error) {
 	}
 	rows, err := r.db.Query(
 		`SELECT id, name, username, url, password, description, created_at, updated_at 
-         FROM lockbox WHERE user_id = ?`,
+         FROM lockbox WHERE user_id = ? AND deleted_at IS NULL`,
 		userID,
 	)
 	if err != nil {
@@ -148,7 +215,7 @@ func (r *SQLiteRepository) GetLockBox(name string) (*models.LockBox, error) {
 
 	err = r.db.QueryRow(
 		`SELECT id, url, username, password, description, created_at, updated_at
-         FROM lockbox WHERE name = ? AND user_id = ?`, name, userID,
+         FROM lockbox WHERE name = ? AND user_id = ? AND deleted_at IS NULL`, name, userID,
 	).Scan(&box.ID, &url, &username, &password, &description, &box.CreatedAt, &box.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -187,18 +254,38 @@ func (r *SQLiteRepository) GetLockBox(name string) (*models.LockBox, error) {
 
 	return dataDecrypt, nil
 }
+
+// Deleted переносит запись в корзину и ставит удаление в очередь отправки.
 func (r *SQLiteRepository) Deleted(name string) error {
 	userID, err := r.getUserID()
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec(
-		`UPDATE lockbox SET deleted_at = ? WHERE name = ? AND user_id = ?`,
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE lockbox SET deleted_at = ? WHERE name = ? AND user_id = ? AND deleted_at IS NULL`,
 		time.Now(), name, userID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errors.ErrNotFound
+	}
+	if err := enqueue(tx, userID, name, models.OutboxDelete); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
+// Updated меняет непустые поля записи и ставит правку в очередь отправки.
 func (r *SQLiteRepository) Updated(data *models.LockBox) error {
 	dataEncrypt, err := crypt.EncryptLockBox(data, r.encryptor)
 	if err != nil {
@@ -210,31 +297,44 @@ func (r *SQLiteRepository) Updated(data *models.LockBox) error {
 	}
 
 	var query strings.Builder
+	var args []any
 	query.WriteString("UPDATE lockbox SET ")
-
-	if data.URL != "" {
-		query.WriteString(fmt.Sprintf("url = '%s', ", dataEncrypt.URL))
-	}
-	if data.Login != "" {
-		query.WriteString(fmt.Sprintf("username = '%s', ", dataEncrypt.Login))
-	}
-	if data.Description != "" {
-		query.WriteString(fmt.Sprintf("description = '%s', ", dataEncrypt.Description))
-	}
-	if data.Password != "" {
-		query.WriteString(fmt.Sprintf("password = '%s', ", dataEncrypt.Password))
+	for _, field := range []struct {
+		column, plain, value string
+	}{
+		{"url", data.URL, dataEncrypt.URL},
+		{"username", data.Login, dataEncrypt.Login},
+		{"description", data.Description, dataEncrypt.Description},
+		{"password", data.Password, dataEncrypt.Password},
+	} {
+		if field.plain != "" {
+			query.WriteString(fmt.Sprintf("%s = ?, ", field.column))
+			args = append(args, field.value)
+		}
 	}
+	query.WriteString("updated_at = CURRENT_TIMESTAMP WHERE name = ? AND user_id = ? AND deleted_at IS NULL")
+	args = append(args, data.Name, userID)
 
-	query.WriteString("updated_at = CURRENT_TIMESTAMP, ")
-	query.WriteString("deleted_at = CASE WHEN deleted_at IS NOT NULL AND updated_at > deleted_at THEN NULL ELSE deleted_at END ")
-	query.WriteString(fmt.Sprintf("WHERE name = '%s' AND user_id = %d", dataEncrypt.Name, userID))
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	_, err = r.db.Exec(query.String())
+	res, err := tx.Exec(query.String(), args...)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
-	return nil
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errors.ErrNotFound
+	}
+	if err := enqueue(tx, userID, data.Name, models.OutboxUpdate); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *SQLiteRepository) Exists(name string) (bool, error) {
@@ -255,13 +355,23 @@ func (r *SQLiteRepository) Exists(name string) (bool, error) {
 
 func (r *SQLiteRepository) SaveToken(token string) {
 	r.authToken = token
+	r.userID = 0
 }
-func (r *SQLiteRepository) PurgeExpiredLocks() error {
+
+func (r *SQLiteRepository) SetKeyfunc(keyfunc jwt.Keyfunc) {
+	r.keyfunc = keyfunc
+}
+
+// PurgeExpiredLocks окончательно удаляет записи, попавшие в корзину раньше
+// before. Время удаления пишется из Go, поэтому и граница передаётся
+// параметром, а не считается функциями SQLite. Удаление, ещё не
+// отправленное на сервер, остаётся до отправки.
+func (r *SQLiteRepository) PurgeExpiredLocks(before time.Time) error {
 	query := `DELETE FROM lockbox 
               WHERE deleted_at IS NOT NULL 
-              AND deleted_at < NOW() - INTERVAL '1 day'`
+              AND deleted_at < ? AND dirty = 0`
 
-	_, err := r.db.Exec(query)
+	_, err := r.db.Exec(query, before)
 	if err != nil {
 		return err
 	}
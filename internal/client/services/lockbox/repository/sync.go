@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"database/sql"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	"time"
+)
+
+// GetPendingLockBoxes возвращает записи с операциями в очереди отправки,
+// включая удалённые: по первой операции каждой записи, которую уже пора
+// отправлять. Содержимое берётся текущее. Записи с отложенным конфликтом не
+// отправляются, пока его не разрешат.
+func (r *SQLiteRepository) GetPendingLockBoxes() ([]models.LockBoxChange, error) {
+	userID, err := r.getUserID()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(
+		`SELECT o.id, o.idempotency_key, o.attempts, l.name, COALESCE(l.username, ''), COALESCE(l.url, ''),
+		        COALESCE(l.password, ''), COALESCE(l.description, ''), l.deleted_at IS NOT NULL, l.revision
+		 FROM outbox o
+		 JOIN lockbox l ON l.name = o.name AND l.user_id = o.user_id
+		 WHERE o.user_id = ?
+		   AND o.id = (SELECT MIN(id) FROM outbox WHERE user_id = o.user_id AND name = o.name)
+		   AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= ?)
+		   AND o.name NOT IN (SELECT name FROM lockbox_conflicts WHERE user_id = ?)
+		 ORDER BY o.id`,
+		userID, time.Now().UTC(), userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []models.LockBoxChange
+	for rows.Next() {
+		var change models.LockBoxChange
+		box := &change.LockBox
+		if err := rows.Scan(&change.OutboxID, &change.IdempotencyKey, &change.Attempts, &box.Name, &box.Login,
+			&box.URL, &box.Password, &box.Description, &change.Deleted, &box.Revision); err != nil {
+			return nil, err
+		}
+		if !change.Deleted {
+			opened, err := crypt.DecryptLockBox(box, r.encryptor)
+			if err != nil {
+				return nil, err
+			}
+			change.LockBox = *opened
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// MarkSynced убирает принятую сервером операцию из очереди и запоминает
+// версию записи, созданную отправкой. Правки, сделанные во время отправки,
+// остаются в очереди и уйдут уже от новой версии сервера.
+func (r *SQLiteRepository) MarkSynced(outboxID int64, revision int) error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var name string
+	err = tx.QueryRow(`DELETE FROM outbox WHERE id = ? AND user_id = ? RETURNING name`, outboxID, userID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`UPDATE lockbox SET revision = ?,
+		                    dirty = (SELECT COUNT(*) FROM outbox WHERE user_id = lockbox.user_id AND name = lockbox.name)
+		 WHERE name = ? AND user_id = ?`,
+		revision, name, userID,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ApplyChange переносит в локальную базу изменение с сервера. Запись с
+// неотправленными локальными правками не трогается: они уйдут на сервер
+// следующей отправкой, и сервер сообщит о конфликте.
+func (r *SQLiteRepository) ApplyChange(change *models.LockBoxChange) error {
+	return r.applyChange(change, false)
+}
+
+// AcceptChange переносит изменение с сервера поверх неотправленных локальных
+// правок и убирает их из очереди: ими пожертвовали при разрешении конфликта.
+func (r *SQLiteRepository) AcceptChange(change *models.LockBoxChange) error {
+	return r.applyChange(change, true)
+}
+
+func (r *SQLiteRepository) applyChange(change *models.LockBoxChange, force bool) error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if force {
+		if _, err := tx.Exec(`DELETE FROM outbox WHERE name = ? AND user_id = ?`, change.LockBox.Name, userID); err != nil {
+			return err
+		}
+	}
+	if change.Deleted {
+		_, err = tx.Exec(
+			`UPDATE lockbox SET deleted_at = ?, dirty = 0
+			 WHERE name = ? AND user_id = ? AND deleted_at IS NULL AND (dirty = 0 OR ?)`,
+			time.Now(), change.LockBox.Name, userID, force,
+		)
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	dataEncrypt, err := crypt.EncryptLockBox(&change.LockBox, r.encryptor)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`INSERT INTO lockbox (name, username, url, password, description, user_id, dirty, revision)
+		 VALUES (?, ?, ?, ?, ?, ?, 0, ?)
+		 ON CONFLICT (name, user_id) DO UPDATE
+		 SET username = excluded.username, url = excluded.url, password = excluded.password,
+		     description = excluded.description, revision = excluded.revision,
+		     updated_at = CURRENT_TIMESTAMP, deleted_at = NULL, dirty = 0
+		 WHERE lockbox.dirty = 0 OR ?`,
+		dataEncrypt.Name, dataEncrypt.Login, dataEncrypt.URL, dataEncrypt.Password, dataEncrypt.Description, userID,
+		dataEncrypt.Revision, force,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetSyncCursor возвращает номер последнего полученного изменения сервера.
+func (r *SQLiteRepository) GetSyncCursor() (int64, error) {
+	userID, err := r.getUserID()
+	if err != nil {
+		return 0, err
+	}
+	var cursor int64
+	err = r.db.QueryRow(`SELECT cursor FROM sync_cursor WHERE user_id = ?`, userID).Scan(&cursor)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return cursor, err
+}
+
+func (r *SQLiteRepository) SaveSyncCursor(cursor int64) error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(
+		`INSERT INTO sync_cursor (user_id, cursor) VALUES (?, ?)
+		 ON CONFLICT (user_id) DO UPDATE SET cursor = excluded.cursor`,
+		userID, cursor,
+	)
+	return err
+}
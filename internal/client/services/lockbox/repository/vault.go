@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+)
+
+func (r *SQLiteRepository) SaveRotation(rotation *models.KeyRotation) error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	oldKDF, err := json.Marshal(rotation.OldKDF)
+	if err != nil {
+		return err
+	}
+	newKDF, err := json.Marshal(rotation.NewKDF)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(
+		`INSERT INTO key_rotation (user_id, old_kdf, new_kdf, committed)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET old_kdf = excluded.old_kdf, new_kdf = excluded.new_kdf, committed = excluded.committed`,
+		userID, string(oldKDF), string(newKDF), rotation.Committed,
+	)
+	return err
+}
+
+func (r *SQLiteRepository) GetRotation() (*models.KeyRotation, error) {
+	userID, err := r.getUserID()
+	if err != nil {
+		return nil, err
+	}
+	var rotation models.KeyRotation
+	var oldKDF, newKDF string
+	err = r.db.QueryRow(
+		`SELECT old_kdf, new_kdf, committed FROM key_rotation WHERE user_id = ?`, userID,
+	).Scan(&oldKDF, &newKDF, &rotation.Committed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(oldKDF), &rotation.OldKDF); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(newKDF), &rotation.NewKDF); err != nil {
+		return nil, err
+	}
+	return &rotation, nil
+}
+
+func (r *SQLiteRepository) DeleteRotation() error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`DELETE FROM key_rotation WHERE user_id = ?`, userID)
+	return err
+}
+
+// ReEncrypt перешифровывает весь локальный кэш пользователя, включая удалённые
+// записи, в одной транзакции: кэш никогда не остаётся зашифрованным двумя ключами.
+func (r *SQLiteRepository) ReEncrypt(oldEncryptor, newEncryptor crypt.Encryptor) error {
+	userID, err := r.getUserID()
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		`SELECT id, COALESCE(username, ''), COALESCE(url, ''), COALESCE(password, ''), COALESCE(description, '')
+		 FROM lockbox WHERE user_id = ?`, userID,
+	)
+	if err != nil {
+		return err
+	}
+	var boxes []models.LockBox
+	for rows.Next() {
+		var box models.LockBox
+		if err := rows.Scan(&box.ID, &box.Login, &box.URL, &box.Password, &box.Description); err != nil {
+			rows.Close()
+			return err
+		}
+		boxes = append(boxes, box)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, box := range boxes {
+		decrypted, err := crypt.DecryptLockBox(&box, oldEncryptor)
+		if err != nil {
+			return err
+		}
+		encrypted, err := crypt.EncryptLockBox(decrypted, newEncryptor)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`UPDATE lockbox SET username = ?, url = ?, password = ?, description = ? WHERE id = ?`,
+			encrypted.Login, encrypted.URL, encrypted.Password, encrypted.Description, box.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, err = tx.Query(`SELECT id, body FROM note WHERE user_id = ?`, userID)
+	if err != nil {
+		return err
+	}
+	var notes []models.Note
+	for rows.Next() {
+		var note models.Note
+		if err := rows.Scan(&note.ID, &note.Body); err != nil {
+			rows.Close()
+			return err
+		}
+		notes = append(notes, note)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, note := range notes {
+		decrypted, err := crypt.DecryptNote(&note, oldEncryptor)
+		if err != nil {
+			return err
+		}
+		encrypted, err := crypt.EncryptNote(decrypted, newEncryptor)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE note SET body = ? WHERE id = ?`, encrypted.Body, note.ID); err != nil {
+			return err
+		}
+	}
+
+	// Отложенные конфликты сравнивались с версиями сервера до смены ключа.
+	// Неотправленные правки остаются, и если конфликт не исчез, сервер
+	// сообщит о нём снова.
+	if _, err := tx.Exec(`DELETE FROM lockbox_conflicts WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
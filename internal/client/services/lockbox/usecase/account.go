@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	errors1 "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+)
+
+func (uc *LockboxUsecase) GetAccount(ctx context.Context) (*models.Account, error) {
+	return uc.lockBoxService.GetAccount(ctx)
+}
+
+// ChangePassword меняет пароль аккаунта. Если хранилище зашифровано ключом
+// из того же пароля (мастер-пароль не задан отдельно), оно перешифровывается
+// ключом нового пароля, иначе после смены его нельзя было бы открыть.
+func (uc *LockboxUsecase) ChangePassword(ctx context.Context, oldPassword, newPassword string, progress func(done, total int, item string)) error {
+	if len(newPassword) < 6 {
+		return errors1.ErrPasswordTooShort
+	}
+	account, err := uc.lockBoxService.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	// Хранилище, которое ещё ни разу не разблокировали, перешифровывать не нужно.
+	params, err := uc.lockBoxService.GetKDFParams(ctx)
+	if err != nil && !errors.Is(err, errors1.ErrNotFound) {
+		return err
+	}
+	sharedKey := err == nil && crypt.VerifyKeyCheck(params.Check, crypt.NewFromPassword(oldPassword, params))
+
+	if err := uc.lockBoxService.ChangePassword(ctx, account.Username, oldPassword, newPassword); err != nil {
+		return err
+	}
+	if !sharedKey {
+		return nil
+	}
+	return uc.RotateKey(ctx, oldPassword, newPassword, progress)
+}
+
+// RenameAccount меняет имя для входа; пароль и хранилище не меняются.
+func (uc *LockboxUsecase) RenameAccount(ctx context.Context, password, newUsername string) error {
+	if len(newUsername) < 3 {
+		return errors1.ErrUsernameTooShort
+	}
+	account, err := uc.lockBoxService.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	return uc.lockBoxService.RenameAccount(ctx, account.Username, password, newUsername)
+}
+
+// DeleteAccount удаляет аккаунт на сервере, а затем локальную копию данных.
+func (uc *LockboxUsecase) DeleteAccount(ctx context.Context, password string) error {
+	account, err := uc.lockBoxService.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	if err := uc.lockBoxService.DeleteAccount(ctx, account.Username, password); err != nil {
+		return err
+	}
+
+	uc.syncMu.Lock()
+	defer uc.syncMu.Unlock()
+	err = uc.lockBoxRepository.DeleteUserData()
+	uc.lockBoxRepository.SaveToken("")
+	uc.unlocked = false
+	return err
+}
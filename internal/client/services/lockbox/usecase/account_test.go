@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+	"testing"
+)
+
+// fakeAccountService запоминает, какой пароль установлен на сервере.
+type fakeAccountService struct {
+	*fakeVaultService
+	password string
+}
+
+func (f *fakeAccountService) GetAccount(ctx context.Context) (*models.Account, error) {
+	return &models.Account{UserID: 1, Username: "alice"}, nil
+}
+
+func (f *fakeAccountService) ChangePassword(ctx context.Context, username, oldPassword, newPassword string) error {
+	f.password = newPassword
+	return nil
+}
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+
+	// Мастер-пароль совпадает с паролем аккаунта: хранилище перешифровывается.
+	server := newFakeVault(t, "old password")
+	service := &fakeAccountService{fakeVaultService: &fakeVaultService{server: server}}
+	uc := &LockboxUsecase{lockBoxService: service, lockBoxRepository: &fakeVaultRepository{}}
+
+	if err := uc.ChangePassword(ctx, "old password", "new password", nil); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if service.password != "new password" || server.batches != 1 {
+		t.Fatalf("password=%q batches=%d", service.password, server.batches)
+	}
+	if !crypt.VerifyKeyCheck(server.params.Check, crypt.NewFromPassword("new password", &server.params)) {
+		t.Fatal("хранилище не перешифровано ключом нового пароля")
+	}
+
+	// Отдельный мастер-пароль: меняется только пароль аккаунта.
+	server = newFakeVault(t, "master password")
+	service = &fakeAccountService{fakeVaultService: &fakeVaultService{server: server}}
+	uc = &LockboxUsecase{lockBoxService: service, lockBoxRepository: &fakeVaultRepository{}}
+
+	if err := uc.ChangePassword(ctx, "old password", "new password", nil); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if service.password != "new password" || server.batches != 0 {
+		t.Fatalf("password=%q batches=%d", service.password, server.batches)
+	}
+}
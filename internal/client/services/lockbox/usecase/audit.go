@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	"context"
+	"gophKeeper/internal/client/services/lockbox/models"
+)
+
+// GetAuditEvents возвращает последние события журнала аудита аккаунта.
+// Журнал ведёт сервер, поэтому без связи команда недоступна.
+func (uc *LockboxUsecase) GetAuditEvents(ctx context.Context, limit int) ([]models.AuditEvent, error) {
+	return uc.lockBoxService.GetAuditEvents(ctx, limit)
+}
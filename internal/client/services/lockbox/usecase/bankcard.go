@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	errors1 "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (uc *LockboxUsecase) CreateBankCard(ctx context.Context, data *models.BankCardInput) (int, error) {
+	if data.Name == "" {
+		return 0, errors1.ErrNameLockboxRequired
+	}
+	if data.Number == "" {
+		return 0, errors1.ErrCardNumberRequired
+	}
+	if err := validateBankCard(data, time.Now()); err != nil {
+		return 0, err
+	}
+	return uc.lockBoxService.CreateCard(ctx, data)
+}
+
+func (uc *LockboxUsecase) GetBankCard(ctx context.Context, name string) (*models.BankCard, error) {
+	if name == "" {
+		return nil, errors1.ErrNameLockboxRequired
+	}
+	return uc.lockBoxService.GetCard(ctx, name)
+}
+
+func (uc *LockboxUsecase) GetBankCards(ctx context.Context) (*[]models.BankCard, error) {
+	return uc.lockBoxService.GetCards(ctx)
+}
+
+// UpdateBankCard меняет карту data.Name: непустые поля data заменяют текущие,
+// поля из clear очищаются, остальные остаются прежними. Сервер получает карту
+// целиком, поэтому очистка не путается с «не менять».
+func (uc *LockboxUsecase) UpdateBankCard(ctx context.Context, data *models.BankCardInput, clear []string) error {
+	if data.Name == "" || (data.Number == "" && data.Holder == "" && data.Expiry == "" &&
+		data.CVV == "" && data.PIN == "" && data.Notes == "" && len(clear) == 0) {
+		return errors1.ErrNodataToUpdate
+	}
+	if err := validateBankCard(data, time.Now()); err != nil {
+		return err
+	}
+	current, err := uc.lockBoxService.GetCard(ctx, data.Name)
+	if err != nil {
+		return err
+	}
+	card, err := mergeBankCard(current, data, clear)
+	if err != nil {
+		return err
+	}
+	return uc.lockBoxService.UpdateCard(ctx, current.ID, card)
+}
+
+// mergeBankCard накладывает изменения на текущую карту. Номер очистить нельзя.
+func mergeBankCard(current *models.BankCard, data *models.BankCardInput, clear []string) (*models.BankCardInput, error) {
+	card := &models.BankCardInput{
+		Name: current.Name, Number: current.Number, Holder: current.Holder, Expiry: current.Expiry,
+		CVV: current.CVV, PIN: current.PIN, Notes: current.Notes,
+	}
+	fields := map[string]*string{
+		"holder": &card.Holder, "expiry": &card.Expiry, "cvv": &card.CVV, "pin": &card.PIN, "notes": &card.Notes,
+	}
+	for _, name := range clear {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "number" {
+			return nil, errors1.ErrCardNumberRequired
+		}
+		field, ok := fields[name]
+		if !ok {
+			return nil, errors1.ErrUnknownCardField
+		}
+		*field = ""
+	}
+	set := func(field *string, value string) {
+		if value != "" {
+			*field = value
+		}
+	}
+	set(&card.Number, data.Number)
+	set(&card.Holder, data.Holder)
+	set(&card.Expiry, data.Expiry)
+	set(&card.CVV, data.CVV)
+	set(&card.PIN, data.PIN)
+	set(&card.Notes, data.Notes)
+	return card, nil
+}
+
+func (uc *LockboxUsecase) DeleteBankCard(ctx context.Context, name string) error {
+	if name == "" {
+		return errors1.ErrNameLockboxRequired
+	}
+	return uc.lockBoxService.DeleteCard(ctx, name)
+}
+
+// validateBankCard проверяет заполненные поля карты. Пустые поля пропускаются,
+// чтобы тот же код подходил для частичного обновления.
+func validateBankCard(data *models.BankCardInput, now time.Time) error {
+	if data.Number != "" {
+		data.Number = normalizeCardNumber(data.Number)
+		if !luhnValid(data.Number) {
+			return errors1.ErrInvalidCardNumber
+		}
+	}
+	if data.Expiry != "" {
+		month, year, err := parseCardExpiry(data.Expiry)
+		if err != nil {
+			return err
+		}
+		// Карта действует до конца указанного месяца включительно.
+		expiresAt := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, now.Location())
+		if !now.Before(expiresAt) {
+			return errors1.ErrCardExpired
+		}
+	}
+	if data.CVV != "" && !isDigits(data.CVV, 3, 4) {
+		return errors1.ErrInvalidCVV
+	}
+	if data.PIN != "" && !isDigits(data.PIN, 4, 12) {
+		return errors1.ErrInvalidPIN
+	}
+	return nil
+}
+
+func normalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+// luhnValid проверяет номер карты по алгоритму Луна.
+func luhnValid(number string) bool {
+	if !isDigits(number, 12, 19) {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func parseCardExpiry(expiry string) (month, year int, err error) {
+	parts := strings.Split(strings.TrimSpace(expiry), "/")
+	if len(parts) != 2 || !isDigits(parts[0], 1, 2) || !isDigits(parts[1], 2, 2) {
+		return 0, 0, errors1.ErrInvalidCardExpiry
+	}
+	month, _ = strconv.Atoi(parts[0])
+	year, _ = strconv.Atoi(parts[1])
+	if month < 1 || month > 12 {
+		return 0, 0, errors1.ErrInvalidCardExpiry
+	}
+	return month, 2000 + year, nil
+}
+
+func isDigits(s string, minLen, maxLen int) bool {
+	if len(s) < minLen || len(s) > maxLen {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
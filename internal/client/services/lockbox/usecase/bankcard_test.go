@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"errors"
+	errors1 "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"testing"
+	"time"
+)
+
+func TestLuhnValid(t *testing.T) {
+	cases := map[string]bool{
+		"4111111111111111": true,
+		"5500005555555559": true,
+		"4111111111111112": false,
+		"41111111111a1111": false,
+		"4111":             false,
+	}
+	for number, want := range cases {
+		if got := luhnValid(number); got != want {
+			t.Errorf("luhnValid(%q) = %v, ожидалось %v", number, got, want)
+		}
+	}
+}
+
+func TestValidateBankCard(t *testing.T) {
+	now := time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		card models.BankCardInput
+		want error
+	}{
+		{"valid", models.BankCardInput{Number: "4111 1111 1111 1111", Expiry: "03/25", CVV: "123", PIN: "1234"}, nil},
+		{"bad luhn", models.BankCardInput{Number: "4111111111111112"}, errors1.ErrInvalidCardNumber},
+		{"bad expiry format", models.BankCardInput{Expiry: "2025-03"}, errors1.ErrInvalidCardExpiry},
+		{"bad month", models.BankCardInput{Expiry: "13/25"}, errors1.ErrInvalidCardExpiry},
+		{"expired", models.BankCardInput{Expiry: "02/25"}, errors1.ErrCardExpired},
+		{"bad cvv", models.BankCardInput{CVV: "12"}, errors1.ErrInvalidCVV},
+		{"bad pin", models.BankCardInput{PIN: "12a4"}, errors1.ErrInvalidPIN},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			card := tc.card
+			if err := validateBankCard(&card, now); !errors.Is(err, tc.want) {
+				t.Errorf("ожидалась ошибка %v, получено %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestMergeBankCard(t *testing.T) {
+	current := &models.BankCard{ID: 3, Name: "visa", Number: "4111111111111111", Holder: "IVAN IVANOV", PIN: "1234", Notes: "old"}
+
+	card, err := mergeBankCard(current, &models.BankCardInput{Name: "visa", Notes: "new"}, []string{"pin", " Holder"})
+	if err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	want := models.BankCardInput{Name: "visa", Number: "4111111111111111", Notes: "new"}
+	if *card != want {
+		t.Errorf("mergeBankCard = %+v, ожидалось %+v", *card, want)
+	}
+
+	if _, err := mergeBankCard(current, &models.BankCardInput{Name: "visa"}, []string{"number"}); !errors.Is(err, errors1.ErrCardNumberRequired) {
+		t.Errorf("Очистка номера: ожидалась ErrCardNumberRequired, получено %v", err)
+	}
+	if _, err := mergeBankCard(current, &models.BankCardInput{Name: "visa"}, []string{"color"}); !errors.Is(err, errors1.ErrUnknownCardField) {
+		t.Errorf("Неизвестное поле: ожидалась ErrUnknownCardField, получено %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	errors1 "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// fileChunkSize — размер открытого чанка при загрузке файла.
+const fileChunkSize = 1 << 20
+
+// UploadFile загружает файл по частям. Если загрузка с такими же параметрами
+// уже начата, сервер вернёт полученные чанки и они будут пропущены.
+func (uc *LockboxUsecase) UploadFile(ctx context.Context, name, path string) error {
+	if name == "" {
+		return errors1.ErrNameLockboxRequired
+	}
+	if path == "" {
+		return errors1.ErrFilePathRequired
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	chunkCount := int((info.Size() + fileChunkSize - 1) / fileChunkSize)
+	if chunkCount == 0 {
+		chunkCount = 1
+	}
+
+	session, err := uc.lockBoxService.StartUpload(ctx, &models.BinaryFile{
+		Name:       name,
+		Size:       info.Size(),
+		ChunkSize:  fileChunkSize,
+		ChunkCount: chunkCount,
+	})
+	if err != nil {
+		return err
+	}
+	received := make(map[int]bool, len(session.Received))
+	for _, index := range session.Received {
+		received[index] = true
+	}
+
+	buf := make([]byte, fileChunkSize)
+	for index := 0; index < chunkCount; index++ {
+		if received[index] {
+			continue
+		}
+		n, err := f.ReadAt(buf, int64(index)*fileChunkSize)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err := uc.lockBoxService.UploadChunk(ctx, session.ID, index, buf[:n]); err != nil {
+			return err
+		}
+	}
+
+	return uc.lockBoxService.CompleteUpload(ctx, session.ID)
+}
+
+// DownloadFile скачивает файл по частям во временный файл рядом с path
+// и переименовывает его только после успешной загрузки всех чанков.
+func (uc *LockboxUsecase) DownloadFile(ctx context.Context, name, path string) error {
+	if name == "" {
+		return errors1.ErrNameLockboxRequired
+	}
+	if path == "" {
+		return errors1.ErrFilePathRequired
+	}
+
+	file, err := uc.lockBoxService.GetBinary(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	var written int64
+	for index := 0; index < file.ChunkCount; index++ {
+		chunk, err := uc.lockBoxService.DownloadChunk(ctx, name, index)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		n, err := tmp.Write(chunk)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		written += int64(n)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if written != file.Size {
+		return errors1.ErrBinaryCorrupted
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (uc *LockboxUsecase) GetFiles(ctx context.Context) (*[]models.BinaryFile, error) {
+	return uc.lockBoxService.GetBinaries(ctx)
+}
+
+func (uc *LockboxUsecase) DeleteFile(ctx context.Context, name string) error {
+	if name == "" {
+		return errors1.ErrNameLockboxRequired
+	}
+	return uc.lockBoxService.DeleteBinary(ctx, name)
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	errors1 "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"time"
+)
+
+// resolvePushConflict разрешает конфликт отправки по политике клиента.
+// local — отклонённое изменение, remote — текущая версия сервера. Возвращает
+// true, если после разрешения есть что отправить повторно.
+func (uc *LockboxUsecase) resolvePushConflict(local, remote *models.LockBoxChange) (bool, error) {
+	name := local.LockBox.Name
+	switch uc.conflictPolicy {
+	case models.ConflictLastWriterWins:
+		return true, uc.lockBoxRepository.SetRevision(name, remote.LockBox.Revision)
+	case models.ConflictManual:
+		return false, uc.lockBoxRepository.SaveConflict(&models.LockBoxConflict{
+			Name:          name,
+			Remote:        remote.LockBox,
+			RemoteDeleted: remote.Deleted,
+		})
+	}
+
+	// Оставить обе версии. Удаление против правки решается в пользу правки:
+	// так не теряются данные.
+	switch {
+	case local.Deleted:
+		return false, uc.lockBoxRepository.AcceptChange(remote)
+	case remote.Deleted:
+		return true, uc.lockBoxRepository.SetRevision(name, remote.LockBox.Revision)
+	case sameContent(&local.LockBox, &remote.LockBox):
+		return false, uc.lockBoxRepository.AcceptChange(remote)
+	}
+	copyName, err := uc.conflictCopyName(name)
+	if err != nil {
+		return false, err
+	}
+	box := local.LockBox
+	box.Name, box.Revision = copyName, 0
+	if err := uc.lockBoxRepository.SaveLockBox(&box); err != nil {
+		return false, err
+	}
+	return true, uc.lockBoxRepository.AcceptChange(remote)
+}
+
+// sameContent — одинаковые ли поля у двух версий записи.
+func sameContent(a, b *models.LockBox) bool {
+	return a.URL == b.URL && a.Login == b.Login && a.Password == b.Password && a.Description == b.Description
+}
+
+// conflictCopyName подбирает свободное имя для копии локальной версии.
+func (uc *LockboxUsecase) conflictCopyName(name string) (string, error) {
+	base := fmt.Sprintf("%s (конфликт %s)", name, time.Now().Format("2006-01-02 15:04"))
+	candidate := base
+	for i := 2; ; i++ {
+		exists, err := uc.lockBoxRepository.Exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s %d", base, i)
+	}
+}
+
+// ListConflicts возвращает конфликты, отложенные до решения пользователя.
+func (uc *LockboxUsecase) ListConflicts(ctx context.Context) ([]models.LockBoxConflict, error) {
+	return uc.lockBoxRepository.GetConflicts()
+}
+
+// ResolveConflict разрешает отложенный конфликт записи name. Выбранная
+// локальная версия или результат слияния уйдут на сервер следующей
+// синхронизацией поверх его версии; версия сервера применяется сразу.
+func (uc *LockboxUsecase) ResolveConflict(ctx context.Context, name string, resolution models.ConflictResolution) error {
+	conflict, err := uc.findConflict(name)
+	if err != nil {
+		return err
+	}
+	side := func(field models.ConflictSide) models.ConflictSide {
+		if field == "" {
+			return resolution.Keep
+		}
+		return field
+	}
+	sides := []models.ConflictSide{resolution.Keep, side(resolution.URL), side(resolution.Login),
+		side(resolution.Password), side(resolution.Description)}
+	for _, s := range sides {
+		if s != models.KeepLocal && s != models.KeepRemote {
+			return errors1.ErrInvalidConflictSide
+		}
+	}
+
+	// С удалённой версией сливать нечего: решает выбор для записи целиком.
+	if conflict.LocalDeleted || conflict.RemoteDeleted || allSides(sides, models.KeepRemote) || allSides(sides, models.KeepLocal) {
+		if resolution.Keep == models.KeepLocal {
+			return uc.lockBoxRepository.ResolveConflict(name, nil, conflict.Remote.Revision)
+		}
+		remote := models.LockBoxChange{Deleted: conflict.RemoteDeleted, LockBox: conflict.Remote}
+		if err := uc.lockBoxRepository.AcceptChange(&remote); err != nil {
+			return err
+		}
+		return uc.lockBoxRepository.DeleteConflict(name)
+	}
+
+	pick := func(s models.ConflictSide, local, remote string) string {
+		if s == models.KeepLocal {
+			return local
+		}
+		return remote
+	}
+	merged := &models.LockBox{
+		Name:        name,
+		URL:         pick(sides[1], conflict.Local.URL, conflict.Remote.URL),
+		Login:       pick(sides[2], conflict.Local.Login, conflict.Remote.Login),
+		Password:    pick(sides[3], conflict.Local.Password, conflict.Remote.Password),
+		Description: pick(sides[4], conflict.Local.Description, conflict.Remote.Description),
+	}
+	return uc.lockBoxRepository.ResolveConflict(name, merged, conflict.Remote.Revision)
+}
+
+func allSides(sides []models.ConflictSide, want models.ConflictSide) bool {
+	for _, s := range sides {
+		if s != want {
+			return false
+		}
+	}
+	return true
+}
+
+func (uc *LockboxUsecase) findConflict(name string) (*models.LockBoxConflict, error) {
+	conflicts, err := uc.lockBoxRepository.GetConflicts()
+	if err != nil {
+		return nil, err
+	}
+	for i := range conflicts {
+		if conflicts[i].Name == name {
+			return &conflicts[i], nil
+		}
+	}
+	return nil, errors1.ErrNotFound
+}
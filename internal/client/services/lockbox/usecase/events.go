@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// syncPollInterval — как часто синхронизироваться, пока потока событий
+	// сервера нет.
+	syncPollInterval = 10 * time.Second
+	// syncEventsPollInterval — страховочная синхронизация при живом потоке.
+	syncEventsPollInterval = 5 * time.Minute
+	// eventsRetryBase — задержка переподключения к потоку после первого
+	// обрыва; следующие удваивают её до eventsRetryMax.
+	eventsRetryBase = time.Second
+	eventsRetryMax  = time.Minute
+)
+
+// RunSyncLoop синхронизирует записи с сервером в фоне до отмены ctx. Поток
+// событий сервера будит синхронизацию сразу после изменения на другом
+// устройстве; пока потока нет, она идёт опросом раз в syncPollInterval.
+func (uc *LockboxUsecase) RunSyncLoop(ctx context.Context) {
+	go uc.watchEvents(ctx)
+	for {
+		timer := time.NewTimer(uc.syncInterval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-uc.syncWake:
+			timer.Stop()
+		case <-timer.C:
+		}
+		uc.SyncUpdatesToServer(ctx)
+		uc.SyncUpdatesToLocal(ctx)
+	}
+}
+
+// syncInterval — сколько ждать следующей синхронизации без событий.
+func (uc *LockboxUsecase) syncInterval() time.Duration {
+	if uc.eventsLive.Load() {
+		return syncEventsPollInterval
+	}
+	return syncPollInterval
+}
+
+// watchEvents держит поток событий сервера и переподключается с растущей
+// задержкой. До входа в аккаунт подключаться не с чем.
+func (uc *LockboxUsecase) watchEvents(ctx context.Context) {
+	delay := eventsRetryBase
+	for {
+		wait := syncPollInterval
+		if uc.lockBoxService.Authenticated() {
+			err := uc.lockBoxService.WatchChanges(ctx, uc.onServerChange)
+			if ctx.Err() != nil {
+				return
+			}
+			if uc.eventsLive.Swap(false) {
+				delay = eventsRetryBase
+			}
+			log.Println("event stream disconnected, falling back to polling:", err)
+			wait, delay = delay, min(delay*2, eventsRetryMax)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// onServerChange будит синхронизацию на событие сервера. Изменения, которые
+// клиент уже забрал по курсору, пропускаются; seq 0 — неизвестно, что
+// пропущено, синхронизация нужна.
+func (uc *LockboxUsecase) onServerChange(seq int64) {
+	uc.eventsLive.Store(true)
+	if seq > 0 {
+		if cursor, err := uc.lockBoxRepository.GetSyncCursor(); err == nil && seq <= cursor {
+			return
+		}
+	}
+	select {
+	case uc.syncWake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+	errors1 "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+)
+
+// GetLockBoxHistory возвращает прежние версии записи. История хранится только
+// на сервере, поэтому без связи команда недоступна.
+func (uc *LockboxUsecase) GetLockBoxHistory(ctx context.Context, name string) ([]models.LockBoxRevision, error) {
+	if name == "" {
+		return nil, errors1.ErrNameLockboxRequired
+	}
+	return uc.lockBoxService.GetLockBoxHistory(ctx, name)
+}
+
+// RestoreLockBox возвращает записи содержимое прежней версии и сразу
+// забирает изменение в локальную копию.
+func (uc *LockboxUsecase) RestoreLockBox(ctx context.Context, name string, revision int) error {
+	if name == "" {
+		return errors1.ErrNameLockboxRequired
+	}
+	uc.syncMu.Lock()
+	defer uc.syncMu.Unlock()
+
+	if err := uc.lockBoxService.RestoreLockBox(ctx, name, revision); err != nil {
+		return err
+	}
+	return uc.pullLockBoxChanges(ctx)
+}
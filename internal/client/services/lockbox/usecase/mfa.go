@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"gophKeeper/internal/client/services/lockbox/models"
+)
+
+// VerifyMFA завершает вход, для которого Authenticate вернул ErrMFARequired.
+func (uc *LockboxUsecase) VerifyMFA(ctx context.Context, code string) error {
+	token, err := uc.lockBoxService.VerifyMFA(ctx, code)
+	if err != nil {
+		return err
+	}
+	uc.lockBoxRepository.SaveToken(token)
+	return nil
+}
+
+func (uc *LockboxUsecase) EnrollTOTP(ctx context.Context) (*models.TOTPEnrollment, error) {
+	return uc.lockBoxService.EnrollTOTP(ctx)
+}
+
+func (uc *LockboxUsecase) ConfirmTOTP(ctx context.Context, code string) ([]string, error) {
+	return uc.lockBoxService.ConfirmTOTP(ctx, code)
+}
+
+func (uc *LockboxUsecase) DisableTOTP(ctx context.Context, code string) error {
+	return uc.lockBoxService.DisableTOTP(ctx, code)
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	errors1 "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"log"
+	"time"
+)
+
+func (uc *LockboxUsecase) CreateNote(ctx context.Context, data *models.NoteInput) (int, error) {
+	if data.Name == "" {
+		return 0, errors1.ErrNameLockboxRequired
+	}
+	if data.Body == "" {
+		return 0, errors1.ErrNoteBodyRequired
+	}
+	note := models.Note{Name: data.Name, Body: data.Body}
+
+	id, err := uc.lockBoxService.CreateNote(ctx, data)
+	if err != nil {
+		if errors.Is(err, errors1.ErrNoteExists) {
+			return 0, err
+		}
+		// Сервер недоступен: заметка сохраняется локально и уйдёт при синхронизации.
+		log.Println("failed to create note on server:", err)
+		if err := uc.lockBoxRepository.SaveNote(&note); err != nil {
+			log.Println("failed to save note locally:", err)
+			return 0, err
+		}
+		return 0, nil
+	}
+
+	note.ID = id
+	note.SyncedAt = time.Now()
+	if err := uc.lockBoxRepository.SaveNote(&note); err != nil {
+		log.Println("failed to save note locally:", err)
+	}
+	return id, nil
+}
+
+func (uc *LockboxUsecase) GetNote(ctx context.Context, name string) (*models.Note, error) {
+	if name == "" {
+		return nil, errors1.ErrNameLockboxRequired
+	}
+	note, err := uc.lockBoxService.GetNote(ctx, name)
+	if err != nil {
+		log.Println(err)
+		note, err = uc.lockBoxRepository.GetNote(name)
+		if err != nil || note == nil {
+			return nil, errors1.ErrNotFound
+		}
+	}
+	return note, nil
+}
+
+func (uc *LockboxUsecase) GetNotes(ctx context.Context) (*[]models.Note, error) {
+	notes, err := uc.lockBoxService.GetNotes(ctx)
+	if err != nil {
+		log.Println(err)
+		notes, err = uc.lockBoxRepository.GetNotes()
+		if err != nil {
+			return nil, errors1.ErrNotFound
+		}
+	}
+	return notes, nil
+}
+
+func (uc *LockboxUsecase) UpdateNote(ctx context.Context, data *models.NoteInput) error {
+	if data.Name == "" || data.Body == "" {
+		return errors1.ErrNodataToUpdate
+	}
+	note := models.Note{Name: data.Name, Body: data.Body}
+
+	if err := uc.lockBoxService.UpdateNote(ctx, data); err != nil {
+		if errors.Is(err, errors1.ErrNotFound) {
+			return err
+		}
+		log.Println("failed to update note on server:", err)
+		return uc.lockBoxRepository.SaveNote(&note)
+	}
+	note.SyncedAt = time.Now()
+	if err := uc.lockBoxRepository.SaveNote(&note); err != nil {
+		log.Println("failed to update note locally:", err)
+	}
+	return nil
+}
+
+func (uc *LockboxUsecase) DeleteNote(ctx context.Context, name string) error {
+	if name == "" {
+		return errors1.ErrNameLockboxRequired
+	}
+	if err := uc.lockBoxRepository.DeletedNote(name); err != nil {
+		log.Println(err)
+	}
+	return uc.lockBoxService.DeleteNote(ctx, name)
+}
+
+// syncNotesToServer отправляет заметки, сохранённые локально без связи с сервером.
+func (uc *LockboxUsecase) syncNotesToServer(ctx context.Context) error {
+	notes, err := uc.lockBoxRepository.GetNotes()
+	if err != nil {
+		return err
+	}
+	for _, note := range *notes {
+		if !note.SyncedAt.IsZero() {
+			continue
+		}
+		if err := uc.lockBoxService.UpdateOrCreateNote(ctx, &note); err != nil {
+			return err
+		}
+		note.SyncedAt = time.Now()
+		if err := uc.lockBoxRepository.SaveNote(&note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *LockboxUsecase) syncNotesToLocal(ctx context.Context) error {
+	notes, err := uc.lockBoxService.GetNotes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, note := range *notes {
+		note.SyncedAt = time.Now()
+		if err := uc.lockBoxRepository.SaveNote(&note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"log"
+	"time"
+)
+
+const (
+	// outboxBaseDelay — задержка перед повтором после первой неудачи; каждая
+	// следующая неудача удваивает её до outboxMaxDelay.
+	outboxBaseDelay = time.Second
+	outboxMaxDelay  = 5 * time.Minute
+	// outboxPollInterval — как часто фоновая отправка проверяет очередь,
+	// если повторов не назначено.
+	outboxPollInterval = 30 * time.Second
+)
+
+// outboxBackoff — задержка перед попыткой номер attempts+1 после attempts
+// неудачных.
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxBaseDelay
+	for i := 1; i < attempts && delay < outboxMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, outboxMaxDelay)
+}
+
+// RunOutboxWorker отправляет очередь операций в фоне до отмены ctx:
+// сразу после новой операции и к сроку ближайшего повтора.
+func (uc *LockboxUsecase) RunOutboxWorker(ctx context.Context) {
+	for {
+		uc.syncMu.Lock()
+		_ = uc.pushOutbox(ctx)
+		uc.syncMu.Unlock()
+
+		timer := time.NewTimer(uc.nextOutboxWait())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-uc.outboxWake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// nextOutboxWait — сколько ждать до ближайшего назначенного повтора.
+func (uc *LockboxUsecase) nextOutboxWait() time.Duration {
+	entries, err := uc.lockBoxRepository.GetOutbox()
+	if err != nil {
+		return outboxPollInterval
+	}
+	wait := outboxPollInterval
+	for _, entry := range entries {
+		if entry.NextAttemptAt.IsZero() {
+			continue
+		}
+		wait = min(wait, max(time.Until(entry.NextAttemptAt), outboxBaseDelay))
+	}
+	return wait
+}
+
+// flushOutbox пробует сразу отправить очередь после локальной правки. Без
+// связи операции остаются в очереди, и фоновая отправка повторит их.
+func (uc *LockboxUsecase) flushOutbox(ctx context.Context) {
+	uc.syncMu.Lock()
+	err := uc.pushOutbox(ctx)
+	uc.syncMu.Unlock()
+	if err != nil {
+		log.Println("lockbox changes queued for sync:", err)
+	}
+	select {
+	case uc.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// GetSyncStatus возвращает очередь неотправленных операций, число
+// отложенных конфликтов и курсор ленты изменений сервера.
+func (uc *LockboxUsecase) GetSyncStatus(ctx context.Context) (*models.SyncStatus, error) {
+	pending, err := uc.lockBoxRepository.GetOutbox()
+	if err != nil {
+		return nil, err
+	}
+	conflicts, err := uc.lockBoxRepository.GetConflicts()
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := uc.lockBoxRepository.GetSyncCursor()
+	if err != nil {
+		return nil, err
+	}
+	return &models.SyncStatus{Pending: pending, Conflicts: len(conflicts), Cursor: cursor}, nil
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+	"gophKeeper/internal/client/services/lockbox/models"
+)
+
+// GetSessions возвращает устройства, с которых выполнен вход в аккаунт.
+// Список есть только на сервере, поэтому без связи команда недоступна.
+func (uc *LockboxUsecase) GetSessions(ctx context.Context) (*[]models.Session, error) {
+	return uc.lockBoxService.GetSessions(ctx)
+}
+
+func (uc *LockboxUsecase) RevokeSession(ctx context.Context, id int) error {
+	return uc.lockBoxService.RevokeSession(ctx, id)
+}
+
+func (uc *LockboxUsecase) RevokeOtherSessions(ctx context.Context) (int, error) {
+	return uc.lockBoxService.RevokeOtherSessions(ctx)
+}
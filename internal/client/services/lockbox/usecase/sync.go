@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"log"
+	"time"
+)
+
+// pushBatchSize — сколько изменений отправляется одним запросом.
+const pushBatchSize = 100
+
+// SyncUpdatesToServer отправляет очередь операций над записями и заметки.
+// Операция уходит из очереди, только когда сервер её принял. Конфликты с
+// правками других устройств разрешаются по политике клиента; если после
+// этого есть что отправить, отправка повторяется один раз.
+func (uc *LockboxUsecase) SyncUpdatesToServer(ctx context.Context) error {
+	uc.syncMu.Lock()
+	defer uc.syncMu.Unlock()
+
+	if err := uc.pushOutbox(ctx); err != nil {
+		return err
+	}
+	return uc.syncNotesToServer(ctx)
+}
+
+// pushOutbox отправляет очередь и повторяет отправку один раз, если
+// разрешение конфликтов оставило в ней операции. Вызывается под syncMu.
+func (uc *LockboxUsecase) pushOutbox(ctx context.Context) error {
+	retry, err := uc.pushLockBoxes(ctx)
+	if err != nil || !retry {
+		return err
+	}
+	_, err = uc.pushLockBoxes(ctx)
+	return err
+}
+
+// pushLockBoxes отправляет операции, которые пора отправлять, пакетами.
+// Неудавшийся пакет откладывается с растущей задержкой. Возвращает true,
+// если разрешение конфликтов оставило операции для повторной отправки.
+// Вызывается под syncMu.
+func (uc *LockboxUsecase) pushLockBoxes(ctx context.Context) (bool, error) {
+	pending, err := uc.lockBoxRepository.GetPendingLockBoxes()
+	if err != nil {
+		return false, err
+	}
+	retry := false
+	for start := 0; start < len(pending); start += pushBatchSize {
+		batch := pending[start:min(start+pushBatchSize, len(pending))]
+		ids := make([]int64, len(batch))
+		for i, change := range batch {
+			ids[i] = change.OutboxID
+		}
+		if err := uc.lockBoxRepository.MarkAttempted(ids); err != nil {
+			return false, err
+		}
+		result, err := uc.lockBoxService.PushChanges(ctx, batch)
+		if err != nil {
+			for _, change := range batch {
+				next := time.Now().Add(outboxBackoff(change.Attempts + 1))
+				if err := uc.lockBoxRepository.RetryLater(change.OutboxID, next, err.Error()); err != nil {
+					log.Println("failed to postpone outbox entry:", err)
+				}
+			}
+			return false, err
+		}
+		conflicted := make(map[int]bool, len(result.Conflicts))
+		for i := range result.Conflicts {
+			conflict := &result.Conflicts[i]
+			conflicted[conflict.Index] = true
+			again, err := uc.resolvePushConflict(&batch[conflict.Index], &conflict.LockBoxChange)
+			if err != nil {
+				return false, err
+			}
+			retry = retry || again
+		}
+		for i, change := range batch {
+			if conflicted[i] {
+				continue
+			}
+			revision := 0
+			if i < len(result.Revisions) {
+				revision = result.Revisions[i]
+			}
+			if err := uc.lockBoxRepository.MarkSynced(change.OutboxID, revision); err != nil {
+				return false, err
+			}
+		}
+	}
+	return retry, nil
+}
+
+// SyncUpdatesToLocal забирает изменения сервера после сохранённого курсора.
+func (uc *LockboxUsecase) SyncUpdatesToLocal(ctx context.Context) error {
+	uc.syncMu.Lock()
+	defer uc.syncMu.Unlock()
+
+	if err := uc.pullLockBoxChanges(ctx); err != nil {
+		return err
+	}
+	return uc.syncNotesToLocal(ctx)
+}
+
+// pullLockBoxChanges применяет ленту изменений страницами и сохраняет курсор
+// после каждой, чтобы прерванная синхронизация продолжилась с того же места.
+// Полный список при Reset тоже приходит страницами: курсор первой из них
+// сохраняется, а отсутствующие на сервере записи удаляются только после
+// последней страницы. Вызывается под syncMu.
+func (uc *LockboxUsecase) pullLockBoxChanges(ctx context.Context) error {
+	cursor, err := uc.lockBoxRepository.GetSyncCursor()
+	if err != nil {
+		return err
+	}
+	var (
+		after       int
+		resetCursor int64
+		remote      map[string]bool
+	)
+	for {
+		set, err := uc.lockBoxService.GetChanges(ctx, cursor, after)
+		if err != nil {
+			return err
+		}
+		switch {
+		case set.Reset && remote == nil:
+			remote = make(map[string]bool)
+			resetCursor = set.Cursor
+		case !set.Reset:
+			// Сервер снова принимает курсор: дальше обычная лента.
+			remote, after = nil, 0
+		}
+		for i := range set.Changes {
+			if remote != nil {
+				remote[set.Changes[i].LockBox.Name] = true
+			}
+			if err := uc.lockBoxRepository.ApplyChange(&set.Changes[i]); err != nil {
+				return err
+			}
+		}
+
+		if remote != nil {
+			if set.More {
+				after = set.After
+				continue
+			}
+			if err := uc.dropMissingLockBoxes(remote); err != nil {
+				return err
+			}
+			return uc.lockBoxRepository.SaveSyncCursor(resetCursor)
+		}
+
+		cursor = set.Cursor
+		if err := uc.lockBoxRepository.SaveSyncCursor(cursor); err != nil {
+			return err
+		}
+		if !set.More {
+			return nil
+		}
+	}
+}
+
+// dropMissingLockBoxes удаляет локальные записи, которых нет в полном
+// списке сервера. Записи с неотправленными правками ApplyChange не трогает.
+func (uc *LockboxUsecase) dropMissingLockBoxes(remote map[string]bool) error {
+	local, err := uc.lockBoxRepository.GetLockBoxes()
+	if err != nil {
+		return err
+	}
+	for _, box := range *local {
+		if remote[box.Name] {
+			continue
+		}
+		change := models.LockBoxChange{Deleted: true, LockBox: models.LockBox{Name: box.Name}}
+		if err := uc.lockBoxRepository.ApplyChange(&change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,471 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"gophKeeper/internal/client/services/lockbox/clients"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/internal/client/services/lockbox/repository"
+	"testing"
+	"time"
+)
+
+// fakeSyncService отдаёт заранее заготовленные страницы ленты изменений.
+type fakeSyncService struct {
+	clients.LockBoxService
+	pages map[int64]*models.ChangeSet
+	// snapshot — следующие страницы полного списка по ключу after.
+	snapshot map[int]*models.ChangeSet
+	pushed   [][]models.LockBoxChange
+	// remote — версии сервера: изменение с другой версией отклоняется.
+	remote map[string]models.LockBox
+	// fail — ошибка отправки, пока сервер недоступен.
+	fail error
+}
+
+func (f *fakeSyncService) GetChanges(ctx context.Context, since int64, after int) (*models.ChangeSet, error) {
+	if after > 0 {
+		return f.snapshot[after], nil
+	}
+	if set, ok := f.pages[since]; ok {
+		return set, nil
+	}
+	return &models.ChangeSet{Cursor: since}, nil
+}
+
+func (f *fakeSyncService) PushChanges(ctx context.Context, changes []models.LockBoxChange) (*models.PushResult, error) {
+	f.pushed = append(f.pushed, changes)
+	if f.fail != nil {
+		return nil, f.fail
+	}
+	result := &models.PushResult{Revisions: make([]int, len(changes))}
+	for i, change := range changes {
+		current, ok := f.remote[change.LockBox.Name]
+		if ok && current.Revision != change.LockBox.Revision {
+			result.Conflicts = append(result.Conflicts, models.PushConflict{
+				Index:         i,
+				LockBoxChange: models.LockBoxChange{LockBox: current},
+			})
+			continue
+		}
+		current = change.LockBox
+		current.Revision++
+		if f.remote != nil {
+			f.remote[change.LockBox.Name] = current
+		}
+		result.Revisions[i] = current.Revision
+		result.Applied++
+	}
+	return result, nil
+}
+
+func (f *fakeSyncService) GetNotes(ctx context.Context) (*[]models.Note, error) {
+	return &[]models.Note{}, nil
+}
+
+// fakeSyncRepository хранит записи по имени, как локальная база.
+type fakeSyncRepository struct {
+	repository.Repository
+	boxes     map[string]models.LockBox
+	deleted   map[string]bool
+	pending   []models.LockBoxChange
+	synced    map[string]int
+	revisions map[string]int
+	conflicts []models.LockBoxConflict
+	cursor    int64
+	// retryAt — на когда отложена операция очереди после неудачи.
+	retryAt map[int64]time.Time
+}
+
+func newFakeSyncRepository() *fakeSyncRepository {
+	return &fakeSyncRepository{
+		boxes:     map[string]models.LockBox{},
+		deleted:   map[string]bool{},
+		synced:    map[string]int{},
+		revisions: map[string]int{},
+		retryAt:   map[int64]time.Time{},
+	}
+}
+
+func (f *fakeSyncRepository) GetLockBoxes() (*[]models.LockBox, error) {
+	var boxes []models.LockBox
+	for name, box := range f.boxes {
+		if !f.deleted[name] {
+			boxes = append(boxes, box)
+		}
+	}
+	return &boxes, nil
+}
+
+func (f *fakeSyncRepository) ApplyChange(change *models.LockBoxChange) error {
+	name := change.LockBox.Name
+	if change.Deleted {
+		f.deleted[name] = true
+		return nil
+	}
+	f.boxes[name] = change.LockBox
+	delete(f.deleted, name)
+	return nil
+}
+
+func (f *fakeSyncRepository) AcceptChange(change *models.LockBoxChange) error {
+	f.synced[change.LockBox.Name] = 0
+	f.revisions[change.LockBox.Name] = change.LockBox.Revision
+	return f.ApplyChange(change)
+}
+
+// GetPendingLockBoxes отдаёт неотправленные правки, как локальная база:
+// с текущей версией сервера и без отложенных конфликтов.
+func (f *fakeSyncRepository) GetPendingLockBoxes() ([]models.LockBoxChange, error) {
+	var pending []models.LockBoxChange
+	for _, change := range f.pending {
+		if _, done := f.synced[change.LockBox.Name]; done {
+			continue
+		}
+		if f.hasConflict(change.LockBox.Name) {
+			continue
+		}
+		change.LockBox.Revision = f.revisions[change.LockBox.Name]
+		pending = append(pending, change)
+	}
+	return pending, nil
+}
+
+func (f *fakeSyncRepository) hasConflict(name string) bool {
+	for _, c := range f.conflicts {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeSyncRepository) MarkAttempted(outboxIDs []int64) error {
+	for _, id := range outboxIDs {
+		for i := range f.pending {
+			if f.pending[i].OutboxID == id {
+				f.pending[i].Attempts++
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeSyncRepository) RetryLater(outboxID int64, at time.Time, lastError string) error {
+	f.retryAt[outboxID] = at
+	return nil
+}
+
+func (f *fakeSyncRepository) MarkSynced(outboxID int64, revision int) error {
+	for _, change := range f.pending {
+		if change.OutboxID == outboxID {
+			f.synced[change.LockBox.Name] = int(outboxID)
+			f.revisions[change.LockBox.Name] = revision
+		}
+	}
+	return nil
+}
+
+func (f *fakeSyncRepository) SetRevision(name string, revision int) error {
+	f.revisions[name] = revision
+	return nil
+}
+
+func (f *fakeSyncRepository) SaveConflict(conflict *models.LockBoxConflict) error {
+	f.conflicts = append(f.conflicts, *conflict)
+	return nil
+}
+
+func (f *fakeSyncRepository) SaveLockBox(box *models.LockBox) error {
+	f.boxes[box.Name] = *box
+	f.pending = append(f.pending, models.LockBoxChange{LockBox: *box, OutboxID: int64(len(f.pending) + 1)})
+	return nil
+}
+
+func (f *fakeSyncRepository) Exists(name string) (bool, error) {
+	_, ok := f.boxes[name]
+	return ok, nil
+}
+
+func (f *fakeSyncRepository) GetSyncCursor() (int64, error) {
+	return f.cursor, nil
+}
+
+func (f *fakeSyncRepository) SaveSyncCursor(cursor int64) error {
+	f.cursor = cursor
+	return nil
+}
+
+func (f *fakeSyncRepository) GetNotes() (*[]models.Note, error) {
+	return &[]models.Note{}, nil
+}
+
+func TestSyncUpdatesToLocal(t *testing.T) {
+	ctx := context.Background()
+	service := &fakeSyncService{pages: map[int64]*models.ChangeSet{
+		3: {Cursor: 5, More: true, Changes: []models.LockBoxChange{
+			{Seq: 4, LockBox: models.LockBox{Name: "mail", Password: "new"}},
+			{Seq: 5, Deleted: true, LockBox: models.LockBox{Name: "old"}},
+		}},
+		5: {Cursor: 6, Changes: []models.LockBoxChange{
+			{Seq: 6, LockBox: models.LockBox{Name: "bank", Password: "pin"}},
+		}},
+	}}
+	repo := newFakeSyncRepository()
+	repo.cursor = 3
+	repo.boxes["old"] = models.LockBox{Name: "old"}
+	uc := &LockboxUsecase{lockBoxService: service, lockBoxRepository: repo}
+
+	if err := uc.SyncUpdatesToLocal(ctx); err != nil {
+		t.Fatalf("SyncUpdatesToLocal: %v", err)
+	}
+	if repo.cursor != 6 {
+		t.Errorf("курсор должен дойти до последней страницы, получено: %d", repo.cursor)
+	}
+	if repo.boxes["mail"].Password != "new" || repo.boxes["bank"].Password != "pin" {
+		t.Errorf("изменения не применены: %+v", repo.boxes)
+	}
+	if !repo.deleted["old"] {
+		t.Error("удаление не применено")
+	}
+}
+
+func TestSyncUpdatesToLocalReset(t *testing.T) {
+	ctx := context.Background()
+	service := &fakeSyncService{
+		pages: map[int64]*models.ChangeSet{
+			9: {Cursor: 20, Reset: true, More: true, After: 4, Changes: []models.LockBoxChange{
+				{Seq: 15, LockBox: models.LockBox{ID: 4, Name: "mail"}},
+			}},
+		},
+		snapshot: map[int]*models.ChangeSet{
+			4: {Cursor: 21, Reset: true, Changes: []models.LockBoxChange{
+				{Seq: 18, LockBox: models.LockBox{ID: 6, Name: "bank"}},
+			}},
+		},
+	}
+	repo := newFakeSyncRepository()
+	repo.cursor = 9
+	repo.boxes["mail"] = models.LockBox{Name: "mail"}
+	repo.boxes["bank"] = models.LockBox{Name: "bank"}
+	repo.boxes["gone"] = models.LockBox{Name: "gone"}
+	uc := &LockboxUsecase{lockBoxService: service, lockBoxRepository: repo}
+
+	if err := uc.SyncUpdatesToLocal(ctx); err != nil {
+		t.Fatalf("SyncUpdatesToLocal: %v", err)
+	}
+	if !repo.deleted["gone"] || repo.deleted["mail"] || repo.deleted["bank"] {
+		t.Errorf("после полной синхронизации должны остаться mail и bank: %+v", repo.deleted)
+	}
+	if repo.cursor != 20 {
+		t.Errorf("неверный курсор: %d", repo.cursor)
+	}
+}
+
+func TestSyncUpdatesToServer(t *testing.T) {
+	ctx := context.Background()
+	service := &fakeSyncService{}
+	repo := newFakeSyncRepository()
+	for i := 0; i < pushBatchSize+1; i++ {
+		repo.pending = append(repo.pending, models.LockBoxChange{
+			LockBox:  models.LockBox{Name: string(rune('a'+i%26)) + string(rune('0'+i/26))},
+			OutboxID: int64(i + 1),
+		})
+	}
+	uc := &LockboxUsecase{lockBoxService: service, lockBoxRepository: repo}
+
+	if err := uc.SyncUpdatesToServer(ctx); err != nil {
+		t.Fatalf("SyncUpdatesToServer: %v", err)
+	}
+	if len(service.pushed) != 2 || len(service.pushed[0]) != pushBatchSize || len(service.pushed[1]) != 1 {
+		t.Fatalf("изменения должны уйти двумя пакетами, получено: %d", len(service.pushed))
+	}
+	last := repo.pending[pushBatchSize]
+	if repo.synced[last.LockBox.Name] != int(last.OutboxID) {
+		t.Errorf("операция %d не убрана из очереди", last.OutboxID)
+	}
+}
+
+func TestSyncUpdatesToServerOffline(t *testing.T) {
+	ctx := context.Background()
+	service := &fakeSyncService{fail: errors.New("connection refused")}
+	repo := newFakeSyncRepository()
+	repo.pending = []models.LockBoxChange{
+		{LockBox: models.LockBox{Name: "mail"}, OutboxID: 1, IdempotencyKey: "k1"},
+		{LockBox: models.LockBox{Name: "bank"}, OutboxID: 2, IdempotencyKey: "k2", Attempts: 3},
+	}
+	uc := &LockboxUsecase{lockBoxService: service, lockBoxRepository: repo}
+
+	if err := uc.SyncUpdatesToServer(ctx); err == nil {
+		t.Fatal("ошибка отправки должна вернуться")
+	}
+	if len(repo.synced) != 0 {
+		t.Errorf("без ответа сервера операции остаются в очереди: %+v", repo.synced)
+	}
+	if key := service.pushed[0][0].IdempotencyKey; key != "k1" {
+		t.Errorf("операция должна уйти со своим ключом, получено: %q", key)
+	}
+	first, second := time.Until(repo.retryAt[1]), time.Until(repo.retryAt[2])
+	if first <= 0 || first > outboxBaseDelay || second <= 4*time.Second || second > 8*time.Second {
+		t.Errorf("повтор должен откладываться тем дольше, чем больше неудач: %v %v", first, second)
+	}
+
+	service.fail = nil
+	if err := uc.SyncUpdatesToServer(ctx); err != nil {
+		t.Fatalf("SyncUpdatesToServer: %v", err)
+	}
+	if len(repo.synced) != 2 {
+		t.Errorf("после восстановления связи очередь должна уйти: %+v", repo.synced)
+	}
+	if key := service.pushed[1][0].IdempotencyKey; key != "k1" {
+		t.Errorf("повтор должен уйти с тем же ключом, получено: %q", key)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		4:  8 * time.Second,
+		30: outboxMaxDelay,
+	} {
+		if got := outboxBackoff(attempts); got != want {
+			t.Errorf("outboxBackoff(%d) = %v, ожидалось %v", attempts, got, want)
+		}
+	}
+}
+
+// newConflictFixture — правка записи mail от версии 1, пока на сервере её
+// уже изменили до версии 2.
+func TestServerEventsWakeSync(t *testing.T) {
+	repo := newFakeSyncRepository()
+	repo.cursor = 5
+	uc := &LockboxUsecase{lockBoxRepository: repo, syncWake: make(chan struct{}, 1)}
+	woken := func() bool {
+		select {
+		case <-uc.syncWake:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if uc.syncInterval() != syncPollInterval {
+		t.Errorf("без потока событий нужен частый опрос, получено: %s", uc.syncInterval())
+	}
+	uc.onServerChange(5)
+	if woken() {
+		t.Error("уже забранное изменение не должно будить синхронизацию")
+	}
+	if uc.syncInterval() != syncEventsPollInterval {
+		t.Errorf("при живом потоке опрос только страховочный, получено: %s", uc.syncInterval())
+	}
+	uc.onServerChange(6)
+	if !woken() {
+		t.Error("новое изменение должно будить синхронизацию")
+	}
+	uc.onServerChange(0)
+	if !woken() {
+		t.Error("после переподключения синхронизация нужна")
+	}
+}
+
+func newConflictFixture(policy models.ConflictPolicy) (*LockboxUsecase, *fakeSyncService, *fakeSyncRepository) {
+	service := &fakeSyncService{remote: map[string]models.LockBox{
+		"mail": {Name: "mail", Password: "theirs", Revision: 2},
+	}}
+	repo := newFakeSyncRepository()
+	repo.boxes["mail"] = models.LockBox{Name: "mail", Password: "mine"}
+	repo.revisions["mail"] = 1
+	repo.pending = []models.LockBoxChange{{LockBox: models.LockBox{Name: "mail", Password: "mine"}, OutboxID: 1}}
+	uc := NewLockboxUsecase(service, repo, policy).(*LockboxUsecase)
+	return uc, service, repo
+}
+
+func TestSyncConflictLastWriterWins(t *testing.T) {
+	uc, service, _ := newConflictFixture(models.ConflictLastWriterWins)
+
+	if err := uc.SyncUpdatesToServer(context.Background()); err != nil {
+		t.Fatalf("SyncUpdatesToServer: %v", err)
+	}
+	if got := service.remote["mail"]; got.Password != "mine" || got.Revision != 3 {
+		t.Errorf("локальная правка должна перезаписать серверную, получено: %+v", got)
+	}
+}
+
+func TestSyncConflictKeepBoth(t *testing.T) {
+	uc, service, repo := newConflictFixture(models.ConflictKeepBoth)
+
+	if err := uc.SyncUpdatesToServer(context.Background()); err != nil {
+		t.Fatalf("SyncUpdatesToServer: %v", err)
+	}
+	if repo.boxes["mail"].Password != "theirs" || service.remote["mail"].Password != "theirs" {
+		t.Errorf("под прежним именем должна остаться версия сервера: %+v", repo.boxes["mail"])
+	}
+	copies := 0
+	for name, box := range service.remote {
+		if name != "mail" && box.Password == "mine" {
+			copies++
+		}
+	}
+	if copies != 1 {
+		t.Errorf("локальная версия должна уйти на сервер копией: %+v", service.remote)
+	}
+}
+
+func TestSyncConflictManual(t *testing.T) {
+	uc, service, repo := newConflictFixture(models.ConflictManual)
+
+	if err := uc.SyncUpdatesToServer(context.Background()); err != nil {
+		t.Fatalf("SyncUpdatesToServer: %v", err)
+	}
+	if len(repo.conflicts) != 1 || repo.conflicts[0].Remote.Password != "theirs" {
+		t.Fatalf("конфликт должен быть отложен: %+v", repo.conflicts)
+	}
+	if service.remote["mail"].Password != "theirs" {
+		t.Error("до решения пользователя версия сервера не меняется")
+	}
+	if _, done := repo.synced["mail"]; done {
+		t.Error("правка с конфликтом не должна считаться отправленной")
+	}
+}
+
+func (f *fakeSyncRepository) GetConflicts() ([]models.LockBoxConflict, error) {
+	return f.conflicts, nil
+}
+
+func (f *fakeSyncRepository) ResolveConflict(name string, box *models.LockBox, revision int) error {
+	if box != nil {
+		f.boxes[name] = *box
+	}
+	f.revisions[name] = revision
+	f.conflicts = nil
+	return nil
+}
+
+func TestResolveConflictMerge(t *testing.T) {
+	repo := newFakeSyncRepository()
+	repo.conflicts = []models.LockBoxConflict{{
+		Name:   "mail",
+		Local:  models.LockBox{Name: "mail", Login: "me", Password: "mine"},
+		Remote: models.LockBox{Name: "mail", Login: "them", Password: "theirs", Revision: 4},
+	}}
+	uc := &LockboxUsecase{lockBoxRepository: repo}
+	ctx := context.Background()
+
+	err := uc.ResolveConflict(ctx, "mail", models.ConflictResolution{Keep: "both"})
+	if err == nil {
+		t.Fatal("неизвестная сторона должна быть отклонена")
+	}
+
+	err = uc.ResolveConflict(ctx, "mail", models.ConflictResolution{Keep: models.KeepRemote, Password: models.KeepLocal})
+	if err != nil {
+		t.Fatalf("ResolveConflict: %v", err)
+	}
+	if got := repo.boxes["mail"]; got.Login != "them" || got.Password != "mine" {
+		t.Errorf("поля должны быть взяты с выбранных сторон, получено: %+v", got)
+	}
+	if repo.revisions["mail"] != 4 || len(repo.conflicts) != 0 {
+		t.Errorf("правка должна перейти на версию сервера и конфликт снят: %d %+v", repo.revisions["mail"], repo.conflicts)
+	}
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	errors1 "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"time"
+)
+
+// ListTrash возвращает удалённые записи. Корзину хранит сервер: локальная
+// база не знает серверных id, по которым записи восстанавливаются.
+func (uc *LockboxUsecase) ListTrash(ctx context.Context) ([]models.LockBox, error) {
+	return uc.lockBoxService.GetTrash(ctx)
+}
+
+// RestoreTrash возвращает запись id из корзины и сразу забирает изменение в
+// локальную копию, чтобы запись снова была видна без связи с сервером.
+func (uc *LockboxUsecase) RestoreTrash(ctx context.Context, id int) error {
+	if id <= 0 {
+		return errors1.ErrNotFound
+	}
+	uc.syncMu.Lock()
+	defer uc.syncMu.Unlock()
+
+	if err := uc.lockBoxService.RestoreFromTrash(ctx, id); err != nil {
+		return err
+	}
+	return uc.pullLockBoxChanges(ctx)
+}
+
+// EmptyTrash окончательно удаляет записи из корзины на сервере и в
+// локальной базе и возвращает число удалённых на сервере записей.
+func (uc *LockboxUsecase) EmptyTrash(ctx context.Context) (int, error) {
+	purged, err := uc.lockBoxService.EmptyTrash(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := uc.lockBoxRepository.PurgeExpiredLocks(time.Now()); err != nil {
+		return purged, err
+	}
+	return purged, nil
+}
+
+// PurgeTrash удаляет из локальной базы записи, пролежавшие в корзине
+// дольше retention. Нулевой срок оставляет их до явной очистки.
+func (uc *LockboxUsecase) PurgeTrash(retention time.Duration) error {
+	if retention <= 0 {
+		return nil
+	}
+	return uc.lockBoxRepository.PurgeExpiredLocks(time.Now().Add(-retention))
+}
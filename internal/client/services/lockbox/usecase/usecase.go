@@ -2,11 +2,12 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	errors1 "gophKeeper/internal/client/errors"
 	"gophKeeper/internal/client/services/lockbox/clients"
 	"gophKeeper/internal/client/services/lockbox/models"
 	"gophKeeper/internal/client/services/lockbox/repository"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"log"
@@ -21,18 +22,88 @@ type ILockBoxUsecase interface {
 	Register(ctx context.Context, username, password string) error
 	Authenticate(ctx context.Context, username, password string) error
 	IsAuthenticated() bool
+	Unlock(ctx context.Context, masterPassword string) error
+	IsUnlocked() bool
+	HasVault(ctx context.Context) (bool, error)
+	RotateKey(ctx context.Context, oldPassword, newPassword string, progress func(done, total int, item string)) error
+	AbortKeyRotation() error
 	SyncUpdatesToServer(ctx context.Context) error
 	SyncUpdatesToLocal(ctx context.Context) error
+	CreateBankCard(ctx context.Context, data *models.BankCardInput) (int, error)
+	GetBankCard(ctx context.Context, name string) (*models.BankCard, error)
+	GetBankCards(ctx context.Context) (*[]models.BankCard, error)
+	UpdateBankCard(ctx context.Context, data *models.BankCardInput, clear []string) error
+	DeleteBankCard(ctx context.Context, name string) error
+	CreateNote(ctx context.Context, data *models.NoteInput) (int, error)
+	GetNote(ctx context.Context, name string) (*models.Note, error)
+	GetNotes(ctx context.Context) (*[]models.Note, error)
+	UpdateNote(ctx context.Context, data *models.NoteInput) error
+	DeleteNote(ctx context.Context, name string) error
+	UploadFile(ctx context.Context, name, path string) error
+	DownloadFile(ctx context.Context, name, path string) error
+	GetFiles(ctx context.Context) (*[]models.BinaryFile, error)
+	DeleteFile(ctx context.Context, name string) error
+	GetSessions(ctx context.Context) (*[]models.Session, error)
+	RevokeSession(ctx context.Context, id int) error
+	RevokeOtherSessions(ctx context.Context) (int, error)
+	VerifyMFA(ctx context.Context, code string) error
+	EnrollTOTP(ctx context.Context) (*models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, code string) error
+	GetAccount(ctx context.Context) (*models.Account, error)
+	ChangePassword(ctx context.Context, oldPassword, newPassword string, progress func(done, total int, item string)) error
+	RenameAccount(ctx context.Context, password, newUsername string) error
+	DeleteAccount(ctx context.Context, password string) error
+	GetAuditEvents(ctx context.Context, limit int) ([]models.AuditEvent, error)
+	GetLockBoxHistory(ctx context.Context, name string) ([]models.LockBoxRevision, error)
+	RestoreLockBox(ctx context.Context, name string, revision int) error
+	ListTrash(ctx context.Context) ([]models.LockBox, error)
+	RestoreTrash(ctx context.Context, id int) error
+	EmptyTrash(ctx context.Context) (int, error)
+	PurgeTrash(retention time.Duration) error
+	ListConflicts(ctx context.Context) ([]models.LockBoxConflict, error)
+	ResolveConflict(ctx context.Context, name string, resolution models.ConflictResolution) error
+	RunOutboxWorker(ctx context.Context)
+	RunSyncLoop(ctx context.Context)
+	GetSyncStatus(ctx context.Context) (*models.SyncStatus, error)
 }
 type LockboxUsecase struct {
 	lockBoxService    clients.LockBoxService
 	lockBoxRepository repository.Repository
+	unlocked          bool
+	// syncMu не даёт фоновой синхронизации писать данные старым ключом во время его смены.
+	syncMu sync.Mutex
+	// conflictPolicy — как разрешать конфликты правок с разных устройств.
+	conflictPolicy models.ConflictPolicy
+	// outboxWake будит фоновую отправку очереди после новой операции.
+	outboxWake chan struct{}
+	// syncWake будит фоновую синхронизацию по событию сервера.
+	syncWake chan struct{}
+	// eventsLive — открыт ли поток событий сервера.
+	eventsLive atomic.Bool
 }
 
-func NewLockboxUsecase(lockBoxService clients.LockBoxService, lockBoxRepository repository.Repository) ILockBoxUsecase {
-	return &LockboxUsecase{lockBoxService: lockBoxService, lockBoxRepository: lockBoxRepository}
+// NewLockboxUsecase создаёт сценарии клиента. Неизвестная политика
+// конфликтов заменяется на ConflictKeepBoth: она не теряет данные и не
+// требует участия пользователя.
+func NewLockboxUsecase(lockBoxService clients.LockBoxService, lockBoxRepository repository.Repository, conflictPolicy models.ConflictPolicy) ILockBoxUsecase {
+	switch conflictPolicy {
+	case models.ConflictLastWriterWins, models.ConflictManual:
+	default:
+		conflictPolicy = models.ConflictKeepBoth
+	}
+	return &LockboxUsecase{
+		lockBoxService:    lockBoxService,
+		lockBoxRepository: lockBoxRepository,
+		conflictPolicy:    conflictPolicy,
+		outboxWake:        make(chan struct{}, 1),
+		syncWake:          make(chan struct{}, 1),
+	}
 }
 
+// CreateLockBox сохраняет запись локально и ставит её создание в очередь
+// отправки, затем сразу пробует отправить очередь. Без связи с сервером
+// запись уйдёт позже.
 func (uc *LockboxUsecase) CreateLockBox(ctx context.Context, data *models.LockBoxInput) (int, error) {
 	if data.Name == "" {
 		return 0, errors1.ErrNameLockboxRequired
@@ -40,53 +111,28 @@ func (uc *LockboxUsecase) CreateLockBox(ctx context.Context, data *models.LockBo
 	if data.URL == "" && data.Login == "" && data.Password == "" && data.Description == "" {
 		return 0, errors1.ErrDataRequired
 	}
-	id, err := uc.lockBoxService.Create(ctx, data)
-	if err != nil {
-		if errors.Is(err, errors1.ErrLockboxNameTakenByUser) {
-			return 0, errors1.ErrExists
-		}
-
-		lockBox := models.LockBox{
-			Name:        data.Name,
-			Login:       data.Login,
-			URL:         data.URL,
-			Password:    data.Password,
-			Description: data.Description,
-		}
-
-		err = uc.lockBoxRepository.SaveLockBox(&lockBox)
-
-		if err != nil {
-			log.Println("failed to save lockbox locally:", err)
-			return 0, err
-		}
-
-		return lockBox.ID, nil
-	}
-
 	lockBox := models.LockBox{
-		ID:          id,
 		Name:        data.Name,
+		Login:       data.Login,
 		URL:         data.URL,
 		Password:    data.Password,
 		Description: data.Description,
-		SyncedAt:    time.Now(),
 	}
-
-	err = uc.lockBoxRepository.SaveLockBox(&lockBox)
-	if err != nil {
-		log.Println("failed to save lockbox locally:", err)
+	if err := uc.lockBoxRepository.SaveLockBox(&lockBox); err != nil {
+		return 0, err
 	}
-
-	return id, nil
+	uc.flushOutbox(ctx)
+	return lockBox.ID, nil
 }
 
+// DeleteLockBox переносит запись в корзину локально и ставит удаление в
+// очередь отправки.
 func (uc *LockboxUsecase) DeleteLockBox(ctx context.Context, name string) error {
-	err := uc.lockBoxRepository.Deleted(name)
-	if err != nil {
-		log.Println(err)
+	if err := uc.lockBoxRepository.Deleted(name); err != nil {
+		return err
 	}
-	return uc.lockBoxService.Delete(ctx, name)
+	uc.flushOutbox(ctx)
+	return nil
 }
 
 func (uc *LockboxUsecase) GetLockBoxById(ctx context.Context, name string) (*models.LockBox, error) {
@@ -118,6 +164,8 @@ func (uc *LockboxUsecase) GetLockBoxAll(ctx context.Context) (*[]models.LockBox,
 
 }
 
+// UpdateLockBox меняет непустые поля записи локально и ставит правку в
+// очередь отправки.
 func (uc *LockboxUsecase) UpdateLockBox(ctx context.Context, data *models.LockBoxInput) error {
 	if data.Name == "" || (data.Login == "" && data.Password == "" && data.Description == "" && data.URL == "") {
 		return errors1.ErrNodataToUpdate
@@ -129,18 +177,10 @@ func (uc *LockboxUsecase) UpdateLockBox(ctx context.Context, data *models.LockBo
 		Password:    data.Password,
 		Description: data.Description,
 	}
-	err := uc.lockBoxService.Update(ctx, data)
-	if err != nil {
-		log.Println("failed to update lockbox service:", err)
-		err1 := uc.lockBoxRepository.Updated(&lockBox)
-		if err1 != nil {
-			log.Println("failed to update lockbox local:", err1)
-			return errors1.ErrNotFound
-		}
-		return nil
+	if err := uc.lockBoxRepository.Updated(&lockBox); err != nil {
+		return err
 	}
-	lockBox.SyncedAt = time.Now()
-	_ = uc.lockBoxRepository.SaveLockBox(&lockBox)
+	uc.flushOutbox(ctx)
 	return nil
 }
 
@@ -173,41 +213,3 @@ func (uc *LockboxUsecase) Authenticate(ctx context.Context, username, password s
 func (uc *LockboxUsecase) IsAuthenticated() bool {
 	return uc.lockBoxService.Authenticated()
 }
-
-func (uc *LockboxUsecase) SyncUpdatesToServer(ctx context.Context) error {
-	items, err := uc.lockBoxRepository.GetLockBoxes()
-	if err != nil {
-		return err
-	}
-	for _, item := range *items {
-		err = uc.lockBoxService.UpdateOrCreate(ctx, &item)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (uc *LockboxUsecase) SyncUpdatesToLocal(ctx context.Context) error {
-	items, err := uc.lockBoxService.GetAll(ctx)
-	if err != nil {
-		return err
-	}
-	for _, item := range *items {
-		exists, err := uc.lockBoxRepository.Exists(item.Name)
-		if err != nil {
-			return err
-		}
-		if !exists {
-			err = uc.lockBoxRepository.SaveLockBox(&item)
-			if err != nil {
-				return err
-			}
-		}
-		err = uc.lockBoxRepository.Updated(&item)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
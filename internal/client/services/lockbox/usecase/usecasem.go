@@ -78,6 +78,29 @@ func (m *MockLockBoxUsecase) IsAuthenticated() bool {
 	return true
 }
 
+func (m *MockLockBoxUsecase) Unlock(ctx context.Context, masterPassword string) error {
+	return nil
+}
+
+func (m *MockLockBoxUsecase) IsUnlocked() bool {
+	return true
+}
+
+func (m *MockLockBoxUsecase) HasVault(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (m *MockLockBoxUsecase) RotateKey(ctx context.Context, oldPassword, newPassword string, progress func(done, total int, item string)) error {
+	if progress != nil {
+		progress(1, 1, "box1")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) AbortKeyRotation() error {
+	return nil
+}
+
 func (m *MockLockBoxUsecase) SyncUpdatesToServer(ctx context.Context) error {
 	return nil
 }
@@ -85,3 +108,249 @@ func (m *MockLockBoxUsecase) SyncUpdatesToServer(ctx context.Context) error {
 func (m *MockLockBoxUsecase) SyncUpdatesToLocal(ctx context.Context) error {
 	return nil
 }
+
+func (m *MockLockBoxUsecase) CreateBankCard(ctx context.Context, data *models.BankCardInput) (int, error) {
+	return 7, nil
+}
+
+func (m *MockLockBoxUsecase) GetBankCard(ctx context.Context, name string) (*models.BankCard, error) {
+	if name == "notfound" {
+		return nil, fmt.Errorf("not found")
+	}
+	return &models.BankCard{
+		Name:      name,
+		Number:    "4111111111111111",
+		Holder:    "IVAN IVANOV",
+		Expiry:    "12/30",
+		CVV:       "123",
+		PIN:       "1234",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+func (m *MockLockBoxUsecase) GetBankCards(ctx context.Context) (*[]models.BankCard, error) {
+	cards := []models.BankCard{
+		{Name: "visa", Number: "4111111111111111", Holder: "IVAN IVANOV", Expiry: "12/30"},
+	}
+	return &cards, nil
+}
+
+func (m *MockLockBoxUsecase) UpdateBankCard(ctx context.Context, data *models.BankCardInput, clear []string) error {
+	return nil
+}
+
+func (m *MockLockBoxUsecase) DeleteBankCard(ctx context.Context, name string) error {
+	if name == "error" {
+		return fmt.Errorf("delete error")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) CreateNote(ctx context.Context, data *models.NoteInput) (int, error) {
+	return 3, nil
+}
+
+func (m *MockLockBoxUsecase) GetNote(ctx context.Context, name string) (*models.Note, error) {
+	if name == "notfound" {
+		return nil, fmt.Errorf("not found")
+	}
+	return &models.Note{
+		Name:      name,
+		Body:      "line 1\nline 2",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+func (m *MockLockBoxUsecase) GetNotes(ctx context.Context) (*[]models.Note, error) {
+	notes := []models.Note{
+		{Name: "recovery", Body: "code-1\ncode-2"},
+	}
+	return &notes, nil
+}
+
+func (m *MockLockBoxUsecase) UpdateNote(ctx context.Context, data *models.NoteInput) error {
+	return nil
+}
+
+func (m *MockLockBoxUsecase) DeleteNote(ctx context.Context, name string) error {
+	if name == "error" {
+		return fmt.Errorf("delete error")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) UploadFile(ctx context.Context, name, path string) error {
+	if name == "error" {
+		return fmt.Errorf("upload error")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) DownloadFile(ctx context.Context, name, path string) error {
+	if name == "notfound" {
+		return fmt.Errorf("not found")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) GetFiles(ctx context.Context) (*[]models.BinaryFile, error) {
+	files := []models.BinaryFile{
+		{Name: "id_rsa", Size: 2048, ChunkCount: 1},
+	}
+	return &files, nil
+}
+
+func (m *MockLockBoxUsecase) DeleteFile(ctx context.Context, name string) error {
+	if name == "error" {
+		return fmt.Errorf("delete error")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) GetSessions(ctx context.Context) (*[]models.Session, error) {
+	return &[]models.Session{
+		{ID: 1, DeviceName: "laptop", OS: "linux", IP: "10.0.0.1", CreatedAt: time.Now(), LastUsedAt: time.Now(), Current: true},
+		{ID: 2, DeviceName: "ci-runner", OS: "linux", IP: "10.0.0.2", CreatedAt: time.Now(), LastUsedAt: time.Now()},
+	}, nil
+}
+
+func (m *MockLockBoxUsecase) RevokeSession(ctx context.Context, id int) error {
+	if id == 404 {
+		return fmt.Errorf("not found")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) RevokeOtherSessions(ctx context.Context) (int, error) {
+	return 1, nil
+}
+
+func (m *MockLockBoxUsecase) VerifyMFA(ctx context.Context, code string) error {
+	if code != "123456" {
+		return fmt.Errorf("invalid code")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) EnrollTOTP(ctx context.Context) (*models.TOTPEnrollment, error) {
+	return &models.TOTPEnrollment{Secret: "JBSWY3DPEHPK3PXP", URI: "otpauth://totp/gophKeeper:user?secret=JBSWY3DPEHPK3PXP"}, nil
+}
+
+func (m *MockLockBoxUsecase) ConfirmTOTP(ctx context.Context, code string) ([]string, error) {
+	if code != "123456" {
+		return nil, fmt.Errorf("invalid code")
+	}
+	return []string{"aaaa-bbbb-cccc-dddd"}, nil
+}
+
+func (m *MockLockBoxUsecase) DisableTOTP(ctx context.Context, code string) error {
+	return nil
+}
+
+func (m *MockLockBoxUsecase) GetAccount(ctx context.Context) (*models.Account, error) {
+	return &models.Account{UserID: 1, Username: "user", UserType: "attendee"}, nil
+}
+
+func (m *MockLockBoxUsecase) ChangePassword(ctx context.Context, oldPassword, newPassword string, progress func(done, total int, item string)) error {
+	if oldPassword != "password" {
+		return fmt.Errorf("invalid password")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) RenameAccount(ctx context.Context, password, newUsername string) error {
+	if password != "password" {
+		return fmt.Errorf("invalid password")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) DeleteAccount(ctx context.Context, password string) error {
+	if password != "password" {
+		return fmt.Errorf("invalid password")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) GetAuditEvents(ctx context.Context, limit int) ([]models.AuditEvent, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("invalid limit")
+	}
+	return []models.AuditEvent{
+		{ID: 2, Action: "lockbox.read", Target: "42", Success: true, IP: "10.0.0.1", CreatedAt: time.Now()},
+		{ID: 1, Action: "login.failure", Target: "user", IP: "10.0.0.9", CreatedAt: time.Now()},
+	}, nil
+}
+
+func (m *MockLockBoxUsecase) GetLockBoxHistory(ctx context.Context, name string) ([]models.LockBoxRevision, error) {
+	if name == "missing" {
+		return nil, fmt.Errorf("not found")
+	}
+	return []models.LockBoxRevision{
+		{Revision: 5, LockBoxID: 1, ReplacedAt: time.Now(), LockBox: models.LockBox{Name: name, Login: "user", Password: "old-secret", UpdatedAt: time.Now()}},
+	}, nil
+}
+
+func (m *MockLockBoxUsecase) RestoreLockBox(ctx context.Context, name string, revision int) error {
+	if revision != 5 {
+		return fmt.Errorf("not found")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) ListTrash(ctx context.Context) ([]models.LockBox, error) {
+	deletedAt := time.Now()
+	return []models.LockBox{
+		{ID: 7, Name: "old-mail", Login: "user", DeletedAt: deletedAt},
+	}, nil
+}
+
+func (m *MockLockBoxUsecase) RestoreTrash(ctx context.Context, id int) error {
+	if id != 7 {
+		return fmt.Errorf("not found")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) EmptyTrash(ctx context.Context) (int, error) {
+	return 1, nil
+}
+
+func (m *MockLockBoxUsecase) PurgeTrash(retention time.Duration) error {
+	return nil
+}
+
+func (m *MockLockBoxUsecase) ListConflicts(ctx context.Context) ([]models.LockBoxConflict, error) {
+	return []models.LockBoxConflict{
+		{
+			Name:      "mail",
+			Local:     models.LockBox{Name: "mail", Login: "user", Password: "mine"},
+			Remote:    models.LockBox{Name: "mail", Login: "user", Password: "theirs", Revision: 4},
+			CreatedAt: time.Now(),
+		},
+	}, nil
+}
+
+func (m *MockLockBoxUsecase) ResolveConflict(ctx context.Context, name string, resolution models.ConflictResolution) error {
+	if name != "mail" {
+		return fmt.Errorf("not found")
+	}
+	return nil
+}
+
+func (m *MockLockBoxUsecase) RunOutboxWorker(ctx context.Context) {}
+
+func (m *MockLockBoxUsecase) RunSyncLoop(ctx context.Context) {}
+
+func (m *MockLockBoxUsecase) GetSyncStatus(ctx context.Context) (*models.SyncStatus, error) {
+	return &models.SyncStatus{
+		Pending: []models.OutboxEntry{
+			{ID: 1, Name: "mail", Op: models.OutboxUpdate, IdempotencyKey: "k1", Attempts: 2,
+				NextAttemptAt: time.Now().Add(time.Minute), LastError: "connection refused", CreatedAt: time.Now()},
+		},
+		Conflicts: 1,
+		Cursor:    42,
+	}, nil
+}
@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	errors1 "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/clients"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/pkg/crypt"
+)
+
+// Unlock выводит ключ шифрования из мастер-пароля и передаёт его клиенту API
+// и локальному хранилищу. Ключ живёт только в памяти процесса. При первой
+// разблокировке генерирует соль и сохраняет параметры KDF на сервере.
+func (uc *LockboxUsecase) Unlock(ctx context.Context, masterPassword string) error {
+	if masterPassword == "" {
+		return errors1.ErrMasterPasswordRequired
+	}
+
+	params, err := uc.lockBoxService.GetKDFParams(ctx)
+	switch {
+	case errors.Is(err, errors1.ErrNotFound):
+		params, err = crypt.NewKDFParams()
+		if err != nil {
+			return err
+		}
+		params.Check, err = crypt.NewKeyCheck(crypt.NewFromPassword(masterPassword, params))
+		if err != nil {
+			return err
+		}
+		if err := uc.lockBoxService.SetKDFParams(ctx, params); err != nil {
+			if !errors.Is(err, errors1.ErrKDFAlreadySet) {
+				return err
+			}
+			// Параметры успел сохранить другой клиент — используем их.
+			if params, err = uc.lockBoxService.GetKDFParams(ctx); err != nil {
+				return err
+			}
+		}
+	case err != nil:
+		return err
+	}
+
+	encryptor := crypt.NewFromPassword(masterPassword, params)
+	if !crypt.VerifyKeyCheck(params.Check, encryptor) {
+		return errors1.ErrWrongMasterPassword
+	}
+
+	uc.lockBoxService.SetEncryptor(encryptor)
+	uc.lockBoxRepository.SetEncryptor(encryptor)
+	uc.unlocked = true
+	return nil
+}
+
+func (uc *LockboxUsecase) IsUnlocked() bool {
+	return uc.unlocked
+}
+
+// HasVault — задан ли уже мастер-пароль: сохранены ли на сервере параметры
+// ключа. Без них первая разблокировка создаст хранилище.
+func (uc *LockboxUsecase) HasVault(ctx context.Context) (bool, error) {
+	_, err := uc.lockBoxService.GetKDFParams(ctx)
+	switch {
+	case errors.Is(err, errors1.ErrNotFound):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	return true, nil
+}
+
+// RotateKey перешифровывает всё хранилище ключом, выведенным из нового мастер-пароля.
+// Параметры нового ключа сначала записываются в локальный журнал, поэтому прерванную
+// смену можно продолжить тем же вызовом. На сервере пакет применяется атомарно:
+// до его фиксации хранилище остаётся на старом ключе, и откат не требуется.
+func (uc *LockboxUsecase) RotateKey(ctx context.Context, oldPassword, newPassword string, progress func(done, total int, item string)) error {
+	if oldPassword == "" || newPassword == "" {
+		return errors1.ErrMasterPasswordRequired
+	}
+	if progress == nil {
+		progress = func(int, int, string) {}
+	}
+
+	rotation, err := uc.lockBoxRepository.GetRotation()
+	if errors.Is(err, errors1.ErrNotFound) {
+		rotation, err = uc.prepareRotation(ctx, oldPassword, newPassword)
+	}
+	if err != nil {
+		return err
+	}
+
+	oldEncryptor := crypt.NewFromPassword(oldPassword, &rotation.OldKDF)
+	if !crypt.VerifyKeyCheck(rotation.OldKDF.Check, oldEncryptor) {
+		return errors1.ErrWrongMasterPassword
+	}
+	newEncryptor := crypt.NewFromPassword(newPassword, &rotation.NewKDF)
+	if !crypt.VerifyKeyCheck(rotation.NewKDF.Check, newEncryptor) {
+		return errors1.ErrWrongMasterPassword
+	}
+
+	if !rotation.Committed {
+		// Неотправленные локальные изменения должны попасть в пакет.
+		if err := uc.SyncUpdatesToServer(ctx); err != nil {
+			return err
+		}
+		if err := uc.commitRotation(ctx, rotation, oldEncryptor, newEncryptor, progress); err != nil {
+			return err
+		}
+	}
+
+	uc.syncMu.Lock()
+	defer uc.syncMu.Unlock()
+	if err := uc.lockBoxRepository.ReEncrypt(oldEncryptor, newEncryptor); err != nil {
+		return err
+	}
+	uc.lockBoxService.SetEncryptor(newEncryptor)
+	uc.lockBoxRepository.SetEncryptor(newEncryptor)
+	uc.unlocked = true
+	return uc.lockBoxRepository.DeleteRotation()
+}
+
+// AbortKeyRotation удаляет журнал незафиксированной смены ключа. Хранилище на
+// сервере при этом не меняется: пакет ещё не был применён.
+func (uc *LockboxUsecase) AbortKeyRotation() error {
+	rotation, err := uc.lockBoxRepository.GetRotation()
+	if err != nil {
+		return err
+	}
+	if rotation.Committed {
+		return errors1.ErrRotationConflict
+	}
+	return uc.lockBoxRepository.DeleteRotation()
+}
+
+// prepareRotation создаёт журнал только после проверки текущего мастер-пароля,
+// чтобы опечатка не закрепила параметры нового ключа.
+func (uc *LockboxUsecase) prepareRotation(ctx context.Context, oldPassword, newPassword string) (*models.KeyRotation, error) {
+	oldParams, err := uc.lockBoxService.GetKDFParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !crypt.VerifyKeyCheck(oldParams.Check, crypt.NewFromPassword(oldPassword, oldParams)) {
+		return nil, errors1.ErrWrongMasterPassword
+	}
+	newParams, err := crypt.NewKDFParams()
+	if err != nil {
+		return nil, err
+	}
+	newParams.Check, err = crypt.NewKeyCheck(crypt.NewFromPassword(newPassword, newParams))
+	if err != nil {
+		return nil, err
+	}
+
+	rotation := &models.KeyRotation{OldKDF: *oldParams, NewKDF: *newParams}
+	if err := uc.lockBoxRepository.SaveRotation(rotation); err != nil {
+		return nil, err
+	}
+	return rotation, nil
+}
+
+// commitRotation перешифровывает данные сервера и отправляет пакет. Если пакет
+// уже был применён до прерывания, повторно он не отправляется.
+func (uc *LockboxUsecase) commitRotation(ctx context.Context, rotation *models.KeyRotation, oldEncryptor, newEncryptor crypt.Encryptor, progress func(done, total int, item string)) error {
+	uc.syncMu.Lock()
+	defer uc.syncMu.Unlock()
+
+	current, err := uc.lockBoxService.GetKDFParams(ctx)
+	if err != nil {
+		return err
+	}
+	switch current.Check {
+	case rotation.NewKDF.Check:
+		rotation.Committed = true
+		return uc.lockBoxRepository.SaveRotation(rotation)
+	case rotation.OldKDF.Check:
+	default:
+		return errors1.ErrRotationForeign
+	}
+
+	batch, err := uc.buildRotationBatch(ctx, oldEncryptor, newEncryptor, progress)
+	if err != nil {
+		return err
+	}
+	batch.OldCheck = rotation.OldKDF.Check
+	batch.KDF = rotation.NewKDF
+	if err := uc.lockBoxService.RotateKey(ctx, batch); err != nil {
+		return err
+	}
+
+	rotation.Committed = true
+	return uc.lockBoxRepository.SaveRotation(rotation)
+}
+
+func (uc *LockboxUsecase) buildRotationBatch(ctx context.Context, oldEncryptor, newEncryptor crypt.Encryptor, progress func(done, total int, item string)) (*models.RotationBatch, error) {
+	oldService := uc.lockBoxService.WithEncryptor(oldEncryptor)
+	newService := uc.lockBoxService.WithEncryptor(newEncryptor)
+
+	lockBoxes, err := oldService.GetAll(ctx)
+	if errors.Is(err, errors1.ErrNotFound) {
+		lockBoxes, err = &[]models.LockBox{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cards, err := oldService.GetCards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := oldService.GetNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files, err := oldService.GetBinaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &models.RotationBatch{}
+	total := len(*lockBoxes) + len(*cards) + len(*notes) + len(*files)
+	done := 0
+
+	for i := range *lockBoxes {
+		// Имя тоже перешифровывается: при включённом шифровании имён смена ключа
+		// заодно скрывает имена записей, созданных до включения режима.
+		box, err := newService.SealLockBox(&(*lockBoxes)[i])
+		if err != nil {
+			return nil, err
+		}
+		batch.LockBoxes = append(batch.LockBoxes, *box)
+		done++
+		progress(done, total, (*lockBoxes)[i].Name)
+	}
+	for _, card := range *cards {
+		// Карты, как и записи, адресуются по id: название перешифровывается.
+		input, err := newService.SealBankCard(&models.BankCardInput{
+			Name: card.Name, Number: card.Number, Holder: card.Holder, Expiry: card.Expiry,
+			CVV: card.CVV, PIN: card.PIN, Notes: card.Notes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		batch.BankCards = append(batch.BankCards, models.BankCard{
+			ID: card.ID, Name: input.Name, NameIndex: input.NameIndex, Number: input.Number, Holder: input.Holder,
+			Expiry: input.Expiry, CVV: input.CVV, PIN: input.PIN, Notes: input.Notes,
+		})
+		done++
+		progress(done, total, card.Name)
+	}
+	for i := range *notes {
+		note, err := crypt.EncryptNote(&(*notes)[i], newEncryptor)
+		if err != nil {
+			return nil, err
+		}
+		batch.Notes = append(batch.Notes, *note)
+		done++
+		progress(done, total, note.Name)
+	}
+	for _, file := range *files {
+		uploadID, err := reuploadFile(ctx, oldService, newService, file)
+		if err != nil {
+			return nil, err
+		}
+		batch.Binaries = append(batch.Binaries, models.RotatedBinary{Name: file.Name, UploadID: uploadID})
+		done++
+		progress(done, total, file.Name)
+	}
+	return batch, nil
+}
+
+// reuploadFile перекладывает содержимое файла в новую незавершённую загрузку,
+// перешифровывая его по чанкам. Уже загруженные чанки при повторе пропускаются;
+// завершает загрузку сервер в пакете смены ключа.
+func reuploadFile(ctx context.Context, oldService, newService clients.LockBoxService, file models.BinaryFile) (int, error) {
+	session, err := newService.StartUpload(ctx, &models.BinaryFile{
+		Name:       file.Name,
+		Size:       file.Size,
+		ChunkSize:  file.ChunkSize,
+		ChunkCount: file.ChunkCount,
+	})
+	if err != nil {
+		return 0, err
+	}
+	received := make(map[int]bool, len(session.Received))
+	for _, index := range session.Received {
+		received[index] = true
+	}
+	for index := 0; index < file.ChunkCount; index++ {
+		if received[index] {
+			continue
+		}
+		chunk, err := oldService.DownloadChunk(ctx, file.Name, index)
+		if err != nil {
+			return 0, err
+		}
+		if err := newService.UploadChunk(ctx, session.ID, index, chunk); err != nil {
+			return 0, err
+		}
+	}
+	return session.ID, nil
+}
@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	errors1 "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/clients"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/internal/client/services/lockbox/repository"
+	"gophKeeper/pkg/crypt"
+	"testing"
+)
+
+// fakeVaultServer хранит зашифрованное хранилище так, как его видит сервер.
+type fakeVaultServer struct {
+	params  models.KDFParams
+	boxes   []models.LockBox
+	notes   []models.Note
+	file    models.BinaryFile
+	chunks  map[int][]byte
+	uploads map[int][]byte
+	batches int
+}
+
+type fakeVaultService struct {
+	clients.LockBoxService
+	server    *fakeVaultServer
+	encryptor crypt.Encryptor
+}
+
+func (f *fakeVaultService) GetKDFParams(ctx context.Context) (*models.KDFParams, error) {
+	params := f.server.params
+	return &params, nil
+}
+
+func (f *fakeVaultService) SetEncryptor(encryptor crypt.Encryptor) {
+	f.encryptor = encryptor
+}
+
+func (f *fakeVaultService) WithEncryptor(encryptor crypt.Encryptor) clients.LockBoxService {
+	return &fakeVaultService{server: f.server, encryptor: encryptor}
+}
+
+func (f *fakeVaultService) GetAll(ctx context.Context) (*[]models.LockBox, error) {
+	var boxes []models.LockBox
+	for i := range f.server.boxes {
+		box, err := crypt.DecryptLockBox(&f.server.boxes[i], f.encryptor)
+		if err != nil {
+			return nil, err
+		}
+		boxes = append(boxes, *box)
+	}
+	return &boxes, nil
+}
+
+func (f *fakeVaultService) SealLockBox(data *models.LockBox) (*models.LockBox, error) {
+	return crypt.EncryptLockBox(data, f.encryptor)
+}
+
+func (f *fakeVaultService) GetCards(ctx context.Context) (*[]models.BankCard, error) {
+	return &[]models.BankCard{}, nil
+}
+
+func (f *fakeVaultService) GetNotes(ctx context.Context) (*[]models.Note, error) {
+	var notes []models.Note
+	for i := range f.server.notes {
+		note, err := crypt.DecryptNote(&f.server.notes[i], f.encryptor)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, *note)
+	}
+	return &notes, nil
+}
+
+func (f *fakeVaultService) GetBinaries(ctx context.Context) (*[]models.BinaryFile, error) {
+	return &[]models.BinaryFile{f.server.file}, nil
+}
+
+func (f *fakeVaultService) StartUpload(ctx context.Context, file *models.BinaryFile) (*models.UploadSession, error) {
+	return &models.UploadSession{BinaryFile: models.BinaryFile{ID: 2}}, nil
+}
+
+func (f *fakeVaultService) UploadChunk(ctx context.Context, uploadID, index int, chunk []byte) error {
+	encrypted, err := crypt.EncryptChunk(chunk, f.encryptor)
+	if err != nil {
+		return err
+	}
+	f.server.uploads[index] = encrypted
+	return nil
+}
+
+func (f *fakeVaultService) DownloadChunk(ctx context.Context, name string, index int) ([]byte, error) {
+	return crypt.DecryptChunk(f.server.chunks[index], f.encryptor)
+}
+
+func (f *fakeVaultService) RotateKey(ctx context.Context, batch *models.RotationBatch) error {
+	if batch.OldCheck != f.server.params.Check {
+		return errors1.ErrRotationConflict
+	}
+	f.server.params = batch.KDF
+	f.server.boxes = batch.LockBoxes
+	f.server.notes = batch.Notes
+	f.server.chunks = f.server.uploads
+	f.server.batches++
+	return nil
+}
+
+type fakeVaultRepository struct {
+	repository.Repository
+	rotation  *models.KeyRotation
+	encryptor crypt.Encryptor
+	reencrypt int
+}
+
+func (f *fakeVaultRepository) GetLockBoxes() (*[]models.LockBox, error) {
+	return &[]models.LockBox{}, nil
+}
+
+func (f *fakeVaultRepository) GetPendingLockBoxes() ([]models.LockBoxChange, error) {
+	return nil, nil
+}
+
+func (f *fakeVaultRepository) GetNotes() (*[]models.Note, error) {
+	return &[]models.Note{}, nil
+}
+
+func (f *fakeVaultRepository) SetEncryptor(encryptor crypt.Encryptor) {
+	f.encryptor = encryptor
+}
+
+func (f *fakeVaultRepository) SaveRotation(rotation *models.KeyRotation) error {
+	saved := *rotation
+	f.rotation = &saved
+	return nil
+}
+
+func (f *fakeVaultRepository) GetRotation() (*models.KeyRotation, error) {
+	if f.rotation == nil {
+		return nil, errors1.ErrNotFound
+	}
+	saved := *f.rotation
+	return &saved, nil
+}
+
+func (f *fakeVaultRepository) DeleteRotation() error {
+	f.rotation = nil
+	return nil
+}
+
+func (f *fakeVaultRepository) ReEncrypt(oldEncryptor, newEncryptor crypt.Encryptor) error {
+	f.reencrypt++
+	return nil
+}
+
+func newFakeVault(t *testing.T, password string) *fakeVaultServer {
+	t.Helper()
+	params, err := crypt.NewKDFParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptor := crypt.NewFromPassword(password, params)
+	params.Check, err = crypt.NewKeyCheck(encryptor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	box, err := crypt.EncryptLockBox(&models.LockBox{Name: "mail", Login: "me", Password: "secret"}, encryptor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	note, err := crypt.EncryptNote(&models.Note{Name: "todo", Body: "buy milk"}, encryptor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk, err := crypt.EncryptChunk([]byte("file content"), encryptor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fakeVaultServer{
+		params:  *params,
+		boxes:   []models.LockBox{*box},
+		notes:   []models.Note{*note},
+		file:    models.BinaryFile{ID: 1, Name: "doc", Size: 12, ChunkSize: fileChunkSize, ChunkCount: 1},
+		chunks:  map[int][]byte{0: chunk},
+		uploads: map[int][]byte{},
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeVault(t, "old password")
+	service := &fakeVaultService{server: server}
+	repo := &fakeVaultRepository{}
+	uc := &LockboxUsecase{lockBoxService: service, lockBoxRepository: repo}
+
+	if err := uc.RotateKey(ctx, "wrong password", "new password", nil); !errors.Is(err, errors1.ErrWrongMasterPassword) {
+		t.Fatalf("ожидалась ErrWrongMasterPassword, получено: %v", err)
+	}
+	if server.batches != 0 || repo.rotation != nil {
+		t.Fatal("смена ключа начата с неверным паролем")
+	}
+
+	var reported int
+	err := uc.RotateKey(ctx, "old password", "new password", func(done, total int, item string) {
+		reported = done
+		if total != 3 {
+			t.Errorf("total = %d, want 3", total)
+		}
+	})
+	if err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	if reported != 3 || server.batches != 1 || repo.reencrypt != 1 || repo.rotation != nil {
+		t.Fatalf("progress=%d batches=%d reencrypt=%d journal=%v", reported, server.batches, repo.reencrypt, repo.rotation)
+	}
+
+	// Хранилище читается новым ключом и только им.
+	newKey := crypt.NewFromPassword("new password", &server.params)
+	box, err := crypt.DecryptLockBox(&server.boxes[0], newKey)
+	if err != nil || box.Password != "secret" {
+		t.Fatalf("lockbox после смены ключа: %+v, %v", box, err)
+	}
+	note, err := crypt.DecryptNote(&server.notes[0], newKey)
+	if err != nil || note.Body != "buy milk" {
+		t.Fatalf("заметка после смены ключа: %+v, %v", note, err)
+	}
+	chunk, err := crypt.DecryptChunk(server.chunks[0], newKey)
+	if err != nil || string(chunk) != "file content" {
+		t.Fatalf("файл после смены ключа: %q, %v", chunk, err)
+	}
+	if repo.encryptor == nil || service.encryptor == nil || !uc.IsUnlocked() {
+		t.Fatal("новый ключ не передан клиенту и хранилищу")
+	}
+}
+
+func TestRotateKeyResumeAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeVault(t, "old password")
+	oldParams := server.params
+
+	// Пакет был применён, но клиент прервался до отметки в журнале.
+	newParams, err := crypt.NewKDFParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newParams.Check, err = crypt.NewKeyCheck(crypt.NewFromPassword("new password", newParams))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.params = *newParams
+
+	repo := &fakeVaultRepository{rotation: &models.KeyRotation{OldKDF: oldParams, NewKDF: *newParams}}
+	uc := &LockboxUsecase{lockBoxService: &fakeVaultService{server: server}, lockBoxRepository: repo}
+
+	if err := uc.RotateKey(ctx, "old password", "new password", nil); err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	if server.batches != 0 {
+		t.Fatal("пакет отправлен повторно")
+	}
+	if repo.reencrypt != 1 || repo.rotation != nil {
+		t.Fatalf("локальный кэш не перешифрован: reencrypt=%d journal=%v", repo.reencrypt, repo.rotation)
+	}
+}
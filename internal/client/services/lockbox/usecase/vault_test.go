@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	errors1 "gophKeeper/internal/client/errors"
+	"gophKeeper/internal/client/services/lockbox/clients"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"gophKeeper/internal/client/services/lockbox/repository"
+	"gophKeeper/pkg/crypt"
+	"testing"
+)
+
+// fakeKDFService хранит параметры KDF в памяти, как это делает сервер.
+type fakeKDFService struct {
+	clients.LockBoxService
+	params    *models.KDFParams
+	encryptor crypt.Encryptor
+}
+
+func (f *fakeKDFService) GetKDFParams(ctx context.Context) (*models.KDFParams, error) {
+	if f.params == nil {
+		return nil, errors1.ErrNotFound
+	}
+	return f.params, nil
+}
+
+func (f *fakeKDFService) SetKDFParams(ctx context.Context, params *models.KDFParams) error {
+	if f.params != nil {
+		return errors1.ErrKDFAlreadySet
+	}
+	f.params = params
+	return nil
+}
+
+func (f *fakeKDFService) SetEncryptor(encryptor crypt.Encryptor) {
+	f.encryptor = encryptor
+}
+
+type fakeKDFRepository struct {
+	repository.Repository
+	encryptor crypt.Encryptor
+}
+
+func (f *fakeKDFRepository) SetEncryptor(encryptor crypt.Encryptor) {
+	f.encryptor = encryptor
+}
+
+func TestUnlock(t *testing.T) {
+	service := &fakeKDFService{}
+	repo := &fakeKDFRepository{}
+	uc := &LockboxUsecase{lockBoxService: service, lockBoxRepository: repo}
+	ctx := context.Background()
+
+	if hasVault, err := uc.HasVault(ctx); err != nil || hasVault {
+		t.Fatalf("до первой разблокировки хранилища нет: %v, %v", hasVault, err)
+	}
+	if err := uc.Unlock(ctx, "master password"); err != nil {
+		t.Fatalf("первая разблокировка: %v", err)
+	}
+	if hasVault, err := uc.HasVault(ctx); err != nil || !hasVault {
+		t.Fatalf("после первой разблокировки хранилище есть: %v, %v", hasVault, err)
+	}
+	if service.params == nil || len(service.params.Salt) == 0 {
+		t.Fatal("параметры KDF не сохранены на сервере")
+	}
+	if !uc.IsUnlocked() || service.encryptor == nil || repo.encryptor == nil {
+		t.Fatal("шифратор не передан клиенту и хранилищу")
+	}
+
+	encrypted, err := service.encryptor.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Повторная разблокировка (например, с другого устройства) выводит тот же ключ.
+	other := &LockboxUsecase{lockBoxService: &fakeKDFService{params: service.params}, lockBoxRepository: &fakeKDFRepository{}}
+	if err := other.Unlock(ctx, "master password"); err != nil {
+		t.Fatalf("повторная разблокировка: %v", err)
+	}
+	decrypted, err := other.lockBoxService.(*fakeKDFService).encryptor.Decrypt(encrypted)
+	if err != nil || decrypted != "secret" {
+		t.Fatalf("данные не расшифровываются ключом, выведенным повторно: %q, %v", decrypted, err)
+	}
+
+	wrong := &LockboxUsecase{lockBoxService: &fakeKDFService{params: service.params}, lockBoxRepository: &fakeKDFRepository{}}
+	if err := wrong.Unlock(ctx, "wrong password"); !errors.Is(err, errors1.ErrWrongMasterPassword) {
+		t.Fatalf("ожидалась ErrWrongMasterPassword, получено: %v", err)
+	}
+	if wrong.IsUnlocked() {
+		t.Fatal("хранилище разблокировано неверным паролем")
+	}
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -20,10 +22,63 @@ type PgConf struct {
 type AppConf struct {
 	Host                string
 	Port                string
+	GRPCPort            string
 	Mode                string
 	SignaturePrivateKey string
 	SignaturePublicKey  string
 	LogLevel            string
+	BlobDir             string
+	TLS                 TLSConf
+	Login               LoginConf
+	History             HistoryConf
+	Trash               TrashConf
+}
+
+// TLSConf — настройки TLS для HTTP и gRPC. Без сертификата сервер работает
+// в открытом виде. ClientCA включает взаимный TLS, SelfSigned создаёт пару
+// в CertFile/KeyFile при первом запуске (только для разработки).
+type TLSConf struct {
+	CertFile   string
+	KeyFile    string
+	ClientCA   string
+	SelfSigned bool
+}
+
+func (c TLSConf) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+// LoginConf — защита входа от перебора паролей. Store выбирает хранилище
+// счётчиков: memory для одного экземпляра сервера, postgres для нескольких.
+// Нулевые значения заменяются значениями по умолчанию.
+//
+// LegacyPassword включает прежний вход с передачей пароля серверу
+// (/api/auth/login, gRPC Login) на время перехода аккаунтов на SRP. Вход
+// работает только для аккаунтов без верификатора: первый же вход сохраняет
+// верификатор и стирает password_hash. По умолчанию выключен; аккаунтам без
+// верификатора тогда нужен новый пароль от администратора.
+type LoginConf struct {
+	Store            string
+	FreeAttempts     int
+	MaxDelay         time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	LegacyPassword   bool
+}
+
+// HistoryConf — хранение прежних версий записей lockbox. Версия удаляется,
+// если у записи больше MaxRevisions версий или она старше MaxAge. Нулевое
+// значение снимает соответствующее ограничение.
+type HistoryConf struct {
+	MaxRevisions int
+	MaxAge       time.Duration
+}
+
+// TrashConf — корзина удалённых записей lockbox. Запись окончательно
+// удаляется через Retention после удаления; нулевое значение оставляет
+// записи в корзине до явной очистки.
+type TrashConf struct {
+	Retention time.Duration
 }
 
 type Config struct {
@@ -38,6 +93,20 @@ func getEnv(key, def string) string {
 	return def
 }
 
+func getInt(key string, def int) int {
+	if n, err := strconv.Atoi(getEnv(key, "")); err == nil {
+		return n
+	}
+	return def
+}
+
+func getDuration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(getEnv(key, "")); err == nil {
+		return d
+	}
+	return def
+}
+
 func New() *Config {
 	_ = godotenv.Load(".env.server")
 	return &Config{
@@ -53,8 +122,35 @@ func New() *Config {
 		App: AppConf{
 			Host:     getEnv("HTTP_HOST", "0.0.0.0"),
 			Port:     getEnv("HTTP_PORT", "8080"),
+			GRPCPort: getEnv("GRPC_PORT", "9090"),
 			Mode:     getEnv("APP_MODE", "debug"),
-			LogLevel: getEnv("LOG_LEVEL", "info"),
+			// Пути к PEM-файлам через запятую: первый закрытый ключ подписывает
+			// токены, остальные ключи только принимаются (ротация).
+			SignaturePrivateKey: getEnv("SIGNATURE_PRIVATE_KEY", ""),
+			SignaturePublicKey:  getEnv("SIGNATURE_PUBLIC_KEY", ""),
+			LogLevel:            getEnv("LOG_LEVEL", "info"),
+			BlobDir:             getEnv("BLOB_DIR", "data/blobs"),
+			TLS: TLSConf{
+				CertFile:   getEnv("TLS_CERT_FILE", ""),
+				KeyFile:    getEnv("TLS_KEY_FILE", ""),
+				ClientCA:   getEnv("TLS_CLIENT_CA_FILE", ""),
+				SelfSigned: getEnv("TLS_SELF_SIGNED", "false") == "true",
+			},
+			Login: LoginConf{
+				Store:            getEnv("LOGIN_ATTEMPTS_STORE", "memory"),
+				FreeAttempts:     getInt("LOGIN_FREE_ATTEMPTS", 0),
+				MaxDelay:         getDuration("LOGIN_MAX_DELAY", 0),
+				LockoutThreshold: getInt("LOGIN_LOCKOUT_THRESHOLD", 0),
+				LockoutDuration:  getDuration("LOGIN_LOCKOUT_DURATION", 0),
+				LegacyPassword:   getEnv("LOGIN_LEGACY_PASSWORD", "false") == "true",
+			},
+			History: HistoryConf{
+				MaxRevisions: getInt("LOCKBOX_HISTORY_MAX_REVISIONS", 20),
+				MaxAge:       getDuration("LOCKBOX_HISTORY_MAX_AGE", 90*24*time.Hour),
+			},
+			Trash: TrashConf{
+				Retention: getDuration("LOCKBOX_TRASH_RETENTION", 30*24*time.Hour),
+			},
 		},
 	}
 }
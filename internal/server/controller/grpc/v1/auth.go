@@ -0,0 +1,174 @@
+package v1
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/auth/attempts"
+	"gophKeeper/internal/server/services/auth/models"
+	"gophKeeper/internal/server/services/auth/usecase"
+	pb "gophKeeper/pkg/pb/gophkeeper/v1"
+	"net"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type AuthServer struct {
+	pb.UnimplementedAuthServiceServer
+	service usecase.IAuthUsecase
+	mware   middleware.IMiddlewareService
+}
+
+// sessionMeta берёт данные устройства из метаданных и адреса клиента.
+func sessionMeta(ctx context.Context) models.SessionMeta {
+	var deviceName, os, userAgent string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		first := func(key string) string {
+			if values := md.Get(key); len(values) > 0 {
+				return values[0]
+			}
+			return ""
+		}
+		deviceName, os, userAgent = first("x-device-name"), first("x-device-os"), first("user-agent")
+	}
+	return models.NewSessionMeta(deviceName, os, userAgent, clientIP(ctx))
+}
+
+func clientIP(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+	}
+	return ""
+}
+
+// clientContext передаёт адрес клиента в usecase: неудачные входы считаются
+// и по имени, и по адресу, а в журнал аудита адрес пишется рядом с событием.
+func clientContext(ctx context.Context) context.Context {
+	return attempts.WithClientIP(ctx, clientIP(ctx))
+}
+
+// tokens открывает сессию и возвращает access- и refresh-токены.
+func (s *AuthServer) tokens(ctx context.Context, userInfo *models.InfoUser) (string, string, error) {
+	sessionId, refreshToken, err := s.service.CreateSession(clientContext(ctx), userInfo, sessionMeta(ctx))
+	if err != nil {
+		return "", "", status.Error(codes.Internal, domain.ErrTokenCreation.Error())
+	}
+	token, err := s.mware.CreateToken(userInfo.UserId, userInfo.Username, userInfo.UserType, sessionId)
+	if err != nil {
+		return "", "", status.Error(codes.Internal, domain.ErrTokenCreation.Error())
+	}
+	return token, refreshToken, nil
+}
+
+// login завершает вход после проверки пароля: при включённой 2FA вместо
+// токенов возвращает mfa_token для VerifyMFA.
+func (s *AuthServer) login(ctx context.Context, userInfo *models.InfoUser) (*pb.LoginResponse, error) {
+	challenge, err := s.service.StartMFA(ctx, userInfo)
+	if err != nil {
+		return nil, internalStatus(err)
+	}
+	if challenge != nil {
+		return &pb.LoginResponse{MfaRequired: challenge.Required, MfaToken: challenge.Token}, nil
+	}
+	token, refreshToken, err := s.tokens(ctx, userInfo)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LoginResponse{Token: token, RefreshToken: refreshToken}, nil
+}
+
+// Login — прежний вход по паролю, как /api/auth/login: без
+// LOGIN_LEGACY_PASSWORD отвечает PermissionDenied.
+func (s *AuthServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	userInfo, err := s.service.CheckUser(clientContext(ctx), &models.AuthUser{Username: req.Username, Password: req.Password})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return s.login(ctx, userInfo)
+}
+
+func (s *AuthServer) SRPInit(ctx context.Context, req *pb.SRPInitRequest) (*pb.SRPInitResponse, error) {
+	challenge, err := s.service.StartSRP(clientContext(ctx), &models.SRPInit{Username: req.Username, A: hex.EncodeToString(req.A)})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	salt, _ := hex.DecodeString(challenge.Salt)
+	b, _ := hex.DecodeString(challenge.B)
+	return &pb.SRPInitResponse{Session: challenge.Session, Salt: salt, B: b}, nil
+}
+
+func (s *AuthServer) SRPVerify(ctx context.Context, req *pb.SRPVerifyRequest) (*pb.SRPVerifyResponse, error) {
+	userInfo, m2, err := s.service.FinishSRP(clientContext(ctx), &models.SRPVerify{Session: req.Session, M1: hex.EncodeToString(req.M1)})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp, err := s.login(ctx, userInfo)
+	if err != nil {
+		return nil, err
+	}
+	proof, _ := hex.DecodeString(m2)
+	return &pb.SRPVerifyResponse{
+		Token:        resp.Token,
+		M2:           proof,
+		RefreshToken: resp.RefreshToken,
+		MfaRequired:  resp.MfaRequired,
+		MfaToken:     resp.MfaToken,
+	}, nil
+}
+
+func (s *AuthServer) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest) (*pb.LoginResponse, error) {
+	userInfo, err := s.service.FinishMFA(clientContext(ctx), &models.MFAVerify{Token: req.MfaToken, Code: req.Code})
+	if err != nil {
+		if errors.Is(err, domain.ErrTooManyAttempts) || errors.Is(err, domain.ErrAccountLocked) {
+			return nil, toStatus(err)
+		}
+		if errors.Is(err, domain.ErrMFAExpired) || errors.Is(err, domain.ErrInvalidMFACode) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, internalStatus(err)
+	}
+	token, refreshToken, err := s.tokens(ctx, userInfo)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LoginResponse{Token: token, RefreshToken: refreshToken}, nil
+}
+
+func (s *AuthServer) SetSRPVerifier(ctx context.Context, req *pb.SRPVerifier) (*emptypb.Empty, error) {
+	verifier := &models.SRPVerifier{Salt: hex.EncodeToString(req.Salt), Verifier: hex.EncodeToString(req.Verifier)}
+	if err := s.service.SetSRPVerifier(ctx, middleware.UserIdFromContext(ctx), verifier); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *AuthServer) Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.LoginResponse, error) {
+	userInfo, sessionId, refreshToken, err := s.service.RefreshSession(clientContext(ctx), req.RefreshToken, sessionMeta(ctx))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefresh) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, internalStatus(err)
+	}
+	token, err := s.mware.CreateToken(userInfo.UserId, userInfo.Username, userInfo.UserType, sessionId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, domain.ErrTokenCreation.Error())
+	}
+	return &pb.LoginResponse{Token: token, RefreshToken: refreshToken}, nil
+}
+
+func (s *AuthServer) Logout(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	err := s.service.RevokeSession(clientContext(ctx), middleware.UserIdFromContext(ctx), middleware.SessionIdFromContext(ctx))
+	if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return nil, internalStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	authusecase "gophKeeper/internal/server/services/auth/usecase"
+	lockboxusecase "gophKeeper/internal/server/services/lockbox/usecase"
+	usersusecase "gophKeeper/internal/server/services/users/usecase"
+	pb "gophKeeper/pkg/pb/gophkeeper/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestAuthClient(t *testing.T, auth authusecase.IAuthUsecase) pb.AuthServiceClient {
+	t.Helper()
+	mware := middleware.NewMock().(*middleware.MockMiddlewareService)
+	mware.On("UnaryAuth", mock.Anything).Return(grpc.UnaryServerInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(ctx, req)
+		}))
+	mware.On("StreamAuth", mock.Anything).Return(grpc.StreamServerInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, ss)
+		}))
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(mware, auth, usersusecase.NewUserUsecaseMock(), lockboxusecase.NewLockBoxUsecaseMock())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewAuthServiceClient(conn)
+}
+
+// TestLoginErrorsGRPC проверяет, что ошибки входа переводятся в коды так же,
+// как REST переводит их в статусы.
+func TestLoginErrorsGRPC(t *testing.T) {
+	auth := authusecase.NewAuthUsecaseMock().(*authusecase.AuthUsecaseMock)
+	client := newTestAuthClient(t, auth)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{domain.ErrInvalidCredentials, codes.Unauthenticated},
+		{domain.ErrSRPSessionExpired, codes.Unauthenticated},
+		{domain.ErrAccountDisabled, codes.PermissionDenied},
+	} {
+		auth.On("FinishSRP", mock.Anything, mock.Anything).Return(nil, "", tc.err).Once()
+		_, err := client.SRPVerify(ctx, &pb.SRPVerifyRequest{Session: "s", M1: []byte{1}})
+		assert.Equal(t, tc.code, status.Code(err), tc.err.Error())
+
+		auth.On("CheckUser", mock.Anything, mock.Anything).Return(nil, tc.err).Once()
+		_, err = client.Login(ctx, &pb.LoginRequest{Username: "alice", Password: "x"})
+		assert.Equal(t, tc.code, status.Code(err), tc.err.Error())
+	}
+	auth.AssertExpectations(t)
+}
+
+// TestInternalErrorsGRPC проверяет, что текст внутренних ошибок остаётся в
+// логе сервера и не уходит клиенту.
+func TestInternalErrorsGRPC(t *testing.T) {
+	auth := authusecase.NewAuthUsecaseMock().(*authusecase.AuthUsecaseMock)
+	client := newTestAuthClient(t, auth)
+	ctx := context.Background()
+	dbErr := errors.New(`relation "refresh_sessions" does not exist`)
+
+	auth.On("FinishSRP", mock.Anything, mock.Anything).Return(nil, "", dbErr).Once()
+	_, err := client.SRPVerify(ctx, &pb.SRPVerifyRequest{Session: "s", M1: []byte{1}})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, domain.ErrInternal.Error(), status.Convert(err).Message())
+
+	auth.On("RefreshSession", mock.Anything, "r", mock.Anything).Return(nil, 0, "", dbErr).Once()
+	_, err = client.Refresh(ctx, &pb.RefreshRequest{RefreshToken: "r"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, domain.ErrInternal.Error(), status.Convert(err).Message())
+	auth.AssertExpectations(t)
+}
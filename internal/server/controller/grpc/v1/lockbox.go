@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/lockbox/models"
+	"gophKeeper/internal/server/services/lockbox/usecase"
+	pb "gophKeeper/pkg/pb/gophkeeper/v1"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type LockBoxServer struct {
+	pb.UnimplementedLockBoxServiceServer
+	lockBoxService usecase.ILockBoxUsecase
+}
+
+func toData(lockBox *pb.LockBox, userId int) *models.Data {
+	return &models.Data{
+		Id:          int(lockBox.Id),
+		Name:        lockBox.Name,
+		NameIndex:   lockBox.NameIndex,
+		Url:         lockBox.Url,
+		Login:       lockBox.Login,
+		Password:    lockBox.Password,
+		Description: lockBox.Description,
+		UserID:      userId,
+		Revision:    int(lockBox.Revision),
+	}
+}
+
+func toProto(data *models.Data) *pb.LockBox {
+	lockBox := &pb.LockBox{
+		Id:          int64(data.Id),
+		Name:        data.Name,
+		NameIndex:   data.NameIndex,
+		Url:         data.Url,
+		Login:       data.Login,
+		Password:    data.Password,
+		Description: data.Description,
+		CreatedAt:   timestamppb.New(data.CreatedAt),
+		UpdatedAt:   timestamppb.New(data.UpdatedAt),
+		Revision:    int64(data.Revision),
+	}
+	if data.DeletedAt != nil {
+		lockBox.DeletedAt = timestamppb.New(*data.DeletedAt)
+	}
+	return lockBox
+}
+
+// find находит запись по любому из ключей: имени, слепому индексу или id.
+func (s *LockBoxServer) find(ctx context.Context, key *pb.LockBoxKey, userId int) (*models.Data, error) {
+	switch k := key.GetKey().(type) {
+	case *pb.LockBoxKey_Name:
+		return s.lockBoxService.GetLockByName(clientContext(ctx), k.Name, userId)
+	case *pb.LockBoxKey_NameIndex:
+		return s.lockBoxService.GetLockByIndex(clientContext(ctx), k.NameIndex, userId)
+	case *pb.LockBoxKey_Id:
+		return s.lockBoxService.GetLockByID(clientContext(ctx), int(k.Id), userId)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "lockbox key is required")
+	}
+}
+
+// writeStatus переводит ошибку изменения записи в статус. К конфликту версий
+// в детали добавляется текущая версия записи, как в ответе 409 REST API.
+func (s *LockBoxServer) writeStatus(ctx context.Context, data *models.Data, err error) error {
+	if !errors.Is(err, domain.ErrLockBoxConflict) {
+		return toStatus(err)
+	}
+	st := status.New(codes.Aborted, domain.ErrLockBoxConflict.Error())
+	var current *models.Data
+	switch {
+	case data.Id != 0:
+		current, err = s.lockBoxService.GetLockByID(clientContext(ctx), data.Id, data.UserID)
+	case data.NameIndex != "":
+		current, err = s.lockBoxService.GetLockByIndex(clientContext(ctx), data.NameIndex, data.UserID)
+	default:
+		current, err = s.lockBoxService.GetLockByName(clientContext(ctx), data.Name, data.UserID)
+	}
+	if err == nil {
+		if detailed, err := st.WithDetails(toProto(current)); err == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+func (s *LockBoxServer) Create(ctx context.Context, req *pb.LockBox) (*pb.LockBoxID, error) {
+	id, err := s.lockBoxService.CreateLock(clientContext(ctx), toData(req, middleware.UserIdFromContext(ctx)))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.LockBoxID{Id: int64(id)}, nil
+}
+
+func (s *LockBoxServer) Get(ctx context.Context, req *pb.LockBoxKey) (*pb.LockBox, error) {
+	data, err := s.find(ctx, req, middleware.UserIdFromContext(ctx))
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, toStatus(err)
+	}
+	return toProto(data), nil
+}
+
+func (s *LockBoxServer) List(ctx context.Context, _ *emptypb.Empty) (*pb.LockBoxList, error) {
+	locks, err := s.lockBoxService.GetAllLocks(clientContext(ctx), middleware.UserIdFromContext(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	list := &pb.LockBoxList{LockBoxes: make([]*pb.LockBox, 0, len(*locks))}
+	for i := range *locks {
+		list.LockBoxes = append(list.LockBoxes, toProto(&(*locks)[i]))
+	}
+	return list, nil
+}
+
+func (s *LockBoxServer) Update(ctx context.Context, req *pb.LockBox) (*emptypb.Empty, error) {
+	data := toData(req, middleware.UserIdFromContext(ctx))
+	var err error
+	if data.Id != 0 {
+		err = s.lockBoxService.UpdateLockByID(clientContext(ctx), data)
+	} else {
+		err = s.lockBoxService.UpdateLock(clientContext(ctx), data)
+	}
+	if err != nil {
+		return nil, s.writeStatus(ctx, data, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *LockBoxServer) Delete(ctx context.Context, req *pb.LockBoxKey) (*emptypb.Empty, error) {
+	userId := middleware.UserIdFromContext(ctx)
+	var err error
+	switch k := req.GetKey().(type) {
+	case *pb.LockBoxKey_Name:
+		err = s.lockBoxService.DeleteLock(clientContext(ctx), k.Name, userId)
+	case *pb.LockBoxKey_Id:
+		err = s.lockBoxService.DeleteLockByID(clientContext(ctx), int(k.Id), userId)
+	default:
+		var data *models.Data
+		data, err = s.find(ctx, req, userId)
+		if err == nil {
+			err = s.lockBoxService.DeleteLockByID(clientContext(ctx), data.Id, userId)
+		}
+	}
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *LockBoxServer) CreateOrUpdate(ctx context.Context, req *pb.LockBox) (*pb.LockBoxID, error) {
+	data := toData(req, middleware.UserIdFromContext(ctx))
+	id, err := s.lockBoxService.CreateOrUpdateLock(clientContext(ctx), data)
+	if err != nil {
+		return nil, s.writeStatus(ctx, data, err)
+	}
+	return &pb.LockBoxID{Id: int64(id)}, nil
+}
+
+// Sync сохраняет записи клиента по мере поступления, подтверждая каждую,
+// и после закрытия отправки передаёт клиенту все записи пользователя.
+func (s *LockBoxServer) Sync(stream grpc.BidiStreamingServer[pb.SyncRequest, pb.SyncResponse]) error {
+	ctx := stream.Context()
+	userId := middleware.UserIdFromContext(ctx)
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		data := toData(req.GetLockBox(), userId)
+		id, err := s.lockBoxService.CreateOrUpdateLock(clientContext(ctx), data)
+		if err != nil {
+			return s.writeStatus(ctx, data, err)
+		}
+		ack := &pb.SyncResponse{Event: &pb.SyncResponse_Ack{Ack: &pb.LockBoxID{Id: int64(id)}}}
+		if err := stream.Send(ack); err != nil {
+			return err
+		}
+	}
+
+	locks, err := s.lockBoxService.GetAllLocks(clientContext(ctx), userId)
+	if err != nil {
+		return toStatus(err)
+	}
+	for i := range *locks {
+		event := &pb.SyncResponse{Event: &pb.SyncResponse_LockBox{LockBox: toProto(&(*locks)[i])}}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	authusecase "gophKeeper/internal/server/services/auth/usecase"
+	"gophKeeper/internal/server/services/lockbox/models"
+	lockboxusecase "gophKeeper/internal/server/services/lockbox/usecase"
+	usersusecase "gophKeeper/internal/server/services/users/usecase"
+	pb "gophKeeper/pkg/pb/gophkeeper/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient поднимает сервер в памяти; интерцепторы пропускают все
+// запросы от имени пользователя 1.
+func newTestClient(t *testing.T, lockBoxes lockboxusecase.ILockBoxUsecase) pb.LockBoxServiceClient {
+	t.Helper()
+	mware := middleware.NewMock().(*middleware.MockMiddlewareService)
+	mware.On("UnaryAuth", mock.Anything).Return(grpc.UnaryServerInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(middleware.ContextWithUserId(ctx, 1), req)
+		}))
+	mware.On("StreamAuth", mock.Anything).Return(grpc.StreamServerInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &userStream{ServerStream: ss})
+		}))
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(mware, authusecase.NewAuthUsecaseMock(), usersusecase.NewUserUsecaseMock(), lockBoxes)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewLockBoxServiceClient(conn)
+}
+
+type userStream struct {
+	grpc.ServerStream
+}
+
+func (s *userStream) Context() context.Context {
+	return middleware.ContextWithUserId(s.ServerStream.Context(), 1)
+}
+
+func TestGetLockBoxGRPC(t *testing.T) {
+	mockService := lockboxusecase.NewLockBoxUsecaseMock()
+	client := newTestClient(t, mockService)
+
+	mockService.On("GetLockByIndex", mock.Anything, "abc", 1).Return(&models.Data{Id: 7, Name: "enc", NameIndex: "abc"}, nil)
+	mockService.On("GetLockByName", mock.Anything, "missing", 1).Return(nil, domain.ErrLockBoxNotFound)
+
+	lockBox, err := client.Get(context.Background(), &pb.LockBoxKey{Key: &pb.LockBoxKey_NameIndex{NameIndex: "abc"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), lockBox.Id)
+
+	_, err = client.Get(context.Background(), &pb.LockBoxKey{Key: &pb.LockBoxKey_Name{Name: "missing"}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.Get(context.Background(), &pb.LockBoxKey{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSyncGRPC(t *testing.T) {
+	mockService := lockboxusecase.NewLockBoxUsecaseMock()
+	client := newTestClient(t, mockService)
+
+	mockService.On("CreateOrUpdateLock", mock.Anything, mock.MatchedBy(func(data *models.Data) bool {
+		return data.Name == "mail" && data.UserID == 1
+	})).Return(3, nil)
+	mockService.On("GetAllLocks", mock.Anything, 1).
+		Return(&[]models.Data{{Id: 3, Name: "mail"}, {Id: 4, Name: "bank"}}, nil)
+
+	stream, err := client.Sync(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.SyncRequest{LockBox: &pb.LockBox{Name: "mail"}}))
+
+	ack, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), ack.GetAck().GetId())
+	require.NoError(t, stream.CloseSend())
+
+	var names []string
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, event.GetLockBox().GetName())
+	}
+	assert.Equal(t, []string{"mail", "bank"}, names)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestUpdateConflictGRPC проверяет, что версия записи доходит до сервиса, а
+// отказ из-за устаревшей версии приходит как ABORTED с текущей версией.
+func TestUpdateConflictGRPC(t *testing.T) {
+	mockService := lockboxusecase.NewLockBoxUsecaseMock()
+	client := newTestClient(t, mockService)
+
+	mockService.On("UpdateLockByID", mock.Anything, mock.MatchedBy(func(data *models.Data) bool {
+		return data.Id == 7 && data.Revision == 2
+	})).Return(domain.ErrLockBoxConflict)
+	mockService.On("GetLockByID", mock.Anything, 7, 1).Return(&models.Data{Id: 7, Name: "enc", Revision: 3}, nil)
+	mockService.On("CreateOrUpdateLock", mock.Anything, mock.MatchedBy(func(data *models.Data) bool {
+		return data.Name == "mail" && data.Revision == 0
+	})).Return(0, domain.ErrLockBoxConflict)
+	mockService.On("GetLockByName", mock.Anything, "mail", 1).Return(&models.Data{Id: 8, Name: "mail", Revision: 5}, nil)
+
+	currentOf := func(err error) *pb.LockBox {
+		st := status.Convert(err)
+		require.Equal(t, codes.Aborted, st.Code())
+		require.Len(t, st.Details(), 1)
+		return st.Details()[0].(*pb.LockBox)
+	}
+
+	_, err := client.Update(context.Background(), &pb.LockBox{Id: 7, Url: "new", Revision: 2})
+	assert.Equal(t, int64(3), currentOf(err).GetRevision())
+
+	_, err = client.CreateOrUpdate(context.Background(), &pb.LockBox{Name: "mail", Url: "new"})
+	assert.Equal(t, int64(5), currentOf(err).GetRevision())
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"errors"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	authusecase "gophKeeper/internal/server/services/auth/usecase"
+	lockboxusecase "gophKeeper/internal/server/services/lockbox/usecase"
+	usersusecase "gophKeeper/internal/server/services/users/usecase"
+	pb "gophKeeper/pkg/pb/gophkeeper/v1"
+	"gophKeeper/util"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods доступны без токена, как /api/auth/login и регистрация в REST API.
+var publicMethods = map[string]bool{
+	pb.AuthService_Login_FullMethodName:     true,
+	pb.AuthService_SRPInit_FullMethodName:   true,
+	pb.AuthService_SRPVerify_FullMethodName: true,
+	pb.AuthService_Refresh_FullMethodName:   true,
+	pb.AuthService_VerifyMFA_FullMethodName: true,
+	pb.UserService_Register_FullMethodName:  true,
+}
+
+// accessPolicy повторяет правила REST-роутов: lockbox доступен ролям
+// admin и attendee, остальным методам достаточно валидного токена.
+func accessPolicy(fullMethod string) (bool, []string) {
+	if publicMethods[fullMethod] {
+		return true, nil
+	}
+	if strings.HasPrefix(fullMethod, "/"+pb.LockBoxService_ServiceDesc.ServiceName+"/") {
+		return false, []string{util.Admin, util.Attendee}
+	}
+	return false, nil
+}
+
+// NewServer создаёт gRPC-сервер поверх тех же usecase, что и REST API.
+func NewServer(mware middleware.IMiddlewareService, auth authusecase.IAuthUsecase, users usersusecase.IUserUsecase,
+	lockBoxes lockboxusecase.ILockBoxUsecase, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.UnaryInterceptor(mware.UnaryAuth(accessPolicy)),
+		grpc.StreamInterceptor(mware.StreamAuth(accessPolicy)),
+	)
+	server := grpc.NewServer(opts...)
+	pb.RegisterAuthServiceServer(server, &AuthServer{service: auth, mware: mware})
+	pb.RegisterUserServiceServer(server, &UserServer{userService: users})
+	pb.RegisterLockBoxServiceServer(server, &LockBoxServer{lockBoxService: lockBoxes})
+	return server
+}
+
+// toStatus переводит доменные ошибки в коды gRPC так же, как REST-хендлеры
+// переводят их в HTTP-статусы.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrLockBoxNotFound), errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrKDFNotSet):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, domain.ErrNameEmpty), errors.Is(err, domain.ErrNoDataToCreate),
+		errors.Is(err, domain.ErrInvalidKDF), errors.Is(err, domain.ErrInvalidUserType):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrKDFAlreadySet):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrLockBoxConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, domain.ErrSRPNotSet):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrTooManyAttempts), errors.Is(err, domain.ErrAccountLocked), errors.Is(err, domain.ErrTooManyLogins):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, domain.ErrAccountDisabled), errors.Is(err, domain.ErrPasswordLogin):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrSRPSessionExpired):
+		return status.Error(codes.Unauthenticated, domain.ErrInvalidCredentials.Error())
+	default:
+		return internalStatus(err)
+	}
+}
+
+// internalStatus пишет причину в лог сервера, а клиенту отдаёт только
+// общий текст: ошибки базы не должны уходить наружу.
+func internalStatus(err error) error {
+	slog.Error("gRPC request failed", "error", err)
+	return status.Error(codes.Internal, domain.ErrInternal.Error())
+}
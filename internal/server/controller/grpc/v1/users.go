@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"context"
+	"encoding/hex"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/users/models"
+	"gophKeeper/internal/server/services/users/usecase"
+	pb "gophKeeper/pkg/pb/gophkeeper/v1"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type UserServer struct {
+	pb.UnimplementedUserServiceServer
+	userService usecase.IUserUsecase
+}
+
+// Register создаёт пользователя только по верификатору SRP: пароль через
+// gRPC не принимается вовсе.
+func (s *UserServer) Register(ctx context.Context, req *pb.RegisterRequest) (*emptypb.Empty, error) {
+	if len(req.GetSrp().GetVerifier()) == 0 {
+		return nil, toStatus(domain.ErrInvalidInput)
+	}
+	user := &models.User{
+		Username:    req.Username,
+		SRPSalt:     hex.EncodeToString(req.GetSrp().GetSalt()),
+		SRPVerifier: hex.EncodeToString(req.GetSrp().GetVerifier()),
+	}
+	if err := s.userService.CreateUser(ctx, user); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *UserServer) GetKDFParams(ctx context.Context, _ *emptypb.Empty) (*pb.KDFParams, error) {
+	params, err := s.userService.GetKDFParams(ctx, middleware.UserIdFromContext(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.KDFParams{
+		Salt:    params.Salt,
+		Time:    params.Time,
+		Memory:  params.Memory,
+		Threads: uint32(params.Threads),
+		Check:   params.Check,
+	}, nil
+}
+
+func (s *UserServer) SetKDFParams(ctx context.Context, req *pb.KDFParams) (*emptypb.Empty, error) {
+	params := &models.KDFParams{
+		Salt:   req.Salt,
+		Time:   req.Time,
+		Memory: req.Memory,
+		Check:  req.Check,
+	}
+	if req.Threads <= 255 {
+		params.Threads = uint8(req.Threads)
+	}
+	if err := s.userService.SetKDFParams(ctx, middleware.UserIdFromContext(ctx), params); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
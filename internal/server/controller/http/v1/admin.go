@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/admin/models"
+	"gophKeeper/internal/server/services/admin/usecase"
+	"gophKeeper/util"
+	"net/http"
+	"strconv"
+)
+
+type AdminHandler struct {
+	config  *config.Config
+	service usecase.IAdminUsecase
+	mware   middleware.IMiddlewareService
+}
+
+// NewAdminHandler регистрирует API управления пользователями. Все маршруты
+// доступны только администраторам.
+func NewAdminHandler(config *config.Config, engine *gin.RouterGroup, service usecase.IAdminUsecase, mware middleware.IMiddlewareService) {
+	handler := AdminHandler{
+		config:  config,
+		service: service,
+		mware:   mware,
+	}
+
+	router := engine.Group("/admin", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin))
+	{
+		router.GET("/users", handler.listUsers)
+		router.POST("/users/:id/disable", handler.disableUser)
+		router.POST("/users/:id/enable", handler.enableUser)
+		router.DELETE("/users/:id/sessions", handler.forceLogout)
+		router.DELETE("/users/:id/totp", handler.resetTOTP)
+		router.PUT("/users/:id/role", handler.setUserType)
+		router.GET("/users/:id/usage", handler.storageUsage)
+	}
+}
+
+// adminError переводит ошибки управления пользователями в ответ.
+func adminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidUserType), errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrAdminSelf):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// userParam разбирает id пользователя из пути; при ошибке отвечает 400.
+func userParam(c *gin.Context) (int, bool) {
+	userId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidUserID.Error()})
+		return 0, false
+	}
+	return userId, true
+}
+
+// listUsers отдаёт страницу пользователей: ?limit=&offset=.
+func (h *AdminHandler) listUsers(c *gin.Context) {
+	limit, err1 := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	offset, err2 := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	page, err := h.service.ListUsers(c, limit, offset)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *AdminHandler) disableUser(c *gin.Context) {
+	userId, ok := userParam(c)
+	if !ok {
+		return
+	}
+	if err := h.service.DisableUser(clientContext(c), c.GetInt("userId"), userId); err != nil {
+		adminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AdminHandler) enableUser(c *gin.Context) {
+	userId, ok := userParam(c)
+	if !ok {
+		return
+	}
+	if err := h.service.EnableUser(clientContext(c), c.GetInt("userId"), userId); err != nil {
+		adminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// forceLogout завершает все сессии пользователя.
+func (h *AdminHandler) forceLogout(c *gin.Context) {
+	userId, ok := userParam(c)
+	if !ok {
+		return
+	}
+	revoked, err := h.service.ForceLogout(clientContext(c), c.GetInt("userId"), userId)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// resetTOTP отключает 2FA пользователя, потерявшего аутентификатор.
+func (h *AdminHandler) resetTOTP(c *gin.Context) {
+	userId, ok := userParam(c)
+	if !ok {
+		return
+	}
+	if err := h.service.ResetTOTP(clientContext(c), c.GetInt("userId"), userId); err != nil {
+		adminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AdminHandler) setUserType(c *gin.Context) {
+	userId, ok := userParam(c)
+	if !ok {
+		return
+	}
+	var req models.RoleChange
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	if err := h.service.SetUserType(clientContext(c), c.GetInt("userId"), userId, req.UserType); err != nil {
+		adminError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AdminHandler) storageUsage(c *gin.Context) {
+	userId, ok := userParam(c)
+	if !ok {
+		return
+	}
+	usage, err := h.service.StorageUsage(c, userId)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
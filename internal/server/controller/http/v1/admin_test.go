@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/admin/models"
+	"gophKeeper/internal/server/services/admin/usecase"
+	"gophKeeper/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := usecase.NewAdminUsecaseMock()
+	mockMiddleware := new(middleware.MockMiddlewareService)
+	mockMiddleware.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	}))
+	mockMiddleware.On("AuthorizeRoles", []string{util.Admin}).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		if ctx.GetHeader("X-Role") != util.Admin {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+		ctx.Next()
+	}))
+
+	router := gin.New()
+	NewAdminHandler(&config.Config{}, router.Group("/api"), mockUsecase, mockMiddleware)
+
+	send := func(method, path, role string, payload any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Role", role)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should deny non-admins", func(t *testing.T) {
+		w := send(http.MethodGet, "/api/admin/users", util.Attendee, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should list users without secrets", func(t *testing.T) {
+		page := &models.UserPage{Users: []models.User{{UserId: 2, Username: "bob", UserType: util.Attendee}}, Total: 1, Limit: 10, Offset: 0}
+		mockUsecase.On("ListUsers", mock.Anything, 10, 0).Return(page, nil).Once()
+
+		w := send(http.MethodGet, "/api/admin/users?limit=10", util.Admin, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"username":"bob"`)
+		assert.Contains(t, w.Body.String(), `"total":1`)
+		assert.NotContains(t, w.Body.String(), "password")
+	})
+
+	t.Run("should reject invalid paging", func(t *testing.T) {
+		w := send(http.MethodGet, "/api/admin/users?offset=x", util.Admin, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should disable user", func(t *testing.T) {
+		mockUsecase.On("DisableUser", mock.Anything, 1, 2).Return(nil).Once()
+
+		w := send(http.MethodPost, "/api/admin/users/2/disable", util.Admin, nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should refuse to disable self", func(t *testing.T) {
+		mockUsecase.On("DisableUser", mock.Anything, 1, 1).Return(domain.ErrAdminSelf).Once()
+
+		w := send(http.MethodPost, "/api/admin/users/1/disable", util.Admin, nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("should force logout", func(t *testing.T) {
+		mockUsecase.On("ForceLogout", mock.Anything, 1, 2).Return(3, nil).Once()
+
+		w := send(http.MethodDelete, "/api/admin/users/2/sessions", util.Admin, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"revoked":3}`, w.Body.String())
+	})
+
+	t.Run("should report unknown user", func(t *testing.T) {
+		mockUsecase.On("ResetTOTP", mock.Anything, 1, 9).Return(domain.ErrUserNotFound).Once()
+
+		w := send(http.MethodDelete, "/api/admin/users/9/totp", util.Admin, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should change role", func(t *testing.T) {
+		mockUsecase.On("SetUserType", mock.Anything, 1, 2, util.Admin).Return(nil).Once()
+
+		w := send(http.MethodPut, "/api/admin/users/2/role", util.Admin, models.RoleChange{UserType: util.Admin})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should return storage usage", func(t *testing.T) {
+		usage := &models.StorageUsage{UserId: 2, LockBoxes: 4, Files: 1, FileBytes: 2048}
+		mockUsecase.On("StorageUsage", mock.Anything, 2).Return(usage, nil).Once()
+
+		w := send(http.MethodGet, "/api/admin/users/2/usage", util.Admin, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"file_bytes":2048`)
+	})
+
+	t.Run("should reject invalid id", func(t *testing.T) {
+		w := send(http.MethodGet, "/api/admin/users/abc/usage", util.Admin, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	mockUsecase.AssertExpectations(t)
+}
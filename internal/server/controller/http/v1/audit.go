@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/audit/usecase"
+	"gophKeeper/util"
+	"net/http"
+	"strconv"
+)
+
+type AuditHandler struct {
+	config  *config.Config
+	service usecase.IAuditUsecase
+	mware   middleware.IMiddlewareService
+}
+
+// NewAuditHandler регистрирует просмотр журнала аудита: пользователь видит
+// только события своего аккаунта, проверка цепочки доступна администраторам.
+func NewAuditHandler(config *config.Config, engine *gin.RouterGroup, service usecase.IAuditUsecase, mware middleware.IMiddlewareService) {
+	handler := AuditHandler{
+		config:  config,
+		service: service,
+		mware:   mware,
+	}
+
+	engine.GET("/audit", mware.MiddlewareJWT(), handler.listEvents)
+	engine.GET("/admin/audit/verify", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin), handler.verify)
+}
+
+// listEvents отдаёт страницу событий текущего пользователя: ?limit=&offset=.
+func (h *AuditHandler) listEvents(c *gin.Context) {
+	limit, err1 := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	offset, err2 := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	events, err := h.service.ListEvents(c, c.GetInt("userId"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// verify проверяет цепочку хешей всего журнала.
+func (h *AuditHandler) verify(c *gin.Context) {
+	report, err := h.service.Verify(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
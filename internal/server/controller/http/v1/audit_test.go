@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/audit/models"
+	"gophKeeper/internal/server/services/audit/usecase"
+	"gophKeeper/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuditHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := usecase.NewAuditUsecaseMock()
+	mockMiddleware := new(middleware.MockMiddlewareService)
+	mockMiddleware.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 3)
+		ctx.Next()
+	}))
+	mockMiddleware.On("AuthorizeRoles", []string{util.Admin}).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		if ctx.GetHeader("X-Role") != util.Admin {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+		ctx.Next()
+	}))
+
+	router := gin.New()
+	NewAuditHandler(&config.Config{}, router.Group("/api"), mockUsecase, mockMiddleware)
+
+	get := func(path, role string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Role", role)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should list own events", func(t *testing.T) {
+		events := []models.Event{{ID: 7, UserId: 3, Action: models.ActionLockBoxRead, Target: "42", Success: true, Hash: "abc"}}
+		mockUsecase.On("ListEvents", mock.Anything, 3, 20, 0).Return(events, nil).Once()
+
+		w := get("/api/audit?limit=20", util.Attendee)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"target":"42"`)
+		assert.NotContains(t, w.Body.String(), "abc")
+	})
+
+	t.Run("should reject bad paging", func(t *testing.T) {
+		w := get("/api/audit?limit=x", util.Attendee)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should verify chain for admins only", func(t *testing.T) {
+		w := get("/api/admin/audit/verify", util.Attendee)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		mockUsecase.On("Verify", mock.Anything).Return(&models.ChainReport{Checked: 5, Valid: true}, nil).Once()
+		w = get("/api/admin/audit/verify", util.Admin)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"checked":5,"valid":true}`, w.Body.String())
+	})
+
+	mockUsecase.AssertExpectations(t)
+}
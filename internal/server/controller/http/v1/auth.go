@@ -1,13 +1,20 @@
 package v1
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"gophKeeper/internal/server/config"
 	"gophKeeper/internal/server/domain"
 	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/auth/attempts"
 	"gophKeeper/internal/server/services/auth/models"
 	"gophKeeper/internal/server/services/auth/usecase"
+	"gophKeeper/util"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 type AuthHandler struct {
@@ -26,9 +33,109 @@ func NewAuthHandler(config *config.Config, engine *gin.RouterGroup, service usec
 	router := engine.Group("/auth")
 	{
 		router.POST("/login", handler.login)
+		router.POST("/srp/init", middleware.RateLimiter(), handler.srpInit)
+		router.POST("/srp/verify", handler.srpVerify)
+		router.PUT("/srp", mware.MiddlewareJWT(), handler.setSRPVerifier)
+		router.POST("/refresh", handler.refresh)
+		router.POST("/logout", mware.MiddlewareJWT(), handler.logout)
+		router.GET("/jwks", handler.jwks)
+		router.POST("/mfa/verify", handler.mfaVerify)
+		router.POST("/totp", mware.MiddlewareJWT(), handler.enrollTOTP)
+		router.POST("/totp/confirm", mware.MiddlewareJWT(), handler.confirmTOTP)
+		router.DELETE("/totp", mware.MiddlewareJWT(), handler.disableTOTP)
+		router.GET("/sessions", mware.MiddlewareJWT(), handler.listSessions)
+		router.DELETE("/sessions", mware.MiddlewareJWT(), handler.revokeOtherSessions)
+		router.DELETE("/sessions/:id", mware.MiddlewareJWT(), handler.revokeSession)
+		router.DELETE("/lockouts/:username", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin), handler.unlockUser)
 	}
 }
 
+// clientContext передаёт адрес клиента в usecase: неудачные входы считаются
+// и по имени, и по адресу, а в журнал аудита адрес пишется рядом с событием.
+func clientContext(c *gin.Context) context.Context {
+	return attempts.WithClientIP(c.Request.Context(), c.ClientIP())
+}
+
+// attemptsError отвечает 429 (или 423 для заблокированного аккаунта) с
+// заголовком Retry-After, если вход отклонён защитой от перебора.
+func attemptsError(c *gin.Context, err error) bool {
+	var blocked *attempts.BlockedError
+	if !errors.As(err, &blocked) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+	status := http.StatusTooManyRequests
+	if blocked.Locked {
+		status = http.StatusLocked
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+	return true
+}
+
+// disabledError отвечает 403 на вход в отключённый аккаунт.
+func disabledError(c *gin.Context, err error) bool {
+	if !errors.Is(err, domain.ErrAccountDisabled) {
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	return true
+}
+
+// sessionMeta описывает устройство по заголовкам X-Device-Name и X-Device-OS,
+// которые передаёт клиент.
+func sessionMeta(c *gin.Context) models.SessionMeta {
+	return models.NewSessionMeta(c.GetHeader("X-Device-Name"), c.GetHeader("X-Device-OS"), c.Request.UserAgent(), c.ClientIP())
+}
+
+// jwks отдаёт открытые ключи подписи токенов. Клиенты кешируют ответ и
+// запрашивают его снова, встретив неизвестный kid.
+func (h *AuthHandler) jwks(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.mware.JWKS())
+}
+
+// issueTokens открывает сессию для вошедшего пользователя и возвращает
+// access- и refresh-токены.
+func (h *AuthHandler) issueTokens(c *gin.Context, userInfo *models.InfoUser) (gin.H, bool) {
+	sessionId, refreshToken, err := h.service.CreateSession(clientContext(c), userInfo, sessionMeta(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrTokenCreation.Error()})
+		return nil, false
+	}
+
+	token, err := h.mware.CreateToken(userInfo.UserId, userInfo.Username, userInfo.UserType, sessionId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrTokenCreation.Error()})
+		return nil, false
+	}
+
+	return gin.H{"token": token, "refresh_token": refreshToken}, true
+}
+
+// internalError пишет причину в лог сервера и отвечает 500 с общим текстом,
+// как internalStatus в gRPC: ошибки базы не должны уходить клиенту.
+func internalError(c *gin.Context, err error) {
+	slog.Error("HTTP request failed", "path", c.FullPath(), "error", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrInternal.Error()})
+}
+
+// completeLogin завершает вход после проверки пароля: при включённой 2FA
+// вместо токенов отдаёт mfa_token, который обменивается на них в /auth/mfa/verify.
+func (h *AuthHandler) completeLogin(c *gin.Context, userInfo *models.InfoUser) (gin.H, bool) {
+	challenge, err := h.service.StartMFA(c, userInfo)
+	if err != nil {
+		internalError(c, err)
+		return nil, false
+	}
+	if challenge != nil {
+		return gin.H{"mfa_required": challenge.Required, "mfa_token": challenge.Token}, true
+	}
+	return h.issueTokens(c, userInfo)
+}
+
+// login — прежний вход с передачей пароля серверу. Работает только при
+// LOGIN_LEGACY_PASSWORD=true и только для аккаунтов без верификатора SRP,
+// иначе отвечает 403.
 func (h *AuthHandler) login(c *gin.Context) {
 	var user models.AuthUser
 	if err := c.ShouldBindJSON(&user); err != nil {
@@ -36,17 +143,275 @@ func (h *AuthHandler) login(c *gin.Context) {
 		return
 	}
 
-	userInfo, err := h.service.CheckUser(c, &user)
+	userInfo, err := h.service.CheckUser(clientContext(c), &user)
 	if err != nil {
+		if attemptsError(c, err) || disabledError(c, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrPasswordLogin) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": domain.ErrInvalidCredentials.Error()})
 		return
 	}
 
-	token, err := h.mware.CreateToken(userInfo.UserId, userInfo.Username, userInfo.UserType)
+	tokens, ok := h.completeLogin(c, userInfo)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// srpInit — первый шаг входа по SRP-6a. Пользователям без верификатора
+// отвечает 409: клиент входит по паролю и сразу переходит на SRP, если
+// сервер разрешает прежний вход (LOGIN_LEGACY_PASSWORD).
+func (h *AuthHandler) srpInit(c *gin.Context) {
+	var req models.SRPInit
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	challenge, err := h.service.StartSRP(clientContext(c), &req)
+	if err != nil {
+		if attemptsError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSRPNotSet):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrTooManyLogins):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		default:
+			internalError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, challenge)
+}
+
+// srpVerify — второй шаг: проверяет M1 и выдаёт токены вместе с M2.
+func (h *AuthHandler) srpVerify(c *gin.Context) {
+	var req models.SRPVerify
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	userInfo, m2, err := h.service.FinishSRP(clientContext(c), &req)
+	if err != nil {
+		if disabledError(c, err) {
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": domain.ErrInvalidCredentials.Error()})
+		return
+	}
+
+	tokens, ok := h.completeLogin(c, userInfo)
+	if !ok {
+		return
+	}
+	tokens["m2"] = m2
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) setSRPVerifier(c *gin.Context) {
+	var verifier models.SRPVerifier
+	if err := c.ShouldBindJSON(&verifier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	if err := h.service.SetSRPVerifier(c, c.GetInt("userId"), &verifier); err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// mfaVerify — второй фактор входа: код из приложения или код восстановления.
+func (h *AuthHandler) mfaVerify(c *gin.Context) {
+	var req models.MFAVerify
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	userInfo, err := h.service.FinishMFA(clientContext(c), &req)
+	if err != nil {
+		if attemptsError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, domain.ErrMFAExpired), errors.Is(err, domain.ErrInvalidMFACode):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			internalError(c, err)
+		}
+		return
+	}
+
+	tokens, ok := h.issueTokens(c, userInfo)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// totpError переводит ошибки настройки 2FA в ответ. Неверный код — 403, а не
+// 401: токен действителен, и клиенту незачем его обновлять.
+func totpError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrTOTPEnabled), errors.Is(err, domain.ErrTOTPNotEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		internalError(c, err)
+	}
+}
+
+// enrollTOTP выдаёт секрет и ссылку otpauth:// для приложения-аутентификатора.
+func (h *AuthHandler) enrollTOTP(c *gin.Context) {
+	enrollment, err := h.service.EnrollTOTP(c, c.GetInt("userId"), c.GetString("username"))
+	if err != nil {
+		totpError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// confirmTOTP включает 2FA по первому коду и возвращает коды восстановления.
+func (h *AuthHandler) confirmTOTP(c *gin.Context) {
+	var req models.TOTPCode
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	codes, err := h.service.ConfirmTOTP(c, c.GetInt("userId"), req.Code)
+	if err != nil {
+		totpError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func (h *AuthHandler) disableTOTP(c *gin.Context) {
+	var req models.TOTPCode
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	if err := h.service.DisableTOTP(c, c.GetInt("userId"), req.Code); err != nil {
+		totpError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// refresh обменивает refresh-токен на новую пару токенов той же сессии.
+func (h *AuthHandler) refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	userInfo, sessionId, refreshToken, err := h.service.RefreshSession(clientContext(c), req.RefreshToken, sessionMeta(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefresh) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	token, err := h.mware.CreateToken(userInfo.UserId, userInfo.Username, userInfo.UserType, sessionId)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrTokenCreation.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, gin.H{"token": token, "refresh_token": refreshToken})
+}
+
+// logout отзывает текущую сессию: её access- и refresh-токены перестают действовать.
+func (h *AuthHandler) logout(c *gin.Context) {
+	err := h.service.RevokeSession(clientContext(c), c.GetInt("userId"), c.GetInt("sessionId"))
+	if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		internalError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// listSessions возвращает устройства, с которых выполнен вход.
+func (h *AuthHandler) listSessions(c *gin.Context) {
+	sessions, err := h.service.ListSessions(c, c.GetInt("userId"), c.GetInt("sessionId"))
+	if err != nil {
+		internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// revokeSession завершает вход на одном устройстве, в том числе текущем.
+func (h *AuthHandler) revokeSession(c *gin.Context) {
+	sessionId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	if err := h.service.RevokeSession(clientContext(c), c.GetInt("userId"), sessionId); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// revokeOtherSessions завершает вход на всех устройствах, кроме текущего.
+func (h *AuthHandler) revokeOtherSessions(c *gin.Context) {
+	revoked, err := h.service.RevokeOtherSessions(clientContext(c), c.GetInt("userId"), c.GetInt("sessionId"))
+	if err != nil {
+		internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// unlockUser снимает блокировку входа с аккаунта (только для администратора).
+func (h *AuthHandler) unlockUser(c *gin.Context) {
+	if err := h.service.UnlockUser(clientContext(c), c.GetInt("userId"), c.Param("username")); err != nil {
+		internalError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
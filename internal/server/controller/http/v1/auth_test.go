@@ -9,12 +9,14 @@ import (
 	"gophKeeper/internal/server/config"
 	"gophKeeper/internal/server/domain"
 	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/auth/attempts"
 	"gophKeeper/internal/server/services/auth/models"
 	"gophKeeper/internal/server/services/auth/usecase"
 
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 // Тест на авторизацию
@@ -36,8 +38,11 @@ func TestAuthHandler_Login(t *testing.T) {
 		mware:   mockMiddlewareService,
 	}
 
+	mockMiddlewareService.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() }))
+
 	r := gin.Default()
 	engine := r.Group("/v1")
+	mockMiddlewareService.On("AuthorizeRoles", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() }))
 	NewAuthHandler(handler.config, engine, mockAuthUsecase, mockMiddlewareService)
 
 	t.Run("Success", func(t *testing.T) {
@@ -48,7 +53,9 @@ func TestAuthHandler_Login(t *testing.T) {
 				UserType: "admin",
 			}, nil)
 
-		mockMiddlewareService.On("CreateToken", 1, "test", "admin").Return("mock_token", nil)
+		mockAuthUsecase.On("StartMFA", mock.Anything, mock.Anything).Return(nil, nil)
+		mockAuthUsecase.On("CreateSession", mock.Anything, mock.Anything, mock.Anything).Return(7, "mock_refresh", nil)
+		mockMiddlewareService.On("CreateToken", 1, "test", "admin", 7).Return("mock_token", nil)
 
 		payload := &models.AuthUser{
 			Username: "test",
@@ -64,6 +71,7 @@ func TestAuthHandler_Login(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Contains(t, w.Body.String(), "mock_token")
+		assert.Contains(t, w.Body.String(), `"refresh_token":"mock_refresh"`)
 
 		mockAuthUsecase.AssertExpectations(t)
 		mockMiddlewareService.AssertExpectations(t)
@@ -99,7 +107,7 @@ func TestAuthHandler_Login(t *testing.T) {
 		r.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
-		assert.Contains(t, w.Body.String(), domain.ErrInvalidCredentials)
+		assert.Contains(t, w.Body.String(), domain.ErrInvalidCredentials.Error())
 	})
 
 	t.Run("InternalError", func(t *testing.T) {
@@ -110,7 +118,9 @@ func TestAuthHandler_Login(t *testing.T) {
 			return user.Username == "test" && user.Password == "password"
 		})).Return(&models.InfoUser{UserId: 1, Username: "test", UserType: "admin"}, nil)
 
-		mockMiddlewareService.On("CreateToken", 1, "test", "admin").Return("", domain.ErrTokenCreation)
+		mockAuthUsecase.On("StartMFA", mock.Anything, mock.Anything).Return(nil, nil)
+		mockAuthUsecase.On("CreateSession", mock.Anything, mock.Anything, mock.Anything).Return(7, "mock_refresh", nil)
+		mockMiddlewareService.On("CreateToken", 1, "test", "admin", 7).Return("", domain.ErrTokenCreation)
 
 		payload := &models.AuthUser{Username: "test", Password: "password"}
 		body, _ := json.Marshal(payload)
@@ -122,3 +132,344 @@ func TestAuthHandler_Login(t *testing.T) {
 		r.ServeHTTP(w, req)
 	})
 }
+
+func TestAuthHandler_SRP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockAuthUsecase := usecase.NewAuthUsecaseMock().(*usecase.AuthUsecaseMock)
+	mockMiddlewareService := middleware.NewMock().(*middleware.MockMiddlewareService)
+	mockMiddlewareService.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	}))
+
+	r := gin.Default()
+	mockMiddlewareService.On("AuthorizeRoles", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() }))
+	NewAuthHandler(&config.Config{}, r.Group("/v1"), mockAuthUsecase, mockMiddlewareService)
+
+	send := func(method, url string, payload any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Init", func(t *testing.T) {
+		mockAuthUsecase.On("StartSRP", mock.Anything, &models.SRPInit{Username: "test", A: "aa"}).
+			Return(&models.SRPChallenge{Session: "s1", Salt: "00", B: "bb"}, nil)
+
+		w := send(http.MethodPost, "/v1/auth/srp/init", models.SRPInit{Username: "test", A: "aa"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"session":"s1"`)
+	})
+
+	t.Run("InitLegacyUser", func(t *testing.T) {
+		mockAuthUsecase.On("StartSRP", mock.Anything, &models.SRPInit{Username: "legacy", A: "aa"}).
+			Return(nil, domain.ErrSRPNotSet)
+
+		w := send(http.MethodPost, "/v1/auth/srp/init", models.SRPInit{Username: "legacy", A: "aa"})
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		mockAuthUsecase.On("FinishSRP", mock.Anything, &models.SRPVerify{Session: "s1", M1: "11"}).
+			Return(&models.InfoUser{UserId: 1, Username: "test", UserType: "attendee"}, "22", nil)
+		mockAuthUsecase.On("StartMFA", mock.Anything, mock.Anything).Return(nil, nil)
+		mockAuthUsecase.On("CreateSession", mock.Anything, mock.Anything, mock.Anything).Return(3, "mock_refresh", nil)
+		mockMiddlewareService.On("CreateToken", 1, "test", "attendee", 3).Return("mock_token", nil)
+
+		w := send(http.MethodPost, "/v1/auth/srp/verify", models.SRPVerify{Session: "s1", M1: "11"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "mock_token")
+		assert.Contains(t, w.Body.String(), `"m2":"22"`)
+	})
+
+	t.Run("VerifyWrongProof", func(t *testing.T) {
+		mockAuthUsecase.On("FinishSRP", mock.Anything, &models.SRPVerify{Session: "s1", M1: "00"}).
+			Return(nil, "", domain.ErrInvalidCredentials)
+
+		w := send(http.MethodPost, "/v1/auth/srp/verify", models.SRPVerify{Session: "s1", M1: "00"})
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("SetVerifier", func(t *testing.T) {
+		verifier := &models.SRPVerifier{Salt: "00", Verifier: "11"}
+		mockAuthUsecase.On("SetSRPVerifier", mock.Anything, 1, verifier).Return(nil)
+
+		w := send(http.MethodPut, "/v1/auth/srp", verifier)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	mockAuthUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockAuthUsecase := usecase.NewAuthUsecaseMock().(*usecase.AuthUsecaseMock)
+	mockMiddlewareService := middleware.NewMock().(*middleware.MockMiddlewareService)
+	mockMiddlewareService.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Set("sessionId", 5)
+		ctx.Next()
+	}))
+
+	r := gin.Default()
+	mockMiddlewareService.On("AuthorizeRoles", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() }))
+	NewAuthHandler(&config.Config{}, r.Group("/v1"), mockAuthUsecase, mockMiddlewareService)
+
+	send := func(method, url string, payload any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAuthUsecase.On("RefreshSession", mock.Anything, "old", mock.Anything).
+			Return(&models.InfoUser{UserId: 1, Username: "test", UserType: "attendee"}, 5, "new", nil)
+		mockMiddlewareService.On("CreateToken", 1, "test", "attendee", 5).Return("mock_token", nil)
+
+		w := send(http.MethodPost, "/v1/auth/refresh", models.RefreshRequest{RefreshToken: "old"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"token":"mock_token"`)
+		assert.Contains(t, w.Body.String(), `"refresh_token":"new"`)
+	})
+
+	t.Run("Reused", func(t *testing.T) {
+		mockAuthUsecase.On("RefreshSession", mock.Anything, "stolen", mock.Anything).
+			Return(nil, 0, "", domain.ErrInvalidRefresh)
+
+		w := send(http.MethodPost, "/v1/auth/refresh", models.RefreshRequest{RefreshToken: "stolen"})
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		mockAuthUsecase.On("RevokeSession", mock.Anything, 1, 5).Return(nil)
+
+		w := send(http.MethodPost, "/v1/auth/logout", nil)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	mockAuthUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_Sessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockAuthUsecase := usecase.NewAuthUsecaseMock().(*usecase.AuthUsecaseMock)
+	mockMiddlewareService := middleware.NewMock().(*middleware.MockMiddlewareService)
+	mockMiddlewareService.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Set("sessionId", 5)
+		ctx.Next()
+	}))
+
+	r := gin.Default()
+	mockMiddlewareService.On("AuthorizeRoles", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() }))
+	NewAuthHandler(&config.Config{}, r.Group("/v1"), mockAuthUsecase, mockMiddlewareService)
+
+	send := func(method, url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("List", func(t *testing.T) {
+		mockAuthUsecase.On("ListSessions", mock.Anything, 1, 5).Return([]models.Session{
+			{SessionId: 5, DeviceName: "laptop", OS: "linux", Current: true},
+			{SessionId: 6, DeviceName: "ci-runner", OS: "linux"},
+		}, nil)
+
+		w := send(http.MethodGet, "/v1/auth/sessions")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var sessions []models.Session
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+		assert.Len(t, sessions, 2)
+		assert.True(t, sessions[0].Current)
+		assert.Equal(t, "ci-runner", sessions[1].DeviceName)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		mockAuthUsecase.On("RevokeSession", mock.Anything, 1, 6).Return(nil)
+
+		w := send(http.MethodDelete, "/v1/auth/sessions/6")
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("RevokeForeign", func(t *testing.T) {
+		mockAuthUsecase.On("RevokeSession", mock.Anything, 1, 42).Return(domain.ErrSessionNotFound)
+
+		w := send(http.MethodDelete, "/v1/auth/sessions/42")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidId", func(t *testing.T) {
+		w := send(http.MethodDelete, "/v1/auth/sessions/abc")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("RevokeOthers", func(t *testing.T) {
+		mockAuthUsecase.On("RevokeOtherSessions", mock.Anything, 1, 5).Return(2, nil)
+
+		w := send(http.MethodDelete, "/v1/auth/sessions")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"revoked":2}`, w.Body.String())
+	})
+
+	mockAuthUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_MFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockAuthUsecase := usecase.NewAuthUsecaseMock().(*usecase.AuthUsecaseMock)
+	mockMiddlewareService := middleware.NewMock().(*middleware.MockMiddlewareService)
+	mockMiddlewareService.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Set("username", "test")
+		ctx.Next()
+	}))
+
+	r := gin.Default()
+	mockMiddlewareService.On("AuthorizeRoles", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() }))
+	NewAuthHandler(&config.Config{}, r.Group("/v1"), mockAuthUsecase, mockMiddlewareService)
+
+	send := func(method, url string, payload any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	user := &models.InfoUser{UserId: 1, Username: "test", UserType: "attendee"}
+
+	t.Run("VerifyRequiresCode", func(t *testing.T) {
+		mockAuthUsecase.On("FinishSRP", mock.Anything, &models.SRPVerify{Session: "s1", M1: "11"}).Return(user, "22", nil)
+		mockAuthUsecase.On("StartMFA", mock.Anything, user).Return(&models.MFAChallenge{Required: true, Token: "partial"}, nil)
+
+		w := send(http.MethodPost, "/v1/auth/srp/verify", models.SRPVerify{Session: "s1", M1: "11"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"mfa_required":true,"mfa_token":"partial","m2":"22"}`, w.Body.String())
+	})
+
+	t.Run("MFAVerify", func(t *testing.T) {
+		mockAuthUsecase.On("FinishMFA", mock.Anything, &models.MFAVerify{Token: "partial", Code: "123456"}).Return(user, nil)
+		mockAuthUsecase.On("CreateSession", mock.Anything, user, mock.Anything).Return(4, "mock_refresh", nil)
+		mockMiddlewareService.On("CreateToken", 1, "test", "attendee", 4).Return("mock_token", nil)
+
+		w := send(http.MethodPost, "/v1/auth/mfa/verify", models.MFAVerify{Token: "partial", Code: "123456"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"token":"mock_token"`)
+	})
+
+	t.Run("MFAWrongCode", func(t *testing.T) {
+		mockAuthUsecase.On("FinishMFA", mock.Anything, &models.MFAVerify{Token: "partial", Code: "000000"}).
+			Return(nil, domain.ErrInvalidMFACode)
+
+		w := send(http.MethodPost, "/v1/auth/mfa/verify", models.MFAVerify{Token: "partial", Code: "000000"})
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Enroll", func(t *testing.T) {
+		mockAuthUsecase.On("EnrollTOTP", mock.Anything, 1, "test").
+			Return(&models.TOTPEnrollment{Secret: "JBSWY3DP", URI: "otpauth://totp/gophKeeper:test"}, nil)
+
+		w := send(http.MethodPost, "/v1/auth/totp", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"uri":"otpauth://totp/gophKeeper:test"`)
+	})
+
+	t.Run("Confirm", func(t *testing.T) {
+		mockAuthUsecase.On("ConfirmTOTP", mock.Anything, 1, "654321").Return([]string{"aaaa-bbbb-cccc-dddd"}, nil)
+
+		w := send(http.MethodPost, "/v1/auth/totp/confirm", models.TOTPCode{Code: "654321"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"recovery_codes":["aaaa-bbbb-cccc-dddd"]}`, w.Body.String())
+	})
+
+	t.Run("DisableNotEnabled", func(t *testing.T) {
+		mockAuthUsecase.On("DisableTOTP", mock.Anything, 1, "111111").Return(domain.ErrTOTPNotEnabled)
+
+		w := send(http.MethodDelete, "/v1/auth/totp", models.TOTPCode{Code: "111111"})
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	mockAuthUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_Lockout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockAuthUsecase := usecase.NewAuthUsecaseMock().(*usecase.AuthUsecaseMock)
+	mockMiddlewareService := middleware.NewMock().(*middleware.MockMiddlewareService)
+	mockMiddlewareService.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() }))
+	mockMiddlewareService.On("AuthorizeRoles", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() }))
+
+	r := gin.New()
+	NewAuthHandler(&config.Config{}, r.Group("/v1"), mockAuthUsecase, mockMiddlewareService)
+
+	login := func(username string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(&models.AuthUser{Username: username, Password: "password"})
+		req, _ := http.NewRequest("POST", "/v1/auth/login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Backoff", func(t *testing.T) {
+		mockAuthUsecase.On("CheckUser", mock.Anything, &models.AuthUser{Username: "slow", Password: "password"}).
+			Return(nil, &attempts.BlockedError{RetryAfter: 1500 * time.Millisecond})
+
+		w := login("slow")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		mockAuthUsecase.On("CheckUser", mock.Anything, &models.AuthUser{Username: "locked", Password: "password"}).
+			Return(nil, &attempts.BlockedError{RetryAfter: 30 * time.Minute, Locked: true})
+
+		w := login("locked")
+		assert.Equal(t, http.StatusLocked, w.Code)
+		assert.Equal(t, "1800", w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), domain.ErrAccountLocked.Error())
+	})
+
+	t.Run("Unlock", func(t *testing.T) {
+		mockAuthUsecase.On("UnlockUser", mock.Anything, 0, "locked").Return(nil)
+
+		req, _ := http.NewRequest("DELETE", "/v1/auth/lockouts/locked", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockAuthUsecase.AssertCalled(t, "UnlockUser", mock.Anything, 0, "locked")
+	})
+}
@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/bankcard/models"
+	"gophKeeper/internal/server/services/bankcard/usecase"
+	"gophKeeper/util"
+	"net/http"
+	"strconv"
+)
+
+type BankCardHandler struct {
+	config      *config.Config
+	cardService usecase.IBankCardUsecase
+	mware       middleware.IMiddlewareService
+}
+
+func NewBankCardHandler(config *config.Config, router *gin.RouterGroup, cardService usecase.IBankCardUsecase, mware middleware.IMiddlewareService) {
+	cardHandler := BankCardHandler{
+		config:      config,
+		cardService: cardService,
+		mware:       mware,
+	}
+
+	cardRouter := router.Group("/bank_cards")
+	{
+		cardRouter.POST("/create", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), cardHandler.createCard)
+		cardRouter.DELETE("/:name", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), cardHandler.deleteCard)
+		cardRouter.GET("/:name", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), cardHandler.getCard)
+		cardRouter.GET("/", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), cardHandler.getCards)
+		// Карты с зашифрованным названием адресуются по слепому индексу и id.
+		cardRouter.GET("/index/:index", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), cardHandler.getCardByIndex)
+		cardRouter.GET("/id/:id", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), cardHandler.getCardByID)
+		cardRouter.PUT("/id/:id", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), cardHandler.updateCard)
+		cardRouter.DELETE("/id/:id", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), cardHandler.deleteCardByID)
+	}
+}
+
+func (h *BankCardHandler) createCard(ctx *gin.Context) {
+	var card models.Card
+	if err := ctx.ShouldBindJSON(&card); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	card.UserID = ctx.GetInt("userId")
+
+	id, err := h.cardService.CreateCard(ctx, &card)
+	if err != nil {
+		if errors.Is(err, domain.ErrBankCardExists) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *BankCardHandler) deleteCard(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	err := h.cardService.DeleteCard(ctx, name, ctx.GetInt("userId"))
+	if err != nil {
+		if errors.Is(err, domain.ErrBankCardNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *BankCardHandler) getCard(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	card, err := h.cardService.GetCardByName(ctx, name, ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, card)
+}
+
+func (h *BankCardHandler) getCards(ctx *gin.Context) {
+	cards, err := h.cardService.GetAllCards(ctx, ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, cards)
+}
+
+// cardErrorStatus переводит ошибки карт в HTTP-статусы.
+func cardErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrBankCardNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrBankCardExists):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (h *BankCardHandler) getCardByIndex(ctx *gin.Context) {
+	card, err := h.cardService.GetCardByIndex(ctx, ctx.Param("index"), ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, card)
+}
+
+func (h *BankCardHandler) getCardByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	card, err := h.cardService.GetCardByID(ctx, id, ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, card)
+}
+
+// updateCard заменяет карту целиком: поле, переданное пустым, очищается.
+func (h *BankCardHandler) updateCard(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var card models.Card
+	if err := ctx.ShouldBindJSON(&card); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	card.Id = id
+	card.UserID = ctx.GetInt("userId")
+
+	if err := h.cardService.UpdateCard(ctx, &card); err != nil {
+		ctx.JSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+func (h *BankCardHandler) deleteCardByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.cardService.DeleteCardByID(ctx, id, ctx.GetInt("userId")); err != nil {
+		ctx.JSON(cardErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
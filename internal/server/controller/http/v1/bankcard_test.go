@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/bankcard/models"
+	"gophKeeper/internal/server/services/bankcard/usecase"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newBankCardRouter(mockService *usecase.BankCardUsecaseMock) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := BankCardHandler{
+		config:      &config.Config{},
+		cardService: mockService,
+	}
+
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	})
+	router.POST("/bank_cards/create", handler.createCard)
+	router.GET("/bank_cards/:name", handler.getCard)
+	router.GET("/bank_cards/", handler.getCards)
+	router.GET("/bank_cards/index/:index", handler.getCardByIndex)
+	router.GET("/bank_cards/id/:id", handler.getCardByID)
+	router.PUT("/bank_cards/id/:id", handler.updateCard)
+	router.DELETE("/bank_cards/:name", handler.deleteCard)
+	router.DELETE("/bank_cards/id/:id", handler.deleteCardByID)
+	return router
+}
+
+func TestCreateBankCard(t *testing.T) {
+	mockService := usecase.NewBankCardUsecaseMock()
+	router := newBankCardRouter(mockService)
+
+	t.Run("should create card successfully", func(t *testing.T) {
+		mockService.On("CreateCard", mock.Anything, &models.Card{Name: "visa", Number: "enc", UserID: 1}).Return(7, nil).Once()
+
+		body, _ := json.Marshal(models.Card{Name: "visa", Number: "enc"})
+		req, _ := http.NewRequest(http.MethodPost, "/bank_cards/create", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, `{"id":7}`, w.Body.String())
+	})
+
+	t.Run("should return conflict for duplicate name", func(t *testing.T) {
+		mockService.On("CreateCard", mock.Anything, &models.Card{Name: "dup", Number: "enc", UserID: 1}).Return(0, domain.ErrBankCardExists).Once()
+
+		body, _ := json.Marshal(models.Card{Name: "dup", Number: "enc"})
+		req, _ := http.NewRequest(http.MethodPost, "/bank_cards/create", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetBankCard(t *testing.T) {
+	mockService := usecase.NewBankCardUsecaseMock()
+	router := newBankCardRouter(mockService)
+
+	mockService.On("GetCardByName", mock.Anything, "visa", 1).Return(&models.Card{Name: "visa", UserID: 1}, nil)
+	mockService.On("GetCardByName", mock.Anything, "missing", 1).Return(nil, domain.ErrBankCardNotFound)
+	mockService.On("GetCardByIndex", mock.Anything, "ix", 1).Return(&models.Card{Id: 7, Name: "enc-name", NameIndex: "ix", UserID: 1}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/bank_cards/visa", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/bank_cards/missing", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/bank_cards/index/ix", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":7`)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateAndDeleteBankCard(t *testing.T) {
+	mockService := usecase.NewBankCardUsecaseMock()
+	router := newBankCardRouter(mockService)
+
+	// Пустой PIN передаётся как есть: обновление заменяет карту целиком.
+	mockService.On("UpdateCard", mock.Anything, &models.Card{Id: 7, Name: "enc-name", NameIndex: "ix", Number: "enc", UserID: 1}).Return(nil)
+	mockService.On("DeleteCard", mock.Anything, "visa", 1).Return(nil)
+	mockService.On("DeleteCard", mock.Anything, "missing", 1).Return(domain.ErrBankCardNotFound)
+	mockService.On("DeleteCardByID", mock.Anything, 7, 1).Return(nil)
+
+	body, _ := json.Marshal(models.Card{Name: "enc-name", NameIndex: "ix", Number: "enc"})
+	req, _ := http.NewRequest(http.MethodPut, "/bank_cards/id/7", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/bank_cards/id/7", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/bank_cards/visa", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/bank_cards/missing", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+}
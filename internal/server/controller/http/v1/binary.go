@@ -0,0 +1,168 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/binary/models"
+	"gophKeeper/internal/server/services/binary/storage"
+	"gophKeeper/internal/server/services/binary/usecase"
+	"gophKeeper/util"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type BinaryHandler struct {
+	config        *config.Config
+	binaryService usecase.IBinaryUsecase
+	mware         middleware.IMiddlewareService
+}
+
+func NewBinaryHandler(config *config.Config, router *gin.RouterGroup, binaryService usecase.IBinaryUsecase, mware middleware.IMiddlewareService) {
+	binaryHandler := BinaryHandler{
+		config:        config,
+		binaryService: binaryService,
+		mware:         mware,
+	}
+
+	binaryRouter := router.Group("/binaries", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee))
+	{
+		binaryRouter.POST("/uploads", binaryHandler.startUpload)
+		binaryRouter.GET("/uploads/:id", binaryHandler.getUpload)
+		binaryRouter.PUT("/uploads/:id/chunks/:index", binaryHandler.putChunk)
+		binaryRouter.POST("/uploads/:id/complete", binaryHandler.completeUpload)
+		binaryRouter.GET("/", binaryHandler.getFiles)
+		binaryRouter.GET("/:name", binaryHandler.getFile)
+		binaryRouter.GET("/:name/chunks/:index", binaryHandler.getChunk)
+		binaryRouter.DELETE("/:name", binaryHandler.deleteFile)
+	}
+}
+
+func binaryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrBinaryNotFound), errors.Is(err, storage.ErrBlobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrUploadCompleted), errors.Is(err, domain.ErrUploadIncomplete):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrChunkTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrNameEmpty), errors.Is(err, domain.ErrInvalidUpload), errors.Is(err, domain.ErrChunkOutOfRange):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *BinaryHandler) startUpload(ctx *gin.Context) {
+	var req models.UploadRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = ctx.GetInt("userId")
+
+	upload, err := h.binaryService.StartUpload(ctx, &req)
+	if err != nil {
+		ctx.JSON(binaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, upload)
+}
+
+func (h *BinaryHandler) getUpload(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	upload, err := h.binaryService.GetUpload(ctx, id, ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(binaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, upload)
+}
+
+// putChunk принимает тело запроса как есть и передаёт его в хранилище потоком.
+func (h *BinaryHandler) putChunk(ctx *gin.Context) {
+	id, err1 := strconv.Atoi(ctx.Param("id"))
+	index, err2 := strconv.Atoi(ctx.Param("index"))
+	if err1 != nil || err2 != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	err := h.binaryService.PutChunk(ctx, id, ctx.GetInt("userId"), index, ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(binaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *BinaryHandler) completeUpload(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	file, err := h.binaryService.CompleteUpload(ctx, id, ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(binaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, file)
+}
+
+func (h *BinaryHandler) getFiles(ctx *gin.Context) {
+	files, err := h.binaryService.GetFiles(ctx, ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(binaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, files)
+}
+
+func (h *BinaryHandler) getFile(ctx *gin.Context) {
+	file, err := h.binaryService.GetFile(ctx, ctx.Param("name"), ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(binaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, file)
+}
+
+func (h *BinaryHandler) getChunk(ctx *gin.Context) {
+	index, err := strconv.Atoi(ctx.Param("index"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+
+	chunk, err := h.binaryService.GetChunk(ctx, ctx.Param("name"), ctx.GetInt("userId"), index)
+	if err != nil {
+		ctx.JSON(binaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer chunk.Close()
+
+	ctx.Header("Content-Type", "application/octet-stream")
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.Writer, chunk); err != nil {
+		ctx.Error(err)
+	}
+}
+
+func (h *BinaryHandler) deleteFile(ctx *gin.Context) {
+	err := h.binaryService.DeleteFile(ctx, ctx.Param("name"), ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(binaryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/binary/models"
+	"gophKeeper/internal/server/services/binary/usecase"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newBinaryRouter(mockService *usecase.BinaryUsecaseMock) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	mockMiddleware := new(middleware.MockMiddlewareService)
+	passThrough := gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	})
+	mockMiddleware.On("MiddlewareJWT").Return(passThrough)
+	mockMiddleware.On("AuthorizeRoles", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() }))
+
+	router := gin.New()
+	NewBinaryHandler(&config.Config{}, router.Group("/api"), mockService, mockMiddleware)
+	return router
+}
+
+func TestBinaryUploadFlow(t *testing.T) {
+	mockService := usecase.NewBinaryUsecaseMock()
+	router := newBinaryRouter(mockService)
+
+	upload := &models.Upload{File: models.File{Id: 5, Name: "key.pem", ChunkCount: 2}, Received: []int{0}}
+	mockService.On("StartUpload", mock.Anything, &models.UploadRequest{Name: "key.pem", Size: 10, ChunkSize: 8, ChunkCount: 2, UserID: 1}).Return(upload, nil)
+	mockService.On("PutChunk", mock.Anything, 5, 1, 1, "encrypted").Return(nil)
+	mockService.On("CompleteUpload", mock.Anything, 5, 1).Return(&models.File{Id: 5, Name: "key.pem", Completed: true}, nil)
+
+	body, _ := json.Marshal(models.UploadRequest{Name: "key.pem", Size: 10, ChunkSize: 8, ChunkCount: 2})
+	req, _ := http.NewRequest(http.MethodPost, "/api/binaries/uploads", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got models.Upload
+	json.Unmarshal(w.Body.Bytes(), &got)
+	assert.Equal(t, []int{0}, got.Received)
+
+	req, _ = http.NewRequest(http.MethodPut, "/api/binaries/uploads/5/chunks/1", strings.NewReader("encrypted"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ = http.NewRequest(http.MethodPost, "/api/binaries/uploads/5/complete", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestBinaryCompleteIncomplete(t *testing.T) {
+	mockService := usecase.NewBinaryUsecaseMock()
+	router := newBinaryRouter(mockService)
+
+	mockService.On("CompleteUpload", mock.Anything, 5, 1).Return(nil, domain.ErrUploadIncomplete)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/binaries/uploads/5/complete", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestBinaryDownloadChunk(t *testing.T) {
+	mockService := usecase.NewBinaryUsecaseMock()
+	router := newBinaryRouter(mockService)
+
+	mockService.On("GetChunk", mock.Anything, "key.pem", 1, 0).Return(io.NopCloser(strings.NewReader("encrypted")), nil)
+	mockService.On("GetChunk", mock.Anything, "key.pem", 1, 9).Return(nil, domain.ErrChunkOutOfRange)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/binaries/key.pem/chunks/0", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "encrypted", w.Body.String())
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/binaries/key.pem/chunks/9", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
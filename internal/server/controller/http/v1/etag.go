@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/lockbox/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lockBoxETag — сильный валидатор записи: id и версия. Версия растёт при
+// каждом изменении, id отличает запись, заново созданную под тем же именем.
+func lockBoxETag(lock *models.Data) string {
+	return fmt.Sprintf(`"%d-%d"`, lock.Id, lock.Revision)
+}
+
+// lockBoxesETag — валидатор списка записей: хеш пар id и версии. Меняется
+// при правке, добавлении и удалении любой записи.
+func lockBoxesETag(locks []models.Data) string {
+	h := sha256.New()
+	for _, lock := range locks {
+		fmt.Fprintf(h, "%d-%d;", lock.Id, lock.Revision)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// setValidators выставляет ETag и Last-Modified ответа.
+func setValidators(ctx *gin.Context, etag string, modified time.Time) {
+	ctx.Header("ETag", etag)
+	if !modified.IsZero() {
+		ctx.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified отвечает 304, если валидатор из If-None-Match совпал с etag.
+// Сравнение слабое, как требует RFC 9110: префикс W/ не учитывается.
+func notModified(ctx *gin.Context, etag string) bool {
+	header := ctx.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			ctx.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch — условие If-Match запроса. Без заголовка present ложно. «*»
+// задаётся нулевыми id и revision. Валидатор чужого формата, слабый или
+// список валидаторов не совпадают ни с одной записью: ok ложно.
+type ifMatch struct {
+	present  bool
+	ok       bool
+	id       int
+	revision int
+}
+
+func parseIfMatch(ctx *gin.Context) ifMatch {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		return ifMatch{}
+	}
+	if header == "*" {
+		return ifMatch{present: true, ok: true}
+	}
+	id, revision, found := strings.Cut(strings.Trim(header, `"`), "-")
+	match := ifMatch{present: true}
+	if !found || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return match
+	}
+	var err1, err2 error
+	match.id, err1 = strconv.Atoi(id)
+	match.revision, err2 = strconv.Atoi(revision)
+	match.ok = err1 == nil && err2 == nil && match.id > 0 && match.revision > 0
+	return match
+}
+
+// conditionalError отвечает на ошибку условной операции: запись изменилась
+// или её больше нет — условие If-Match не выполнено.
+func conditionalError(ctx *gin.Context, err error) {
+	if errors.Is(err, domain.ErrLockBoxConflict) || errors.Is(err, domain.ErrLockBoxNotFound) {
+		preconditionFailed(ctx)
+		return
+	}
+	ctx.JSON(lockBoxErrorStatus(err), gin.H{"error": err.Error()})
+}
+
+func preconditionFailed(ctx *gin.Context) {
+	ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": domain.ErrPreconditionFailed.Error()})
+}
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/events"
+	"gophKeeper/util"
+	"io"
+	"net/http"
+	"time"
+)
+
+// eventsHeartbeat — как часто в тихий поток уходит комментарий: прокси не
+// закрывают соединение, а клиент замечает обрыв. Перед ним же проверяется,
+// не отозвана ли сессия.
+var eventsHeartbeat = 15 * time.Second
+
+type EventsHandler struct {
+	config *config.Config
+	hub    *events.Hub
+	mware  middleware.IMiddlewareService
+}
+
+// NewEventsHandler регистрирует поток Server-Sent Events, которым сервер
+// сообщает устройствам пользователя об изменениях его записей.
+func NewEventsHandler(config *config.Config, router *gin.RouterGroup, hub *events.Hub, mware middleware.IMiddlewareService) {
+	handler := EventsHandler{
+		config: config,
+		hub:    hub,
+		mware:  mware,
+	}
+
+	router.GET("/events", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), handler.stream)
+}
+
+// stream держит поток событий пользователя: сначала ready, затем lockbox с
+// номером изменения в ленте. Событие не несёт данных записи — клиент
+// забирает их синхронизацией со своего курсора. Поток закрывается, когда
+// истекает access-токен или отзывается сессия: клиент переподключится с
+// новым токеном.
+func (h *EventsHandler) stream(ctx *gin.Context) {
+	userId, sessionId := ctx.GetInt("userId"), ctx.GetInt("sessionId")
+	updates, cancel := h.hub.Subscribe(userId)
+	defer cancel()
+
+	var expired <-chan time.Time
+	if expiresAt := ctx.GetTime("tokenExpiresAt"); !expiresAt.IsZero() {
+		timer := time.NewTimer(time.Until(expiresAt))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	// Поток живёт дольше WriteTimeout сервера.
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.SSEvent("ready", gin.H{})
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-updates:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent("lockbox", event)
+			return true
+		case <-heartbeat.C:
+			if !h.mware.SessionActive(ctx.Request.Context(), userId, sessionId) {
+				return false
+			}
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case <-expired:
+			return false
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/events"
+	"gophKeeper/util"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventsStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	heartbeat := eventsHeartbeat
+	eventsHeartbeat = 20 * time.Millisecond
+	defer func() { eventsHeartbeat = heartbeat }()
+
+	// openStream подключается к потоку пользователя 3 с токеном, истекающим
+	// в expiresAt, и возвращает чтение следующего события.
+	openStream := func(t *testing.T, hub *events.Hub, expiresAt time.Time, active ...bool) func() (string, bool) {
+		mockMiddleware := new(middleware.MockMiddlewareService)
+		mockMiddleware.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+			ctx.Set("userId", 3)
+			ctx.Set("sessionId", 8)
+			ctx.Set("tokenExpiresAt", expiresAt)
+			ctx.Next()
+		}))
+		mockMiddleware.On("AuthorizeRoles", []string{util.Admin, util.Attendee}).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+			ctx.Next()
+		}))
+		for _, a := range active {
+			mockMiddleware.On("SessionActive", mock.Anything, 3, 8).Return(a).Once()
+		}
+		mockMiddleware.On("SessionActive", mock.Anything, 3, 8).Return(true).Maybe()
+
+		router := gin.New()
+		NewEventsHandler(&config.Config{}, router.Group("/api"), hub, mockMiddleware)
+		ts := httptest.NewServer(router)
+		t.Cleanup(ts.Close)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/events", nil)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		lines := bufio.NewScanner(resp.Body)
+		return func() (string, bool) {
+			var event []string
+			for lines.Scan() {
+				switch line := lines.Text(); {
+				case line == "" && len(event) > 0:
+					return strings.Join(event, "\n"), true
+				case line == "", strings.HasPrefix(line, ":"):
+				default:
+					event = append(event, line)
+				}
+			}
+			return "", false
+		}
+	}
+
+	t.Run("should deliver own events", func(t *testing.T) {
+		hub := events.NewHub()
+		next := openStream(t, hub, time.Now().Add(time.Minute))
+		event, _ := next()
+		assert.Equal(t, "event:ready\ndata:{}", event)
+
+		// Событие другого пользователя в поток не попадает.
+		hub.Publish(4, events.Event{Seq: 9})
+		hub.Publish(3, events.Event{Seq: 5})
+		event, _ = next()
+		assert.Equal(t, "event:lockbox\ndata:{\"seq\":5}", event)
+	})
+
+	t.Run("should close when token expires", func(t *testing.T) {
+		next := openStream(t, events.NewHub(), time.Now().Add(50*time.Millisecond))
+		next()
+		_, open := next()
+		assert.False(t, open)
+	})
+
+	t.Run("should close when session is revoked", func(t *testing.T) {
+		next := openStream(t, events.NewHub(), time.Now().Add(time.Minute), true, false)
+		next()
+		_, open := next()
+		assert.False(t, open)
+	})
+}
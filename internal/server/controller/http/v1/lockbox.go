@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"gophKeeper/internal/server/config"
 	"gophKeeper/internal/server/domain"
@@ -10,6 +11,8 @@ import (
 	"gophKeeper/util"
 	"log"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type LockBoxHandler struct {
@@ -33,8 +36,22 @@ func NewLockBoxHandlerHandler(config *config.Config, router *gin.RouterGroup, lo
 		lockBoxRouter.GET("/", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.getLockBoxes)
 		lockBoxRouter.POST("/create/update", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.createOrUpdateLockBox)
 		lockBoxRouter.PUT("/", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.updateLockBox)
+		lockBoxRouter.GET("/index/:index", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.getLockBoxByIndex)
+		lockBoxRouter.GET("/id/:id", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.getLockBoxByID)
+		lockBoxRouter.PUT("/id/:id", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.updateLockBoxByID)
+		lockBoxRouter.DELETE("/id/:id", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.deleteLockBoxByID)
+		// gin требует одинаковое имя параметра в одном сегменте пути, поэтому
+		// id записи здесь приходит в :name.
+		lockBoxRouter.GET("/:name/history", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.getHistory)
+		lockBoxRouter.POST("/:name/history/:revision/restore", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.restoreRevision)
 
 	}
+	trashRouter := router.Group("/trash")
+	{
+		trashRouter.GET("/", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.getTrash)
+		trashRouter.POST("/:id/restore", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.restoreFromTrash)
+		trashRouter.DELETE("/", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), lockBoxHandler.emptyTrash)
+	}
 }
 
 func (l *LockBoxHandler) createLockBox(ctx *gin.Context) {
@@ -46,7 +63,7 @@ func (l *LockBoxHandler) createLockBox(ctx *gin.Context) {
 	}
 	userId := ctx.GetInt("userId")
 	data.UserID = userId
-	id, err := l.lockBoxService.CreateLock(ctx, &data)
+	id, err := l.lockBoxService.CreateLock(clientContext(ctx), &data)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -54,11 +71,26 @@ func (l *LockBoxHandler) createLockBox(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, gin.H{"id": id})
 }
 
+// deleteLockBox удаляет запись по имени. С If-Match удаление условное.
+// Без него запись удаляется безусловно: это поведение старых клиентов,
+// оставленное намеренно, — удалённое попадает в корзину и восстанавливается.
 func (l *LockBoxHandler) deleteLockBox(ctx *gin.Context) {
 	name := ctx.Param("name")
 
 	userId := ctx.GetInt("userId")
-	err := l.lockBoxService.DeleteLock(ctx, name, userId)
+	if match := parseIfMatch(ctx); match.present {
+		if !match.ok {
+			preconditionFailed(ctx)
+			return
+		}
+		if err := l.lockBoxService.DeleteLockIfRevision(clientContext(ctx), name, match.id, match.revision, userId); err != nil {
+			conditionalError(ctx, err)
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+	err := l.lockBoxService.DeleteLock(clientContext(ctx), name, userId)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -66,6 +98,9 @@ func (l *LockBoxHandler) deleteLockBox(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// updateLockBox меняет запись по имени. Без If-Match версия берётся из
+// поля revision тела; без неё или при устаревшей версии ответ — 409 с
+// текущей записью, слепой перезаписи нет.
 func (l *LockBoxHandler) updateLockBox(ctx *gin.Context) {
 	userId := ctx.GetInt("userId")
 	var data models.Data
@@ -75,8 +110,17 @@ func (l *LockBoxHandler) updateLockBox(ctx *gin.Context) {
 		return
 	}
 	data.UserID = userId
-	log.Println(data)
-	err := l.lockBoxService.UpdateLock(ctx, &data)
+	if match := parseIfMatch(ctx); match.present {
+		if l.updateLockBoxIfMatch(ctx, &data, match) {
+			ctx.Status(http.StatusOK)
+		}
+		return
+	}
+	err := l.lockBoxService.UpdateLock(clientContext(ctx), &data)
+	if errors.Is(err, domain.ErrLockBoxConflict) {
+		l.lockBoxConflict(ctx, &data)
+		return
+	}
 	if err != nil {
 		log.Println(err.Error())
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -85,23 +129,43 @@ func (l *LockBoxHandler) updateLockBox(ctx *gin.Context) {
 	ctx.Status(http.StatusOK)
 
 }
+
+// updateLockBoxIfMatch меняет запись по имени при условии If-Match и
+// выставляет новый ETag. При ошибке отвечает сам и возвращает false.
+func (l *LockBoxHandler) updateLockBoxIfMatch(ctx *gin.Context, data *models.Data, match ifMatch) bool {
+	if !match.ok {
+		preconditionFailed(ctx)
+		return false
+	}
+	data.Id, data.Revision = match.id, match.revision
+	if err := l.lockBoxService.UpdateLockIfRevision(clientContext(ctx), data); err != nil {
+		conditionalError(ctx, err)
+		return false
+	}
+	ctx.Header("ETag", lockBoxETag(data))
+	return true
+}
+
 func (l *LockBoxHandler) getLockBox(ctx *gin.Context) {
 	name := ctx.Param("name")
 
 	userId := ctx.GetInt("userId")
-	lockBox, err := l.lockBoxService.GetLockByName(ctx, name, userId)
+	lockBox, err := l.lockBoxService.GetLockByName(clientContext(ctx), name, userId)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
-	log.Println(lockBox)
+	setValidators(ctx, lockBoxETag(lockBox), lockBox.UpdatedAt)
+	if notModified(ctx, lockBoxETag(lockBox)) {
+		return
+	}
 	ctx.JSON(http.StatusOK, lockBox)
 }
 
 func (l *LockBoxHandler) getLockBoxes(ctx *gin.Context) {
 
 	userId := ctx.GetInt("userId")
-	lockBoxes, err := l.lockBoxService.GetAllLocks(ctx, userId)
+	lockBoxes, err := l.lockBoxService.GetAllLocks(clientContext(ctx), userId)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -110,10 +174,24 @@ func (l *LockBoxHandler) getLockBoxes(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": domain.ErrLockBoxNotFound.Error()})
 		return
 	}
+	var modified time.Time
+	for _, lock := range *lockBoxes {
+		if lock.UpdatedAt.After(modified) {
+			modified = lock.UpdatedAt
+		}
+	}
+	etag := lockBoxesETag(*lockBoxes)
+	setValidators(ctx, etag, modified)
+	if notModified(ctx, etag) {
+		return
+	}
 	ctx.JSON(http.StatusOK, lockBoxes)
 
 }
 
+// createOrUpdateLockBox создаёт запись или меняет существующую. Как и в
+// updateLockBox, существующая запись без If-Match меняется только при
+// совпадении revision из тела, иначе — 409 с текущей записью.
 func (l *LockBoxHandler) createOrUpdateLockBox(ctx *gin.Context) {
 	var data models.Data
 	if err := ctx.ShouldBindJSON(&data); err != nil {
@@ -122,10 +200,221 @@ func (l *LockBoxHandler) createOrUpdateLockBox(ctx *gin.Context) {
 	}
 	data.UserID = ctx.GetInt("userId")
 
-	id, err := l.lockBoxService.CreateOrUpdateLock(ctx, &data)
+	// С If-Match запись только обновляется: условие к несуществующей
+	// записи не выполняется. Версии не сохраняются, как и без условия.
+	if match := parseIfMatch(ctx); match.present {
+		data.NoHistory = true
+		if l.updateLockBoxIfMatch(ctx, &data, match) {
+			ctx.JSON(http.StatusOK, gin.H{"id": data.Id})
+		}
+		return
+	}
+	id, err := l.lockBoxService.CreateOrUpdateLock(clientContext(ctx), &data)
+	if errors.Is(err, domain.ErrLockBoxConflict) {
+		l.lockBoxConflict(ctx, &data)
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"id": id})
 }
+
+// lockBoxConflict отвечает 409 вместе с текущей версией записи, чтобы
+// клиент мог разрешить конфликт.
+func (l *LockBoxHandler) lockBoxConflict(ctx *gin.Context, data *models.Data) {
+	var current *models.Data
+	var err error
+	switch {
+	case data.Id != 0:
+		current, err = l.lockBoxService.GetLockByID(clientContext(ctx), data.Id, data.UserID)
+	case data.NameIndex != "":
+		current, err = l.lockBoxService.GetLockByIndex(clientContext(ctx), data.NameIndex, data.UserID)
+	default:
+		current, err = l.lockBoxService.GetLockByName(clientContext(ctx), data.Name, data.UserID)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusConflict, gin.H{"error": domain.ErrLockBoxConflict.Error()})
+		return
+	}
+	ctx.JSON(http.StatusConflict, gin.H{"error": domain.ErrLockBoxConflict.Error(), "current": current})
+}
+
+// lockBoxErrorStatus переводит ошибки адресации по id и индексу в HTTP-статусы.
+func lockBoxErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrLockBoxNotFound), errors.Is(err, domain.ErrRevisionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrLockBoxExists), errors.Is(err, domain.ErrLockBoxConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrNameEmpty):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (l *LockBoxHandler) getLockBoxByIndex(ctx *gin.Context) {
+	lockBox, err := l.lockBoxService.GetLockByIndex(clientContext(ctx), ctx.Param("index"), ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(lockBoxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	setValidators(ctx, lockBoxETag(lockBox), lockBox.UpdatedAt)
+	if notModified(ctx, lockBoxETag(lockBox)) {
+		return
+	}
+	ctx.JSON(http.StatusOK, lockBox)
+}
+
+func (l *LockBoxHandler) getLockBoxByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	lockBox, err := l.lockBoxService.GetLockByID(clientContext(ctx), id, ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(lockBoxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	setValidators(ctx, lockBoxETag(lockBox), lockBox.UpdatedAt)
+	if notModified(ctx, lockBoxETag(lockBox)) {
+		return
+	}
+	ctx.JSON(http.StatusOK, lockBox)
+}
+
+func (l *LockBoxHandler) updateLockBoxByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var data models.Data
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data.Id = id
+	data.UserID = ctx.GetInt("userId")
+
+	// If-Match заменяет версию из тела; несовпадение — 412 вместо 409.
+	match := parseIfMatch(ctx)
+	if match.present {
+		if !match.ok || (match.id != 0 && match.id != id) {
+			preconditionFailed(ctx)
+			return
+		}
+		data.Revision = match.revision
+		if err := l.lockBoxService.UpdateLockByID(clientContext(ctx), &data); err != nil {
+			conditionalError(ctx, err)
+			return
+		}
+		ctx.Header("ETag", lockBoxETag(&data))
+		ctx.JSON(http.StatusOK, gin.H{"revision": data.Revision})
+		return
+	}
+	if err := l.lockBoxService.UpdateLockByID(clientContext(ctx), &data); err != nil {
+		if errors.Is(err, domain.ErrLockBoxConflict) {
+			l.lockBoxConflict(ctx, &data)
+			return
+		}
+		ctx.JSON(lockBoxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("ETag", lockBoxETag(&data))
+	ctx.JSON(http.StatusOK, gin.H{"revision": data.Revision})
+}
+
+// deleteLockBoxByID удаляет запись по id; без If-Match — безусловно, как
+// и deleteLockBox.
+func (l *LockBoxHandler) deleteLockBoxByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if match := parseIfMatch(ctx); match.present {
+		if !match.ok || (match.id != 0 && match.id != id) {
+			preconditionFailed(ctx)
+			return
+		}
+		if err := l.lockBoxService.DeleteLockIfRevision(clientContext(ctx), "", id, match.revision, ctx.GetInt("userId")); err != nil {
+			conditionalError(ctx, err)
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+	if err := l.lockBoxService.DeleteLockByID(clientContext(ctx), id, ctx.GetInt("userId")); err != nil {
+		ctx.JSON(lockBoxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getHistory отдаёт прежние версии записи, новые первыми.
+func (l *LockBoxHandler) getHistory(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("name"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	revisions, err := l.lockBoxService.GetHistory(clientContext(ctx), id, ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(lockBoxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, revisions)
+}
+
+// restoreRevision возвращает записи содержимое выбранной версии.
+func (l *LockBoxHandler) restoreRevision(ctx *gin.Context) {
+	id, err1 := strconv.Atoi(ctx.Param("name"))
+	revisionId, err2 := strconv.Atoi(ctx.Param("revision"))
+	if err1 != nil || err2 != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := l.lockBoxService.RestoreRevision(clientContext(ctx), id, revisionId, ctx.GetInt("userId")); err != nil {
+		ctx.JSON(lockBoxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getTrash отдаёт удалённые записи, которые ещё не удалены окончательно.
+func (l *LockBoxHandler) getTrash(ctx *gin.Context) {
+	locks, err := l.lockBoxService.GetTrash(clientContext(ctx), ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(lockBoxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, locks)
+}
+
+// restoreFromTrash возвращает запись из корзины.
+func (l *LockBoxHandler) restoreFromTrash(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := l.lockBoxService.RestoreFromTrash(clientContext(ctx), id, ctx.GetInt("userId")); err != nil {
+		ctx.JSON(lockBoxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// emptyTrash окончательно удаляет всё содержимое корзины.
+func (l *LockBoxHandler) emptyTrash(ctx *gin.Context) {
+	purged, err := l.lockBoxService.EmptyTrash(clientContext(ctx), ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(lockBoxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"purged": purged})
+}
@@ -2,18 +2,20 @@ package v1
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
 	"gophKeeper/internal/server/middleware"
 	"gophKeeper/internal/server/services/lockbox/models"
 	"gophKeeper/internal/server/services/lockbox/usecase"
+	"gophKeeper/util"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestCreateLockBox(t *testing.T) {
@@ -91,13 +93,16 @@ func TestGetLockBox(t *testing.T) {
 	}
 
 	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	})
 	router.GET("/lock_boxes/:name", handler.getLockBox)
 
 	t.Run("should get lockbox successfully", func(t *testing.T) {
 		mockService.On("GetLockByName", mock.Anything, "testBox", 1).Return(&models.Data{Name: "testBox", UserID: 1}, nil)
 
 		req, _ := http.NewRequest(http.MethodGet, "/lock_boxes/testBox", nil)
-		req = req.WithContext(context.WithValue(req.Context(), "userId", 1))
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
 
@@ -106,3 +111,380 @@ func TestGetLockBox(t *testing.T) {
 	})
 
 }
+
+func TestLockBoxByIndexAndID(t *testing.T) {
+	mockService := usecase.NewLockBoxUsecaseMock()
+	mockConfig := &config.Config{}
+
+	handler := LockBoxHandler{
+		config:         mockConfig,
+		lockBoxService: mockService,
+	}
+
+	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	})
+	router.GET("/lock_boxes/index/:index", handler.getLockBoxByIndex)
+	router.PUT("/lock_boxes/id/:id", handler.updateLockBoxByID)
+	router.DELETE("/lock_boxes/id/:id", handler.deleteLockBoxByID)
+
+	t.Run("should get lockbox by index", func(t *testing.T) {
+		mockService.On("GetLockByIndex", mock.Anything, "abc", 1).Return(&models.Data{Id: 7, Name: "enc", NameIndex: "abc"}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/lock_boxes/index/abc", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":7`)
+	})
+
+	t.Run("should return 404 for unknown index", func(t *testing.T) {
+		mockService.On("GetLockByIndex", mock.Anything, "missing", 1).Return(nil, domain.ErrLockBoxNotFound)
+
+		req, _ := http.NewRequest(http.MethodGet, "/lock_boxes/index/missing", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should update lockbox by id", func(t *testing.T) {
+		mockService.On("UpdateLockByID", mock.Anything, mock.MatchedBy(func(data *models.Data) bool {
+			return data.Id == 7 && data.UserID == 1 && data.NameIndex == "abc"
+		})).Return(nil)
+
+		body, _ := json.Marshal(models.Data{Name: "enc", NameIndex: "abc"})
+		req, _ := http.NewRequest(http.MethodPut, "/lock_boxes/id/7", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should reject stale update with current version", func(t *testing.T) {
+		mockService.On("UpdateLockByID", mock.Anything, mock.MatchedBy(func(data *models.Data) bool {
+			return data.Id == 8 && data.Revision == 2
+		})).Return(domain.ErrLockBoxConflict)
+		mockService.On("GetLockByID", mock.Anything, 8, 1).Return(&models.Data{Id: 8, Name: "enc", Revision: 3}, nil)
+
+		body, _ := json.Marshal(models.Data{Name: "enc", Password: "mine", Revision: 2})
+		req, _ := http.NewRequest(http.MethodPut, "/lock_boxes/id/8", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), `"revision":3`)
+	})
+
+	t.Run("should delete lockbox by id", func(t *testing.T) {
+		mockService.On("DeleteLockByID", mock.Anything, 7, 1).Return(nil)
+
+		req, _ := http.NewRequest(http.MethodDelete, "/lock_boxes/id/7", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should reject non-numeric id", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodDelete, "/lock_boxes/id/abc", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+// TestLockBoxStaleWrite проверяет, что запись без текущей версии не
+// перезаписывается: ответ 409 несёт текущую версию.
+func TestLockBoxStaleWrite(t *testing.T) {
+	mockService := usecase.NewLockBoxUsecaseMock()
+	handler := LockBoxHandler{config: &config.Config{}, lockBoxService: mockService}
+
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	})
+	router.POST("/lock_boxes/create/update", handler.createOrUpdateLockBox)
+	router.PUT("/lock_boxes/", handler.updateLockBox)
+
+	current := &models.Data{Id: 7, Name: "mail", Revision: 4}
+	mockService.On("GetLockByName", mock.Anything, "mail", 1).Return(current, nil)
+
+	t.Run("should reject create or update without revision", func(t *testing.T) {
+		mockService.On("CreateOrUpdateLock", mock.Anything, mock.MatchedBy(func(data *models.Data) bool {
+			return data.Name == "mail" && data.Revision == 0
+		})).Return(0, domain.ErrLockBoxConflict).Once()
+
+		body, _ := json.Marshal(models.Data{Name: "mail", Url: "new"})
+		req, _ := http.NewRequest(http.MethodPost, "/lock_boxes/create/update", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), `"revision":4`)
+	})
+
+	t.Run("should reject update of stale revision", func(t *testing.T) {
+		mockService.On("UpdateLock", mock.Anything, mock.MatchedBy(func(data *models.Data) bool {
+			return data.Name == "mail" && data.Revision == 3
+		})).Return(domain.ErrLockBoxConflict).Once()
+
+		body, _ := json.Marshal(models.Data{Name: "mail", Url: "new", Revision: 3})
+		req, _ := http.NewRequest(http.MethodPut, "/lock_boxes/", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), `"current"`)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestLockBoxConditionalRequests(t *testing.T) {
+	mockService := usecase.NewLockBoxUsecaseMock()
+	mockConfig := &config.Config{}
+
+	handler := LockBoxHandler{
+		config:         mockConfig,
+		lockBoxService: mockService,
+	}
+
+	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	})
+	router.GET("/lock_boxes/id/:id", handler.getLockBoxByID)
+	router.PUT("/lock_boxes/id/:id", handler.updateLockBoxByID)
+	router.PUT("/lock_boxes/", handler.updateLockBox)
+	router.DELETE("/lock_boxes/:name", handler.deleteLockBox)
+
+	updatedAt := time.Date(2025, 5, 12, 10, 0, 0, 0, time.UTC)
+	mockService.On("GetLockByID", mock.Anything, 7, 1).Return(&models.Data{Id: 7, Name: "mail", Revision: 3, UpdatedAt: updatedAt}, nil)
+
+	t.Run("should return validators", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/lock_boxes/id/7", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"7-3"`, w.Header().Get("ETag"))
+		assert.Equal(t, "Mon, 12 May 2025 10:00:00 GMT", w.Header().Get("Last-Modified"))
+	})
+
+	t.Run("should answer not modified", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/lock_boxes/id/7", nil)
+		req.Header.Set("If-None-Match", `W/"7-2", "7-3"`)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("should update by id when version matches", func(t *testing.T) {
+		mockService.On("UpdateLockByID", mock.Anything, mock.MatchedBy(func(data *models.Data) bool {
+			return data.Id == 7 && data.Revision == 3
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Data).Revision = 4
+		}).Return(nil).Once()
+
+		body, _ := json.Marshal(models.Data{Password: "new"})
+		req, _ := http.NewRequest(http.MethodPut, "/lock_boxes/id/7", bytes.NewReader(body))
+		req.Header.Set("If-Match", `"7-3"`)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"7-4"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("should fail precondition for stale version", func(t *testing.T) {
+		mockService.On("UpdateLockByID", mock.Anything, mock.MatchedBy(func(data *models.Data) bool {
+			return data.Id == 7 && data.Revision == 2
+		})).Return(domain.ErrLockBoxConflict).Once()
+
+		body, _ := json.Marshal(models.Data{Password: "new"})
+		req, _ := http.NewRequest(http.MethodPut, "/lock_boxes/id/7", bytes.NewReader(body))
+		req.Header.Set("If-Match", `"7-2"`)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("should fail precondition for another lockbox", func(t *testing.T) {
+		body, _ := json.Marshal(models.Data{Password: "new"})
+		req, _ := http.NewRequest(http.MethodPut, "/lock_boxes/id/7", bytes.NewReader(body))
+		req.Header.Set("If-Match", `"8-3"`)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("should update by name when version matches", func(t *testing.T) {
+		mockService.On("UpdateLockIfRevision", mock.Anything, mock.MatchedBy(func(data *models.Data) bool {
+			return data.Name == "mail" && data.Id == 7 && data.Revision == 3
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Data).Revision = 4
+		}).Return(nil).Once()
+
+		body, _ := json.Marshal(models.Data{Name: "mail", Password: "new"})
+		req, _ := http.NewRequest(http.MethodPut, "/lock_boxes/", bytes.NewReader(body))
+		req.Header.Set("If-Match", `"7-3"`)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"7-4"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("should fail precondition for malformed validator", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodDelete, "/lock_boxes/mail", nil)
+		req.Header.Set("If-Match", `W/"7-3"`)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("should delete by name when version matches", func(t *testing.T) {
+		mockService.On("DeleteLockIfRevision", mock.Anything, "mail", 7, 3, 1).Return(nil).Once()
+
+		req, _ := http.NewRequest(http.MethodDelete, "/lock_boxes/mail", nil)
+		req.Header.Set("If-Match", `"7-3"`)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestLockBoxHistory(t *testing.T) {
+	mockService := usecase.NewLockBoxUsecaseMock()
+	mockMiddleware := new(middleware.MockMiddlewareService)
+	mockMiddleware.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	}))
+	mockMiddleware.On("AuthorizeRoles", []string{util.Admin, util.Attendee}).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	router := gin.New()
+	NewLockBoxHandlerHandler(&config.Config{}, router.Group("/api"), mockService, mockMiddleware)
+
+	t.Run("should list revisions", func(t *testing.T) {
+		revisions := []models.Revision{{RevisionId: 3, LockBoxId: 7, Name: "enc", Password: "old"}}
+		mockService.On("GetHistory", mock.Anything, 7, 1).Return(revisions, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/lock_boxes/7/history", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"revision_id":3`)
+	})
+
+	t.Run("should restore revision", func(t *testing.T) {
+		mockService.On("RestoreRevision", mock.Anything, 7, 3, 1).Return(nil).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/lock_boxes/7/history/3/restore", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should report unknown revision", func(t *testing.T) {
+		mockService.On("RestoreRevision", mock.Anything, 7, 99, 1).Return(domain.ErrRevisionNotFound).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/lock_boxes/7/history/99/restore", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should reject non-numeric id", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/api/lock_boxes/work/history", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestLockBoxTrash(t *testing.T) {
+	mockService := usecase.NewLockBoxUsecaseMock()
+	mockMiddleware := new(middleware.MockMiddlewareService)
+	mockMiddleware.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	}))
+	mockMiddleware.On("AuthorizeRoles", []string{util.Admin, util.Attendee}).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	router := gin.New()
+	NewLockBoxHandlerHandler(&config.Config{}, router.Group("/api"), mockService, mockMiddleware)
+
+	t.Run("should list deleted lockboxes", func(t *testing.T) {
+		deletedAt := time.Now()
+		locks := []models.Data{{Id: 7, Name: "enc", DeletedAt: &deletedAt}}
+		mockService.On("GetTrash", mock.Anything, 1).Return(&locks, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/trash/", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":7`)
+	})
+
+	t.Run("should restore from trash", func(t *testing.T) {
+		mockService.On("RestoreFromTrash", mock.Anything, 7, 1).Return(nil).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/trash/7/restore", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should report lockbox missing from trash", func(t *testing.T) {
+		mockService.On("RestoreFromTrash", mock.Anything, 8, 1).Return(domain.ErrLockBoxNotFound).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/trash/8/restore", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should empty trash", func(t *testing.T) {
+		mockService.On("EmptyTrash", mock.Anything, 1).Return(int64(2), nil).Once()
+
+		req, _ := http.NewRequest(http.MethodDelete, "/api/trash/", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"purged":2}`, w.Body.String())
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/note/models"
+	"gophKeeper/internal/server/services/note/usecase"
+	"gophKeeper/util"
+	"net/http"
+)
+
+type NoteHandler struct {
+	config      *config.Config
+	noteService usecase.INoteUsecase
+	mware       middleware.IMiddlewareService
+}
+
+func NewNoteHandler(config *config.Config, router *gin.RouterGroup, noteService usecase.INoteUsecase, mware middleware.IMiddlewareService) {
+	noteHandler := NoteHandler{
+		config:      config,
+		noteService: noteService,
+		mware:       mware,
+	}
+
+	noteRouter := router.Group("/notes")
+	{
+		noteRouter.POST("/create", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), noteHandler.createNote)
+		noteRouter.DELETE("/:name", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), noteHandler.deleteNote)
+		noteRouter.GET("/:name", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), noteHandler.getNote)
+		noteRouter.GET("/", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), noteHandler.getNotes)
+		noteRouter.POST("/create/update", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), noteHandler.createOrUpdateNote)
+		noteRouter.PUT("/", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), noteHandler.updateNote)
+	}
+}
+
+func (h *NoteHandler) createNote(ctx *gin.Context) {
+	var note models.Note
+	if err := ctx.ShouldBindJSON(&note); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	note.UserID = ctx.GetInt("userId")
+
+	id, err := h.noteService.CreateNote(ctx, &note)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoteExists) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *NoteHandler) deleteNote(ctx *gin.Context) {
+	err := h.noteService.DeleteNote(ctx, ctx.Param("name"), ctx.GetInt("userId"))
+	if err != nil {
+		if errors.Is(err, domain.ErrNoteNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *NoteHandler) getNote(ctx *gin.Context) {
+	note, err := h.noteService.GetNoteByName(ctx, ctx.Param("name"), ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, note)
+}
+
+func (h *NoteHandler) getNotes(ctx *gin.Context) {
+	notes, err := h.noteService.GetAllNotes(ctx, ctx.GetInt("userId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, notes)
+}
+
+func (h *NoteHandler) updateNote(ctx *gin.Context) {
+	var note models.Note
+	if err := ctx.ShouldBindJSON(&note); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	note.UserID = ctx.GetInt("userId")
+
+	if err := h.noteService.UpdateNote(ctx, &note); err != nil {
+		if errors.Is(err, domain.ErrNoteNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+func (h *NoteHandler) createOrUpdateNote(ctx *gin.Context) {
+	var note models.Note
+	if err := ctx.ShouldBindJSON(&note); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	note.UserID = ctx.GetInt("userId")
+
+	id, err := h.noteService.CreateOrUpdateNote(ctx, &note)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"id": id})
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/note/models"
+	"gophKeeper/internal/server/services/note/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newNoteRouter(mockService *usecase.NoteUsecaseMock) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NoteHandler{
+		config:      &config.Config{},
+		noteService: mockService,
+	}
+
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	})
+	router.POST("/notes/create", handler.createNote)
+	router.POST("/notes/create/update", handler.createOrUpdateNote)
+	router.GET("/notes/:name", handler.getNote)
+	router.DELETE("/notes/:name", handler.deleteNote)
+	return router
+}
+
+func TestCreateNote(t *testing.T) {
+	mockService := usecase.NewNoteUsecaseMock()
+	router := newNoteRouter(mockService)
+
+	// Тело заметки больше прежнего лимита description в 1200 символов.
+	body := strings.Repeat("x", 5000)
+	mockService.On("CreateNote", mock.Anything, &models.Note{Name: "codes", Body: body, UserID: 1}).Return(3, nil)
+	mockService.On("CreateNote", mock.Anything, &models.Note{Name: "dup", Body: "b", UserID: 1}).Return(0, domain.ErrNoteExists)
+
+	payload, _ := json.Marshal(models.Note{Name: "codes", Body: body})
+	req, _ := http.NewRequest(http.MethodPost, "/notes/create", bytes.NewReader(payload))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	payload, _ = json.Marshal(models.Note{Name: "dup", Body: "b"})
+	req, _ = http.NewRequest(http.MethodPost, "/notes/create", bytes.NewReader(payload))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateOrUpdateNote(t *testing.T) {
+	mockService := usecase.NewNoteUsecaseMock()
+	router := newNoteRouter(mockService)
+
+	mockService.On("CreateOrUpdateNote", mock.Anything, &models.Note{Name: "codes", Body: "enc", UserID: 1}).Return(3, nil)
+
+	payload, _ := json.Marshal(models.Note{Name: "codes", Body: "enc"})
+	req, _ := http.NewRequest(http.MethodPost, "/notes/create/update", bytes.NewReader(payload))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":3}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAndDeleteNote(t *testing.T) {
+	mockService := usecase.NewNoteUsecaseMock()
+	router := newNoteRouter(mockService)
+
+	mockService.On("GetNoteByName", mock.Anything, "codes", 1).Return(&models.Note{Name: "codes", Body: "enc"}, nil)
+	mockService.On("DeleteNote", mock.Anything, "missing", 1).Return(domain.ErrNoteNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/notes/codes", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/notes/missing", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+}
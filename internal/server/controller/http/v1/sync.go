@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/lockbox/models"
+	"gophKeeper/internal/server/services/lockbox/usecase"
+	"gophKeeper/util"
+	"net/http"
+	"strconv"
+)
+
+type SyncHandler struct {
+	config         *config.Config
+	lockBoxService usecase.ILockBoxUsecase
+	mware          middleware.IMiddlewareService
+}
+
+// NewSyncHandler регистрирует разностную синхронизацию записей: клиент
+// забирает ленту изменений после своего курсора и отправляет пакетом только
+// то, что изменил сам.
+func NewSyncHandler(config *config.Config, router *gin.RouterGroup, lockBoxService usecase.ILockBoxUsecase, mware middleware.IMiddlewareService) {
+	handler := SyncHandler{
+		config:         config,
+		lockBoxService: lockBoxService,
+		mware:          mware,
+	}
+
+	syncRouter := router.Group("/sync")
+	{
+		syncRouter.GET("/changes", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), handler.getChanges)
+		syncRouter.POST("/push", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), handler.pushChanges)
+	}
+}
+
+// syncErrorStatus переводит ошибки синхронизации в HTTP-статусы.
+func syncErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCursor), errors.Is(err, domain.ErrInvalidIdempotencyKey):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrSyncBatchTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return lockBoxErrorStatus(err)
+	}
+}
+
+// getChanges отдаёт изменения после курсора: ?since=&after=&limit=.
+func (h *SyncHandler) getChanges(ctx *gin.Context) {
+	since, err1 := strconv.ParseInt(ctx.DefaultQuery("since", "0"), 10, 64)
+	after, err2 := strconv.Atoi(ctx.DefaultQuery("after", "0"))
+	limit, err3 := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+	if err1 != nil || err2 != nil || err3 != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidCursor.Error()})
+		return
+	}
+
+	set, err := h.lockBoxService.GetChanges(clientContext(ctx), ctx.GetInt("userId"), since, after, limit)
+	if err != nil {
+		ctx.JSON(syncErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, set)
+}
+
+// pushChanges применяет пакет изменений клиента. Если часть изменений
+// отклонена из-за правок с другого устройства, ответ — 409 с текущими
+// версиями этих записей; остальные изменения при этом применены.
+func (h *SyncHandler) pushChanges(ctx *gin.Context) {
+	var req struct {
+		Changes []models.Change `json:"changes"`
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.lockBoxService.PushChanges(clientContext(ctx), ctx.GetInt("userId"), req.Changes)
+	if err != nil {
+		applied := 0
+		if result != nil {
+			applied = result.Applied
+		}
+		ctx.JSON(syncErrorStatus(err), gin.H{"error": err.Error(), "applied": applied})
+		return
+	}
+	if len(result.Conflicts) > 0 {
+		ctx.JSON(http.StatusConflict, result)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/lockbox/models"
+	"gophKeeper/internal/server/services/lockbox/usecase"
+	"gophKeeper/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSyncHandler(t *testing.T) {
+	mockService := usecase.NewLockBoxUsecaseMock()
+	mockMiddleware := new(middleware.MockMiddlewareService)
+	mockMiddleware.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	}))
+	mockMiddleware.On("AuthorizeRoles", []string{util.Admin, util.Attendee}).Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Next()
+	}))
+
+	router := gin.New()
+	NewSyncHandler(&config.Config{}, router.Group("/api"), mockService, mockMiddleware)
+
+	t.Run("should return changes after cursor", func(t *testing.T) {
+		set := &models.ChangeSet{Cursor: 12, Changes: []models.Change{
+			{Seq: 11, Data: models.Data{Id: 7, Name: "mail"}},
+			{Seq: 12, Deleted: true, Data: models.Data{Id: 8, Name: "old"}},
+		}}
+		mockService.On("GetChanges", mock.Anything, 1, int64(10), 0, 0).Return(set, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/sync/changes?since=10", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"cursor":12`)
+		assert.Contains(t, w.Body.String(), `"deleted":true`)
+	})
+
+	t.Run("should page full snapshot after id", func(t *testing.T) {
+		set := &models.ChangeSet{Cursor: 30, Reset: true, More: true, After: 9, Changes: []models.Change{
+			{Seq: 21, Data: models.Data{Id: 9, Name: "bank"}},
+		}}
+		mockService.On("GetChanges", mock.Anything, 1, int64(3), 7, 1).Return(set, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/sync/changes?since=3&after=7&limit=1", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"after":9`)
+		assert.Contains(t, w.Body.String(), `"more":true`)
+	})
+
+	t.Run("should reject malformed cursor", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/api/sync/changes?since=abc", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should apply pushed batch", func(t *testing.T) {
+		changes := []models.Change{
+			{Data: models.Data{Name: "mail", Password: "enc"}},
+			{Deleted: true, Data: models.Data{Name: "old"}},
+		}
+		result := &models.PushResult{Applied: 2, Revisions: []int{3, 0}}
+		mockService.On("PushChanges", mock.Anything, 1, changes).Return(result, nil).Once()
+
+		body := `{"changes":[{"data":{"name":"mail","password":"enc"}},{"deleted":true,"data":{"name":"old"}}]}`
+		req, _ := http.NewRequest(http.MethodPost, "/api/sync/push", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"applied":2,"revisions":[3,0]}`, w.Body.String())
+	})
+
+	t.Run("should report conflicts with current versions", func(t *testing.T) {
+		result := &models.PushResult{
+			Applied:   0,
+			Revisions: []int{0},
+			Conflicts: []models.Conflict{{Index: 0, Data: models.Data{Id: 4, Name: "mail", Password: "theirs", Revision: 5}}},
+		}
+		mockService.On("PushChanges", mock.Anything, 1, mock.Anything).Return(result, nil).Once()
+
+		body := `{"changes":[{"data":{"name":"mail","password":"mine","revision":4}}]}`
+		req, _ := http.NewRequest(http.MethodPost, "/api/sync/push", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var got models.PushResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Len(t, got.Conflicts, 1)
+		assert.Equal(t, 5, got.Conflicts[0].Data.Revision)
+	})
+
+	t.Run("should reject oversized batch", func(t *testing.T) {
+		mockService.On("PushChanges", mock.Anything, 1, mock.Anything).Return(&models.PushResult{}, domain.ErrSyncBatchTooLarge).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/sync/push", bytes.NewBufferString(`{"changes":[]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
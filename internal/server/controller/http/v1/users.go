@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"gophKeeper/internal/server/config"
 	"gophKeeper/internal/server/domain"
@@ -8,7 +9,6 @@ import (
 	"gophKeeper/internal/server/services/users/models"
 	"gophKeeper/internal/server/services/users/usecase"
 	"net/http"
-	"strconv"
 )
 
 type UserHandler struct {
@@ -29,9 +29,12 @@ func NewUserHandler(config *config.Config, router *gin.RouterGroup, userService
 		//userRouter.GET("", userHandler.GetUsers)
 
 		userRouter.POST("/", middleware.RateLimiter(), userHandler.RegisterUser)
-		//userRouter.PUT("/:id", userHandler.UpdateUser)
-		//userRouter.DELETE("/:id", userHandler.DeleteUser)
-
+		userRouter.GET("/kdf", mware.MiddlewareJWT(), userHandler.GetKDFParams)
+		userRouter.PUT("/kdf", mware.MiddlewareJWT(), userHandler.SetKDFParams)
+		userRouter.GET("/me", mware.MiddlewareJWT(), userHandler.GetMe)
+		userRouter.PUT("/me/password", mware.MiddlewareJWT(), userHandler.ChangePassword)
+		userRouter.PUT("/me/username", mware.MiddlewareJWT(), userHandler.RenameUser)
+		userRouter.DELETE("/me", mware.MiddlewareJWT(), userHandler.DeleteUser)
 	}
 }
 
@@ -43,45 +46,123 @@ func (uh *UserHandler) RegisterUser(c *gin.Context) {
 	}
 
 	if err := uh.userService.CreateUser(c, &user); err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) || errors.Is(err, domain.ErrInvalidUserType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.Status(http.StatusCreated)
 }
-func (uh *UserHandler) UpdateUser(c *gin.Context) {
-	userId, err := strconv.Atoi(c.Param("id"))
+
+// accountError переводит ошибки управления аккаунтом в ответ. Неверный
+// пароль — 403, а не 401: токен действителен, и клиенту незачем его обновлять.
+func accountError(c *gin.Context, err error) {
+	if attemptsError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrSRPSessionExpired):
+		c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrInvalidCredentials.Error()})
+	case errors.Is(err, domain.ErrPasswordLogin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, domain.ErrNameEmpty):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrUsernameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (uh *UserHandler) GetMe(c *gin.Context) {
+	user, err := uh.userService.GetUserByID(c, c.GetInt("userId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidUserID.Error()})
+		accountError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, user)
+}
 
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// ChangePassword заменяет пароль. Текущий пароль подтверждается
+// доказательством SRP, поэтому ни старый, ни новый пароль сервер не получает.
+func (uh *UserHandler) ChangePassword(c *gin.Context) {
+	var req models.PasswordChange
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
 		return
 	}
-	user.UserId = userId
 
-	if err := uh.userService.UpdateUser(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := uh.userService.ChangePassword(clientContext(c), c.GetInt("userId"), c.GetInt("sessionId"), &req); err != nil {
+		accountError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (uh *UserHandler) RenameUser(c *gin.Context) {
+	var req models.UsernameChange
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
 		return
 	}
 
-	c.Status(http.StatusOK)
+	if err := uh.userService.RenameUser(clientContext(c), c.GetInt("userId"), c.GetInt("sessionId"), &req); err != nil {
+		accountError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
 }
 
+// DeleteUser удаляет аккаунт вместе со всеми записями и файлами.
 func (uh *UserHandler) DeleteUser(c *gin.Context) {
-	userId, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidUserID.Error()})
+	var req models.AccountDelete
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
 		return
 	}
 
-	if err := uh.userService.DeleteUser(userId); err != nil {
+	if err := uh.userService.DeleteUser(clientContext(c), c.GetInt("userId"), &req.Proof); err != nil {
+		accountError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (uh *UserHandler) GetKDFParams(c *gin.Context) {
+	params, err := uh.userService.GetKDFParams(c, c.GetInt("userId"))
+	if err != nil {
+		if errors.Is(err, domain.ErrKDFNotSet) || errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, params)
+}
 
+func (uh *UserHandler) SetKDFParams(c *gin.Context) {
+	var params models.KDFParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := uh.userService.SetKDFParams(c, c.GetInt("userId"), &params); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrKDFAlreadySet):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidKDF):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
 	c.Status(http.StatusNoContent)
 }
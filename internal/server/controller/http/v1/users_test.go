@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
 	"gophKeeper/internal/server/middleware"
 	"gophKeeper/internal/server/services/users/models"
 	"gophKeeper/internal/server/services/users/usecase"
@@ -21,6 +22,7 @@ func TestRegisterUser(t *testing.T) {
 
 	mockUsecase := new(usecase.UserUsecaseMock)
 	mockMiddleware := new(middleware.MockMiddlewareService)
+	mockMiddleware.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() }))
 
 	router := gin.Default()
 	apiGroup := router.Group("/api")
@@ -28,9 +30,10 @@ func TestRegisterUser(t *testing.T) {
 
 	t.Run("should register user successfully", func(t *testing.T) {
 		user := models.User{
-			Username: "testuser",
-			Password: "securepassword",
-			UserType: util.Attendee,
+			Username:    "testuser",
+			SRPSalt:     "aa",
+			SRPVerifier: "bb",
+			UserType:    util.Attendee,
 		}
 		mockUsecase.On("CreateUser", mock.Anything, mock.AnythingOfType("*models.User")).Return(nil)
 
@@ -56,3 +59,136 @@ func TestRegisterUser(t *testing.T) {
 	})
 
 }
+
+func TestKDFParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := new(usecase.UserUsecaseMock)
+	mockMiddleware := new(middleware.MockMiddlewareService)
+	mockMiddleware.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	}))
+
+	router := gin.New()
+	NewUserHandler(&config.Config{}, router.Group("/api"), mockUsecase, mockMiddleware)
+
+	params := &models.KDFParams{Salt: "c2FsdA==", Time: 3, Memory: 65536, Threads: 4, Check: "check"}
+
+	t.Run("should return params", func(t *testing.T) {
+		mockUsecase.On("GetKDFParams", mock.Anything, 1).Return(params, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/users/kdf", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var got models.KDFParams
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, *params, got)
+	})
+
+	t.Run("should return 404 when params are not set", func(t *testing.T) {
+		mockUsecase.On("GetKDFParams", mock.Anything, 1).Return(nil, domain.ErrKDFNotSet).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/users/kdf", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should return 409 when params are already set", func(t *testing.T) {
+		mockUsecase.On("SetKDFParams", mock.Anything, 1, params).Return(domain.ErrKDFAlreadySet).Once()
+
+		body, _ := json.Marshal(params)
+		req, _ := http.NewRequest(http.MethodPut, "/api/users/kdf", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("should save params", func(t *testing.T) {
+		mockUsecase.On("SetKDFParams", mock.Anything, 1, params).Return(nil).Once()
+
+		body, _ := json.Marshal(params)
+		req, _ := http.NewRequest(http.MethodPut, "/api/users/kdf", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := new(usecase.UserUsecaseMock)
+	mockMiddleware := new(middleware.MockMiddlewareService)
+	mockMiddleware.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Set("sessionId", 7)
+		ctx.Next()
+	}))
+
+	router := gin.New()
+	NewUserHandler(&config.Config{}, router.Group("/api"), mockUsecase, mockMiddleware)
+
+	send := func(method, path string, payload any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	proof := models.PasswordProof{Session: "s1", M1: "11"}
+
+	t.Run("should return current user", func(t *testing.T) {
+		mockUsecase.On("GetUserByID", mock.Anything, 1).
+			Return(&models.User{UserId: 1, Username: "alice", UserType: util.Attendee}, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/users/me", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"username":"alice"`)
+	})
+
+	t.Run("should change password", func(t *testing.T) {
+		change := &models.PasswordChange{Proof: proof, SRPSalt: "aa", SRPVerifier: "bb"}
+		mockUsecase.On("ChangePassword", mock.Anything, 1, 7, change).Return(nil).Once()
+
+		w := send(http.MethodPut, "/api/users/me/password", change)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should reject wrong current password", func(t *testing.T) {
+		change := &models.PasswordChange{Proof: models.PasswordProof{Password: "wrong"}, SRPSalt: "aa", SRPVerifier: "bb"}
+		mockUsecase.On("ChangePassword", mock.Anything, 1, 7, change).Return(domain.ErrInvalidCredentials).Once()
+
+		w := send(http.MethodPut, "/api/users/me/password", change)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should report taken username", func(t *testing.T) {
+		rename := &models.UsernameChange{Proof: proof, Username: "bob", SRPSalt: "aa", SRPVerifier: "bb"}
+		mockUsecase.On("RenameUser", mock.Anything, 1, 7, rename).Return(domain.ErrUsernameTaken).Once()
+
+		w := send(http.MethodPut, "/api/users/me/username", rename)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("should delete account", func(t *testing.T) {
+		mockUsecase.On("DeleteUser", mock.Anything, 1, &proof).Return(nil).Once()
+
+		w := send(http.MethodDelete, "/api/users/me", &models.AccountDelete{Proof: proof})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
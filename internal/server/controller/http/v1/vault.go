@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	"gophKeeper/internal/server/services/vault/models"
+	"gophKeeper/internal/server/services/vault/usecase"
+	"gophKeeper/util"
+	"net/http"
+)
+
+type VaultHandler struct {
+	config       *config.Config
+	vaultService usecase.IVaultUsecase
+	mware        middleware.IMiddlewareService
+}
+
+func NewVaultHandler(config *config.Config, router *gin.RouterGroup, vaultService usecase.IVaultUsecase, mware middleware.IMiddlewareService) {
+	vaultHandler := VaultHandler{
+		config:       config,
+		vaultService: vaultService,
+		mware:        mware,
+	}
+
+	vaultRouter := router.Group("/vault")
+	{
+		vaultRouter.POST("/rotate", mware.MiddlewareJWT(), mware.AuthorizeRoles(util.Admin, util.Attendee), vaultHandler.rotateKey)
+	}
+}
+
+func (h *VaultHandler) rotateKey(ctx *gin.Context) {
+	var req models.RotateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.vaultService.RotateKey(ctx, ctx.GetInt("userId"), &req); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrStaleKey), errors.Is(err, domain.ErrRotationIncomplete):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidKDF), errors.Is(err, domain.ErrInvalidUpload), errors.Is(err, domain.ErrUploadIncomplete):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
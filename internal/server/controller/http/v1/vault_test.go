@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/middleware"
+	usersmodels "gophKeeper/internal/server/services/users/models"
+	"gophKeeper/internal/server/services/vault/models"
+	"gophKeeper/internal/server/services/vault/usecase"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRotateKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := usecase.NewVaultUsecaseMock()
+	mockMiddleware := new(middleware.MockMiddlewareService)
+	mockMiddleware.On("MiddlewareJWT").Return(gin.HandlerFunc(func(ctx *gin.Context) {
+		ctx.Set("userId", 1)
+		ctx.Next()
+	}))
+	mockMiddleware.On("AuthorizeRoles", mock.Anything).Return(gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() }))
+
+	router := gin.New()
+	NewVaultHandler(&config.Config{}, router.Group("/api"), mockService, mockMiddleware)
+
+	req := models.RotateRequest{
+		OldCheck:  "old",
+		KDF:       usersmodels.KDFParams{Salt: "c2FsdA==", Time: 3, Memory: 65536, Threads: 4, Check: "new"},
+		LockBoxes: []models.LockBox{{Id: 1, Name: "box", Password: "enc"}},
+	}
+	body, _ := json.Marshal(req)
+
+	t.Run("should rotate key", func(t *testing.T) {
+		mockService.On("RotateKey", mock.Anything, 1, &req).Return(nil).Once()
+
+		r, _ := http.NewRequest(http.MethodPost, "/api/vault/rotate", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("should return 409 on stale key", func(t *testing.T) {
+		mockService.On("RotateKey", mock.Anything, 1, &req).Return(domain.ErrStaleKey).Once()
+
+		r, _ := http.NewRequest(http.MethodPost, "/api/vault/rotate", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("should return 400 on invalid JSON", func(t *testing.T) {
+		r, _ := http.NewRequest(http.MethodPost, "/api/vault/rotate", bytes.NewBufferString("{invalid"))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS bank_card
+(
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(1000) NOT NULL,
+    number      VARCHAR(1000),
+    holder      VARCHAR(1000),
+    expiry      VARCHAR(1000),
+    cvv         VARCHAR(1000),
+    pin         VARCHAR(1000),
+    notes       VARCHAR(2000),
+    user_id     INT REFERENCES users (user_id) ON DELETE CASCADE,
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    CONSTRAINT unique_bank_card_name_per_user UNIQUE (name, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS bank_card;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS note
+(
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(1000) NOT NULL,
+    body        TEXT NOT NULL DEFAULT '',
+    user_id     INT REFERENCES users (user_id) ON DELETE CASCADE,
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    CONSTRAINT unique_note_name_per_user UNIQUE (name, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS note;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS binary_file
+(
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(1000) NOT NULL,
+    user_id     INT REFERENCES users (user_id) ON DELETE CASCADE,
+    size        BIGINT NOT NULL,
+    chunk_size  INT NOT NULL,
+    chunk_count INT NOT NULL,
+    completed   BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_binary_file_name_per_user
+    ON binary_file (name, user_id) WHERE completed;
+
+CREATE TABLE IF NOT EXISTS binary_chunk
+(
+    file_id INT REFERENCES binary_file (id) ON DELETE CASCADE,
+    idx     INT NOT NULL,
+    size    INT NOT NULL,
+    PRIMARY KEY (file_id, idx)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS binary_chunk;
+DROP TABLE IF EXISTS binary_file;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN kdf_salt    VARCHAR(255) DEFAULT NULL,
+    ADD COLUMN kdf_time    INT DEFAULT NULL,
+    ADD COLUMN kdf_memory  INT DEFAULT NULL,
+    ADD COLUMN kdf_threads SMALLINT DEFAULT NULL,
+    ADD COLUMN kdf_check   TEXT DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN kdf_salt,
+    DROP COLUMN kdf_time,
+    DROP COLUMN kdf_memory,
+    DROP COLUMN kdf_threads,
+    DROP COLUMN kdf_check;
+-- +goose StatementEnd
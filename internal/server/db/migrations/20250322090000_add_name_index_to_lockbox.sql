@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- name_index — HMAC нормализованного имени, вычисленный клиентом. Позволяет
+-- хранить имя зашифрованным и при этом искать и проверять уникальность.
+ALTER TABLE lockbox
+    ADD COLUMN name_index VARCHAR(64) DEFAULT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS unique_lockbox_name_index_per_user
+    ON lockbox (name_index, user_id) WHERE name_index IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS unique_lockbox_name_index_per_user;
+ALTER TABLE lockbox
+    DROP COLUMN name_index;
+-- +goose StatementEnd
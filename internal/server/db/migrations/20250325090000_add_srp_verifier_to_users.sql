@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ALTER COLUMN password_hash DROP NOT NULL,
+    ADD COLUMN srp_salt     VARCHAR(64) DEFAULT NULL,
+    ADD COLUMN srp_verifier TEXT DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Пользователи, перешедшие на SRP, после отката войти не смогут: пустой хеш
+-- не совпадает ни с одним паролем.
+UPDATE users SET password_hash = '' WHERE password_hash IS NULL;
+ALTER TABLE users
+    ALTER COLUMN password_hash SET NOT NULL,
+    DROP COLUMN srp_salt,
+    DROP COLUMN srp_verifier;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS sessions
+(
+    session_id        SERIAL PRIMARY KEY,
+    user_id           INT         NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    refresh_hash      VARCHAR(64) NOT NULL UNIQUE,
+    prev_refresh_hash VARCHAR(64) DEFAULT NULL,
+    user_agent        TEXT        NOT NULL DEFAULT '',
+    ip                VARCHAR(64) NOT NULL DEFAULT '',
+    created_at        TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at        TIMESTAMP   NOT NULL,
+    revoked_at        TIMESTAMP   DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_prev_refresh_hash ON sessions (prev_refresh_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS sessions;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions
+    ADD COLUMN device_name VARCHAR(128) NOT NULL DEFAULT '',
+    ADD COLUMN os          VARCHAR(32)  NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions
+    DROP COLUMN device_name,
+    DROP COLUMN os;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN totp_secret    VARCHAR(64) DEFAULT NULL,
+    ADD COLUMN totp_enabled   BOOLEAN     NOT NULL DEFAULT FALSE,
+    ADD COLUMN totp_last_step BIGINT      NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS recovery_codes
+(
+    recovery_code_id SERIAL PRIMARY KEY,
+    user_id          INT         NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    code_hash        VARCHAR(64) NOT NULL,
+    used_at          TIMESTAMP   DEFAULT NULL,
+    UNIQUE (user_id, code_hash)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS recovery_codes;
+ALTER TABLE users
+    DROP COLUMN totp_secret,
+    DROP COLUMN totp_enabled,
+    DROP COLUMN totp_last_step;
+-- +goose StatementEnd
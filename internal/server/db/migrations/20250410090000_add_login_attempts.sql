@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    attempt_key  VARCHAR(300) PRIMARY KEY,
+    failures     INT       NOT NULL DEFAULT 0,
+    last_failure TIMESTAMP NOT NULL DEFAULT now(),
+    locked_until TIMESTAMP DEFAULT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS login_attempts;
+-- +goose StatementEnd
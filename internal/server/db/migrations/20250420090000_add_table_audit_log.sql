@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    audit_id   BIGSERIAL PRIMARY KEY,
+    user_id    INT                      DEFAULT NULL,
+    actor_id   INT                      DEFAULT NULL,
+    action     VARCHAR(64)              NOT NULL,
+    target     VARCHAR(255)             NOT NULL DEFAULT '',
+    success    BOOLEAN                  NOT NULL,
+    ip         VARCHAR(64)              NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    prev_hash  VARCHAR(64)              NOT NULL,
+    hash       VARCHAR(64)              NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON audit_log (user_id, audit_id);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update
+    BEFORE UPDATE OR DELETE
+    ON audit_log
+    FOR EACH ROW
+EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE
+    ON audit_log
+    FOR EACH STATEMENT
+EXECUTE FUNCTION audit_log_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
+-- +goose StatementEnd
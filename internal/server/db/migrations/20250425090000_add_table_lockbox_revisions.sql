@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Прежние версии записей lockbox. Поля хранятся так же, как в самой записи, —
+-- зашифрованными на клиенте, поэтому сервер не видит и старых паролей.
+CREATE TABLE IF NOT EXISTS lockbox_revisions
+(
+    revision_id SERIAL PRIMARY KEY,
+    lockbox_id  INT                      NOT NULL REFERENCES lockbox (id) ON DELETE CASCADE,
+    user_id     INT                      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    name        VARCHAR(1000)            NOT NULL,
+    name_index  VARCHAR(64),
+    url         VARCHAR(255),
+    username    VARCHAR(1000),
+    password    VARCHAR(1000),
+    description VARCHAR(1200),
+    updated_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    replaced_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_lockbox_revisions_lockbox_id ON lockbox_revisions (lockbox_id, revision_id);
+CREATE INDEX IF NOT EXISTS idx_lockbox_revisions_replaced_at ON lockbox_revisions (replaced_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS lockbox_revisions;
+-- +goose StatementEnd
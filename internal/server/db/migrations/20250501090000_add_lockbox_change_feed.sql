@@ -0,0 +1,91 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Счётчик изменений записей lockbox для каждого пользователя. Строка счётчика
+-- заблокирована до конца транзакции, которая его увеличила, поэтому номера
+-- фиксируются по возрастанию: клиент, получивший номер N, уже видит все
+-- изменения с меньшими номерами. pruned_seq — наибольший номер удалённого
+-- надгробия; клиенту с курсором меньше него нужна полная синхронизация.
+CREATE TABLE IF NOT EXISTS lockbox_sync_state
+(
+    user_id    INT PRIMARY KEY REFERENCES users (user_id) ON DELETE CASCADE,
+    seq        BIGINT NOT NULL DEFAULT 0,
+    pruned_seq BIGINT NOT NULL DEFAULT 0
+);
+
+ALTER TABLE lockbox
+    ADD COLUMN change_seq BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_lockbox_user_change_seq ON lockbox (user_id, change_seq);
+
+-- Надгробия окончательно удалённых записей: без них клиент, не заставший
+-- запись в корзине, не узнал бы об удалении.
+CREATE TABLE IF NOT EXISTS lockbox_tombstones
+(
+    lockbox_id INT PRIMARY KEY,
+    user_id    INT                      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    name       VARCHAR(1000)            NOT NULL,
+    name_index VARCHAR(64)              DEFAULT NULL,
+    change_seq BIGINT                   NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_lockbox_tombstones_user_change_seq ON lockbox_tombstones (user_id, change_seq);
+
+CREATE OR REPLACE FUNCTION lockbox_next_seq(uid INT) RETURNS BIGINT AS
+$$
+    INSERT INTO lockbox_sync_state AS s (user_id, seq)
+    VALUES (uid, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = s.seq + 1
+    RETURNING seq;
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION lockbox_track_change() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.user_id IS NOT NULL THEN
+        NEW.change_seq := lockbox_next_seq(NEW.user_id);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER lockbox_change_seq
+    BEFORE INSERT OR UPDATE
+    ON lockbox
+    FOR EACH ROW
+EXECUTE FUNCTION lockbox_track_change();
+
+CREATE OR REPLACE FUNCTION lockbox_track_delete() RETURNS trigger AS
+$$
+BEGIN
+    -- При удалении пользователя записи удаляются каскадом, надгробия не нужны.
+    IF EXISTS (SELECT 1 FROM users WHERE user_id = OLD.user_id) THEN
+        INSERT INTO lockbox_tombstones (lockbox_id, user_id, name, name_index, change_seq)
+        VALUES (OLD.id, OLD.user_id, OLD.name, OLD.name_index, lockbox_next_seq(OLD.user_id))
+        ON CONFLICT (lockbox_id) DO NOTHING;
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER lockbox_tombstone
+    AFTER DELETE
+    ON lockbox
+    FOR EACH ROW
+EXECUTE FUNCTION lockbox_track_delete();
+
+-- Существующие записи получают номера через тот же триггер.
+UPDATE lockbox SET change_seq = 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS lockbox_tombstone ON lockbox;
+DROP TRIGGER IF EXISTS lockbox_change_seq ON lockbox;
+DROP FUNCTION IF EXISTS lockbox_track_delete();
+DROP FUNCTION IF EXISTS lockbox_track_change();
+DROP FUNCTION IF EXISTS lockbox_next_seq(INT);
+DROP TABLE IF EXISTS lockbox_tombstones;
+DROP INDEX IF EXISTS idx_lockbox_user_change_seq;
+ALTER TABLE lockbox
+    DROP COLUMN change_seq;
+DROP TABLE IF EXISTS lockbox_sync_state;
+-- +goose StatementEnd
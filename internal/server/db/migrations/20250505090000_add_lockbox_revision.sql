@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Номер версии записи. Клиент присылает номер, от которого начинал правку;
+-- если запись с тех пор изменили с другого устройства, правка отклоняется.
+ALTER TABLE lockbox
+    ADD COLUMN revision INT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION lockbox_bump_revision() RETURNS trigger AS
+$$
+BEGIN
+    NEW.revision := OLD.revision + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER lockbox_revision
+    BEFORE UPDATE
+    ON lockbox
+    FOR EACH ROW
+EXECUTE FUNCTION lockbox_bump_revision();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS lockbox_revision ON lockbox;
+DROP FUNCTION IF EXISTS lockbox_bump_revision();
+ALTER TABLE lockbox
+    DROP COLUMN revision;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ключи идемпотентности применённых изменений: клиент повторяет отправку,
+-- не зная, дошла ли она, и повтор с тем же ключом возвращает прежний
+-- результат вместо повторного применения.
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    user_id    INT                      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    key        VARCHAR(64)              NOT NULL,
+    revision   INT                      NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, key)
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Каждый новый номер ленты изменений пользователя отправляется в канал
+-- lockbox_changes как "user_id:seq". Postgres доставляет уведомления после
+-- фиксации транзакции, поэтому получатель уже видит изменение; так узнают об
+-- изменениях все экземпляры сервера, а через них — устройства пользователя.
+CREATE OR REPLACE FUNCTION lockbox_next_seq(uid INT) RETURNS BIGINT AS
+$$
+DECLARE
+    next BIGINT;
+BEGIN
+    INSERT INTO lockbox_sync_state AS s (user_id, seq)
+    VALUES (uid, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = s.seq + 1
+    RETURNING seq INTO next;
+    PERFORM pg_notify('lockbox_changes', uid || ':' || next);
+    RETURN next;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION lockbox_next_seq(uid INT) RETURNS BIGINT AS
+$$
+    INSERT INTO lockbox_sync_state AS s (user_id, seq)
+    VALUES (uid, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = s.seq + 1
+    RETURNING seq;
+$$ LANGUAGE sql;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- name_index — слепой индекс названия карты, как у lockbox: название
+-- хранится зашифрованным, а поиск и уникальность идут по индексу.
+ALTER TABLE bank_card
+    ADD COLUMN name_index VARCHAR(64) DEFAULT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS unique_bank_card_name_index_per_user
+    ON bank_card (name_index, user_id) WHERE name_index IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS unique_bank_card_name_index_per_user;
+ALTER TABLE bank_card
+    DROP COLUMN name_index;
+-- +goose StatementEnd
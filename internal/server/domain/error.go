@@ -8,16 +8,65 @@ var (
 	ErrNameEmpty          = errors.New("name is empty")
 	ErrNoDataToCreate     = errors.New("no data to create")
 	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrSRPNotSet          = errors.New("srp verifier is not set")
+	ErrSRPSessionExpired  = errors.New("srp session expired")
+	ErrInvalidRefresh     = errors.New("invalid or expired refresh token")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrMFAExpired         = errors.New("mfa challenge expired")
+	ErrInvalidMFACode     = errors.New("invalid mfa code")
+	ErrTOTPEnabled        = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTooManyAttempts    = errors.New("too many failed login attempts")
+	ErrAccountLocked      = errors.New("account is temporarily locked")
+	ErrAccountDisabled    = errors.New("account is disabled")
+	ErrTooManyLogins      = errors.New("too many unfinished logins, try again later")
+	ErrPasswordLogin      = errors.New("password login is disabled, use srp")
 )
 
 var (
 	ErrInvalidInput  = errors.New("invalid input")
 	ErrTokenCreation = errors.New("could not create token")
+	ErrInternal      = errors.New("internal error")
 )
 
 var (
-	ErrLockBoxNotFound = errors.New("lockbox not found")
+	ErrLockBoxNotFound  = errors.New("lockbox not found")
+	ErrLockBoxExists    = errors.New("lockbox with this name already exists")
+	ErrRevisionNotFound = errors.New("lockbox revision not found")
+)
+var (
+	ErrBankCardNotFound = errors.New("bank card not found")
+	ErrBankCardExists   = errors.New("bank card with this name already exists")
+)
+var (
+	ErrNoteNotFound = errors.New("note not found")
+	ErrNoteExists   = errors.New("note with this name already exists")
+)
+var (
+	ErrBinaryNotFound   = errors.New("binary file not found")
+	ErrInvalidUpload    = errors.New("invalid upload parameters")
+	ErrChunkOutOfRange  = errors.New("chunk index out of range")
+	ErrChunkTooLarge    = errors.New("chunk is too large")
+	ErrUploadCompleted  = errors.New("upload already completed")
+	ErrUploadIncomplete = errors.New("upload has missing chunks")
 )
 var (
 	ErrInvalidUserID = errors.New("invalid user ID")
+	ErrUsernameTaken = errors.New("username is already taken")
+	ErrKDFNotSet     = errors.New("key derivation parameters are not set")
+	ErrKDFAlreadySet = errors.New("key derivation parameters are already set")
+	ErrInvalidKDF    = errors.New("invalid key derivation parameters")
+	ErrAdminSelf     = errors.New("administrators cannot disable or demote themselves")
+	ErrAdminExists   = errors.New("an administrator already exists")
+)
+var (
+	ErrStaleKey           = errors.New("vault key has changed since the rotation started")
+	ErrRotationIncomplete = errors.New("rotation batch does not cover every vault item")
+)
+var (
+	ErrSyncBatchTooLarge     = errors.New("sync batch is too large")
+	ErrInvalidCursor         = errors.New("invalid sync cursor")
+	ErrLockBoxConflict       = errors.New("lockbox was changed on another device")
+	ErrPreconditionFailed    = errors.New("lockbox does not match If-Match")
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
 )
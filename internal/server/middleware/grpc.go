@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AccessPolicy описывает доступ к gRPC-методу: public — метод доступен без
+// токена, roles — допустимые роли (пустой список — достаточно валидного токена).
+type AccessPolicy func(fullMethod string) (public bool, roles []string)
+
+type userIdKey struct{}
+
+type sessionIdKey struct{}
+
+// UserIdFromContext возвращает id пользователя, проверенного интерцептором.
+func UserIdFromContext(ctx context.Context) int {
+	userId, _ := ctx.Value(userIdKey{}).(int)
+	return userId
+}
+
+// ContextWithUserId кладёт id пользователя в контекст так же, как это делает интерцептор.
+func ContextWithUserId(ctx context.Context, userId int) context.Context {
+	return context.WithValue(ctx, userIdKey{}, userId)
+}
+
+// SessionIdFromContext возвращает id сессии, к которой привязан токен запроса.
+func SessionIdFromContext(ctx context.Context) int {
+	sessionId, _ := ctx.Value(sessionIdKey{}).(int)
+	return sessionId
+}
+
+// authorizeGRPC — аналог MiddlewareJWT и AuthorizeRoles: токен передаётся
+// в метаданных authorization.
+func (ms *MiddlewareService) authorizeGRPC(ctx context.Context, fullMethod string, policy AccessPolicy) (context.Context, error) {
+	public, roles := policy(fullMethod)
+	if public {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var tokenString string
+	if values := md.Get("authorization"); len(values) > 0 {
+		tokenString = values[0]
+	}
+
+	claims, err := ms.verifyToken(ctx, tokenString)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	userId := int(claims["user_id"].(float64))
+	sessionId := int(claims["sid"].(float64))
+
+	if len(roles) > 0 {
+		userType, err := ms.roles.UserType(ctx, userId)
+		if err != nil {
+			return nil, status.Error(codes.PermissionDenied, "Access denied")
+		}
+		allowed := false
+		for _, role := range roles {
+			if role == userType {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, status.Error(codes.PermissionDenied, "Access denied")
+		}
+	}
+
+	return context.WithValue(ContextWithUserId(ctx, userId), sessionIdKey{}, sessionId), nil
+}
+
+func (ms *MiddlewareService) UnaryAuth(policy AccessPolicy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := ms.authorizeGRPC(ctx, info.FullMethod, policy)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authStream подменяет контекст потока на контекст с id пользователя.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+func (ms *MiddlewareService) StreamAuth(policy AccessPolicy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := ms.authorizeGRPC(ss.Context(), info.FullMethod, policy)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TestUnaryAuth проверяет, что интерцептор пропускает публичные методы,
+// отклоняет запросы без токена и передаёт id пользователя обработчику.
+func TestUnaryAuth(t *testing.T) {
+	ms := newTestMiddleware(fakeDB{}, 1)
+	policy := func(fullMethod string) (bool, []string) {
+		return fullMethod == "/test.Service/Public", nil
+	}
+	interceptor := ms.UnaryAuth(policy)
+
+	var gotUserId int
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		gotUserId = UserIdFromContext(ctx)
+		return "ok", nil
+	}
+	call := func(ctx context.Context, method string) error {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	if err := call(context.Background(), "/test.Service/Public"); err != nil {
+		t.Fatalf("Public method rejected: %v", err)
+	}
+
+	if err := call(context.Background(), "/test.Service/Private"); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Expected Unauthenticated without token, got %v", err)
+	}
+
+	bad := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "garbage"))
+	if err := call(bad, "/test.Service/Private"); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Expected Unauthenticated for invalid token, got %v", err)
+	}
+
+	tokenStr, err := ms.CreateToken(789, "grpcuser", "attendee", 1)
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", tokenStr))
+	if err := call(ctx, "/test.Service/Private"); err != nil {
+		t.Fatalf("Valid token rejected: %v", err)
+	}
+	if gotUserId != 789 {
+		t.Errorf("Expected userId 789, got %d", gotUserId)
+	}
+}
+
+// TestUnaryAuthRolesAfterRename проверяет, что после переименования аккаунта
+// методы с ролями принимают прежний токен.
+func TestUnaryAuthRolesAfterRename(t *testing.T) {
+	ms := newTestMiddleware(fakeDB{}, 1)
+	users := fakeRoles{789: {username: "oldname", userType: "attendee"}}
+	ms.roles = users
+	interceptor := ms.UnaryAuth(func(fullMethod string) (bool, []string) {
+		return false, []string{"admin", "attendee"}
+	})
+
+	tokenStr, err := ms.CreateToken(789, "oldname", "attendee", 1)
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	users[789].username = "newname"
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", tokenStr))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	if _, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Private"}, handler); err != nil {
+		t.Fatalf("Token rejected after rename: %v", err)
+	}
+}
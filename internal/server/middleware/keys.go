@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/pkg/jwks"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// SigningKeys — ключ, которым сервер подписывает токены, и все ключи, по
+// которым он их принимает. Для ротации новый ключ ставится первым в
+// SIGNATURE_PRIVATE_KEY, прежний остаётся в списке (или его открытая часть —
+// в SIGNATURE_PUBLIC_KEY), пока не истекут выпущенные им токены.
+type SigningKeys struct {
+	kid    string
+	method jwt.SigningMethod
+	signer crypto.Signer
+	public map[string]crypto.PublicKey
+	set    jwks.Set
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// LoadSigningKeys загружает ключи из файлов, перечисленных в конфигурации.
+// Если файла активного ключа ещё нет, создаётся ключ Ed25519; если ключи не
+// настроены вовсе, ключ живёт только в памяти и токены не переживут перезапуск.
+func LoadSigningKeys(conf config.AppConf) (*SigningKeys, error) {
+	keys := &SigningKeys{public: map[string]crypto.PublicKey{}}
+
+	privatePaths := splitList(conf.SignaturePrivateKey)
+	for i, path := range privatePaths {
+		signer, err := jwks.LoadPrivateKey(path)
+		if i == 0 && errors.Is(err, os.ErrNotExist) {
+			slog.Warn("Signing key not found, generating Ed25519 key", "path", path)
+			signer, err = jwks.GenerateKeyFile(path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			keys.signer = signer
+		}
+		if err := keys.add(signer.Public()); err != nil {
+			return nil, err
+		}
+	}
+
+	if keys.signer == nil {
+		slog.Warn("No signing key configured, using ephemeral Ed25519 key")
+		_, signer, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		keys.signer = signer
+		if err := keys.add(signer.Public()); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range splitList(conf.SignaturePublicKey) {
+		key, err := jwks.LoadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := keys.add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if keys.kid, err = jwks.KeyID(keys.signer.Public()); err != nil {
+		return nil, err
+	}
+	if keys.method, err = jwks.Method(keys.signer.Public()); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (k *SigningKeys) add(key crypto.PublicKey) error {
+	jwk, err := jwks.NewKey(key)
+	if err != nil {
+		return err
+	}
+	if _, ok := k.public[jwk.Kid]; ok {
+		return nil
+	}
+	k.public[jwk.Kid] = key
+	k.set.Keys = append(k.set.Keys, jwk)
+	return nil
+}
+
+// Sign подписывает claims активным ключом и указывает его kid в заголовке.
+func (k *SigningKeys) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.kid
+	return token.SignedString(k.signer)
+}
+
+// Keyfunc проверяет токены любым из известных ключей.
+func (k *SigningKeys) Keyfunc() jwt.Keyfunc {
+	return jwks.Keyfunc(func(kid string) (crypto.PublicKey, error) {
+		key, ok := k.public[kid]
+		if !ok {
+			return nil, jwks.ErrUnknownKey
+		}
+		return key, nil
+	})
+}
+
+// Set возвращает открытые ключи для эндпоинта JWKS.
+func (k *SigningKeys) Set() jwks.Set {
+	return k.set
+}
@@ -1,25 +1,42 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
 	"gophKeeper/internal/server/config"
 	"gophKeeper/internal/server/db"
+	"gophKeeper/pkg/jwks"
 	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var jwtSecret = []byte("your-secret-key") // todo вынести
+// accessTokenTTL — срок жизни access-токена. Он короткий: дольше живёт
+// refresh-токен, который можно отозвать вместе с сессией.
+const accessTokenTTL = 15 * time.Minute
+
+var (
+	errNoToken        = errors.New("No token provided")
+	errInvalidToken   = errors.New("Invalid token")
+	errInvalidClaims  = errors.New("Invalid token claims")
+	errSessionRevoked = errors.New("Session revoked")
+)
 
 type IMiddlewareService interface {
-	GetJWTSecret() []byte
+	JWKS() jwks.Set
 	MiddlewareJWT() gin.HandlerFunc
 	ValidateUserId() gin.HandlerFunc
 	AuthorizeRoles(allowedRoles ...string) gin.HandlerFunc
-	CreateToken(userId int, username, userType string) (string, error)
+	CreateToken(userId int, username, userType string, sessionId int) (string, error)
+	SessionActive(ctx context.Context, userId, sessionId int) bool
+	UnaryAuth(policy AccessPolicy) grpc.UnaryServerInterceptor
+	StreamAuth(policy AccessPolicy) grpc.StreamServerInterceptor
 }
 
 var (
@@ -29,23 +46,54 @@ var (
 )
 
 type limiterEntry struct {
-	limiter    *rate.Limiter
-	lastAccess time.Time
+	limiter *rate.Limiter
+	// lastAccess — время последнего запроса в UnixNano; пишется из разных горутин.
+	lastAccess atomic.Int64
 }
 
 func getLimiter(ip string) *rate.Limiter {
+	now := time.Now().UnixNano()
 	if entry, ok := limiters.Load(ip); ok {
 		limiterEntry := entry.(*limiterEntry)
-		limiterEntry.lastAccess = time.Now()
+		limiterEntry.lastAccess.Store(now)
 		return limiterEntry.limiter
 	}
 
-	limiter := rate.NewLimiter(5, 5)
-	limiters.Store(ip, &limiterEntry{
-		limiter:    limiter,
-		lastAccess: time.Now(),
+	entry := &limiterEntry{limiter: rate.NewLimiter(5, 5)}
+	entry.lastAccess.Store(now)
+	actual, _ := limiters.LoadOrStore(ip, entry)
+	return actual.(*limiterEntry).limiter
+}
+
+// cleanupLimiters удаляет лимитеры адресов, не обращавшихся дольше limiterTime.
+func cleanupLimiters(now time.Time) int {
+	removed := 0
+	limiters.Range(func(key, value any) bool {
+		if now.Sub(time.Unix(0, value.(*limiterEntry).lastAccess.Load())) > limiterTime {
+			limiters.Delete(key)
+			removed++
+		}
+		return true
 	})
-	return limiter
+	return removed
+}
+
+// StartLimiterJanitor раз в cleanupInterval чистит лимитеры, пока не отменён ctx.
+func StartLimiterJanitor(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				if removed := cleanupLimiters(now); removed > 0 {
+					slog.Debug("Rate limiters cleaned up", "removed", removed)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 }
 
 func RateLimiter() gin.HandlerFunc {
@@ -63,70 +111,148 @@ func RateLimiter() gin.HandlerFunc {
 	}
 }
 
+// sessionStore отвечает, не отозвана ли сессия, к которой привязан токен.
+type sessionStore interface {
+	Active(ctx context.Context, userId, sessionId int) bool
+}
+
+type dbSessions struct {
+	database db.IDatabase
+}
+
+func (s dbSessions) Active(ctx context.Context, userId, sessionId int) bool {
+	var active bool
+	query := `SELECT EXISTS (SELECT 1 FROM sessions
+              WHERE session_id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > now())`
+	if err := s.database.GetDB().QueryRow(ctx, query, sessionId, userId).Scan(&active); err != nil {
+		return false
+	}
+	return active
+}
+
+// roleStore возвращает роль пользователя. Роль ищется только по id: имя
+// в токене после переименования аккаунта устаревает.
+type roleStore interface {
+	UserType(ctx context.Context, userId int) (string, error)
+}
+
+type dbRoles struct {
+	database db.IDatabase
+}
+
+func (r dbRoles) UserType(ctx context.Context, userId int) (string, error) {
+	var userType string
+	query := `SELECT user_type FROM "users" WHERE user_id = $1`
+	err := r.database.GetDB().QueryRow(ctx, query, userId).Scan(&userType)
+	return userType, err
+}
+
 type MiddlewareService struct {
 	config   *config.Config
 	database db.IDatabase
+	sessions sessionStore
+	roles    roleStore
+	keys     *SigningKeys
 }
 
+// NewMiddlewareService загружает ключи подписи из конфигурации; без них
+// сервер работать не может, поэтому ошибка загрузки приводит к панике.
 func NewMiddlewareService(config *config.Config, database db.IDatabase) IMiddlewareService {
-	return &MiddlewareService{config: config, database: database}
+	keys, err := LoadSigningKeys(config.App)
+	if err != nil {
+		panic(err)
+	}
+	return &MiddlewareService{
+		config:   config,
+		database: database,
+		sessions: dbSessions{database: database},
+		roles:    dbRoles{database: database},
+		keys:     keys,
+	}
 }
 
-func (ms *MiddlewareService) CreateToken(userId int, username string, userType string) (string, error) {
+// CreateToken выпускает access-токен сессии sessionId.
+func (ms *MiddlewareService) CreateToken(userId int, username string, userType string, sessionId int) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id":   userId,
 		"user_name": username,
 		"user_type": userType,
-		"exp":       time.Now().Add(time.Hour * 72).Unix(),
+		"sid":       sessionId,
+		"exp":       time.Now().Add(accessTokenTTL).Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(ms.GetJWTSecret())
+	tokenString, err := ms.keys.Sign(claims)
 	if err != nil {
 		return "", err
 	}
 	return tokenString, nil
 }
 
-func (ms *MiddlewareService) GetJWTSecret() []byte { // todo для конфига
-	return jwtSecret
+// SessionActive — не отозвана ли сессия и не истёк ли её срок. Долгие
+// соединения проверяют ею сессию, открытую при подключении.
+func (ms *MiddlewareService) SessionActive(ctx context.Context, userId, sessionId int) bool {
+	return ms.sessions.Active(ctx, userId, sessionId)
 }
 
-func (ms *MiddlewareService) MiddlewareJWT() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		tokenString := ctx.GetHeader("Authorization")
-		if tokenString == "" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "No token provided"})
-			return
-		}
+// JWKS возвращает открытые ключи, которыми клиенты проверяют токены.
+func (ms *MiddlewareService) JWKS() jwks.Set {
+	return ms.keys.Set()
+}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return ms.GetJWTSecret(), nil
-		})
+// verifyToken проверяет подпись и срок токена и то, что его сессия не отозвана.
+func (ms *MiddlewareService) verifyToken(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	if tokenString == "" {
+		return nil, errNoToken
+	}
 
-		if err != nil || !token.Valid {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			return
-		}
+	token, err := jwt.Parse(tokenString, ms.keys.Keyfunc())
+	if err != nil || !token.Valid {
+		return nil, errInvalidToken
+	}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errInvalidClaims
+	}
+	userId, ok1 := claims["user_id"].(float64)
+	sessionId, ok2 := claims["sid"].(float64)
+	if !ok1 || !ok2 {
+		return nil, errInvalidClaims
+	}
+
+	if !ms.sessions.Active(ctx, int(userId), int(sessionId)) {
+		return nil, errSessionRevoked
+	}
+	return claims, nil
+}
+
+func (ms *MiddlewareService) MiddlewareJWT() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, err := ms.verifyToken(ctx, ctx.GetHeader("Authorization"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
 
 		userId := int(claims["user_id"].(float64))
 		ctx.Set("userId", userId)
+		ctx.Set("sessionId", int(claims["sid"].(float64)))
+		if exp, ok := claims["exp"].(float64); ok {
+			ctx.Set("tokenExpiresAt", time.Unix(int64(exp), 0))
+		}
 
 		userType, ok := claims["user_type"].(string)
 		if ok {
 			ctx.Set("userType", userType)
 		}
+		if username, ok := claims["user_name"].(string); ok {
+			ctx.Set("username", username)
+		}
 
 		ctx.Next()
 	}
 }
 
-// ValidateUserId todo избежать дублирования кода
+// ValidateUserId пропускает запрос без токена, но проверяет токен, если он передан.
 func (ms *MiddlewareService) ValidateUserId() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		tokenString := ctx.GetHeader("Authorization")
@@ -134,18 +260,9 @@ func (ms *MiddlewareService) ValidateUserId() gin.HandlerFunc {
 			slog.Info("Not authorized user, next()")
 			ctx.Next()
 		} else {
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				return ms.GetJWTSecret(), nil
-			})
-
-			if err != nil || !token.Valid {
-				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-				return
-			}
-
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok {
-				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			claims, err := ms.verifyToken(ctx, tokenString)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 				return
 			}
 
@@ -159,40 +276,21 @@ func (ms *MiddlewareService) ValidateUserId() gin.HandlerFunc {
 
 func (ms *MiddlewareService) AuthorizeRoles(allowedRoles ...string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		tokenString := ctx.GetHeader("Authorization")
-		if tokenString == "" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "No token provided"})
-			return
-		}
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return ms.GetJWTSecret(), nil
-		})
-
-		if err != nil || !token.Valid {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			return
-		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		claims, err := ms.verifyToken(ctx, ctx.GetHeader("Authorization"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
 
 		userId := int(claims["user_id"].(float64))
-		username := claims["user_name"].(string)
-
-		var infoUser struct{ UserType string }
-		query := `SELECT user_type FROM "users" WHERE user_id = $1 and username = $2`
-		err = ms.database.GetDB().QueryRow(ctx, query, userId, username).Scan(&infoUser.UserType)
+		userType, err := ms.roles.UserType(ctx, userId)
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 			return
 		}
 
 		for _, role := range allowedRoles {
-			if role == infoUser.UserType {
+			if role == userType {
 				ctx.Next()
 				return
 			}
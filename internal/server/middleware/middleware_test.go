@@ -3,11 +3,14 @@ package middleware
 
 import (
 	"context"
+	"crypto"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 	"unsafe"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +18,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"gophKeeper/internal/server/config"
+	"gophKeeper/pkg/jwks"
 )
 
 // ---------------- Фейковая база данных для тестирования AuthorizeRoles ---------------- //
@@ -56,17 +60,59 @@ func (r fakeRow) Scan(dest ...interface{}) error {
 	return nil
 }
 
+// fakeSessions считает активными только перечисленные сессии.
+type fakeSessions map[int]bool
+
+func (f fakeSessions) Active(ctx context.Context, userId, sessionId int) bool {
+	return f[sessionId]
+}
+
+// fakeUser — строка таблицы users, которую видит roleStore.
+type fakeUser struct {
+	username string
+	userType string
+}
+
+// fakeRoles — таблица пользователей по id.
+type fakeRoles map[int]*fakeUser
+
+func (f fakeRoles) UserType(ctx context.Context, userId int) (string, error) {
+	user, ok := f[userId]
+	if !ok {
+		return "", pgx.ErrNoRows
+	}
+	return user.userType, nil
+}
+
+// newTestMiddleware создаёт сервис, в котором активны сессии activeSessions.
+func newTestMiddleware(db fakeDB, activeSessions ...int) *MiddlewareService {
+	sessions := fakeSessions{}
+	for _, id := range activeSessions {
+		sessions[id] = true
+	}
+	ms := NewMiddlewareService(&config.Config{}, db).(*MiddlewareService)
+	ms.sessions = sessions
+	ms.roles = fakeRoles{}
+	return ms
+}
+
 // TestCreateToken проверяет, что при создании токена в его claims присутствуют ожидаемые значения.
 func TestCreateToken(t *testing.T) {
 	db := fakeDB{}
 	ms := NewMiddlewareService(&config.Config{}, db)
-	tokenStr, err := ms.CreateToken(123, "testuser", "admin")
+	tokenStr, err := ms.CreateToken(123, "testuser", "admin", 1)
 	if err != nil {
 		t.Fatalf("CreateToken failed: %v", err)
 	}
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return ms.GetJWTSecret(), nil
-	})
+	// Проверяем так же, как клиент: по опубликованному JWKS.
+	token, err := jwt.Parse(tokenStr, jwks.Keyfunc(func(kid string) (crypto.PublicKey, error) {
+		for _, key := range ms.JWKS().Keys {
+			if key.Kid == kid {
+				return key.PublicKey()
+			}
+		}
+		return nil, jwks.ErrUnknownKey
+	}))
 	if err != nil || !token.Valid {
 		t.Fatalf("Token is not valid: %v", err)
 	}
@@ -83,13 +129,16 @@ func TestCreateToken(t *testing.T) {
 	if claims["user_type"].(string) != "admin" {
 		t.Errorf("Expected user_type 'admin', got %v", claims["user_type"])
 	}
+	if int(claims["sid"].(float64)) != 1 {
+		t.Errorf("Expected sid 1, got %v", claims["sid"])
+	}
 }
 
 // TestMiddlewareJWT_ValidToken проверяет middleware MiddlewareJWT при наличии валидного токена.
 func TestMiddlewareJWT_ValidToken(t *testing.T) {
 	db := fakeDB{}
-	ms := NewMiddlewareService(&config.Config{}, db)
-	tokenStr, err := ms.CreateToken(456, "anotheruser", "user")
+	ms := newTestMiddleware(db, 1)
+	tokenStr, err := ms.CreateToken(456, "anotheruser", "user", 1)
 	if err != nil {
 		t.Fatalf("CreateToken failed: %v", err)
 	}
@@ -134,11 +183,126 @@ func TestMiddlewareJWT_MissingToken(t *testing.T) {
 	}
 }
 
+// TestMiddlewareJWT_RevokedSession проверяет, что токен отозванной сессии отклоняется.
+func TestMiddlewareJWT_RevokedSession(t *testing.T) {
+	ms := newTestMiddleware(fakeDB{}, 1)
+	tokenStr, err := ms.CreateToken(456, "anotheruser", "user", 2)
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ms.MiddlewareJWT())
+	r.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", tokenStr)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 Unauthorized for revoked session, got %d", w.Code)
+	}
+}
+
+// TestAuthorizeRolesAfterRename проверяет, что токен, выпущенный до
+// переименования аккаунта, по-прежнему проходит проверку роли.
+func TestAuthorizeRolesAfterRename(t *testing.T) {
+	ms := newTestMiddleware(fakeDB{}, 1)
+	users := fakeRoles{456: {username: "oldname", userType: "attendee"}}
+	ms.roles = users
+	tokenStr, err := ms.CreateToken(456, "oldname", "attendee", 1)
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	users[456].username = "newname"
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/lock_boxes", ms.MiddlewareJWT(), ms.AuthorizeRoles("admin", "attendee"), func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+	call := func(token string) int {
+		req := httptest.NewRequest("GET", "/lock_boxes", nil)
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := call(tokenStr); code != http.StatusOK {
+		t.Fatalf("Expected 200 OK after rename, got %d", code)
+	}
+
+	unknown, err := ms.CreateToken(789, "ghost", "attendee", 1)
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	if code := call(unknown); code != http.StatusForbidden {
+		t.Fatalf("Expected 403 for unknown user, got %d", code)
+	}
+}
+
+// TestKeyRotation проверяет, что после ротации токены подписываются новым
+// ключом, а токены прежнего ключа ещё принимаются.
+func TestKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldKey, newKey := filepath.Join(dir, "old.pem"), filepath.Join(dir, "new.pem")
+
+	before := newTestMiddleware(fakeDB{}, 1)
+	before.keys = mustLoadKeys(t, config.AppConf{SignaturePrivateKey: oldKey})
+	oldToken, err := before.CreateToken(1, "user", "attendee", 1)
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+
+	after := newTestMiddleware(fakeDB{}, 1)
+	after.keys = mustLoadKeys(t, config.AppConf{SignaturePrivateKey: newKey + "," + oldKey})
+	newToken, err := after.CreateToken(1, "user", "attendee", 1)
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+
+	if len(after.JWKS().Keys) != 2 {
+		t.Fatalf("Expected both keys in JWKS, got %d", len(after.JWKS().Keys))
+	}
+	if _, err := after.verifyToken(context.Background(), oldToken); err != nil {
+		t.Errorf("Token of the previous key rejected: %v", err)
+	}
+	if _, err := after.verifyToken(context.Background(), newToken); err != nil {
+		t.Errorf("Token of the active key rejected: %v", err)
+	}
+	if _, err := before.verifyToken(context.Background(), newToken); err == nil {
+		t.Error("Expected token of an unknown key to be rejected")
+	}
+}
+
+// TestRejectsSharedSecret проверяет, что токены на общем секрете больше не принимаются.
+func TestRejectsSharedSecret(t *testing.T) {
+	ms := newTestMiddleware(fakeDB{}, 1)
+	claims := jwt.MapClaims{"user_id": 1, "sid": 1, "exp": time.Now().Add(time.Hour).Unix()}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = ms.keys.kid
+	tokenStr, _ := token.SignedString([]byte("your-secret-key"))
+
+	if _, err := ms.verifyToken(context.Background(), tokenStr); err == nil {
+		t.Error("Expected HS256 token to be rejected")
+	}
+}
+
+func mustLoadKeys(t *testing.T, conf config.AppConf) *SigningKeys {
+	t.Helper()
+	keys, err := LoadSigningKeys(conf)
+	if err != nil {
+		t.Fatalf("LoadSigningKeys failed: %v", err)
+	}
+	return keys
+}
+
 // TestValidateUserId_WithToken проверяет ValidateUserId при наличии валидного токена.
 func TestValidateUserId_WithToken(t *testing.T) {
 	db := fakeDB{}
-	ms := NewMiddlewareService(&config.Config{}, db)
-	tokenStr, err := ms.CreateToken(789, "validuser", "user")
+	ms := newTestMiddleware(db, 1)
+	tokenStr, err := ms.CreateToken(789, "validuser", "user", 1)
 	if err != nil {
 		t.Fatalf("CreateToken failed: %v", err)
 	}
@@ -214,3 +378,23 @@ func TestRateLimiter(t *testing.T) {
 		t.Fatalf("Expected 429 Too Many Requests on 6th request, got %d", w.Code)
 	}
 }
+
+// TestCleanupLimiters проверяет, что janitor удаляет только давно неактивные лимитеры.
+func TestCleanupLimiters(t *testing.T) {
+	limiters = sync.Map{}
+
+	getLimiter("10.0.0.1")
+	getLimiter("10.0.0.2")
+	stale, _ := limiters.Load("10.0.0.1")
+	stale.(*limiterEntry).lastAccess.Store(time.Now().Add(-2 * limiterTime).UnixNano())
+
+	if removed := cleanupLimiters(time.Now()); removed != 1 {
+		t.Fatalf("Expected 1 limiter removed, got %d", removed)
+	}
+	if _, ok := limiters.Load("10.0.0.1"); ok {
+		t.Error("Expected stale limiter to be removed")
+	}
+	if _, ok := limiters.Load("10.0.0.2"); !ok {
+		t.Error("Expected active limiter to be kept")
+	}
+}
@@ -1,8 +1,11 @@
 package middleware
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"gophKeeper/pkg/jwks"
 )
 
 // Mock for IMiddlewareService
@@ -10,12 +13,12 @@ type MockMiddlewareService struct {
 	mock.Mock
 }
 
-func (m *MockMiddlewareService) GetJWTSecret() []byte {
+func (m *MockMiddlewareService) JWKS() jwks.Set {
 	args := m.Called()
-	if secret, ok := args.Get(0).([]byte); ok {
-		return secret
+	if set, ok := args.Get(0).(jwks.Set); ok {
+		return set
 	}
-	return nil
+	return jwks.Set{}
 }
 
 func (m *MockMiddlewareService) MiddlewareJWT() gin.HandlerFunc {
@@ -42,11 +45,32 @@ func (m *MockMiddlewareService) AuthorizeRoles(allowedRoles ...string) gin.Handl
 	return nil
 }
 
-func (m *MockMiddlewareService) CreateToken(userId int, username, userType string) (string, error) {
-	args := m.Called(userId, username, userType)
+func (m *MockMiddlewareService) CreateToken(userId int, username, userType string, sessionId int) (string, error) {
+	args := m.Called(userId, username, userType, sessionId)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockMiddlewareService) SessionActive(ctx context.Context, userId, sessionId int) bool {
+	args := m.Called(ctx, userId, sessionId)
+	return args.Bool(0)
+}
+
+func (m *MockMiddlewareService) UnaryAuth(policy AccessPolicy) grpc.UnaryServerInterceptor {
+	args := m.Called(policy)
+	if interceptor, ok := args.Get(0).(grpc.UnaryServerInterceptor); ok {
+		return interceptor
+	}
+	return nil
+}
+
+func (m *MockMiddlewareService) StreamAuth(policy AccessPolicy) grpc.StreamServerInterceptor {
+	args := m.Called(policy)
+	if interceptor, ok := args.Get(0).(grpc.StreamServerInterceptor); ok {
+		return interceptor
+	}
+	return nil
+}
+
 func NewMock() IMiddlewareService {
 	return &MockMiddlewareService{}
 }
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+const (
+	// DefaultPageSize — размер страницы списка пользователей по умолчанию.
+	DefaultPageSize = 50
+	// MaxPageSize ограничивает страницу, чтобы один запрос не выгружал всю таблицу.
+	MaxPageSize = 500
+)
+
+// User — пользователь в списке администратора. Хеш пароля и верификатор
+// сюда не попадают.
+type User struct {
+	UserId         int        `json:"user_id"`
+	Username       string     `json:"username"`
+	UserType       string     `json:"user_type"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	TOTPEnabled    bool       `json:"totp_enabled"`
+	ActiveSessions int        `json:"active_sessions"`
+}
+
+// UserPage — страница списка пользователей и их общее число.
+type UserPage struct {
+	Users  []User `json:"users"`
+	Total  int    `json:"total"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+// StorageUsage — сколько данных хранит пользователь. Удалённые, но ещё не
+// очищенные записи учитываются: они тоже занимают место.
+type StorageUsage struct {
+	UserId    int   `json:"user_id"`
+	LockBoxes int   `json:"lock_boxes"`
+	BankCards int   `json:"bank_cards"`
+	Notes     int   `json:"notes"`
+	Files     int   `json:"files"`
+	FileBytes int64 `json:"file_bytes"`
+}
+
+type RoleChange struct {
+	UserType string `json:"user_type" binding:"required"`
+}
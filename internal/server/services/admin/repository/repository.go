@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gophKeeper/internal/server/db"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/admin/models"
+	"gophKeeper/util"
+)
+
+type IAdminRepo interface {
+	ListUsers(ctx context.Context, limit, offset int) ([]models.User, int, error)
+	SetDisabled(ctx context.Context, userId int, disabled bool) error
+	RevokeSessions(ctx context.Context, userId int) (int, error)
+	ResetTOTP(ctx context.Context, userId int) error
+	SetUserType(ctx context.Context, userId int, userType string) error
+	StorageUsage(ctx context.Context, userId int) (*models.StorageUsage, error)
+	Bootstrap(ctx context.Context, username, salt, verifier string) (int, error)
+}
+
+type AdminRepo struct {
+	db db.IDatabase
+}
+
+func NewAdminRepo(db db.IDatabase) IAdminRepo {
+	return &AdminRepo{
+		db: db,
+	}
+}
+
+func (r *AdminRepo) ListUsers(ctx context.Context, limit, offset int) ([]models.User, int, error) {
+	var total int
+	if err := r.db.GetDB().QueryRow(ctx, `SELECT count(*) FROM users`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT u.user_id, u.username, u.user_type, u.created_at, u.disabled_at, u.totp_enabled,
+                     (SELECT count(*) FROM sessions s
+                      WHERE s.user_id = u.user_id AND s.revoked_at IS NULL AND s.expires_at > now())
+              FROM users u
+              ORDER BY u.user_id
+              LIMIT $1 OFFSET $2`
+	rows, err := r.db.GetDB().Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(&user.UserId, &user.Username, &user.UserType, &user.CreatedAt,
+			&user.DisabledAt, &user.TOTPEnabled, &user.ActiveSessions)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	return users, total, rows.Err()
+}
+
+// SetDisabled отключает или снова включает аккаунт. При отключении все сессии
+// отзываются в той же транзакции: выданные токены перестают действовать сразу.
+func (r *AdminRepo) SetDisabled(ctx context.Context, userId int, disabled bool) error {
+	tx, err := r.db.GetDB().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE users SET disabled_at = NULL WHERE user_id = $1`
+	if disabled {
+		query = `UPDATE users SET disabled_at = COALESCE(disabled_at, now()) WHERE user_id = $1`
+	}
+	res, err := tx.Exec(ctx, query, userId)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	if disabled {
+		if _, err := tx.Exec(ctx, `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`, userId); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// RevokeSessions завершает вход пользователя на всех устройствах.
+func (r *AdminRepo) RevokeSessions(ctx context.Context, userId int) (int, error) {
+	if err := r.exists(ctx, userId); err != nil {
+		return 0, err
+	}
+	res, err := r.db.GetDB().Exec(ctx, `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`, userId)
+	if err != nil {
+		return 0, err
+	}
+	return int(res.RowsAffected()), nil
+}
+
+// ResetTOTP отключает 2FA вместе с кодами восстановления, если пользователь
+// потерял доступ к приложению-аутентификатору.
+func (r *AdminRepo) ResetTOTP(ctx context.Context, userId int) error {
+	tx, err := r.db.GetDB().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := tx.Exec(ctx, `UPDATE users SET totp_secret = NULL, totp_enabled = FALSE, totp_last_step = 0 WHERE user_id = $1`, userId)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userId); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *AdminRepo) SetUserType(ctx context.Context, userId int, userType string) error {
+	res, err := r.db.GetDB().Exec(ctx, `UPDATE users SET user_type = $2 WHERE user_id = $1`, userId, userType)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *AdminRepo) StorageUsage(ctx context.Context, userId int) (*models.StorageUsage, error) {
+	if err := r.exists(ctx, userId); err != nil {
+		return nil, err
+	}
+	usage := models.StorageUsage{UserId: userId}
+	query := `SELECT (SELECT count(*) FROM lockbox WHERE user_id = $1),
+                     (SELECT count(*) FROM bank_card WHERE user_id = $1),
+                     (SELECT count(*) FROM note WHERE user_id = $1),
+                     (SELECT count(*) FROM binary_file WHERE user_id = $1 AND completed),
+                     (SELECT COALESCE(sum(c.size), 0) FROM binary_chunk c
+                      JOIN binary_file f ON f.id = c.file_id WHERE f.user_id = $1)`
+	err := r.db.GetDB().QueryRow(ctx, query, userId).Scan(
+		&usage.LockBoxes, &usage.BankCards, &usage.Notes, &usage.Files, &usage.FileBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// Bootstrap создаёт первого администратора или назначает администратором
+// существующего пользователя. Если администратор уже есть, возвращает
+// ErrAdminExists: дальше роли раздаются через API.
+func (r *AdminRepo) Bootstrap(ctx context.Context, username, salt, verifier string) (int, error) {
+	tx, err := r.db.GetDB().Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка не даёт двум одновременным запускам создать двух администраторов.
+	if _, err := tx.Exec(ctx, `LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return 0, err
+	}
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE user_type = $1)`, util.Admin).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if exists {
+		return 0, domain.ErrAdminExists
+	}
+
+	var userId int
+	err = tx.QueryRow(ctx, `UPDATE users SET user_type = $2 WHERE username = $1 RETURNING user_id`, username, util.Admin).Scan(&userId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = tx.QueryRow(ctx, `INSERT INTO users (username, srp_salt, srp_verifier, user_type)
+                                VALUES ($1, $2, $3, $4) RETURNING user_id`,
+			username, salt, verifier, util.Admin).Scan(&userId)
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, domain.ErrUsernameTaken
+		}
+		return 0, err
+	}
+	return userId, tx.Commit(ctx)
+}
+
+func (r *AdminRepo) exists(ctx context.Context, userId int) error {
+	var exists bool
+	if err := r.db.GetDB().QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE user_id = $1)`, userId).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"encoding/hex"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/admin/models"
+	"gophKeeper/internal/server/services/admin/repository"
+	auditmodels "gophKeeper/internal/server/services/audit/models"
+	audit "gophKeeper/internal/server/services/audit/usecase"
+	"gophKeeper/pkg/srp"
+	"gophKeeper/util"
+	"strconv"
+	"strings"
+)
+
+type IAdminUsecase interface {
+	ListUsers(ctx context.Context, limit, offset int) (*models.UserPage, error)
+	DisableUser(ctx context.Context, adminId, userId int) error
+	EnableUser(ctx context.Context, adminId, userId int) error
+	ForceLogout(ctx context.Context, adminId, userId int) (int, error)
+	ResetTOTP(ctx context.Context, adminId, userId int) error
+	SetUserType(ctx context.Context, adminId, userId int, userType string) error
+	StorageUsage(ctx context.Context, userId int) (*models.StorageUsage, error)
+	Bootstrap(ctx context.Context, username, password string) (int, error)
+}
+
+type AdminUsecase struct {
+	repo  repository.IAdminRepo
+	audit audit.Recorder
+}
+
+func NewAdminUsecase(repo repository.IAdminRepo, recorder audit.Recorder) IAdminUsecase {
+	return &AdminUsecase{repo: repo, audit: recorder}
+}
+
+// record пишет действие администратора в журнал затронутого пользователя.
+func (u *AdminUsecase) record(ctx context.Context, action string, adminId, userId int, target string, err error) {
+	u.audit.Record(ctx, auditmodels.Event{
+		UserId: userId, ActorId: adminId, Action: action, Target: target, Success: err == nil,
+	})
+}
+
+// ListUsers возвращает страницу пользователей. Неположительный limit
+// заменяется размером по умолчанию, слишком большой — ограничивается.
+func (u *AdminUsecase) ListUsers(ctx context.Context, limit, offset int) (*models.UserPage, error) {
+	if limit <= 0 {
+		limit = models.DefaultPageSize
+	}
+	if limit > models.MaxPageSize {
+		limit = models.MaxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	users, total, err := u.repo.ListUsers(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserPage{Users: users, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// DisableUser запрещает вход и завершает все сессии пользователя. Отключить
+// себя администратор не может, чтобы не остаться без доступа к API.
+func (u *AdminUsecase) DisableUser(ctx context.Context, adminId, userId int) error {
+	if adminId == userId {
+		return domain.ErrAdminSelf
+	}
+	err := u.repo.SetDisabled(ctx, userId, true)
+	u.record(ctx, auditmodels.ActionAdminDisable, adminId, userId, strconv.Itoa(userId), err)
+	return err
+}
+
+func (u *AdminUsecase) EnableUser(ctx context.Context, adminId, userId int) error {
+	err := u.repo.SetDisabled(ctx, userId, false)
+	u.record(ctx, auditmodels.ActionAdminEnable, adminId, userId, strconv.Itoa(userId), err)
+	return err
+}
+
+// ForceLogout завершает вход пользователя на всех устройствах и возвращает
+// число отозванных сессий.
+func (u *AdminUsecase) ForceLogout(ctx context.Context, adminId, userId int) (int, error) {
+	revoked, err := u.repo.RevokeSessions(ctx, userId)
+	u.record(ctx, auditmodels.ActionAdminLogout, adminId, userId, strconv.Itoa(userId), err)
+	return revoked, err
+}
+
+func (u *AdminUsecase) ResetTOTP(ctx context.Context, adminId, userId int) error {
+	err := u.repo.ResetTOTP(ctx, userId)
+	u.record(ctx, auditmodels.ActionAdminResetTOTP, adminId, userId, strconv.Itoa(userId), err)
+	return err
+}
+
+// SetUserType меняет роль пользователя. Снять роль администратора с себя
+// нельзя по той же причине, что и отключить себя.
+func (u *AdminUsecase) SetUserType(ctx context.Context, adminId, userId int, userType string) error {
+	if !util.IsValidUserTypeForAdmin(userType) {
+		return domain.ErrInvalidUserType
+	}
+	if adminId == userId && userType != util.Admin {
+		return domain.ErrAdminSelf
+	}
+	err := u.repo.SetUserType(ctx, userId, userType)
+	u.record(ctx, auditmodels.ActionAdminRole, adminId, userId, userType, err)
+	return err
+}
+
+func (u *AdminUsecase) StorageUsage(ctx context.Context, userId int) (*models.StorageUsage, error) {
+	return u.repo.StorageUsage(ctx, userId)
+}
+
+// Bootstrap создаёт первого администратора. Команда запускается на сервере,
+// поэтому верификатор SRP вычисляется здесь же и пароль никуда не передаётся.
+// Для существующего пользователя пароль не меняется.
+func (u *AdminUsecase) Bootstrap(ctx context.Context, username, password string) (int, error) {
+	username = strings.TrimSpace(username)
+	if len(username) < 3 || len(password) < 6 {
+		return 0, domain.ErrInvalidInput
+	}
+	salt, err := srp.NewSalt()
+	if err != nil {
+		return 0, err
+	}
+	verifier := srp.Verifier(username, password, salt)
+	return u.repo.Bootstrap(ctx, username, hex.EncodeToString(salt), hex.EncodeToString(verifier))
+}
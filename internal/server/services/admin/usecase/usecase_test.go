@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"encoding/hex"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/admin/models"
+	"gophKeeper/internal/server/services/admin/repository"
+	auditmodels "gophKeeper/internal/server/services/audit/models"
+	audit "gophKeeper/internal/server/services/audit/usecase"
+	"gophKeeper/pkg/srp"
+	"gophKeeper/util"
+	"testing"
+)
+
+type fakeAdminRepo struct {
+	repository.IAdminRepo
+	limit, offset int
+	disabled      map[int]bool
+	types         map[int]string
+	salt          string
+	verifier      string
+}
+
+func (r *fakeAdminRepo) ListUsers(ctx context.Context, limit, offset int) ([]models.User, int, error) {
+	r.limit, r.offset = limit, offset
+	return []models.User{}, 0, nil
+}
+
+func (r *fakeAdminRepo) SetDisabled(ctx context.Context, userId int, disabled bool) error {
+	r.disabled[userId] = disabled
+	return nil
+}
+
+func (r *fakeAdminRepo) SetUserType(ctx context.Context, userId int, userType string) error {
+	r.types[userId] = userType
+	return nil
+}
+
+func (r *fakeAdminRepo) Bootstrap(ctx context.Context, username, salt, verifier string) (int, error) {
+	r.salt, r.verifier = salt, verifier
+	return 1, nil
+}
+
+func newFakeAdminRepo() *fakeAdminRepo {
+	return &fakeAdminRepo{disabled: map[int]bool{}, types: map[int]string{}}
+}
+
+func TestListUsersPaging(t *testing.T) {
+	repo := newFakeAdminRepo()
+	uc := NewAdminUsecase(repo, audit.Nop)
+
+	page, err := uc.ListUsers(context.Background(), 0, -5)
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if repo.limit != models.DefaultPageSize || repo.offset != 0 || page.Limit != models.DefaultPageSize {
+		t.Errorf("limit=%d offset=%d", repo.limit, repo.offset)
+	}
+	if _, err := uc.ListUsers(context.Background(), 100000, 20); err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if repo.limit != models.MaxPageSize || repo.offset != 20 {
+		t.Errorf("limit=%d offset=%d", repo.limit, repo.offset)
+	}
+}
+
+type fakeRecorder struct {
+	events []auditmodels.Event
+}
+
+func (r *fakeRecorder) Record(ctx context.Context, event auditmodels.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestAdminCannotLockOutThemselves(t *testing.T) {
+	repo := newFakeAdminRepo()
+	recorder := &fakeRecorder{}
+	uc := NewAdminUsecase(repo, recorder)
+	ctx := context.Background()
+
+	if err := uc.DisableUser(ctx, 1, 1); err != domain.ErrAdminSelf {
+		t.Errorf("Ожидалась ErrAdminSelf, получено: %v", err)
+	}
+	if err := uc.SetUserType(ctx, 1, 1, util.Attendee); err != domain.ErrAdminSelf {
+		t.Errorf("Ожидалась ErrAdminSelf, получено: %v", err)
+	}
+	if err := uc.SetUserType(ctx, 1, 2, "root"); err != domain.ErrInvalidUserType {
+		t.Errorf("Ожидалась ErrInvalidUserType, получено: %v", err)
+	}
+	if len(repo.disabled) != 0 || len(repo.types) != 0 {
+		t.Fatal("Изменения применены несмотря на ошибку")
+	}
+
+	if err := uc.DisableUser(ctx, 1, 2); err != nil || !repo.disabled[2] {
+		t.Errorf("DisableUser: %v", err)
+	}
+	if err := uc.SetUserType(ctx, 1, 2, util.Admin); err != nil || repo.types[2] != util.Admin {
+		t.Errorf("SetUserType: %v", err)
+	}
+
+	if len(recorder.events) != 2 {
+		t.Fatalf("Ожидалось 2 события аудита, получено: %d", len(recorder.events))
+	}
+	disable := recorder.events[0]
+	if disable.Action != auditmodels.ActionAdminDisable || disable.ActorId != 1 || disable.UserId != 2 || !disable.Success {
+		t.Errorf("Отключение записано неверно: %+v", disable)
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	repo := newFakeAdminRepo()
+	uc := NewAdminUsecase(repo, audit.Nop)
+
+	if _, err := uc.Bootstrap(context.Background(), "ro", "secret1"); err != domain.ErrInvalidInput {
+		t.Errorf("Ожидалась ErrInvalidInput, получено: %v", err)
+	}
+	if _, err := uc.Bootstrap(context.Background(), " root ", "secret1"); err != nil {
+		t.Fatalf("Bootstrap: %v", err)
+	}
+	salt, _ := hex.DecodeString(repo.salt)
+	if repo.verifier != hex.EncodeToString(srp.Verifier("root", "secret1", salt)) {
+		t.Error("Верификатор не соответствует имени и паролю")
+	}
+}
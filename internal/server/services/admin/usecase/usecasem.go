@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/services/admin/models"
+)
+
+type AdminUsecaseMock struct {
+	mock.Mock
+}
+
+func NewAdminUsecaseMock() *AdminUsecaseMock {
+	return &AdminUsecaseMock{}
+}
+
+func (u *AdminUsecaseMock) ListUsers(ctx context.Context, limit, offset int) (*models.UserPage, error) {
+	args := u.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserPage), args.Error(1)
+}
+
+func (u *AdminUsecaseMock) DisableUser(ctx context.Context, adminId, userId int) error {
+	args := u.Called(ctx, adminId, userId)
+	return args.Error(0)
+}
+
+func (u *AdminUsecaseMock) EnableUser(ctx context.Context, adminId, userId int) error {
+	args := u.Called(ctx, adminId, userId)
+	return args.Error(0)
+}
+
+func (u *AdminUsecaseMock) ForceLogout(ctx context.Context, adminId, userId int) (int, error) {
+	args := u.Called(ctx, adminId, userId)
+	return args.Int(0), args.Error(1)
+}
+
+func (u *AdminUsecaseMock) ResetTOTP(ctx context.Context, adminId, userId int) error {
+	args := u.Called(ctx, adminId, userId)
+	return args.Error(0)
+}
+
+func (u *AdminUsecaseMock) SetUserType(ctx context.Context, adminId, userId int, userType string) error {
+	args := u.Called(ctx, adminId, userId, userType)
+	return args.Error(0)
+}
+
+func (u *AdminUsecaseMock) StorageUsage(ctx context.Context, userId int) (*models.StorageUsage, error) {
+	args := u.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StorageUsage), args.Error(1)
+}
+
+func (u *AdminUsecaseMock) Bootstrap(ctx context.Context, username, password string) (int, error) {
+	args := u.Called(ctx, username, password)
+	return args.Int(0), args.Error(1)
+}
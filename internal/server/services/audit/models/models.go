@@ -0,0 +1,84 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Действия, которые попадают в журнал аудита.
+const (
+	ActionLoginSuccess    = "login.success"
+	ActionLoginFailure    = "login.failure"
+	ActionTokenRefresh    = "token.refresh"
+	ActionTokenRevoke     = "token.revoke"
+	ActionLockBoxCreate   = "lockbox.create"
+	ActionLockBoxRead     = "lockbox.read"
+	ActionLockBoxList     = "lockbox.list"
+	ActionLockBoxUpdate   = "lockbox.update"
+	ActionLockBoxDelete   = "lockbox.delete"
+	ActionLockBoxHistory  = "lockbox.history"
+	ActionLockBoxRestore  = "lockbox.restore"
+	ActionLockBoxTrash    = "lockbox.trash"
+	ActionLockBoxUndelete = "lockbox.undelete"
+	ActionLockBoxPurge    = "lockbox.purge"
+	ActionAdminDisable    = "admin.disable"
+	ActionAdminEnable     = "admin.enable"
+	ActionAdminLogout     = "admin.logout"
+	ActionAdminResetTOTP  = "admin.totp_reset"
+	ActionAdminRole       = "admin.role"
+	ActionAdminUnlock     = "admin.unlock"
+)
+
+const (
+	// DefaultPageSize — сколько событий отдаётся без явного limit.
+	DefaultPageSize = 50
+	// MaxPageSize ограничивает страницу журнала.
+	MaxPageSize = 500
+)
+
+// Event — запись журнала аудита. UserId — чей аккаунт затронут, ActorId —
+// администратор, если действие выполнил он. Target содержит только
+// идентификаторы (id записи, сессии, имя пользователя), но не содержимое.
+type Event struct {
+	ID        int64     `json:"id"`
+	UserId    int       `json:"user_id,omitempty"`
+	ActorId   int       `json:"actor_id,omitempty"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target,omitempty"`
+	Success   bool      `json:"success"`
+	IP        string    `json:"ip,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Username определяет UserId при неудачном входе, когда известно только
+	// введённое имя. В журнал не пишется.
+	Username string `json:"-"`
+	PrevHash string `json:"-"`
+	Hash     string `json:"-"`
+}
+
+// ComputeHash считает хеш записи вместе с хешем предыдущей. Изменение или
+// удаление любой записи рвёт цепочку начиная с неё.
+func (e *Event) ComputeHash() string {
+	fields := []string{
+		e.PrevHash,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		strconv.Itoa(e.UserId),
+		strconv.Itoa(e.ActorId),
+		e.Action,
+		e.Target,
+		strconv.FormatBool(e.Success),
+		e.IP,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ChainReport — результат проверки цепочки хешей. BrokenAt — id первой
+// записи, которая не сходится с предыдущими.
+type ChainReport struct {
+	Checked  int   `json:"checked"`
+	Valid    bool  `json:"valid"`
+	BrokenAt int64 `json:"broken_at,omitempty"`
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"gophKeeper/internal/server/db"
+	"gophKeeper/internal/server/services/audit/models"
+	"time"
+)
+
+// appendLockKey — ключ advisory-блокировки, под которой дописывается журнал:
+// две записи не должны сослаться на один и тот же предыдущий хеш.
+const appendLockKey = 0x617564697400
+
+type IAuditRepo interface {
+	Append(ctx context.Context, event *models.Event) error
+	List(ctx context.Context, userId, limit, offset int) ([]models.Event, error)
+	Walk(ctx context.Context, fn func(event *models.Event) error) error
+}
+
+type AuditRepo struct {
+	db db.IDatabase
+}
+
+func NewAuditRepo(db db.IDatabase) IAuditRepo {
+	return &AuditRepo{
+		db: db,
+	}
+}
+
+// Append дописывает событие в конец цепочки и заполняет его id, время и хеши.
+func (r *AuditRepo) Append(ctx context.Context, event *models.Event) error {
+	tx, err := r.db.GetDB().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, appendLockKey); err != nil {
+		return err
+	}
+
+	if event.UserId == 0 && event.Username != "" {
+		err := tx.QueryRow(ctx, `SELECT user_id FROM users WHERE username = $1`, event.Username).Scan(&event.UserId)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+	}
+
+	err = tx.QueryRow(ctx, `SELECT hash FROM audit_log ORDER BY audit_id DESC LIMIT 1`).Scan(&event.PrevHash)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	// Postgres хранит время с точностью до микросекунды; хеш считается от
+	// того же значения, которое потом будет прочитано.
+	event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	event.Hash = event.ComputeHash()
+
+	query := `INSERT INTO audit_log (user_id, actor_id, action, target, success, ip, created_at, prev_hash, hash)
+              VALUES (NULLIF($1, 0), NULLIF($2, 0), $3, $4, $5, $6, $7, $8, $9)
+              RETURNING audit_id`
+	err = tx.QueryRow(ctx, query, event.UserId, event.ActorId, event.Action, event.Target, event.Success,
+		event.IP, event.CreatedAt, event.PrevHash, event.Hash).Scan(&event.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+const selectEvents = `SELECT audit_id, COALESCE(user_id, 0), COALESCE(actor_id, 0), action, target, success, ip,
+                             created_at, prev_hash, hash
+                      FROM audit_log`
+
+func scanEvent(row pgx.Row) (*models.Event, error) {
+	var event models.Event
+	err := row.Scan(&event.ID, &event.UserId, &event.ActorId, &event.Action, &event.Target, &event.Success,
+		&event.IP, &event.CreatedAt, &event.PrevHash, &event.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// List возвращает события аккаунта userId, новые первыми.
+func (r *AuditRepo) List(ctx context.Context, userId, limit, offset int) ([]models.Event, error) {
+	rows, err := r.db.GetDB().Query(ctx, selectEvents+` WHERE user_id = $1 ORDER BY audit_id DESC LIMIT $2 OFFSET $3`,
+		userId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	return events, rows.Err()
+}
+
+// Walk передаёт fn все события по порядку цепочки. Ошибка fn прекращает обход.
+func (r *AuditRepo) Walk(ctx context.Context, fn func(event *models.Event) error) error {
+	rows, err := r.db.GetDB().Query(ctx, selectEvents+` ORDER BY audit_id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"gophKeeper/internal/server/services/audit/models"
+	"gophKeeper/internal/server/services/audit/repository"
+	"gophKeeper/internal/server/services/auth/attempts"
+	"log/slog"
+)
+
+// Recorder записывает события в журнал аудита. Его получают usecase,
+// действия которых нужно учитывать.
+type Recorder interface {
+	Record(ctx context.Context, event models.Event)
+}
+
+type nopRecorder struct{}
+
+func (nopRecorder) Record(context.Context, models.Event) {}
+
+// Nop ничего не записывает; используется там, где журнал не нужен, например в тестах.
+var Nop Recorder = nopRecorder{}
+
+type IAuditUsecase interface {
+	Recorder
+	ListEvents(ctx context.Context, userId, limit, offset int) ([]models.Event, error)
+	Verify(ctx context.Context) (*models.ChainReport, error)
+}
+
+type AuditUsecase struct {
+	repo repository.IAuditRepo
+}
+
+func NewAuditUsecase(repo repository.IAuditRepo) IAuditUsecase {
+	return &AuditUsecase{repo: repo}
+}
+
+// Record дописывает событие в журнал. Адрес клиента берётся из контекста
+// запроса. Сбой журнала не отменяет само действие и только пишется в лог.
+func (u *AuditUsecase) Record(ctx context.Context, event models.Event) {
+	if event.IP == "" {
+		event.IP = attempts.ClientIP(ctx)
+	}
+	if err := u.repo.Append(ctx, &event); err != nil {
+		slog.Error("Failed to write audit event", "action", event.Action, "userId", event.UserId, "error", err)
+	}
+}
+
+// ListEvents возвращает страницу событий аккаунта, новые первыми.
+func (u *AuditUsecase) ListEvents(ctx context.Context, userId, limit, offset int) ([]models.Event, error) {
+	if limit <= 0 {
+		limit = models.DefaultPageSize
+	}
+	if limit > models.MaxPageSize {
+		limit = models.MaxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return u.repo.List(ctx, userId, limit, offset)
+}
+
+var errChainBroken = errors.New("audit chain is broken")
+
+// Verify проходит журнал целиком и проверяет, что каждая запись ссылается на
+// хеш предыдущей, а её собственный хеш сходится с содержимым.
+func (u *AuditUsecase) Verify(ctx context.Context) (*models.ChainReport, error) {
+	report := &models.ChainReport{Valid: true}
+	prev := ""
+	err := u.repo.Walk(ctx, func(event *models.Event) error {
+		if event.PrevHash != prev || event.ComputeHash() != event.Hash {
+			report.Valid = false
+			report.BrokenAt = event.ID
+			return errChainBroken
+		}
+		prev = event.Hash
+		report.Checked++
+		return nil
+	})
+	if err != nil && !errors.Is(err, errChainBroken) {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"gophKeeper/internal/server/services/audit/models"
+	"gophKeeper/internal/server/services/audit/repository"
+	"gophKeeper/internal/server/services/auth/attempts"
+	"testing"
+	"time"
+)
+
+// fakeAuditRepo держит журнал в памяти и строит цепочку так же, как AuditRepo.
+type fakeAuditRepo struct {
+	repository.IAuditRepo
+	events        []models.Event
+	limit, offset int
+}
+
+func (r *fakeAuditRepo) Append(ctx context.Context, event *models.Event) error {
+	if n := len(r.events); n > 0 {
+		event.PrevHash = r.events[n-1].Hash
+	}
+	event.ID = int64(len(r.events) + 1)
+	event.CreatedAt = time.Now().UTC()
+	event.Hash = event.ComputeHash()
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *fakeAuditRepo) List(ctx context.Context, userId, limit, offset int) ([]models.Event, error) {
+	r.limit, r.offset = limit, offset
+	return nil, nil
+}
+
+func (r *fakeAuditRepo) Walk(ctx context.Context, fn func(event *models.Event) error) error {
+	for i := range r.events {
+		event := r.events[i]
+		if err := fn(&event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestVerifyChain(t *testing.T) {
+	repo := &fakeAuditRepo{}
+	uc := NewAuditUsecase(repo)
+	ctx := attempts.WithClientIP(context.Background(), "10.0.0.1")
+
+	uc.Record(ctx, models.Event{UserId: 1, Action: models.ActionLoginSuccess, Target: "5", Success: true})
+	uc.Record(ctx, models.Event{UserId: 1, Action: models.ActionLockBoxRead, Target: "42", Success: true})
+	uc.Record(ctx, models.Event{UserId: 2, ActorId: 1, Action: models.ActionAdminDisable, Target: "2", Success: true})
+	if repo.events[0].IP != "10.0.0.1" {
+		t.Errorf("Адрес клиента не записан: %q", repo.events[0].IP)
+	}
+
+	report, err := uc.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if !report.Valid || report.Checked != 3 {
+		t.Fatalf("Цепочка должна сходиться: %+v", report)
+	}
+
+	repo.events[1].Target = "43"
+	report, err = uc.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if report.Valid || report.BrokenAt != 2 || report.Checked != 1 {
+		t.Fatalf("Изменение записи должно рвать цепочку: %+v", report)
+	}
+
+	repo.events = append(repo.events[:1], repo.events[2:]...)
+	report, _ = uc.Verify(ctx)
+	if report.Valid || report.BrokenAt != 3 {
+		t.Fatalf("Удаление записи должно рвать цепочку: %+v", report)
+	}
+}
+
+func TestListEventsPaging(t *testing.T) {
+	repo := &fakeAuditRepo{}
+	uc := NewAuditUsecase(repo)
+
+	if _, err := uc.ListEvents(context.Background(), 1, 0, -1); err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if repo.limit != models.DefaultPageSize || repo.offset != 0 {
+		t.Errorf("limit=%d offset=%d", repo.limit, repo.offset)
+	}
+	if _, err := uc.ListEvents(context.Background(), 1, 100000, 10); err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if repo.limit != models.MaxPageSize || repo.offset != 10 {
+		t.Errorf("limit=%d offset=%d", repo.limit, repo.offset)
+	}
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/services/audit/models"
+)
+
+type AuditUsecaseMock struct {
+	mock.Mock
+}
+
+func NewAuditUsecaseMock() *AuditUsecaseMock {
+	return &AuditUsecaseMock{}
+}
+
+func (u *AuditUsecaseMock) Record(ctx context.Context, event models.Event) {
+	u.Called(ctx, event)
+}
+
+func (u *AuditUsecaseMock) ListEvents(ctx context.Context, userId, limit, offset int) ([]models.Event, error) {
+	args := u.Called(ctx, userId, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (u *AuditUsecaseMock) Verify(ctx context.Context) (*models.ChainReport, error) {
+	args := u.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChainReport), args.Error(1)
+}
@@ -0,0 +1,239 @@
+// Package attempts ограничивает подбор паролей: после нескольких неудачных
+// входов каждая следующая попытка для того же имени или адреса откладывается
+// вдвое дольше, а после LockoutThreshold ошибок аккаунт блокируется на время.
+package attempts
+
+import (
+	"context"
+	"fmt"
+	"gophKeeper/internal/server/config"
+	"gophKeeper/internal/server/db"
+	"gophKeeper/internal/server/domain"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Record — неудачные попытки входа по одному ключу (имени или адресу).
+type Record struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// Store хранит попытки. Память подходит для одного экземпляра сервера,
+// Postgres — когда экземпляров несколько.
+type Store interface {
+	Get(ctx context.Context, key string) (Record, error)
+	// Fail увеличивает счётчик; если последняя ошибка старше since, счёт начинается заново.
+	Fail(ctx context.Context, key string, now, since time.Time) (Record, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+	// Cleanup удаляет записи без блокировки, последняя ошибка которых старше before.
+	Cleanup(ctx context.Context, before time.Time) (int, error)
+}
+
+// Policy — параметры задержек и блокировки.
+type Policy struct {
+	// FreeAttempts — сколько ошибок для имени пользователя допускается без задержки.
+	FreeAttempts int
+	// IPFreeAttempts — то же для адреса: за одним NAT бывает много пользователей.
+	IPFreeAttempts int
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	// LockoutThreshold — после стольких ошибок подряд аккаунт блокируется.
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// Window — через сколько после последней ошибки счётчик забывается.
+	Window time.Duration
+}
+
+// DefaultPolicy — задержки 1с, 2с, 4с… до 15 минут после трёх ошибок и
+// блокировка на 30 минут после десяти.
+func DefaultPolicy() Policy {
+	return Policy{
+		FreeAttempts:     3,
+		IPFreeAttempts:   10,
+		BaseDelay:        time.Second,
+		MaxDelay:         15 * time.Minute,
+		LockoutThreshold: 10,
+		LockoutDuration:  30 * time.Minute,
+		Window:           24 * time.Hour,
+	}
+}
+
+// BlockedError — попытка входа отклонена до её проверки. errors.Is сопоставляет
+// её с domain.ErrAccountLocked или domain.ErrTooManyAttempts.
+type BlockedError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *BlockedError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("%s, retry after %s", domain.ErrAccountLocked, e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("%s, retry after %s", domain.ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *BlockedError) Is(target error) bool {
+	if e.Locked {
+		return target == domain.ErrAccountLocked
+	}
+	return target == domain.ErrTooManyAttempts
+}
+
+type Guard struct {
+	store  Store
+	policy Policy
+	now    func() time.Time
+}
+
+func NewGuard(store Store, policy Policy) *Guard {
+	return &Guard{store: store, policy: policy, now: time.Now}
+}
+
+func userKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// delay — сколько ждать после failures ошибок: 0, пока их меньше free, затем
+// BaseDelay, удваиваясь с каждой ошибкой до MaxDelay.
+func (g *Guard) delay(failures, free int) time.Duration {
+	if failures < free {
+		return 0
+	}
+	delay := g.policy.BaseDelay
+	for i := free; i < failures && delay < g.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, g.policy.MaxDelay)
+}
+
+func (g *Guard) check(ctx context.Context, key string, free int, now time.Time) error {
+	rec, err := g.store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if rec.LockedUntil.After(now) {
+		return &BlockedError{RetryAfter: rec.LockedUntil.Sub(now), Locked: true}
+	}
+	if rec.LastFailure.Before(now.Add(-g.policy.Window)) {
+		return nil
+	}
+	if until := rec.LastFailure.Add(g.delay(rec.Failures, free)); until.After(now) {
+		return &BlockedError{RetryAfter: until.Sub(now)}
+	}
+	return nil
+}
+
+// Check отклоняет попытку, если имя заблокировано или с прошлой ошибки для
+// имени или адреса прошло меньше положенной задержки. Пустой ip не проверяется.
+func (g *Guard) Check(ctx context.Context, username, ip string) error {
+	now := g.now()
+	if err := g.check(ctx, userKey(username), g.policy.FreeAttempts, now); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return g.check(ctx, ipKey(ip), g.policy.IPFreeAttempts, now)
+}
+
+// Failure учитывает неудачную попытку. Несуществующие имена учитываются так
+// же, чтобы по задержкам нельзя было понять, зарегистрировано ли имя.
+func (g *Guard) Failure(ctx context.Context, username, ip string) error {
+	now := g.now()
+	since := now.Add(-g.policy.Window)
+	rec, err := g.store.Fail(ctx, userKey(username), now, since)
+	if err != nil {
+		return err
+	}
+	if g.policy.LockoutThreshold > 0 && rec.Failures >= g.policy.LockoutThreshold && !rec.LockedUntil.After(now) {
+		slog.Warn("Account locked after failed logins", "username", username, "ip", ip, "failures", rec.Failures)
+		if err := g.store.Lock(ctx, userKey(username), now.Add(g.policy.LockoutDuration)); err != nil {
+			return err
+		}
+	}
+	if ip == "" {
+		return nil
+	}
+	_, err = g.store.Fail(ctx, ipKey(ip), now, since)
+	return err
+}
+
+// Success сбрасывает счётчик имени после завершённого входа. Счётчик адреса
+// не сбрасывается: иначе перебор чужих паролей можно перемежать входом в свой аккаунт.
+func (g *Guard) Success(ctx context.Context, username string) error {
+	return g.store.Reset(ctx, userKey(username))
+}
+
+// Unlock снимает блокировку и задержки с аккаунта (действие администратора).
+func (g *Guard) Unlock(ctx context.Context, username string) error {
+	return g.store.Reset(ctx, userKey(username))
+}
+
+// RunJanitor периодически удаляет забытые счётчики, пока не отменён ctx.
+func (g *Guard) RunJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			removed, err := g.store.Cleanup(ctx, g.now().Add(-g.policy.Window))
+			if err != nil {
+				slog.Error("Failed to clean up login attempts", "error", err)
+				continue
+			}
+			if removed > 0 {
+				slog.Debug("Login attempts cleaned up", "removed", removed)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+type ipKeyType struct{}
+
+// WithClientIP сохраняет адрес клиента в контексте запроса: по нему Guard
+// считает попытки входа с одного адреса.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, ipKeyType{}, ip)
+}
+
+// ClientIP возвращает адрес, сохранённый WithClientIP.
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(ipKeyType{}).(string)
+	return ip
+}
+
+// NewPolicy дополняет DefaultPolicy заданными в конфигурации значениями.
+func NewPolicy(conf config.LoginConf) Policy {
+	policy := DefaultPolicy()
+	if conf.FreeAttempts > 0 {
+		policy.FreeAttempts = conf.FreeAttempts
+	}
+	if conf.MaxDelay > 0 {
+		policy.MaxDelay = conf.MaxDelay
+	}
+	if conf.LockoutThreshold > 0 {
+		policy.LockoutThreshold = conf.LockoutThreshold
+	}
+	if conf.LockoutDuration > 0 {
+		policy.LockoutDuration = conf.LockoutDuration
+	}
+	return policy
+}
+
+// NewStore создаёт хранилище, выбранное в конфигурации.
+func NewStore(conf config.LoginConf, database db.IDatabase) Store {
+	if conf.Store == "postgres" {
+		return NewPostgresStore(database)
+	}
+	return NewMemoryStore()
+}
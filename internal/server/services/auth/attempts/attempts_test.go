@@ -0,0 +1,106 @@
+package attempts
+
+import (
+	"context"
+	"errors"
+	"gophKeeper/internal/server/domain"
+	"testing"
+	"time"
+)
+
+func newTestGuard(now *time.Time) *Guard {
+	g := NewGuard(NewMemoryStore(), DefaultPolicy())
+	g.now = func() time.Time { return *now }
+	return g
+}
+
+func TestBackoff(t *testing.T) {
+	now := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+	g := newTestGuard(&now)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := g.Check(ctx, "alice", "10.0.0.1"); err != nil {
+			t.Fatalf("Попытка %d не должна задерживаться: %v", i+1, err)
+		}
+		g.Failure(ctx, "alice", "10.0.0.1")
+	}
+
+	var blocked *BlockedError
+	err := g.Check(ctx, "Alice", "10.0.0.2")
+	if !errors.As(err, &blocked) || !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Fatalf("Ожидалась ErrTooManyAttempts, получено: %v", err)
+	}
+	if blocked.RetryAfter != time.Second {
+		t.Errorf("Первая задержка должна быть 1s, получили %s", blocked.RetryAfter)
+	}
+
+	now = now.Add(time.Second)
+	if err := g.Check(ctx, "alice", "10.0.0.1"); err != nil {
+		t.Fatalf("После задержки попытка должна пройти: %v", err)
+	}
+	g.Failure(ctx, "alice", "10.0.0.1")
+	if err := g.Check(ctx, "alice", "10.0.0.1"); !errors.As(err, &blocked) || blocked.RetryAfter != 2*time.Second {
+		t.Errorf("Вторая задержка должна быть 2s, получено: %v", err)
+	}
+
+	if err := g.Check(ctx, "bob", "10.0.0.1"); err != nil {
+		t.Errorf("Четыре ошибки с адреса не должны задерживать другие имена: %v", err)
+	}
+}
+
+func TestDelayIsCapped(t *testing.T) {
+	g := NewGuard(NewMemoryStore(), DefaultPolicy())
+	if got := g.delay(2, 3); got != 0 {
+		t.Errorf("delay(2) = %s, ожидался 0", got)
+	}
+	if got := g.delay(5, 3); got != 4*time.Second {
+		t.Errorf("delay(5) = %s, ожидалось 4s", got)
+	}
+	if got := g.delay(1000, 3); got != 15*time.Minute {
+		t.Errorf("delay(1000) = %s, ожидалось 15m", got)
+	}
+}
+
+func TestLockoutAndUnlock(t *testing.T) {
+	now := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+	g := newTestGuard(&now)
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Hour)
+		g.Failure(ctx, "alice", "")
+	}
+	if err := g.Check(ctx, "alice", ""); !errors.Is(err, domain.ErrAccountLocked) {
+		t.Fatalf("После 10 ошибок ожидалась ErrAccountLocked, получено: %v", err)
+	}
+
+	if err := g.Unlock(ctx, "alice"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := g.Check(ctx, "alice", ""); err != nil {
+		t.Errorf("После разблокировки вход должен быть разрешён: %v", err)
+	}
+}
+
+func TestWindowForgetsFailures(t *testing.T) {
+	now := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+	g := newTestGuard(&now)
+	store := g.store.(*MemoryStore)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		g.Failure(ctx, "alice", "10.0.0.1")
+	}
+	now = now.Add(25 * time.Hour)
+	if err := g.Check(ctx, "alice", "10.0.0.1"); err != nil {
+		t.Errorf("Ошибки старше окна не должны учитываться: %v", err)
+	}
+	g.Failure(ctx, "alice", "")
+	if rec, _ := store.Get(ctx, userKey("alice")); rec.Failures != 1 {
+		t.Errorf("Счётчик должен начаться заново, получили %d", rec.Failures)
+	}
+	if removed, _ := store.Cleanup(ctx, now.Add(-time.Hour)); removed != 1 {
+		t.Errorf("Cleanup должен удалить только запись адреса, удалено %d", removed)
+	}
+}
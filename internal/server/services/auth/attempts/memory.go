@@ -0,0 +1,65 @@
+package attempts
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore хранит попытки в памяти процесса.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records[key], nil
+}
+
+func (s *MemoryStore) Fail(ctx context.Context, key string, now, since time.Time) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.records[key]
+	if rec.LastFailure.Before(since) {
+		rec.Failures = 0
+	}
+	rec.Failures++
+	rec.LastFailure = now
+	s.records[key] = rec
+	return rec, nil
+}
+
+func (s *MemoryStore) Lock(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.records[key]
+	rec.LockedUntil = until
+	s.records[key] = rec
+	return nil
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func (s *MemoryStore) Cleanup(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := 0
+	for key, rec := range s.records {
+		if rec.LastFailure.Before(before) && rec.LockedUntil.Before(time.Now()) {
+			delete(s.records, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
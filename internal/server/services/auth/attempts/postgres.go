@@ -0,0 +1,76 @@
+package attempts
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"gophKeeper/internal/server/db"
+	"time"
+)
+
+// PostgresStore хранит попытки в таблице login_attempts: счётчики общие для
+// всех экземпляров сервера и переживают перезапуск.
+type PostgresStore struct {
+	db db.IDatabase
+}
+
+func NewPostgresStore(db db.IDatabase) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Get(ctx context.Context, key string) (Record, error) {
+	var rec Record
+	var lockedUntil *time.Time
+	query := `SELECT failures, last_failure, locked_until FROM login_attempts WHERE attempt_key = $1`
+	err := s.db.GetDB().QueryRow(ctx, query, key).Scan(&rec.Failures, &rec.LastFailure, &lockedUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Record{}, nil
+		}
+		return Record{}, err
+	}
+	if lockedUntil != nil {
+		rec.LockedUntil = *lockedUntil
+	}
+	return rec, nil
+}
+
+func (s *PostgresStore) Fail(ctx context.Context, key string, now, since time.Time) (Record, error) {
+	var rec Record
+	var lockedUntil *time.Time
+	query := `INSERT INTO login_attempts (attempt_key, failures, last_failure)
+              VALUES ($1, 1, $2)
+              ON CONFLICT (attempt_key) DO UPDATE SET
+                  failures = CASE WHEN login_attempts.last_failure < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+                  last_failure = EXCLUDED.last_failure
+              RETURNING failures, last_failure, locked_until`
+	err := s.db.GetDB().QueryRow(ctx, query, key, now, since).Scan(&rec.Failures, &rec.LastFailure, &lockedUntil)
+	if err != nil {
+		return Record{}, err
+	}
+	if lockedUntil != nil {
+		rec.LockedUntil = *lockedUntil
+	}
+	return rec, nil
+}
+
+func (s *PostgresStore) Lock(ctx context.Context, key string, until time.Time) error {
+	query := `UPDATE login_attempts SET locked_until = $2 WHERE attempt_key = $1`
+	_, err := s.db.GetDB().Exec(ctx, query, key, until)
+	return err
+}
+
+func (s *PostgresStore) Reset(ctx context.Context, key string) error {
+	_, err := s.db.GetDB().Exec(ctx, `DELETE FROM login_attempts WHERE attempt_key = $1`, key)
+	return err
+}
+
+func (s *PostgresStore) Cleanup(ctx context.Context, before time.Time) (int, error) {
+	query := `DELETE FROM login_attempts
+              WHERE last_failure < $1 AND (locked_until IS NULL OR locked_until < now())`
+	tag, err := s.db.GetDB().Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -1,5 +1,12 @@
 package models
 
+import (
+	"encoding/hex"
+	"gophKeeper/pkg/srp"
+	"strings"
+	"time"
+)
+
 type AuthUser struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -9,4 +16,122 @@ type InfoUser struct {
 	UserId   int
 	Username string
 	UserType string
+	// Disabled — аккаунт отключён администратором, вход запрещён.
+	Disabled bool
+}
+
+// SRPInit — первый шаг входа по SRP-6a: имя пользователя и публичный ключ A клиента.
+type SRPInit struct {
+	Username string `json:"username" binding:"required"`
+	A        string `json:"a" binding:"required"` // hex
+}
+
+// SRPChallenge — ответ сервера на первый шаг. Session связывает два шага входа.
+type SRPChallenge struct {
+	Session string `json:"session"`
+	Salt    string `json:"salt"` // hex
+	B       string `json:"b"`    // hex
+}
+
+// SRPVerify — второй шаг: доказательство клиента M1.
+type SRPVerify struct {
+	Session string `json:"session" binding:"required"`
+	M1      string `json:"m1" binding:"required"` // hex
+}
+
+// SRPVerifier — соль и верификатор, которые сервер хранит вместо пароля.
+type SRPVerifier struct {
+	Salt     string `json:"srp_salt" binding:"required"`     // hex
+	Verifier string `json:"srp_verifier" binding:"required"` // hex
+}
+
+// Valid проверяет, что соль и верификатор — hex-строки разумной длины.
+func (v *SRPVerifier) Valid() bool {
+	salt, err1 := hex.DecodeString(v.Salt)
+	verifier, err2 := hex.DecodeString(v.Verifier)
+	return err1 == nil && err2 == nil && len(salt) >= srp.SaltSize && len(verifier) > 0
+}
+
+type SRPCredentials struct {
+	InfoUser
+	SRPVerifier
+}
+
+// SessionMeta — данные устройства, с которого выполнен вход. DeviceName и OS
+// клиент передаёт сам; без них устройство узнаётся по UserAgent.
+type SessionMeta struct {
+	DeviceName string
+	OS         string
+	UserAgent  string
+	IP         string
+}
+
+// NewSessionMeta обрезает поля, которые клиент задаёт сам, до размеров колонок.
+func NewSessionMeta(deviceName, os, userAgent, ip string) SessionMeta {
+	return SessionMeta{
+		DeviceName: truncate(strings.TrimSpace(deviceName), 128),
+		OS:         truncate(strings.TrimSpace(os), 32),
+		UserAgent:  userAgent,
+		IP:         ip,
+	}
+}
+
+func truncate(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
+
+// Session — сессия входа. Ей принадлежат refresh-токен и все access-токены,
+// выпущенные по нему; отзыв сессии делает их недействительными.
+type Session struct {
+	SessionId  int       `json:"session_id"`
+	UserId     int       `json:"user_id"`
+	DeviceName string    `json:"device_name"`
+	OS         string    `json:"os"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Current отмечает сессию, токеном которой сделан запрос.
+	Current bool `json:"current"`
+}
+
+// MFAChallenge — ответ на вход пользователя с включённой 2FA: вместо токенов
+// сервер выдаёт короткоживущий MFAToken, который обменивается на них вместе с кодом.
+type MFAChallenge struct {
+	Required bool   `json:"mfa_required"`
+	Token    string `json:"mfa_token"`
+}
+
+// MFAVerify — второй фактор входа: код из приложения или код восстановления.
+type MFAVerify struct {
+	Token string `json:"mfa_token" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
+
+// TOTPCode — код подтверждения при включении и отключении 2FA.
+type TOTPCode struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TOTP — настройки второго фактора пользователя. Пока Enabled == false,
+// секрет выдан, но вход без кода ещё возможен.
+type TOTP struct {
+	Secret   string
+	Enabled  bool
+	LastStep int64
+}
+
+// TOTPEnrollment — секрет для приложения-аутентификатора и ссылка otpauth://.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
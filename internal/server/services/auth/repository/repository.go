@@ -2,15 +2,30 @@ package repository
 
 import (
 	"context"
-	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5"
 	"golang.org/x/crypto/bcrypt"
 	"gophKeeper/internal/server/db"
 	"gophKeeper/internal/server/domain"
 	"gophKeeper/internal/server/services/auth/models"
+	"time"
 )
 
 type IAuthRepo interface {
 	GetInfoUser(ctx context.Context, user *models.AuthUser) (*models.InfoUser, error)
+	GetSRPCredentials(ctx context.Context, username string) (*models.SRPCredentials, error)
+	SetSRPVerifier(ctx context.Context, userId int, verifier *models.SRPVerifier) error
+	CreateSession(ctx context.Context, userId int, refreshHash string, meta models.SessionMeta, expiresAt time.Time) (int, error)
+	RotateSession(ctx context.Context, refreshHash, newHash string, meta models.SessionMeta, expiresAt time.Time) (*models.InfoUser, int, error)
+	RevokeSession(ctx context.Context, userId, sessionId int) error
+	ListSessions(ctx context.Context, userId int) ([]models.Session, error)
+	RevokeOtherSessions(ctx context.Context, userId, keepId int) (int, error)
+	GetTOTP(ctx context.Context, userId int) (*models.TOTP, error)
+	SetTOTPSecret(ctx context.Context, userId int, secret string) error
+	EnableTOTP(ctx context.Context, userId int, step int64, recoveryHashes []string) error
+	DisableTOTP(ctx context.Context, userId int) error
+	UseTOTPStep(ctx context.Context, userId int, step int64) error
+	UseRecoveryCode(ctx context.Context, userId int, codeHash string) error
 }
 
 type authRepository struct {
@@ -27,11 +42,14 @@ func (a *authRepository) GetInfoUser(ctx context.Context, user *models.AuthUser)
 	var infoUser models.InfoUser
 	var storedPasswordHash string
 
-	query := `SELECT user_id, username, user_type, password_hash FROM "users" WHERE username = $1`
+	// Аккаунт с верификатором SRP входит только по SRP, даже если хеш пароля
+	// почему-то остался.
+	query := `SELECT user_id, username, user_type, disabled_at IS NOT NULL, COALESCE(password_hash, '')
+              FROM "users" WHERE username = $1 AND srp_verifier IS NULL`
 	row := a.db.GetDB().QueryRow(ctx, query, user.Username)
-	err := row.Scan(&infoUser.UserId, &infoUser.Username, &infoUser.UserType, &storedPasswordHash)
+	err := row.Scan(&infoUser.UserId, &infoUser.Username, &infoUser.UserType, &infoUser.Disabled, &storedPasswordHash)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrInvalidCredentials
 		}
 		return nil, err
@@ -44,3 +62,227 @@ func (a *authRepository) GetInfoUser(ctx context.Context, user *models.AuthUser)
 
 	return &infoUser, nil
 }
+
+// GetSRPCredentials возвращает соль и верификатор пользователя. Пользователи,
+// зарегистрированные до перехода на SRP, получают ErrSRPNotSet.
+func (a *authRepository) GetSRPCredentials(ctx context.Context, username string) (*models.SRPCredentials, error) {
+	var creds models.SRPCredentials
+
+	query := `SELECT user_id, username, user_type, disabled_at IS NOT NULL, COALESCE(srp_salt, ''), COALESCE(srp_verifier, '')
+              FROM "users" WHERE username = $1`
+	err := a.db.GetDB().QueryRow(ctx, query, username).Scan(
+		&creds.UserId, &creds.Username, &creds.UserType, &creds.Disabled, &creds.Salt, &creds.Verifier)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if creds.Verifier == "" {
+		return nil, domain.ErrSRPNotSet
+	}
+	return &creds, nil
+}
+
+// SetSRPVerifier сохраняет верификатор и удаляет хеш пароля: после этого
+// войти можно только через SRP.
+func (a *authRepository) SetSRPVerifier(ctx context.Context, userId int, verifier *models.SRPVerifier) error {
+	query := `UPDATE users SET srp_salt = $2, srp_verifier = $3, password_hash = NULL WHERE user_id = $1`
+	tag, err := a.db.GetDB().Exec(ctx, query, userId, verifier.Salt, verifier.Verifier)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (a *authRepository) CreateSession(ctx context.Context, userId int, refreshHash string, meta models.SessionMeta, expiresAt time.Time) (int, error) {
+	var sessionId int
+	query := `INSERT INTO sessions (user_id, refresh_hash, device_name, os, user_agent, ip, expires_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING session_id`
+	err := a.db.GetDB().QueryRow(ctx, query, userId, refreshHash, meta.DeviceName, meta.OS, meta.UserAgent, meta.IP, expiresAt).Scan(&sessionId)
+	if err != nil {
+		return 0, err
+	}
+	return sessionId, nil
+}
+
+// RotateSession заменяет refresh-токен сессии новым. Повторное предъявление
+// уже заменённого токена означает, что его украли: сессия отзывается.
+// Адрес устройства обновляется: ноутбук мог сменить сеть.
+func (a *authRepository) RotateSession(ctx context.Context, refreshHash, newHash string, meta models.SessionMeta, expiresAt time.Time) (*models.InfoUser, int, error) {
+	var infoUser models.InfoUser
+	var sessionId int
+
+	query := `UPDATE sessions s
+              SET refresh_hash = $2, prev_refresh_hash = $1, last_used_at = now(), expires_at = $3,
+                  ip = COALESCE(NULLIF($4, ''), s.ip)
+              FROM users u
+              WHERE s.refresh_hash = $1 AND s.revoked_at IS NULL AND s.expires_at > now()
+                AND u.user_id = s.user_id AND u.disabled_at IS NULL
+              RETURNING s.session_id, u.user_id, u.username, u.user_type`
+	err := a.db.GetDB().QueryRow(ctx, query, refreshHash, newHash, expiresAt, meta.IP).Scan(
+		&sessionId, &infoUser.UserId, &infoUser.Username, &infoUser.UserType)
+	if err == nil {
+		return &infoUser, sessionId, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, err
+	}
+
+	reuse := `UPDATE sessions SET revoked_at = now() WHERE prev_refresh_hash = $1 AND revoked_at IS NULL`
+	if _, err := a.db.GetDB().Exec(ctx, reuse, refreshHash); err != nil {
+		return nil, 0, err
+	}
+	return nil, 0, domain.ErrInvalidRefresh
+}
+
+func (a *authRepository) RevokeSession(ctx context.Context, userId, sessionId int) error {
+	query := `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND session_id = $2 AND revoked_at IS NULL`
+	tag, err := a.db.GetDB().Exec(ctx, query, userId, sessionId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+// ListSessions возвращает действующие сессии пользователя, начиная с последней активной.
+func (a *authRepository) ListSessions(ctx context.Context, userId int) ([]models.Session, error) {
+	query := `SELECT session_id, user_id, device_name, os, user_agent, ip, created_at, last_used_at, expires_at
+              FROM sessions
+              WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now()
+              ORDER BY last_used_at DESC`
+	rows, err := a.db.GetDB().Query(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.SessionId, &session.UserId, &session.DeviceName, &session.OS,
+			&session.UserAgent, &session.IP, &session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeOtherSessions отзывает все сессии пользователя, кроме keepId, и
+// возвращает число отозванных.
+func (a *authRepository) RevokeOtherSessions(ctx context.Context, userId, keepId int) (int, error) {
+	query := `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND session_id <> $2 AND revoked_at IS NULL`
+	tag, err := a.db.GetDB().Exec(ctx, query, userId, keepId)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (a *authRepository) GetTOTP(ctx context.Context, userId int) (*models.TOTP, error) {
+	var totp models.TOTP
+	query := `SELECT COALESCE(totp_secret, ''), totp_enabled, totp_last_step FROM users WHERE user_id = $1`
+	err := a.db.GetDB().QueryRow(ctx, query, userId).Scan(&totp.Secret, &totp.Enabled, &totp.LastStep)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// SetTOTPSecret сохраняет новый секрет, пока 2FA не включена: повторная
+// попытка подключить приложение заменяет неподтверждённый секрет.
+func (a *authRepository) SetTOTPSecret(ctx context.Context, userId int, secret string) error {
+	query := `UPDATE users SET totp_secret = $2 WHERE user_id = $1 AND NOT totp_enabled`
+	tag, err := a.db.GetDB().Exec(ctx, query, userId, secret)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTOTPEnabled
+	}
+	return nil
+}
+
+// EnableTOTP включает 2FA и заменяет коды восстановления в одной транзакции.
+// step — шаг подтверждающего кода: повторно им войти нельзя.
+func (a *authRepository) EnableTOTP(ctx context.Context, userId int, step int64, recoveryHashes []string) error {
+	tx, err := a.db.GetDB().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE users SET totp_enabled = TRUE, totp_last_step = $2
+              WHERE user_id = $1 AND totp_secret IS NOT NULL AND NOT totp_enabled`
+	tag, err := tx.Exec(ctx, query, userId, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTOTPEnabled
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userId); err != nil {
+		return err
+	}
+	for _, hash := range recoveryHashes {
+		if _, err := tx.Exec(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userId, hash); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (a *authRepository) DisableTOTP(ctx context.Context, userId int) error {
+	tx, err := a.db.GetDB().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE users SET totp_secret = NULL, totp_enabled = FALSE, totp_last_step = 0 WHERE user_id = $1`
+	if _, err := tx.Exec(ctx, query, userId); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userId); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UseTOTPStep запоминает шаг принятого кода. Код того же или более раннего
+// шага уже использован: его перехватили или отправили повторно.
+func (a *authRepository) UseTOTPStep(ctx context.Context, userId int, step int64) error {
+	query := `UPDATE users SET totp_last_step = $2 WHERE user_id = $1 AND totp_last_step < $2`
+	tag, err := a.db.GetDB().Exec(ctx, query, userId, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// UseRecoveryCode гасит код восстановления; каждый действует один раз.
+func (a *authRepository) UseRecoveryCode(ctx context.Context, userId int, codeHash string) error {
+	query := `UPDATE recovery_codes SET used_at = now() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	tag, err := a.db.GetDB().Exec(ctx, query, userId, codeHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+	return nil
+}
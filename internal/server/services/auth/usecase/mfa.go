@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/auth/attempts"
+	"gophKeeper/internal/server/services/auth/models"
+	"gophKeeper/pkg/totp"
+	"strings"
+	"time"
+)
+
+const (
+	// totpIssuer — имя сервиса в приложении-аутентификаторе.
+	totpIssuer = "gophKeeper"
+	// mfaTTL — сколько сервер ждёт код после верного пароля.
+	mfaTTL = 5 * time.Minute
+	// mfaAttempts — число попыток ввести код на один вход; дальше нужен новый вход.
+	mfaAttempts = 5
+	// recoveryCodeCount — сколько кодов восстановления выдаётся при включении 2FA.
+	recoveryCodeCount = 10
+)
+
+// mfaChallenge — вход, ожидающий второй фактор.
+type mfaChallenge struct {
+	user     *models.InfoUser
+	expires  time.Time
+	attempts int
+}
+
+// StartMFA вызывается после проверки пароля. Если у пользователя включена
+// 2FA, возвращает токен незавершённого входа; иначе nil — токены выдаются сразу.
+func (a *AuthUsecase) StartMFA(ctx context.Context, user *models.InfoUser) (*models.MFAChallenge, error) {
+	settings, err := a.repo.GetTOTP(ctx, user.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(id)
+
+	a.mu.Lock()
+	a.mfa[token] = &mfaChallenge{user: user, expires: time.Now().Add(mfaTTL)}
+	a.mu.Unlock()
+
+	return &models.MFAChallenge{Required: true, Token: token}, nil
+}
+
+// FinishMFA проверяет код второго фактора и возвращает пользователя, для
+// которого можно открыть сессию. Токен входа гасится после успеха или
+// после mfaAttempts неверных кодов.
+func (a *AuthUsecase) FinishMFA(ctx context.Context, req *models.MFAVerify) (*models.InfoUser, error) {
+	a.mu.Lock()
+	challenge, ok := a.mfa[req.Token]
+	if ok {
+		challenge.attempts++
+		if challenge.attempts >= mfaAttempts {
+			delete(a.mfa, req.Token)
+		}
+	}
+	a.mu.Unlock()
+	if !ok || time.Now().After(challenge.expires) {
+		return nil, domain.ErrMFAExpired
+	}
+	if err := a.guard.Check(ctx, challenge.user.Username, attempts.ClientIP(ctx)); err != nil {
+		return nil, err
+	}
+
+	settings, err := a.repo.GetTOTP(ctx, challenge.user.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.checkCode(ctx, challenge.user.UserId, settings, req.Code); err != nil {
+		if errors.Is(err, domain.ErrInvalidMFACode) {
+			a.failure(ctx, challenge.user.Username)
+		}
+		return nil, err
+	}
+
+	a.mu.Lock()
+	delete(a.mfa, req.Token)
+	a.mu.Unlock()
+	return challenge.user, nil
+}
+
+// checkCode принимает код из приложения или неиспользованный код восстановления.
+func (a *AuthUsecase) checkCode(ctx context.Context, userId int, settings *models.TOTP, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(settings.Secret, code, time.Now())
+		if !ok {
+			return domain.ErrInvalidMFACode
+		}
+		return a.repo.UseTOTPStep(ctx, userId, step)
+	}
+	return a.repo.UseRecoveryCode(ctx, userId, hashRecoveryCode(code))
+}
+
+// EnrollTOTP выдаёт новый секрет. 2FA включается только после ConfirmTOTP,
+// чтобы ошибка при сканировании QR-кода не закрыла доступ к аккаунту.
+func (a *AuthUsecase) EnrollTOTP(ctx context.Context, userId int, username string) (*models.TOTPEnrollment, error) {
+	settings, err := a.repo.GetTOTP(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Enabled {
+		return nil, domain.ErrTOTPEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.SetTOTPSecret(ctx, userId, secret); err != nil {
+		return nil, err
+	}
+	return &models.TOTPEnrollment{Secret: secret, URI: totp.URI(totpIssuer, username, secret)}, nil
+}
+
+// ConfirmTOTP включает 2FA по первому коду из приложения и возвращает коды
+// восстановления. В базе хранятся только их хеши, показать их снова нельзя.
+func (a *AuthUsecase) ConfirmTOTP(ctx context.Context, userId int, code string) ([]string, error) {
+	settings, err := a.repo.GetTOTP(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Enabled {
+		return nil, domain.ErrTOTPEnabled
+	}
+	if settings.Secret == "" {
+		return nil, domain.ErrTOTPNotEnabled
+	}
+	step, ok := totp.Validate(settings.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = newRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	if err := a.repo.EnableTOTP(ctx, userId, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP отключает 2FA; нужен действующий код или код восстановления.
+func (a *AuthUsecase) DisableTOTP(ctx context.Context, userId int, code string) error {
+	settings, err := a.repo.GetTOTP(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if !settings.Enabled {
+		return domain.ErrTOTPNotEnabled
+	}
+	if err := a.checkCode(ctx, userId, settings, code); err != nil {
+		return err
+	}
+	return a.repo.DisableTOTP(ctx, userId)
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCode возвращает код вида abcd-efgh-ijkl-mnop (80 бит).
+func newRecoveryCode() (string, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(recoveryEncoding.EncodeToString(raw))
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16], nil
+}
+
+// hashRecoveryCode не зависит от регистра и разделителей, которые человек
+// может ввести по-своему.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,29 +2,366 @@ package usecase
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"gophKeeper/internal/server/domain"
+	auditmodels "gophKeeper/internal/server/services/audit/models"
+	audit "gophKeeper/internal/server/services/audit/usecase"
+	"gophKeeper/internal/server/services/auth/attempts"
 	"gophKeeper/internal/server/services/auth/models"
 	"gophKeeper/internal/server/services/auth/repository"
+	"gophKeeper/pkg/srp"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// srpSessionTTL — сколько сервер ждёт второй шаг входа.
+	srpSessionTTL = 2 * time.Minute
+	// srpMaxPending и srpMaxPendingPerIP ограничивают незавершённые входы:
+	// первый шаг доступен без пароля, и иначе память сервера можно было бы
+	// заполнить сессиями для случайных имён.
+	srpMaxPending      = 10000
+	srpMaxPendingPerIP = 20
+	// refreshTTL — срок жизни refresh-токена; каждое обновление его продлевает.
+	refreshTTL = 30 * 24 * time.Hour
 )
 
 type IAuthUsecase interface {
 	CheckUser(ctx context.Context, user *models.AuthUser) (*models.InfoUser, error)
+	StartSRP(ctx context.Context, req *models.SRPInit) (*models.SRPChallenge, error)
+	FinishSRP(ctx context.Context, req *models.SRPVerify) (*models.InfoUser, string, error)
+	SetSRPVerifier(ctx context.Context, userId int, verifier *models.SRPVerifier) error
+	CreateSession(ctx context.Context, user *models.InfoUser, meta models.SessionMeta) (int, string, error)
+	RefreshSession(ctx context.Context, refreshToken string, meta models.SessionMeta) (*models.InfoUser, int, string, error)
+	RevokeSession(ctx context.Context, userId, sessionId int) error
+	ListSessions(ctx context.Context, userId, currentId int) ([]models.Session, error)
+	RevokeOtherSessions(ctx context.Context, userId, currentId int) (int, error)
+	StartMFA(ctx context.Context, user *models.InfoUser) (*models.MFAChallenge, error)
+	FinishMFA(ctx context.Context, req *models.MFAVerify) (*models.InfoUser, error)
+	EnrollTOTP(ctx context.Context, userId int, username string) (*models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userId int, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userId int, code string) error
+	UnlockUser(ctx context.Context, adminId int, username string) error
+	RunJanitor(ctx context.Context, interval time.Duration)
+}
+
+// srpSession — состояние обмена между первым и вторым шагом входа.
+// user == nil для несуществующего пользователя: обмен доводится до конца,
+// чтобы по ответам нельзя было узнать, зарегистрировано ли имя.
+type srpSession struct {
+	server   *srp.Server
+	username string
+	user     *models.InfoUser
+	ip       string
+	expires  time.Time
 }
 
 type AuthUsecase struct {
-	repo repository.IAuthRepo
+	repo  repository.IAuthRepo
+	guard *attempts.Guard
+	audit audit.Recorder
+
+	mu       sync.Mutex
+	sessions map[string]*srpSession
+	// pendingByIP — число незавершённых входов с каждого адреса.
+	pendingByIP map[string]int
+	mfa         map[string]*mfaChallenge
+	// fakeKey задаёт правдоподобную соль для несуществующих пользователей;
+	// соль одного и того же имени не меняется между попытками.
+	fakeKey []byte
+	// legacyPassword разрешает вход по паролю аккаунтам без верификатора SRP.
+	legacyPassword bool
 }
 
-func NewAuthUsecase(repo repository.IAuthRepo) IAuthUsecase {
+// NewAuthUsecase создаёт usecase авторизации. legacyPassword включает
+// прежний вход по паролю (см. config.LoginConf.LegacyPassword).
+func NewAuthUsecase(repo repository.IAuthRepo, guard *attempts.Guard, recorder audit.Recorder, legacyPassword bool) IAuthUsecase {
+	fakeKey := make([]byte, 32)
+	_, _ = rand.Read(fakeKey)
 	return &AuthUsecase{
-		repo: repo,
+		repo:           repo,
+		guard:          guard,
+		audit:          recorder,
+		sessions:       make(map[string]*srpSession),
+		pendingByIP:    make(map[string]int),
+		mfa:            make(map[string]*mfaChallenge),
+		fakeKey:        fakeKey,
+		legacyPassword: legacyPassword,
 	}
 }
 
+// CheckUser проверяет пароль, переданный открытым текстом. Это прежний вход
+// для аккаунтов, ещё не перешедших на SRP; без legacyPassword он отключён.
 func (a *AuthUsecase) CheckUser(ctx context.Context, user *models.AuthUser) (*models.InfoUser, error) {
+	if !a.legacyPassword {
+		return nil, domain.ErrPasswordLogin
+	}
+	if err := a.guard.Check(ctx, user.Username, attempts.ClientIP(ctx)); err != nil {
+		return nil, err
+	}
 	infoUser, err := a.repo.GetInfoUser(ctx, user)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			a.failure(ctx, user.Username)
+		}
 		return nil, err
 	}
+	if infoUser.Disabled {
+		a.audit.Record(ctx, auditmodels.Event{UserId: infoUser.UserId, Action: auditmodels.ActionLoginFailure, Target: infoUser.Username})
+		return nil, domain.ErrAccountDisabled
+	}
 
 	return infoUser, nil
 }
+
+func (a *AuthUsecase) fakeCredentials(username string) *models.SRPCredentials {
+	mac := hmac.New(sha256.New, a.fakeKey)
+	mac.Write([]byte(username))
+	sum := mac.Sum(nil)
+	return &models.SRPCredentials{SRPVerifier: models.SRPVerifier{
+		Salt:     hex.EncodeToString(sum[:srp.SaltSize]),
+		Verifier: hex.EncodeToString(sum),
+	}}
+}
+
+// StartSRP выполняет первый шаг входа: возвращает соль и публичный ключ B.
+func (a *AuthUsecase) StartSRP(ctx context.Context, req *models.SRPInit) (*models.SRPChallenge, error) {
+	clientA, err := hex.DecodeString(req.A)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if err := a.guard.Check(ctx, req.Username, attempts.ClientIP(ctx)); err != nil {
+		return nil, err
+	}
+
+	var user *models.InfoUser
+	creds, err := a.repo.GetSRPCredentials(ctx, req.Username)
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		creds = a.fakeCredentials(req.Username)
+	case err != nil:
+		return nil, err
+	default:
+		user = &creds.InfoUser
+	}
+
+	salt, _ := hex.DecodeString(creds.Salt)
+	verifier, _ := hex.DecodeString(creds.Verifier)
+	server, err := srp.NewServer(req.Username, salt, verifier, clientA)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	session := hex.EncodeToString(id)
+
+	ip := attempts.ClientIP(ctx)
+	a.mu.Lock()
+	if len(a.sessions) >= srpMaxPending || a.pendingByIP[ip] >= srpMaxPendingPerIP {
+		a.mu.Unlock()
+		return nil, domain.ErrTooManyLogins
+	}
+	a.sessions[session] = &srpSession{server: server, username: req.Username, user: user, ip: ip, expires: time.Now().Add(srpSessionTTL)}
+	a.pendingByIP[ip]++
+	a.mu.Unlock()
+
+	return &models.SRPChallenge{
+		Session: session,
+		Salt:    creds.Salt,
+		B:       hex.EncodeToString(server.B),
+	}, nil
+}
+
+// FinishSRP проверяет доказательство клиента и возвращает пользователя и
+// доказательство сервера M2. Сессия одноразовая.
+func (a *AuthUsecase) FinishSRP(ctx context.Context, req *models.SRPVerify) (*models.InfoUser, string, error) {
+	a.mu.Lock()
+	session, ok := a.sessions[req.Session]
+	a.dropSRP(req.Session)
+	a.mu.Unlock()
+	if !ok || time.Now().After(session.expires) {
+		return nil, "", domain.ErrSRPSessionExpired
+	}
+
+	m1, err := hex.DecodeString(req.M1)
+	if err != nil {
+		a.failure(ctx, session.username)
+		return nil, "", domain.ErrInvalidCredentials
+	}
+	m2, err := session.server.Verify(m1)
+	if err != nil || session.user == nil {
+		a.failure(ctx, session.username)
+		return nil, "", domain.ErrInvalidCredentials
+	}
+	// Об отключении сообщаем только после верного пароля, иначе по ответу
+	// можно было бы узнать статус чужого аккаунта.
+	if session.user.Disabled {
+		a.audit.Record(ctx, auditmodels.Event{UserId: session.user.UserId, Action: auditmodels.ActionLoginFailure, Target: session.username})
+		return nil, "", domain.ErrAccountDisabled
+	}
+	return session.user, hex.EncodeToString(m2), nil
+}
+
+// dropSRP удаляет сессию входа и её учёт по адресу. Вызывается под mu.
+func (a *AuthUsecase) dropSRP(key string) {
+	session, ok := a.sessions[key]
+	if !ok {
+		return
+	}
+	delete(a.sessions, key)
+	if a.pendingByIP[session.ip]--; a.pendingByIP[session.ip] <= 0 {
+		delete(a.pendingByIP, session.ip)
+	}
+}
+
+// RunJanitor периодически удаляет просроченные сессии входа и ожидания
+// второго фактора, пока не отменён ctx.
+func (a *AuthUsecase) RunJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			a.removeExpired(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (a *AuthUsecase) removeExpired(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, s := range a.sessions {
+		if now.After(s.expires) {
+			a.dropSRP(key)
+		}
+	}
+	for key, c := range a.mfa {
+		if now.After(c.expires) {
+			delete(a.mfa, key)
+		}
+	}
+}
+
+// SetSRPVerifier переводит пользователя, вошедшего по паролю, на SRP.
+func (a *AuthUsecase) SetSRPVerifier(ctx context.Context, userId int, verifier *models.SRPVerifier) error {
+	if !verifier.Valid() {
+		return domain.ErrInvalidInput
+	}
+	return a.repo.SetSRPVerifier(ctx, userId, verifier)
+}
+
+// newRefreshToken возвращает случайный refresh-токен и его хеш. В базе
+// хранится только хеш: утечка таблицы сессий не даёт войти.
+func newRefreshToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(raw)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession открывает сессию после успешного входа и возвращает её id
+// и refresh-токен. Счётчик неудачных попыток сбрасывается только здесь, а не
+// после пароля: иначе знание пароля позволяло бы бесконечно подбирать код 2FA.
+func (a *AuthUsecase) CreateSession(ctx context.Context, user *models.InfoUser, meta models.SessionMeta) (int, string, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return 0, "", err
+	}
+	sessionId, err := a.repo.CreateSession(ctx, user.UserId, hash, meta, time.Now().Add(refreshTTL))
+	if err != nil {
+		return 0, "", err
+	}
+	if err := a.guard.Success(ctx, user.Username); err != nil {
+		slog.Error("Failed to reset login attempts", "username", user.Username, "error", err)
+	}
+	a.audit.Record(ctx, auditmodels.Event{
+		UserId: user.UserId, Action: auditmodels.ActionLoginSuccess, Target: strconv.Itoa(sessionId), Success: true,
+	})
+	return sessionId, token, nil
+}
+
+// RefreshSession обменивает refresh-токен на новый. Старый токен после этого
+// недействителен, а его повторное использование отзывает всю сессию.
+func (a *AuthUsecase) RefreshSession(ctx context.Context, refreshToken string, meta models.SessionMeta) (*models.InfoUser, int, string, error) {
+	if refreshToken == "" {
+		return nil, 0, "", domain.ErrInvalidRefresh
+	}
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, 0, "", err
+	}
+	user, sessionId, err := a.repo.RotateSession(ctx, hashRefreshToken(refreshToken), hash, meta, time.Now().Add(refreshTTL))
+	if err != nil {
+		return nil, 0, "", err
+	}
+	a.audit.Record(ctx, auditmodels.Event{
+		UserId: user.UserId, Action: auditmodels.ActionTokenRefresh, Target: strconv.Itoa(sessionId), Success: true,
+	})
+	return user, sessionId, token, nil
+}
+
+func (a *AuthUsecase) RevokeSession(ctx context.Context, userId, sessionId int) error {
+	err := a.repo.RevokeSession(ctx, userId, sessionId)
+	a.audit.Record(ctx, auditmodels.Event{
+		UserId: userId, Action: auditmodels.ActionTokenRevoke, Target: strconv.Itoa(sessionId), Success: err == nil,
+	})
+	return err
+}
+
+// ListSessions возвращает устройства, с которых выполнен вход, и отмечает
+// среди них текущее.
+func (a *AuthUsecase) ListSessions(ctx context.Context, userId, currentId int) ([]models.Session, error) {
+	sessions, err := a.repo.ListSessions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].SessionId == currentId
+	}
+	return sessions, nil
+}
+
+// RevokeOtherSessions завершает вход на всех устройствах, кроме текущего.
+func (a *AuthUsecase) RevokeOtherSessions(ctx context.Context, userId, currentId int) (int, error) {
+	revoked, err := a.repo.RevokeOtherSessions(ctx, userId, currentId)
+	if revoked > 0 {
+		a.audit.Record(ctx, auditmodels.Event{UserId: userId, Action: auditmodels.ActionTokenRevoke, Target: "others", Success: true})
+	}
+	return revoked, err
+}
+
+// failure учитывает неудачный вход. Ошибка хранилища не должна превращать
+// неверный пароль в ошибку сервера, поэтому только пишется в лог.
+func (a *AuthUsecase) failure(ctx context.Context, username string) {
+	a.audit.Record(ctx, auditmodels.Event{Username: username, Action: auditmodels.ActionLoginFailure, Target: username})
+	if err := a.guard.Failure(ctx, username, attempts.ClientIP(ctx)); err != nil {
+		slog.Error("Failed to record login attempt", "username", username, "error", err)
+	}
+}
+
+// UnlockUser снимает блокировку входа и задержки с аккаунта.
+func (a *AuthUsecase) UnlockUser(ctx context.Context, adminId int, username string) error {
+	err := a.guard.Unlock(ctx, username)
+	a.audit.Record(ctx, auditmodels.Event{
+		Username: username, ActorId: adminId, Action: auditmodels.ActionAdminUnlock, Target: username, Success: err == nil,
+	})
+	return err
+}
@@ -1 +1,496 @@
 package usecase
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"gophKeeper/internal/server/domain"
+	auditmodels "gophKeeper/internal/server/services/audit/models"
+	audit "gophKeeper/internal/server/services/audit/usecase"
+	"gophKeeper/internal/server/services/auth/attempts"
+	"gophKeeper/internal/server/services/auth/models"
+	"gophKeeper/pkg/srp"
+	"gophKeeper/pkg/totp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeAuthRepo struct {
+	creds map[string]*models.SRPCredentials
+	// sessions хранит текущий и предыдущий хеш refresh-токена каждой сессии.
+	sessions map[int]*fakeSession
+	totp     models.TOTP
+	// recovery — хеши неиспользованных кодов восстановления.
+	recovery map[string]bool
+}
+
+// fakeRecorder запоминает события аудита вместо записи в журнал.
+type fakeRecorder struct {
+	events []auditmodels.Event
+}
+
+func (r *fakeRecorder) Record(ctx context.Context, event auditmodels.Event) {
+	r.events = append(r.events, event)
+}
+
+func (r *fakeRecorder) actions() []string {
+	actions := make([]string, 0, len(r.events))
+	for _, event := range r.events {
+		actions = append(actions, event.Action)
+	}
+	return actions
+}
+
+type fakeSession struct {
+	user     models.InfoUser
+	meta     models.SessionMeta
+	hash     string
+	prevHash string
+	revoked  bool
+}
+
+func (r *fakeAuthRepo) GetInfoUser(ctx context.Context, user *models.AuthUser) (*models.InfoUser, error) {
+	return nil, domain.ErrInvalidCredentials
+}
+
+func (r *fakeAuthRepo) GetSRPCredentials(ctx context.Context, username string) (*models.SRPCredentials, error) {
+	creds, ok := r.creds[username]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return creds, nil
+}
+
+func (r *fakeAuthRepo) SetSRPVerifier(ctx context.Context, userId int, verifier *models.SRPVerifier) error {
+	return nil
+}
+
+func (r *fakeAuthRepo) CreateSession(ctx context.Context, userId int, refreshHash string, meta models.SessionMeta, expiresAt time.Time) (int, error) {
+	if r.sessions == nil {
+		r.sessions = map[int]*fakeSession{}
+	}
+	id := len(r.sessions) + 1
+	r.sessions[id] = &fakeSession{user: models.InfoUser{UserId: userId}, meta: meta, hash: refreshHash}
+	return id, nil
+}
+
+func (r *fakeAuthRepo) RotateSession(ctx context.Context, refreshHash, newHash string, meta models.SessionMeta, expiresAt time.Time) (*models.InfoUser, int, error) {
+	for id, s := range r.sessions {
+		if !s.revoked && s.hash == refreshHash {
+			s.prevHash, s.hash = s.hash, newHash
+			return &s.user, id, nil
+		}
+	}
+	for _, s := range r.sessions {
+		if s.prevHash == refreshHash {
+			s.revoked = true
+		}
+	}
+	return nil, 0, domain.ErrInvalidRefresh
+}
+
+func (r *fakeAuthRepo) RevokeSession(ctx context.Context, userId, sessionId int) error {
+	s, ok := r.sessions[sessionId]
+	if !ok || s.user.UserId != userId {
+		return domain.ErrSessionNotFound
+	}
+	s.revoked = true
+	return nil
+}
+
+func (r *fakeAuthRepo) ListSessions(ctx context.Context, userId int) ([]models.Session, error) {
+	var sessions []models.Session
+	for id := 1; id <= len(r.sessions); id++ {
+		s := r.sessions[id]
+		if !s.revoked && s.user.UserId == userId {
+			sessions = append(sessions, models.Session{SessionId: id, UserId: userId, DeviceName: s.meta.DeviceName, OS: s.meta.OS})
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeAuthRepo) RevokeOtherSessions(ctx context.Context, userId, keepId int) (int, error) {
+	revoked := 0
+	for id, s := range r.sessions {
+		if id != keepId && !s.revoked && s.user.UserId == userId {
+			s.revoked = true
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (r *fakeAuthRepo) GetTOTP(ctx context.Context, userId int) (*models.TOTP, error) {
+	totp := r.totp
+	return &totp, nil
+}
+
+func (r *fakeAuthRepo) SetTOTPSecret(ctx context.Context, userId int, secret string) error {
+	if r.totp.Enabled {
+		return domain.ErrTOTPEnabled
+	}
+	r.totp.Secret = secret
+	return nil
+}
+
+func (r *fakeAuthRepo) EnableTOTP(ctx context.Context, userId int, step int64, recoveryHashes []string) error {
+	r.totp.Enabled, r.totp.LastStep = true, step
+	r.recovery = map[string]bool{}
+	for _, hash := range recoveryHashes {
+		r.recovery[hash] = true
+	}
+	return nil
+}
+
+func (r *fakeAuthRepo) DisableTOTP(ctx context.Context, userId int) error {
+	r.totp, r.recovery = models.TOTP{}, nil
+	return nil
+}
+
+func (r *fakeAuthRepo) UseTOTPStep(ctx context.Context, userId int, step int64) error {
+	if step <= r.totp.LastStep {
+		return domain.ErrInvalidMFACode
+	}
+	r.totp.LastStep = step
+	return nil
+}
+
+func (r *fakeAuthRepo) UseRecoveryCode(ctx context.Context, userId int, codeHash string) error {
+	if !r.recovery[codeHash] {
+		return domain.ErrInvalidMFACode
+	}
+	delete(r.recovery, codeHash)
+	return nil
+}
+
+// login проходит оба шага входа так, как это делает клиент.
+// newGuard не мешает тестам, проверяющим сам вход: задержки начинаются
+// только после сотни ошибок.
+func newGuard() *attempts.Guard {
+	policy := attempts.DefaultPolicy()
+	policy.FreeAttempts, policy.IPFreeAttempts = 100, 100
+	return attempts.NewGuard(attempts.NewMemoryStore(), policy)
+}
+
+func login(t *testing.T, uc IAuthUsecase, username, password string) (*models.InfoUser, error) {
+	t.Helper()
+	client, err := srp.NewClient(username, password)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	challenge, err := uc.StartSRP(context.Background(), &models.SRPInit{Username: username, A: hex.EncodeToString(client.A)})
+	if err != nil {
+		t.Fatalf("StartSRP: %v", err)
+	}
+	salt, _ := hex.DecodeString(challenge.Salt)
+	B, _ := hex.DecodeString(challenge.B)
+	m1, err := client.Proof(salt, B)
+	if err != nil {
+		t.Fatalf("Proof: %v", err)
+	}
+	user, m2, err := uc.FinishSRP(context.Background(), &models.SRPVerify{Session: challenge.Session, M1: hex.EncodeToString(m1)})
+	if err != nil {
+		return nil, err
+	}
+	proof, _ := hex.DecodeString(m2)
+	if err := client.VerifyServer(proof); err != nil {
+		t.Fatalf("VerifyServer: %v", err)
+	}
+	return user, nil
+}
+
+func TestSRPLogin(t *testing.T) {
+	salt, _ := srp.NewSalt()
+	repo := &fakeAuthRepo{creds: map[string]*models.SRPCredentials{
+		"alice": {
+			InfoUser: models.InfoUser{UserId: 1, Username: "alice", UserType: "attendee"},
+			SRPVerifier: models.SRPVerifier{
+				Salt:     hex.EncodeToString(salt),
+				Verifier: hex.EncodeToString(srp.Verifier("alice", "secret", salt)),
+			},
+		},
+	}}
+	uc := NewAuthUsecase(repo, newGuard(), audit.Nop, false)
+
+	user, err := login(t, uc, "alice", "secret")
+	if err != nil {
+		t.Fatalf("Вход с верным паролем не удался: %v", err)
+	}
+	if user.UserId != 1 {
+		t.Errorf("Ожидался пользователь 1, получили %d", user.UserId)
+	}
+
+	if _, err := login(t, uc, "alice", "wrong"); err != domain.ErrInvalidCredentials {
+		t.Errorf("Ожидалась ошибка ErrInvalidCredentials, получено: %v", err)
+	}
+	if _, err := login(t, uc, "bob", "secret"); err != domain.ErrInvalidCredentials {
+		t.Errorf("Для неизвестного пользователя ожидалась ErrInvalidCredentials, получено: %v", err)
+	}
+
+	// Отключённый аккаунт узнаётся только по верному паролю.
+	repo.creds["alice"].Disabled = true
+	if _, err := login(t, uc, "alice", "wrong"); err != domain.ErrInvalidCredentials {
+		t.Errorf("Ожидалась ошибка ErrInvalidCredentials, получено: %v", err)
+	}
+	if _, err := login(t, uc, "alice", "secret"); err != domain.ErrAccountDisabled {
+		t.Errorf("Ожидалась ошибка ErrAccountDisabled, получено: %v", err)
+	}
+}
+
+func TestSRPSessionIsSingleUse(t *testing.T) {
+	uc := NewAuthUsecase(&fakeAuthRepo{}, newGuard(), audit.Nop, false)
+	client, _ := srp.NewClient("bob", "secret")
+
+	first, _ := uc.StartSRP(context.Background(), &models.SRPInit{Username: "bob", A: hex.EncodeToString(client.A)})
+	second, _ := uc.StartSRP(context.Background(), &models.SRPInit{Username: "bob", A: hex.EncodeToString(client.A)})
+	if first.Salt != second.Salt {
+		t.Error("Соль несуществующего пользователя должна быть стабильной")
+	}
+
+	uc.FinishSRP(context.Background(), &models.SRPVerify{Session: first.Session, M1: "00"})
+	if _, _, err := uc.FinishSRP(context.Background(), &models.SRPVerify{Session: first.Session, M1: "00"}); err != domain.ErrSRPSessionExpired {
+		t.Errorf("Ожидалась ошибка ErrSRPSessionExpired, получено: %v", err)
+	}
+}
+
+// TestSRPPendingLimit проверяет, что незавершённые входы с одного адреса
+// ограничены и место освобождается после их истечения.
+func TestSRPPendingLimit(t *testing.T) {
+	uc := NewAuthUsecase(&fakeAuthRepo{}, newGuard(), audit.Nop, false).(*AuthUsecase)
+	client, _ := srp.NewClient("bob", "secret")
+	init := &models.SRPInit{Username: "bob", A: hex.EncodeToString(client.A)}
+	ctx := attempts.WithClientIP(context.Background(), "10.0.0.1")
+
+	for i := 0; i < srpMaxPendingPerIP; i++ {
+		if _, err := uc.StartSRP(ctx, init); err != nil {
+			t.Fatalf("Неожиданная ошибка: %v", err)
+		}
+	}
+	if _, err := uc.StartSRP(ctx, init); err != domain.ErrTooManyLogins {
+		t.Errorf("Ожидалась ошибка ErrTooManyLogins, получено: %v", err)
+	}
+	if _, err := uc.StartSRP(attempts.WithClientIP(context.Background(), "10.0.0.2"), init); err != nil {
+		t.Errorf("Лимит одного адреса не должен касаться другого: %v", err)
+	}
+
+	uc.removeExpired(time.Now().Add(srpSessionTTL + time.Second))
+	if len(uc.sessions) != 0 || len(uc.pendingByIP) != 0 {
+		t.Errorf("Просроченные сессии должны удаляться: %d сессий, %d адресов", len(uc.sessions), len(uc.pendingByIP))
+	}
+	if _, err := uc.StartSRP(ctx, init); err != nil {
+		t.Errorf("После очистки вход должен быть доступен: %v", err)
+	}
+}
+
+// TestRefreshRotation проверяет, что refresh-токен одноразовый, а повторное
+// предъявление старого токена отзывает сессию вместе с новым.
+func TestRefreshRotation(t *testing.T) {
+	repo := &fakeAuthRepo{}
+	uc := NewAuthUsecase(repo, newGuard(), audit.Nop, false)
+	ctx := context.Background()
+
+	sessionId, first, err := uc.CreateSession(ctx, &models.InfoUser{UserId: 1}, models.SessionMeta{})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if repo.sessions[sessionId].hash == first {
+		t.Fatal("В базе должен храниться хеш refresh-токена, а не сам токен")
+	}
+
+	_, gotId, second, err := uc.RefreshSession(ctx, first, models.SessionMeta{})
+	if err != nil {
+		t.Fatalf("RefreshSession: %v", err)
+	}
+	if gotId != sessionId || second == first {
+		t.Fatalf("Ожидалась новая пара для сессии %d, получили сессию %d", sessionId, gotId)
+	}
+
+	if _, _, _, err := uc.RefreshSession(ctx, first, models.SessionMeta{}); err != domain.ErrInvalidRefresh {
+		t.Fatalf("Повторное использование токена: ожидалась ErrInvalidRefresh, получили %v", err)
+	}
+	if _, _, _, err := uc.RefreshSession(ctx, second, models.SessionMeta{}); err != domain.ErrInvalidRefresh {
+		t.Fatalf("После повторного использования сессия должна быть отозвана, получили %v", err)
+	}
+}
+
+func TestSessionDevices(t *testing.T) {
+	repo := &fakeAuthRepo{}
+	uc := NewAuthUsecase(repo, newGuard(), audit.Nop, false)
+	ctx := context.Background()
+	user := &models.InfoUser{UserId: 1}
+
+	laptop, _, _ := uc.CreateSession(ctx, user, models.NewSessionMeta(" laptop ", "linux", "", ""))
+	ci, _, _ := uc.CreateSession(ctx, user, models.NewSessionMeta("ci-runner", "linux", "", ""))
+	uc.CreateSession(ctx, &models.InfoUser{UserId: 2}, models.SessionMeta{})
+
+	sessions, err := uc.ListSessions(ctx, 1, laptop)
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("Ожидались 2 сессии пользователя, получили %d", len(sessions))
+	}
+	if !sessions[0].Current || sessions[0].DeviceName != "laptop" || sessions[1].Current {
+		t.Fatalf("Текущей должна быть только сессия ноутбука: %+v", sessions)
+	}
+
+	revoked, err := uc.RevokeOtherSessions(ctx, 1, laptop)
+	if err != nil || revoked != 1 {
+		t.Fatalf("RevokeOtherSessions: ожидалась 1 отозванная сессия, получили %d (%v)", revoked, err)
+	}
+	if !repo.sessions[ci].revoked || repo.sessions[laptop].revoked || repo.sessions[3].revoked {
+		t.Fatal("Отозвана должна быть только сессия CI")
+	}
+}
+
+func TestTOTPLogin(t *testing.T) {
+	repo := &fakeAuthRepo{}
+	uc := NewAuthUsecase(repo, newGuard(), audit.Nop, false)
+	ctx := context.Background()
+	user := &models.InfoUser{UserId: 1, Username: "alice"}
+
+	if challenge, err := uc.StartMFA(ctx, user); err != nil || challenge != nil {
+		t.Fatalf("Без 2FA код не нужен, получили %+v (%v)", challenge, err)
+	}
+
+	enrollment, err := uc.EnrollTOTP(ctx, 1, "alice")
+	if err != nil {
+		t.Fatalf("EnrollTOTP: %v", err)
+	}
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/gophKeeper:alice?") {
+		t.Errorf("Неверный URI: %s", enrollment.URI)
+	}
+	if _, err := uc.ConfirmTOTP(ctx, 1, "000000"); err != domain.ErrInvalidMFACode {
+		t.Fatalf("Неверный код не должен включать 2FA, получили %v", err)
+	}
+	now := time.Now()
+	code, _ := totp.Code(enrollment.Secret, now)
+	recovery, err := uc.ConfirmTOTP(ctx, 1, code)
+	if err != nil || len(recovery) != recoveryCodeCount {
+		t.Fatalf("ConfirmTOTP: ожидалось %d кодов восстановления, получили %d (%v)", recoveryCodeCount, len(recovery), err)
+	}
+	for hash := range repo.recovery {
+		if hash == recovery[0] {
+			t.Fatal("В базе должны храниться хеши кодов восстановления")
+		}
+	}
+
+	challenge, err := uc.StartMFA(ctx, user)
+	if err != nil || challenge == nil || !challenge.Required {
+		t.Fatalf("С 2FA вход должен требовать код, получили %+v (%v)", challenge, err)
+	}
+	if _, err := uc.FinishMFA(ctx, &models.MFAVerify{Token: challenge.Token, Code: code}); err != domain.ErrInvalidMFACode {
+		t.Fatalf("Код подтверждения нельзя использовать повторно, получили %v", err)
+	}
+	next, _ := totp.Code(enrollment.Secret, now.Add(totp.Period*time.Second))
+	got, err := uc.FinishMFA(ctx, &models.MFAVerify{Token: challenge.Token, Code: next})
+	if err != nil || got.UserId != 1 {
+		t.Fatalf("FinishMFA: %v", err)
+	}
+	if _, err := uc.FinishMFA(ctx, &models.MFAVerify{Token: challenge.Token, Code: next}); err != domain.ErrMFAExpired {
+		t.Fatalf("Токен входа одноразовый, получили %v", err)
+	}
+
+	challenge, _ = uc.StartMFA(ctx, user)
+	spaced := strings.ToUpper(strings.ReplaceAll(recovery[0], "-", " "))
+	if _, err := uc.FinishMFA(ctx, &models.MFAVerify{Token: challenge.Token, Code: spaced}); err != nil {
+		t.Fatalf("Код восстановления должен приниматься в любом регистре: %v", err)
+	}
+	challenge, _ = uc.StartMFA(ctx, user)
+	if _, err := uc.FinishMFA(ctx, &models.MFAVerify{Token: challenge.Token, Code: recovery[0]}); err != domain.ErrInvalidMFACode {
+		t.Fatalf("Код восстановления одноразовый, получили %v", err)
+	}
+}
+
+func TestMFAAttemptsLimit(t *testing.T) {
+	repo := &fakeAuthRepo{totp: models.TOTP{Secret: "JBSWY3DPEHPK3PXP", Enabled: true}}
+	uc := NewAuthUsecase(repo, newGuard(), audit.Nop, false)
+	ctx := context.Background()
+
+	challenge, _ := uc.StartMFA(ctx, &models.InfoUser{UserId: 1})
+	for i := 0; i < mfaAttempts; i++ {
+		uc.FinishMFA(ctx, &models.MFAVerify{Token: challenge.Token, Code: "wrong"})
+	}
+	code, _ := totp.Code(repo.totp.Secret, time.Now())
+	if _, err := uc.FinishMFA(ctx, &models.MFAVerify{Token: challenge.Token, Code: code}); err != domain.ErrMFAExpired {
+		t.Fatalf("После %d ошибок нужен новый вход, получили %v", mfaAttempts, err)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	salt, _ := srp.NewSalt()
+	repo := &fakeAuthRepo{creds: map[string]*models.SRPCredentials{
+		"alice": {
+			InfoUser: models.InfoUser{UserId: 1, Username: "alice"},
+			SRPVerifier: models.SRPVerifier{
+				Salt:     hex.EncodeToString(salt),
+				Verifier: hex.EncodeToString(srp.Verifier("alice", "secret", salt)),
+			},
+		},
+	}}
+	policy := attempts.DefaultPolicy()
+	policy.FreeAttempts, policy.LockoutThreshold = 2, 2
+	recorder := &fakeRecorder{}
+	uc := NewAuthUsecase(repo, attempts.NewGuard(attempts.NewMemoryStore(), policy), recorder, false)
+
+	for i := 0; i < 2; i++ {
+		if _, err := login(t, uc, "alice", "wrong"); err != domain.ErrInvalidCredentials {
+			t.Fatalf("Попытка %d: ожидалась ErrInvalidCredentials, получено: %v", i+1, err)
+		}
+	}
+	client, _ := srp.NewClient("alice", "secret")
+	_, err := uc.StartSRP(context.Background(), &models.SRPInit{Username: "alice", A: hex.EncodeToString(client.A)})
+	if !errors.Is(err, domain.ErrAccountLocked) {
+		t.Fatalf("Даже с верным паролем ожидалась ErrAccountLocked, получено: %v", err)
+	}
+
+	if err := uc.UnlockUser(context.Background(), 7, "alice"); err != nil {
+		t.Fatalf("UnlockUser: %v", err)
+	}
+	if _, err := login(t, uc, "alice", "secret"); err != nil {
+		t.Fatalf("После разблокировки вход должен пройти: %v", err)
+	}
+
+	want := []string{auditmodels.ActionLoginFailure, auditmodels.ActionLoginFailure, auditmodels.ActionAdminUnlock}
+	if got := recorder.actions(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("События аудита: %v, ожидалось %v", got, want)
+	}
+	if unlock := recorder.events[2]; unlock.ActorId != 7 || unlock.Target != "alice" {
+		t.Errorf("Разблокировка записана неверно: %+v", unlock)
+	}
+}
+
+// TestPasswordLoginUnknownUserCounts проверяет, что вход по паролю с
+// несуществующим именем считается неудачей адреса: перебор по разным
+// именам упирается в задержку так же, как перебор паролей одного имени.
+func TestPasswordLoginUnknownUserCounts(t *testing.T) {
+	policy := attempts.DefaultPolicy()
+	policy.FreeAttempts, policy.IPFreeAttempts = 100, 2
+	uc := NewAuthUsecase(&fakeAuthRepo{}, attempts.NewGuard(attempts.NewMemoryStore(), policy), audit.Nop, true)
+	ctx := attempts.WithClientIP(context.Background(), "10.0.0.1")
+
+	for _, username := range []string{"ghost1", "ghost2"} {
+		if _, err := uc.CheckUser(ctx, &models.AuthUser{Username: username, Password: "x"}); err != domain.ErrInvalidCredentials {
+			t.Fatalf("%s: ожидалась ErrInvalidCredentials, получено: %v", username, err)
+		}
+	}
+	if _, err := uc.CheckUser(ctx, &models.AuthUser{Username: "ghost3", Password: "x"}); !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Errorf("Ожидалась ErrTooManyAttempts для адреса, получено: %v", err)
+	}
+}
+
+// TestPasswordLoginDisabled проверяет, что без LOGIN_LEGACY_PASSWORD вход по
+// паролю отклоняется до обращения к базе и не считается неудачей.
+func TestPasswordLoginDisabled(t *testing.T) {
+	policy := attempts.DefaultPolicy()
+	policy.FreeAttempts, policy.IPFreeAttempts = 1, 1
+	uc := NewAuthUsecase(&fakeAuthRepo{}, attempts.NewGuard(attempts.NewMemoryStore(), policy), audit.Nop, false)
+	ctx := attempts.WithClientIP(context.Background(), "10.0.0.1")
+
+	for i := 0; i < 3; i++ {
+		if _, err := uc.CheckUser(ctx, &models.AuthUser{Username: "alice", Password: "x"}); err != domain.ErrPasswordLogin {
+			t.Fatalf("Ожидалась ErrPasswordLogin, получено: %v", err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/stretchr/testify/mock"
 	"gophKeeper/internal/server/services/auth/models"
+	"time"
 )
 
 type AuthUsecaseMock struct {
@@ -21,3 +22,99 @@ func (m *AuthUsecaseMock) CheckUser(ctx context.Context, user *models.AuthUser)
 	}
 	return nil, args.Error(1)
 }
+
+func (m *AuthUsecaseMock) StartSRP(ctx context.Context, req *models.SRPInit) (*models.SRPChallenge, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.SRPChallenge), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AuthUsecaseMock) FinishSRP(ctx context.Context, req *models.SRPVerify) (*models.InfoUser, string, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.InfoUser), args.String(1), args.Error(2)
+	}
+	return nil, args.String(1), args.Error(2)
+}
+
+func (m *AuthUsecaseMock) SetSRPVerifier(ctx context.Context, userId int, verifier *models.SRPVerifier) error {
+	args := m.Called(ctx, userId, verifier)
+	return args.Error(0)
+}
+
+func (m *AuthUsecaseMock) CreateSession(ctx context.Context, user *models.InfoUser, meta models.SessionMeta) (int, string, error) {
+	args := m.Called(ctx, user, meta)
+	return args.Int(0), args.String(1), args.Error(2)
+}
+
+func (m *AuthUsecaseMock) RefreshSession(ctx context.Context, refreshToken string, meta models.SessionMeta) (*models.InfoUser, int, string, error) {
+	args := m.Called(ctx, refreshToken, meta)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.InfoUser), args.Int(1), args.String(2), args.Error(3)
+	}
+	return nil, args.Int(1), args.String(2), args.Error(3)
+}
+
+func (m *AuthUsecaseMock) RevokeSession(ctx context.Context, userId, sessionId int) error {
+	args := m.Called(ctx, userId, sessionId)
+	return args.Error(0)
+}
+
+func (m *AuthUsecaseMock) ListSessions(ctx context.Context, userId, currentId int) ([]models.Session, error) {
+	args := m.Called(ctx, userId, currentId)
+	if args.Get(0) != nil {
+		return args.Get(0).([]models.Session), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AuthUsecaseMock) RevokeOtherSessions(ctx context.Context, userId, currentId int) (int, error) {
+	args := m.Called(ctx, userId, currentId)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *AuthUsecaseMock) StartMFA(ctx context.Context, user *models.InfoUser) (*models.MFAChallenge, error) {
+	args := m.Called(ctx, user)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.MFAChallenge), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AuthUsecaseMock) FinishMFA(ctx context.Context, req *models.MFAVerify) (*models.InfoUser, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.InfoUser), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AuthUsecaseMock) EnrollTOTP(ctx context.Context, userId int, username string) (*models.TOTPEnrollment, error) {
+	args := m.Called(ctx, userId, username)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.TOTPEnrollment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AuthUsecaseMock) ConfirmTOTP(ctx context.Context, userId int, code string) ([]string, error) {
+	args := m.Called(ctx, userId, code)
+	if args.Get(0) != nil {
+		return args.Get(0).([]string), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AuthUsecaseMock) DisableTOTP(ctx context.Context, userId int, code string) error {
+	args := m.Called(ctx, userId, code)
+	return args.Error(0)
+}
+
+func (m *AuthUsecaseMock) UnlockUser(ctx context.Context, adminId int, username string) error {
+	args := m.Called(ctx, adminId, username)
+	return args.Error(0)
+}
+
+func (m *AuthUsecaseMock) RunJanitor(ctx context.Context, interval time.Duration) {}
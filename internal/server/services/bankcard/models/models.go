@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Card хранит зашифрованные на клиенте реквизиты банковской карты.
+// NameIndex — слепой индекс названия; если он задан, Name зашифрован.
+type Card struct {
+	Id        int        `json:"id"`
+	Name      string     `json:"name"`
+	NameIndex string     `json:"name_index,omitempty"`
+	Number    string     `json:"number"`
+	Holder    string     `json:"holder"`
+	Expiry    string     `json:"expiry"`
+	CVV       string     `json:"cvv"`
+	PIN       string     `json:"pin"`
+	Notes     string     `json:"notes"`
+	UserID    int        `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
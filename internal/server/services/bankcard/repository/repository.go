@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gophKeeper/internal/server/db"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/bankcard/models"
+)
+
+type IBankCardRepo interface {
+	Create(ctx context.Context, card *models.Card) (int, error)
+	Get(ctx context.Context, name string, userId int) (*models.Card, error)
+	GetByIndex(ctx context.Context, index string, userId int) (*models.Card, error)
+	GetByID(ctx context.Context, id, userId int) (*models.Card, error)
+	GetAll(ctx context.Context, userId int) (*[]models.Card, error)
+	UpdateByID(ctx context.Context, card *models.Card) error
+	Delete(ctx context.Context, name string, userId int) error
+	DeleteByID(ctx context.Context, id, userId int) error
+}
+
+type BankCardRepo struct {
+	db db.IDatabase
+}
+
+func NewBankCardRepo(db db.IDatabase) IBankCardRepo {
+	return &BankCardRepo{
+		db: db,
+	}
+}
+
+const cardColumns = `id, name, COALESCE(name_index, ''), number, holder, expiry, cvv, pin, notes, user_id, created_at, updated_at, deleted_at`
+
+func scanCard(row pgx.Row) (*models.Card, error) {
+	var card models.Card
+	err := row.Scan(&card.Id, &card.Name, &card.NameIndex, &card.Number, &card.Holder, &card.Expiry, &card.CVV, &card.PIN, &card.Notes,
+		&card.UserID, &card.CreatedAt, &card.UpdatedAt, &card.DeletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBankCardNotFound
+		}
+		return nil, err
+	}
+	return &card, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (r *BankCardRepo) Create(ctx context.Context, card *models.Card) (int, error) {
+	query := `INSERT INTO bank_card (name, name_index, number, holder, expiry, cvv, pin, notes, user_id)
+              VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+
+	var id int
+	err := r.db.GetDB().QueryRow(ctx, query, card.Name, card.NameIndex, card.Number, card.Holder, card.Expiry, card.CVV, card.PIN, card.Notes, card.UserID).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			// Удалённая карта с тем же названием или индексом восстанавливается
+			// с новыми реквизитами.
+			restoreQuery := `UPDATE bank_card
+                             SET deleted_at = NULL, updated_at = NOW(), name = $1,
+                                 number = $4, holder = $5, expiry = $6, cvv = $7, pin = $8, notes = $9
+                             WHERE user_id = $3 AND deleted_at IS NOT NULL
+                               AND (name_index = NULLIF($2, '') OR (NULLIF($2, '') IS NULL AND name = $1))
+                             RETURNING id`
+
+			err = r.db.GetDB().QueryRow(ctx, restoreQuery, card.Name, card.NameIndex, card.UserID, card.Number, card.Holder, card.Expiry, card.CVV, card.PIN, card.Notes).Scan(&id)
+			if err == nil {
+				return id, nil
+			}
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, domain.ErrBankCardExists
+			}
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *BankCardRepo) Get(ctx context.Context, name string, userId int) (*models.Card, error) {
+	query := `SELECT ` + cardColumns + ` FROM bank_card WHERE user_id = $1 AND name = $2 AND deleted_at IS NULL`
+	return scanCard(r.db.GetDB().QueryRow(ctx, query, userId, name))
+}
+
+// GetByIndex находит карту по слепому индексу зашифрованного названия.
+func (r *BankCardRepo) GetByIndex(ctx context.Context, index string, userId int) (*models.Card, error) {
+	query := `SELECT ` + cardColumns + ` FROM bank_card WHERE user_id = $1 AND name_index = $2 AND deleted_at IS NULL`
+	return scanCard(r.db.GetDB().QueryRow(ctx, query, userId, index))
+}
+
+func (r *BankCardRepo) GetByID(ctx context.Context, id, userId int) (*models.Card, error) {
+	query := `SELECT ` + cardColumns + ` FROM bank_card WHERE user_id = $1 AND id = $2 AND deleted_at IS NULL`
+	return scanCard(r.db.GetDB().QueryRow(ctx, query, userId, id))
+}
+
+func (r *BankCardRepo) GetAll(ctx context.Context, userId int) (*[]models.Card, error) {
+	query := `SELECT ` + cardColumns + ` FROM bank_card WHERE user_id = $1 AND deleted_at IS NULL`
+	rows, err := r.db.GetDB().Query(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cards := []models.Card{}
+	for rows.Next() {
+		card, err := scanCard(rows)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, *card)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &cards, nil
+}
+
+// UpdateByID заменяет карту целиком: пустое поле в запросе очищает значение,
+// а не оставляет прежнее. Название и индекс меняются вместе.
+func (r *BankCardRepo) UpdateByID(ctx context.Context, card *models.Card) error {
+	query := `UPDATE bank_card
+              SET name = $3, name_index = NULLIF($4, ''), number = $5, holder = $6, expiry = $7,
+                  cvv = $8, pin = $9, notes = $10, updated_at = NOW()
+              WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	res, err := r.db.GetDB().Exec(ctx, query, card.Id, card.UserID, card.Name, card.NameIndex, card.Number, card.Holder, card.Expiry, card.CVV, card.PIN, card.Notes)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrBankCardExists
+		}
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrBankCardNotFound
+	}
+	return nil
+}
+
+func (r *BankCardRepo) Delete(ctx context.Context, name string, userId int) error {
+	query := `UPDATE bank_card
+              SET deleted_at = NOW()
+              WHERE name = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	res, err := r.db.GetDB().Exec(ctx, query, name, userId)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrBankCardNotFound
+	}
+	return nil
+}
+
+func (r *BankCardRepo) DeleteByID(ctx context.Context, id, userId int) error {
+	query := `UPDATE bank_card
+              SET deleted_at = NOW()
+              WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	res, err := r.db.GetDB().Exec(ctx, query, id, userId)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrBankCardNotFound
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/bankcard/models"
+	"gophKeeper/internal/server/services/bankcard/repository"
+)
+
+type IBankCardUsecase interface {
+	CreateCard(ctx context.Context, card *models.Card) (int, error)
+	GetCardByName(ctx context.Context, name string, userId int) (*models.Card, error)
+	GetCardByIndex(ctx context.Context, index string, userId int) (*models.Card, error)
+	GetCardByID(ctx context.Context, id, userId int) (*models.Card, error)
+	GetAllCards(ctx context.Context, userId int) (*[]models.Card, error)
+	UpdateCard(ctx context.Context, card *models.Card) error
+	DeleteCard(ctx context.Context, name string, userId int) error
+	DeleteCardByID(ctx context.Context, id, userId int) error
+}
+
+type BankCardUsecase struct {
+	repo repository.IBankCardRepo
+}
+
+func NewBankCardUsecase(repo repository.IBankCardRepo) IBankCardUsecase {
+	return &BankCardUsecase{repo: repo}
+}
+
+// CreateCard сохраняет карту. Реквизиты приходят уже зашифрованными,
+// поэтому сервер проверяет только наличие имени и номера.
+func (u *BankCardUsecase) CreateCard(ctx context.Context, card *models.Card) (int, error) {
+	if card.Name == "" {
+		return 0, domain.ErrNameEmpty
+	}
+	if card.UserID == 0 || card.Number == "" {
+		return 0, domain.ErrNoDataToCreate
+	}
+	return u.repo.Create(ctx, card)
+}
+
+func (u *BankCardUsecase) GetCardByName(ctx context.Context, name string, userId int) (*models.Card, error) {
+	if name == "" {
+		return nil, domain.ErrNameEmpty
+	}
+	return u.repo.Get(ctx, name, userId)
+}
+
+func (u *BankCardUsecase) GetCardByIndex(ctx context.Context, index string, userId int) (*models.Card, error) {
+	if index == "" {
+		return nil, domain.ErrNameEmpty
+	}
+	return u.repo.GetByIndex(ctx, index, userId)
+}
+
+func (u *BankCardUsecase) GetCardByID(ctx context.Context, id, userId int) (*models.Card, error) {
+	return u.repo.GetByID(ctx, id, userId)
+}
+
+func (u *BankCardUsecase) GetAllCards(ctx context.Context, userId int) (*[]models.Card, error) {
+	return u.repo.GetAll(ctx, userId)
+}
+
+// UpdateCard заменяет карту с card.Id целиком. Номер обязателен, как при
+// создании; остальные поля можно очистить, передав пустыми.
+func (u *BankCardUsecase) UpdateCard(ctx context.Context, card *models.Card) error {
+	if card.Name == "" {
+		return domain.ErrNameEmpty
+	}
+	if card.Number == "" {
+		return domain.ErrInvalidInput
+	}
+	return u.repo.UpdateByID(ctx, card)
+}
+
+func (u *BankCardUsecase) DeleteCard(ctx context.Context, name string, userId int) error {
+	if name == "" {
+		return domain.ErrNameEmpty
+	}
+	return u.repo.Delete(ctx, name, userId)
+}
+
+func (u *BankCardUsecase) DeleteCardByID(ctx context.Context, id, userId int) error {
+	return u.repo.DeleteByID(ctx, id, userId)
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/services/bankcard/models"
+)
+
+type BankCardUsecaseMock struct {
+	mock.Mock
+}
+
+func NewBankCardUsecaseMock() *BankCardUsecaseMock {
+	return &BankCardUsecaseMock{}
+}
+
+func (u *BankCardUsecaseMock) CreateCard(ctx context.Context, card *models.Card) (int, error) {
+	args := u.Called(ctx, card)
+	return args.Int(0), args.Error(1)
+}
+
+func (u *BankCardUsecaseMock) GetCardByName(ctx context.Context, name string, userId int) (*models.Card, error) {
+	args := u.Called(ctx, name, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Card), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *BankCardUsecaseMock) GetCardByIndex(ctx context.Context, index string, userId int) (*models.Card, error) {
+	args := u.Called(ctx, index, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Card), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *BankCardUsecaseMock) GetCardByID(ctx context.Context, id, userId int) (*models.Card, error) {
+	args := u.Called(ctx, id, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Card), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *BankCardUsecaseMock) GetAllCards(ctx context.Context, userId int) (*[]models.Card, error) {
+	args := u.Called(ctx, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*[]models.Card), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *BankCardUsecaseMock) UpdateCard(ctx context.Context, card *models.Card) error {
+	args := u.Called(ctx, card)
+	return args.Error(0)
+}
+
+func (u *BankCardUsecaseMock) DeleteCard(ctx context.Context, name string, userId int) error {
+	args := u.Called(ctx, name, userId)
+	return args.Error(0)
+}
+
+func (u *BankCardUsecaseMock) DeleteCardByID(ctx context.Context, id, userId int) error {
+	args := u.Called(ctx, id, userId)
+	return args.Error(0)
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// File описывает бинарный секрет. Содержимое хранится в blob-хранилище
+// в виде зашифрованных на клиенте чанков.
+type File struct {
+	Id         int       `json:"id"`
+	Name       string    `json:"name"`
+	UserID     int       `json:"-"`
+	Size       int64     `json:"size"`
+	ChunkSize  int       `json:"chunk_size"`
+	ChunkCount int       `json:"chunk_count"`
+	Completed  bool      `json:"completed"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// UploadRequest открывает (или возобновляет) загрузку файла.
+type UploadRequest struct {
+	Name       string `json:"name"`
+	Size       int64  `json:"size"`
+	ChunkSize  int    `json:"chunk_size"`
+	ChunkCount int    `json:"chunk_count"`
+	UserID     int    `json:"-"`
+}
+
+// Upload — состояние загрузки: какие чанки сервер уже получил.
+type Upload struct {
+	File
+	Received []int `json:"received"`
+}
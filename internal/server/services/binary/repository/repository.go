@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"gophKeeper/internal/server/db"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/binary/models"
+)
+
+type IBinaryRepo interface {
+	FindPending(ctx context.Context, req *models.UploadRequest) (*models.File, error)
+	CreatePending(ctx context.Context, req *models.UploadRequest) (*models.File, error)
+	GetByID(ctx context.Context, id, userId int) (*models.File, error)
+	GetByName(ctx context.Context, name string, userId int) (*models.File, error)
+	GetAll(ctx context.Context, userId int) (*[]models.File, error)
+	ReceivedChunks(ctx context.Context, fileId int) ([]int, error)
+	SaveChunk(ctx context.Context, fileId, index, size int) error
+	Complete(ctx context.Context, file *models.File) (replaced []int, err error)
+	Delete(ctx context.Context, id int) error
+}
+
+type BinaryRepo struct {
+	db db.IDatabase
+}
+
+func NewBinaryRepo(db db.IDatabase) IBinaryRepo {
+	return &BinaryRepo{
+		db: db,
+	}
+}
+
+const fileColumns = `id, name, user_id, size, chunk_size, chunk_count, completed, created_at, updated_at`
+
+func scanFile(row pgx.Row) (*models.File, error) {
+	var file models.File
+	err := row.Scan(&file.Id, &file.Name, &file.UserID, &file.Size, &file.ChunkSize, &file.ChunkCount, &file.Completed, &file.CreatedAt, &file.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBinaryNotFound
+		}
+		return nil, err
+	}
+	return &file, nil
+}
+
+// FindPending ищет незавершённую загрузку с теми же параметрами, чтобы её можно было продолжить.
+func (r *BinaryRepo) FindPending(ctx context.Context, req *models.UploadRequest) (*models.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM binary_file
+              WHERE user_id = $1 AND name = $2 AND size = $3 AND chunk_size = $4 AND chunk_count = $5 AND NOT completed
+              ORDER BY id DESC LIMIT 1`
+	return scanFile(r.db.GetDB().QueryRow(ctx, query, req.UserID, req.Name, req.Size, req.ChunkSize, req.ChunkCount))
+}
+
+func (r *BinaryRepo) CreatePending(ctx context.Context, req *models.UploadRequest) (*models.File, error) {
+	query := `INSERT INTO binary_file (name, user_id, size, chunk_size, chunk_count)
+              VALUES ($1, $2, $3, $4, $5) RETURNING ` + fileColumns
+	return scanFile(r.db.GetDB().QueryRow(ctx, query, req.Name, req.UserID, req.Size, req.ChunkSize, req.ChunkCount))
+}
+
+func (r *BinaryRepo) GetByID(ctx context.Context, id, userId int) (*models.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM binary_file WHERE id = $1 AND user_id = $2`
+	return scanFile(r.db.GetDB().QueryRow(ctx, query, id, userId))
+}
+
+func (r *BinaryRepo) GetByName(ctx context.Context, name string, userId int) (*models.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM binary_file WHERE name = $1 AND user_id = $2 AND completed`
+	return scanFile(r.db.GetDB().QueryRow(ctx, query, name, userId))
+}
+
+func (r *BinaryRepo) GetAll(ctx context.Context, userId int) (*[]models.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM binary_file WHERE user_id = $1 AND completed ORDER BY name`
+	rows, err := r.db.GetDB().Query(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := []models.File{}
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *file)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &files, nil
+}
+
+func (r *BinaryRepo) ReceivedChunks(ctx context.Context, fileId int) ([]int, error) {
+	rows, err := r.db.GetDB().Query(ctx, `SELECT idx FROM binary_chunk WHERE file_id = $1 ORDER BY idx`, fileId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	received := []int{}
+	for rows.Next() {
+		var idx int
+		if err := rows.Scan(&idx); err != nil {
+			return nil, err
+		}
+		received = append(received, idx)
+	}
+	return received, rows.Err()
+}
+
+func (r *BinaryRepo) SaveChunk(ctx context.Context, fileId, index, size int) error {
+	query := `INSERT INTO binary_chunk (file_id, idx, size) VALUES ($1, $2, $3)
+              ON CONFLICT (file_id, idx) DO UPDATE SET size = EXCLUDED.size`
+	_, err := r.db.GetDB().Exec(ctx, query, fileId, index, size)
+	return err
+}
+
+// Complete помечает загрузку завершённой и в той же транзакции удаляет
+// предыдущую версию файла с тем же именем. Возвращает id удалённых версий,
+// чтобы вызывающий мог очистить их содержимое в blob-хранилище.
+func (r *BinaryRepo) Complete(ctx context.Context, file *models.File) ([]int, error) {
+	tx, err := r.db.GetDB().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `DELETE FROM binary_file WHERE name = $1 AND user_id = $2 AND completed RETURNING id`, file.Name, file.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var replaced []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		replaced = append(replaced, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE binary_file SET completed = TRUE, updated_at = NOW() WHERE id = $1`, file.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	file.Completed = true
+	return replaced, nil
+}
+
+func (r *BinaryRepo) Delete(ctx context.Context, id int) error {
+	_, err := r.db.GetDB().Exec(ctx, `DELETE FROM binary_file WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore — хранилище содержимого бинарных секретов. Ключи имеют вид
+// "<user>/<file>/<chunk>", Delete удаляет все ключи с указанным префиксом.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, prefix string) error
+}
+
+// UserKey — префикс всех файлов пользователя, используется при удалении аккаунта.
+func UserKey(userId int) string {
+	return fmt.Sprintf("%d", userId)
+}
+
+// FileKey — префикс всех чанков файла, используется для удаления.
+func FileKey(userId, fileId int) string {
+	return fmt.Sprintf("%d/%d", userId, fileId)
+}
+
+func ChunkKey(userId, fileId, index int) string {
+	return fmt.Sprintf("%d/%d/%06d", userId, fileId, index)
+}
+
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (BlobStore, error) {
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return nil, err
+	}
+	return &LocalStore{root: root}, nil
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return "", errors.New("invalid blob key")
+	}
+	return filepath.Join(s.root, clean), nil
+}
+
+// Put пишет данные во временный файл и переименовывает его, поэтому
+// прерванная запись не оставляет частично записанный чанк.
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, prefix string) error {
+	path, err := s.path(prefix)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := store.Put(ctx, "1/2/0", strings.NewReader("chunk"))
+	if err != nil || n != 5 {
+		t.Fatalf("Put: n=%d err=%v", n, err)
+	}
+
+	r, err := store.Get(ctx, "1/2/0")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "chunk" {
+		t.Errorf("ожидалось %q, получено %q", "chunk", data)
+	}
+
+	if err := store.Delete(ctx, "1/2"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "1/2/0"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("ожидалась ErrBlobNotFound, получено %v", err)
+	}
+
+	if _, err := store.Put(ctx, "../escape", strings.NewReader("x")); err == nil {
+		t.Error("ключ за пределами корня хранилища должен отклоняться")
+	}
+}
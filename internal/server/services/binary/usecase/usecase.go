@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/binary/models"
+	"gophKeeper/internal/server/services/binary/repository"
+	"gophKeeper/internal/server/services/binary/storage"
+	"io"
+	"log"
+)
+
+// MaxChunkSize — максимальный размер открытого чанка, который может объявить клиент.
+const MaxChunkSize = 4 << 20
+
+type IBinaryUsecase interface {
+	StartUpload(ctx context.Context, req *models.UploadRequest) (*models.Upload, error)
+	GetUpload(ctx context.Context, fileId, userId int) (*models.Upload, error)
+	PutChunk(ctx context.Context, fileId, userId, index int, r io.Reader) error
+	CompleteUpload(ctx context.Context, fileId, userId int) (*models.File, error)
+	GetFile(ctx context.Context, name string, userId int) (*models.File, error)
+	GetFiles(ctx context.Context, userId int) (*[]models.File, error)
+	GetChunk(ctx context.Context, name string, userId, index int) (io.ReadCloser, error)
+	DeleteFile(ctx context.Context, name string, userId int) error
+}
+
+type BinaryUsecase struct {
+	repo  repository.IBinaryRepo
+	store storage.BlobStore
+}
+
+func NewBinaryUsecase(repo repository.IBinaryRepo, store storage.BlobStore) IBinaryUsecase {
+	return &BinaryUsecase{repo: repo, store: store}
+}
+
+// maxEncryptedChunk ограничивает тело запроса с чанком: шифротекст в base64
+// занимает примерно 4/3 от открытого текста плюс IV и MAC.
+func maxEncryptedChunk(chunkSize int) int64 {
+	return int64(chunkSize)*2 + 4096
+}
+
+// StartUpload открывает загрузку или возвращает уже начатую с теми же
+// параметрами вместе со списком полученных чанков, чтобы клиент мог её продолжить.
+func (u *BinaryUsecase) StartUpload(ctx context.Context, req *models.UploadRequest) (*models.Upload, error) {
+	if req.Name == "" {
+		return nil, domain.ErrNameEmpty
+	}
+	if req.Size < 0 || req.ChunkSize <= 0 || req.ChunkSize > MaxChunkSize {
+		return nil, domain.ErrInvalidUpload
+	}
+	expectedChunks := int((req.Size + int64(req.ChunkSize) - 1) / int64(req.ChunkSize))
+	if expectedChunks == 0 {
+		expectedChunks = 1
+	}
+	if req.ChunkCount != expectedChunks {
+		return nil, domain.ErrInvalidUpload
+	}
+
+	file, err := u.repo.FindPending(ctx, req)
+	if errors.Is(err, domain.ErrBinaryNotFound) {
+		file, err = u.repo.CreatePending(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u.upload(ctx, file)
+}
+
+func (u *BinaryUsecase) GetUpload(ctx context.Context, fileId, userId int) (*models.Upload, error) {
+	file, err := u.repo.GetByID(ctx, fileId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return u.upload(ctx, file)
+}
+
+func (u *BinaryUsecase) upload(ctx context.Context, file *models.File) (*models.Upload, error) {
+	received, err := u.repo.ReceivedChunks(ctx, file.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Upload{File: *file, Received: received}, nil
+}
+
+func (u *BinaryUsecase) PutChunk(ctx context.Context, fileId, userId, index int, r io.Reader) error {
+	file, err := u.repo.GetByID(ctx, fileId, userId)
+	if err != nil {
+		return err
+	}
+	if file.Completed {
+		return domain.ErrUploadCompleted
+	}
+	if index < 0 || index >= file.ChunkCount {
+		return domain.ErrChunkOutOfRange
+	}
+
+	limit := maxEncryptedChunk(file.ChunkSize)
+	limited := &io.LimitedReader{R: r, N: limit + 1}
+	n, err := u.store.Put(ctx, storage.ChunkKey(userId, fileId, index), limited)
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return domain.ErrChunkTooLarge
+	}
+	return u.repo.SaveChunk(ctx, fileId, index, int(n))
+}
+
+func (u *BinaryUsecase) CompleteUpload(ctx context.Context, fileId, userId int) (*models.File, error) {
+	upload, err := u.GetUpload(ctx, fileId, userId)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Completed {
+		return &upload.File, nil
+	}
+	if len(upload.Received) != upload.ChunkCount {
+		return nil, domain.ErrUploadIncomplete
+	}
+
+	replaced, err := u.repo.Complete(ctx, &upload.File)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range replaced {
+		if err := u.store.Delete(ctx, storage.FileKey(userId, id)); err != nil {
+			log.Println("failed to delete replaced binary content:", err)
+		}
+	}
+	return &upload.File, nil
+}
+
+func (u *BinaryUsecase) GetFile(ctx context.Context, name string, userId int) (*models.File, error) {
+	if name == "" {
+		return nil, domain.ErrNameEmpty
+	}
+	return u.repo.GetByName(ctx, name, userId)
+}
+
+func (u *BinaryUsecase) GetFiles(ctx context.Context, userId int) (*[]models.File, error) {
+	return u.repo.GetAll(ctx, userId)
+}
+
+func (u *BinaryUsecase) GetChunk(ctx context.Context, name string, userId, index int) (io.ReadCloser, error) {
+	file, err := u.GetFile(ctx, name, userId)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= file.ChunkCount {
+		return nil, domain.ErrChunkOutOfRange
+	}
+	return u.store.Get(ctx, storage.ChunkKey(userId, file.Id, index))
+}
+
+func (u *BinaryUsecase) DeleteFile(ctx context.Context, name string, userId int) error {
+	file, err := u.GetFile(ctx, name, userId)
+	if err != nil {
+		return err
+	}
+	if err := u.repo.Delete(ctx, file.Id); err != nil {
+		return err
+	}
+	return u.store.Delete(ctx, storage.FileKey(userId, file.Id))
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"io"
+
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/services/binary/models"
+)
+
+type BinaryUsecaseMock struct {
+	mock.Mock
+}
+
+func NewBinaryUsecaseMock() *BinaryUsecaseMock {
+	return &BinaryUsecaseMock{}
+}
+
+func (u *BinaryUsecaseMock) StartUpload(ctx context.Context, req *models.UploadRequest) (*models.Upload, error) {
+	args := u.Called(ctx, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Upload), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *BinaryUsecaseMock) GetUpload(ctx context.Context, fileId, userId int) (*models.Upload, error) {
+	args := u.Called(ctx, fileId, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Upload), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *BinaryUsecaseMock) PutChunk(ctx context.Context, fileId, userId, index int, r io.Reader) error {
+	data, _ := io.ReadAll(r)
+	args := u.Called(ctx, fileId, userId, index, string(data))
+	return args.Error(0)
+}
+
+func (u *BinaryUsecaseMock) CompleteUpload(ctx context.Context, fileId, userId int) (*models.File, error) {
+	args := u.Called(ctx, fileId, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.File), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *BinaryUsecaseMock) GetFile(ctx context.Context, name string, userId int) (*models.File, error) {
+	args := u.Called(ctx, name, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.File), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *BinaryUsecaseMock) GetFiles(ctx context.Context, userId int) (*[]models.File, error) {
+	args := u.Called(ctx, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*[]models.File), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *BinaryUsecaseMock) GetChunk(ctx context.Context, name string, userId, index int) (io.ReadCloser, error) {
+	args := u.Called(ctx, name, userId, index)
+	if args.Get(0) != nil {
+		return args.Get(0).(io.ReadCloser), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *BinaryUsecaseMock) DeleteFile(ctx context.Context, name string, userId int) error {
+	args := u.Called(ctx, name, userId)
+	return args.Error(0)
+}
@@ -0,0 +1,101 @@
+// Package events сообщает подключённым устройствам пользователя об
+// изменениях его записей. Hub раздаёт события подписчикам внутри процесса,
+// а Listen наполняет его уведомлениями Postgres, поэтому события доходят и
+// до устройств, подключённых к другим экземплярам сервера.
+package events
+
+import "sync"
+
+// Event — изменение записей пользователя. Seq — номер в ленте изменений;
+// 0 — события могли потеряться, и клиенту стоит синхронизироваться.
+type Event struct {
+	Seq int64 `json:"seq"`
+}
+
+// Hub — подписки пользователей на их события. Каждому подписчику хранится
+// только последнее недоставленное событие: оно лишь будит синхронизацию,
+// а что изменилось, клиент забирает из ленты по своему курсору.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[int]map[chan Event]struct{}
+	closed bool
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: map[int]map[chan Event]struct{}{}}
+}
+
+// Subscribe подписывает на события пользователя userID. Вызов cancel
+// отписывает и закрывает канал; после Close канал сразу закрыт.
+func (h *Hub) Subscribe(userID int) (<-chan Event, func()) {
+	ch := make(chan Event, 1)
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	if h.subs[userID] == nil {
+		h.subs[userID] = map[chan Event]struct{}{}
+	}
+	h.subs[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[userID][ch]; !ok {
+			return
+		}
+		delete(h.subs[userID], ch)
+		if len(h.subs[userID]) == 0 {
+			delete(h.subs, userID)
+		}
+		close(ch)
+	}
+}
+
+// Close закрывает все подписки, чтобы потоки событий завершились при
+// остановке сервера.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for ch := range subs {
+			close(ch)
+		}
+	}
+	h.subs = map[int]map[chan Event]struct{}{}
+	h.closed = true
+}
+
+// Publish отправляет событие всем подпискам пользователя userID, не
+// дожидаясь получателей.
+func (h *Hub) Publish(userID int, event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[userID] {
+		deliver(ch, event)
+	}
+}
+
+// Broadcast отправляет событие всем подпискам.
+func (h *Hub) Broadcast(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for ch := range subs {
+			deliver(ch, event)
+		}
+	}
+}
+
+// deliver заменяет недоставленное событие новым. Отправляет только Hub под
+// своей блокировкой, поэтому место в канале после чтения не займёт другой.
+func deliver(ch chan Event, event Event) {
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- event
+}
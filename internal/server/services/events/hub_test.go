@@ -0,0 +1,51 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+	first, cancelFirst := hub.Subscribe(1)
+	second, cancelSecond := hub.Subscribe(1)
+	other, cancelOther := hub.Subscribe(2)
+	defer cancelSecond()
+	defer cancelOther()
+
+	// Недоставленное событие заменяется новым.
+	hub.Publish(1, Event{Seq: 1})
+	hub.Publish(1, Event{Seq: 2})
+	assert.Equal(t, Event{Seq: 2}, <-first)
+	assert.Equal(t, Event{Seq: 2}, <-second)
+	assert.Empty(t, other)
+
+	cancelFirst()
+	cancelFirst()
+	_, open := <-first
+	assert.False(t, open)
+
+	hub.Broadcast(Event{})
+	assert.Equal(t, Event{}, <-second)
+	assert.Equal(t, Event{}, <-other)
+
+	hub.Close()
+	_, open = <-second
+	assert.False(t, open)
+	late, _ := hub.Subscribe(1)
+	_, open = <-late
+	assert.False(t, open)
+}
+
+func TestParsePayload(t *testing.T) {
+	userID, seq, ok := parsePayload("3:42")
+	assert.True(t, ok)
+	assert.Equal(t, 3, userID)
+	assert.Equal(t, int64(42), seq)
+
+	for _, payload := range []string{"", "3", "x:1", "3:y"} {
+		_, _, ok := parsePayload(payload)
+		assert.False(t, ok, payload)
+	}
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Channel — канал уведомлений, в который база пишет "user_id:seq" при
+// каждом изменении записей lockbox.
+const Channel = "lockbox_changes"
+
+const (
+	listenBaseDelay = time.Second
+	listenMaxDelay  = 30 * time.Second
+)
+
+// Listen слушает канал Channel на отдельном соединении из pool и публикует
+// уведомления в hub, пока не отменён ctx. Соединение восстанавливается с
+// растущей задержкой; уведомления за время обрыва теряются, поэтому после
+// восстановления всем подписчикам уходит событие с Seq 0.
+func Listen(ctx context.Context, pool *pgxpool.Pool, hub *Hub) {
+	delay := listenBaseDelay
+	for {
+		connected, err := listen(ctx, pool, hub)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Error("Lockbox change listener stopped", "error", err)
+		if connected {
+			delay = listenBaseDelay
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay = min(delay*2, listenMaxDelay)
+	}
+}
+
+// listen обслуживает одно соединение до ошибки. connected — удалось ли
+// подписаться на канал.
+func listen(ctx context.Context, pool *pgxpool.Pool, hub *Hub) (connected bool, err error) {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	// Соединение с LISTEN не возвращается в пул: иначе его уведомления
+	// достались бы случайному запросу.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return false, err
+	}
+	hub.Broadcast(Event{})
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		userID, seq, ok := parsePayload(notification.Payload)
+		if !ok {
+			slog.Warn("Malformed lockbox change notification", "payload", notification.Payload)
+			continue
+		}
+		hub.Publish(userID, Event{Seq: seq})
+	}
+}
+
+// parsePayload разбирает уведомление вида "user_id:seq".
+func parsePayload(payload string) (int, int64, bool) {
+	user, seq, found := strings.Cut(payload, ":")
+	if !found {
+		return 0, 0, false
+	}
+	userID, err1 := strconv.Atoi(user)
+	n, err2 := strconv.ParseInt(seq, 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return userID, n, true
+}
@@ -5,6 +5,7 @@ import "time"
 type Data struct {
 	Id          int        `json:"id"`
 	Name        string     `json:"name"`
+	NameIndex   string     `json:"name_index,omitempty"` // слепой индекс зашифрованного имени
 	Url         string     `json:"url"`
 	Login       string     `json:"login"`
 	Password    string     `json:"password"`
@@ -13,4 +14,83 @@ type Data struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at"`
+	// Revision растёт при каждом изменении записи. В запросе на изменение —
+	// версия, от которой клиент начинал правку. Существующую запись без
+	// версии изменить нельзя: это конфликт, как и устаревшая версия.
+	Revision int `json:"revision"`
+	// NoHistory — запись пришла синхронизацией всего хранилища, а не правкой
+	// пользователя. Клиент каждый раз шифрует поля заново, и сохранение
+	// версий заполнило бы историю копиями.
+	NoHistory bool `json:"-"`
+}
+
+// Revision — прежняя версия записи. UpdatedAt — когда версия была сохранена,
+// ReplacedAt — когда её заменила следующая.
+type Revision struct {
+	RevisionId  int       `json:"revision_id"`
+	LockBoxId   int       `json:"lockbox_id"`
+	Name        string    `json:"name"`
+	NameIndex   string    `json:"name_index,omitempty"`
+	Url         string    `json:"url"`
+	Login       string    `json:"login"`
+	Password    string    `json:"password"`
+	Description string    `json:"description"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	ReplacedAt  time.Time `json:"replaced_at"`
+}
+
+const (
+	// DefaultChangesPage — сколько изменений отдаётся за один запрос ленты.
+	DefaultChangesPage = 500
+	// MaxChangesPage ограничивает страницу ленты изменений.
+	MaxChangesPage = 1000
+	// MaxPushBatch ограничивает число изменений в одной отправке клиента.
+	MaxPushBatch = 500
+	// MaxIdempotencyKey ограничивает длину ключа идемпотентности.
+	MaxIdempotencyKey = 64
+	// IdempotencyKeyTTL — сколько хранится результат применённого изменения.
+	// Клиент, повторяющий отправку дольше, получит конфликт вместо повтора.
+	IdempotencyKeyTTL = 7 * 24 * time.Hour
+)
+
+// Change — изменение записи в ленте синхронизации. Запись, удалённая в
+// корзину, приходит целиком с Deleted; от окончательно удалённой остаются
+// только id и имя. IdempotencyKey присылает клиент: изменение с уже
+// применённым ключом не применяется повторно.
+type Change struct {
+	Seq            int64  `json:"seq"`
+	Deleted        bool   `json:"deleted"`
+	Data           Data   `json:"data"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// ChangeSet — страница ленты изменений. Cursor передаётся в следующий
+// запрос, More — остались ли изменения после этой страницы. Reset означает,
+// что курсор устарел: Changes содержит все записи, а остальные клиент
+// должен удалить у себя. Полный список тоже отдаётся страницами: After —
+// id последней записи страницы, его передают в следующий запрос вместе с
+// прежним since.
+type ChangeSet struct {
+	Cursor  int64    `json:"cursor"`
+	Reset   bool     `json:"reset"`
+	More    bool     `json:"more"`
+	After   int      `json:"after,omitempty"`
+	Changes []Change `json:"changes"`
+}
+
+// PushResult — итог применения пакета. Revisions — новая версия каждого
+// изменения пакета по порядку, 0 для удалений и конфликтов.
+type PushResult struct {
+	Applied   int        `json:"applied"`
+	Revisions []int      `json:"revisions"`
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+}
+
+// Conflict — изменение пакета, отклонённое из-за правки с другого
+// устройства. Index — номер изменения в пакете, Data — текущая версия на
+// сервере; Deleted — запись на сервере удалена.
+type Conflict struct {
+	Index   int  `json:"index"`
+	Deleted bool `json:"deleted"`
+	Data    Data `json:"data"`
 }
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"gophKeeper/internal/server/config"
 	"gophKeeper/internal/server/db"
+	"gophKeeper/internal/server/domain"
 	"gophKeeper/internal/server/services/lockbox/models"
-	"strings"
 )
 
 type ILockBoxRepo interface {
@@ -18,49 +20,109 @@ type ILockBoxRepo interface {
 	GetAll(ctx context.Context, userId int) (*[]models.Data, error)
 	Exists(ctx context.Context, name string, userId int) (bool, error)
 	PurgeExpiredLocks(ctx context.Context) (int64, error)
+	GetByID(ctx context.Context, id, userId int) (*models.Data, error)
+	GetByIndex(ctx context.Context, index string, userId int) (*models.Data, error)
+	UpdateByID(ctx context.Context, data *models.Data) error
+	Lookup(ctx context.Context, name, index string, userId int) (*models.Data, error)
+	UpdateIfRevision(ctx context.Context, data *models.Data) error
+	DeleteIfRevision(ctx context.Context, id, revision, userId int) error
+	DeleteByID(ctx context.Context, id, userId int) error
+	History(ctx context.Context, id, userId int) ([]models.Revision, error)
+	Restore(ctx context.Context, id, revisionId, userId int) error
+	PurgeRevisions(ctx context.Context) (int64, error)
+	Trash(ctx context.Context, userId int) (*[]models.Data, error)
+	Undelete(ctx context.Context, id, userId int) error
+	EmptyTrash(ctx context.Context, userId int) (int64, error)
+	Changes(ctx context.Context, userId int, since int64, after int, limit int) (*models.ChangeSet, error)
+	PurgeTombstones(ctx context.Context) (int64, error)
+	GetIdempotencyKey(ctx context.Context, userId int, key string) (int, bool, error)
+	SaveIdempotencyKey(ctx context.Context, userId int, key string, revision int) error
+	PurgeIdempotencyKeys(ctx context.Context) (int64, error)
 }
 
 type LockBoxRepo struct {
-	db db.IDatabase
+	db      db.IDatabase
+	history config.HistoryConf
+	trash   config.TrashConf
 }
 
-func NewLockBoxRepo(db db.IDatabase) ILockBoxRepo {
+func NewLockBoxRepo(db db.IDatabase, history config.HistoryConf, trash config.TrashConf) ILockBoxRepo {
 	return &LockBoxRepo{
-		db: db,
+		db:      db,
+		history: history,
+		trash:   trash,
 	}
 }
 
+// Update меняет переданные поля записи по имени и сохраняет прежнюю версию.
+// Запись меняется, только если её версия — data.Revision, иначе
+// ErrLockBoxConflict. После обновления в data.Revision — новая версия.
 func (l *LockBoxRepo) Update(ctx context.Context, data *models.Data) error {
-	var query strings.Builder
-	query.WriteString("UPDATE lockbox SET ")
-
-	if data.Url != "" {
-		query.WriteString(fmt.Sprintf("url = '%s', ", data.Url))
-	}
-	if data.Login != "" {
-		query.WriteString(fmt.Sprintf("username = '%s', ", data.Login))
+	tx, err := l.db.GetDB().Begin(ctx)
+	if err != nil {
+		return err
 	}
-	if data.Name != "" {
-		query.WriteString(fmt.Sprintf("name = '%s', ", data.Name))
+	defer tx.Rollback(ctx)
+
+	if !data.NoHistory {
+		if err := l.saveRevision(ctx, tx, `name = $1 AND user_id = $2`, data.Name, data.UserID); err != nil {
+			return err
+		}
 	}
-	if data.Description != "" {
-		query.WriteString(fmt.Sprintf("description = '%s', ", data.Description))
+
+	query := `UPDATE lockbox
+              SET url = COALESCE(NULLIF($3, ''), url),
+                  username = COALESCE(NULLIF($4, ''), username),
+                  password = COALESCE(NULLIF($5, ''), password),
+                  description = COALESCE(NULLIF($6, ''), description),
+                  updated_at = NOW(),
+                  deleted_at = CASE WHEN deleted_at IS NOT NULL AND NOW() > deleted_at THEN NULL ELSE deleted_at END
+              WHERE name = $1 AND user_id = $2 AND revision = $7
+              RETURNING revision`
+	err = tx.QueryRow(ctx, query, data.Name, data.UserID, data.Url, data.Login, data.Password, data.Description,
+		data.Revision).Scan(&data.Revision)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := l.Lookup(ctx, data.Name, "", data.UserID); err == nil {
+			return domain.ErrLockBoxConflict
+		}
+		return domain.ErrLockBoxNotFound
 	}
-	if data.Password != "" {
-		query.WriteString(fmt.Sprintf("password = '%s', ", data.Password))
+	if err != nil {
+		return err
 	}
+	return tx.Commit(ctx)
+}
 
-	query.WriteString("updated_at = NOW(), ")
-	query.WriteString("deleted_at = CASE WHEN deleted_at IS NOT NULL AND NOW() > deleted_at THEN NULL ELSE deleted_at END ")
-
-	query.WriteString(fmt.Sprintf("WHERE name = '%s'", data.Name))
+const revisionColumns = `revision_id, lockbox_id, name, COALESCE(name_index, ''), COALESCE(url, ''), COALESCE(username, ''),
+                         COALESCE(password, ''), COALESCE(description, ''), updated_at, replaced_at`
 
-	_, err := l.db.GetDB().Exec(ctx, query.String())
+// saveRevision копирует текущую версию записи в историю перед изменением и
+// удаляет версии сверх лимита хранения. Записи может не быть — тогда
+// сохранять нечего.
+func (l *LockBoxRepo) saveRevision(ctx context.Context, tx pgx.Tx, where string, args ...any) error {
+	query := `INSERT INTO lockbox_revisions (lockbox_id, user_id, name, name_index, url, username, password, description, updated_at)
+              SELECT id, user_id, name, name_index, url, username, password, description, COALESCE(updated_at, created_at, NOW())
+              FROM lockbox
+              WHERE ` + where + `
+              FOR UPDATE
+              RETURNING lockbox_id`
+	var lockBoxId int
+	err := tx.QueryRow(ctx, query, args...).Scan(&lockBoxId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
-	return nil
+	prune := `DELETE FROM lockbox_revisions
+              WHERE lockbox_id = $1
+                AND (($2::int > 0 AND revision_id NOT IN (SELECT revision_id FROM lockbox_revisions
+                                                     WHERE lockbox_id = $1
+                                                     ORDER BY revision_id DESC LIMIT $2))
+                  OR ($3::float8 > 0 AND replaced_at < NOW() - make_interval(secs => $3::float8)))`
+	_, err = tx.Exec(ctx, prune, lockBoxId, l.history.MaxRevisions, l.history.MaxAge.Seconds())
+	return err
 }
 
 func (l *LockBoxRepo) Delete(ctx context.Context, name string, userId int) error {
@@ -82,12 +144,12 @@ func (l *LockBoxRepo) Delete(ctx context.Context, name string, userId int) error
 }
 
 func (l *LockBoxRepo) Get(ctx context.Context, name string, userId int) (*models.Data, error) {
-	query := `SELECT id, url, username, password, description, created_at, updated_at, deleted_at 
+	query := `SELECT id, url, username, password, description, created_at, updated_at, deleted_at, revision 
           FROM lockbox 
           WHERE user_id = $1 AND name = $2`
 	var data models.Data
 
-	err := l.db.GetDB().QueryRow(ctx, query, userId, name).Scan(&data.Id, &data.Url, &data.Login, &data.Password, &data.Description, &data.CreatedAt, &data.UpdatedAt, &data.DeletedAt)
+	err := l.db.GetDB().QueryRow(ctx, query, userId, name).Scan(&data.Id, &data.Url, &data.Login, &data.Password, &data.Description, &data.CreatedAt, &data.UpdatedAt, &data.DeletedAt, &data.Revision)
 	if err != nil {
 		fmt.Println(err)
 		return nil, err
@@ -99,21 +161,23 @@ func (l *LockBoxRepo) Get(ctx context.Context, name string, userId int) (*models
 }
 
 func (l *LockBoxRepo) Create(ctx context.Context, data *models.Data) (int, error) {
-	query := `INSERT INTO lockbox (name, url, username, password, description, user_id)
-              VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	query := `INSERT INTO lockbox (name, url, username, password, description, user_id, name_index)
+              VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')) RETURNING id, revision`
 
 	var id int
-	err := l.db.GetDB().QueryRow(ctx, query, data.Name, data.Url, data.Login, data.Password, data.Description, data.UserID).Scan(&id)
+	err := l.db.GetDB().QueryRow(ctx, query, data.Name, data.Url, data.Login, data.Password, data.Description, data.UserID, data.NameIndex).Scan(&id, &data.Revision)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			// Зашифрованное имя каждый раз разное, поэтому удалённую запись ищем по индексу.
 			restoreQuery := `UPDATE lockbox 
-                             SET deleted_at = NULL, updated_at = NOW(), 
+                             SET deleted_at = NULL, updated_at = NOW(), name = $1,
                                  url = $2, username = $3, password = $4, description = $5 
-                             WHERE name = $1 AND deleted_at IS NOT NULL 
-                             RETURNING id`
+                             WHERE user_id = $6 AND deleted_at IS NOT NULL
+                               AND CASE WHEN $7 <> '' THEN name_index = $7 ELSE name = $1 END
+                             RETURNING id, revision`
 
-			err = l.db.GetDB().QueryRow(ctx, restoreQuery, data.Name, data.Url, data.Login, data.Password, data.Description).Scan(&id)
+			err = l.db.GetDB().QueryRow(ctx, restoreQuery, data.Name, data.Url, data.Login, data.Password, data.Description, data.UserID, data.NameIndex).Scan(&id, &data.Revision)
 			if err == nil {
 				return id, nil
 			}
@@ -125,7 +189,7 @@ func (l *LockBoxRepo) Create(ctx context.Context, data *models.Data) (int, error
 }
 
 func (l *LockBoxRepo) GetAll(ctx context.Context, userId int) (*[]models.Data, error) {
-	query := `SELECT id, name, url, username, password, description, created_at, updated_at, deleted_at
+	query := `SELECT id, name, COALESCE(name_index, ''), url, username, password, description, created_at, updated_at, deleted_at, revision
               FROM lockbox 
               WHERE user_id = $1 AND deleted_at IS NULL`
 	rows, err := l.db.GetDB().Query(ctx, query, userId)
@@ -137,7 +201,7 @@ func (l *LockBoxRepo) GetAll(ctx context.Context, userId int) (*[]models.Data, e
 	var dataList []models.Data
 	for rows.Next() {
 		var data models.Data
-		if err := rows.Scan(&data.Id, &data.Name, &data.Url, &data.Login, &data.Password, &data.Description, &data.CreatedAt, &data.UpdatedAt, &data.DeletedAt); err != nil {
+		if err := rows.Scan(&data.Id, &data.Name, &data.NameIndex, &data.Url, &data.Login, &data.Password, &data.Description, &data.CreatedAt, &data.UpdatedAt, &data.DeletedAt, &data.Revision); err != nil {
 			return nil, err
 		}
 		dataList = append(dataList, data)
@@ -158,12 +222,434 @@ func (l *LockBoxRepo) Exists(ctx context.Context, name string, userId int) (bool
 	}
 	return exists, nil
 }
+
+// PurgeExpiredLocks окончательно удаляет записи, пролежавшие в корзине
+// дольше срока хранения.
 func (l *LockBoxRepo) PurgeExpiredLocks(ctx context.Context) (int64, error) {
+	if l.trash.Retention <= 0 {
+		return 0, nil
+	}
 	query := `DELETE FROM lockbox 
               WHERE deleted_at IS NOT NULL 
-              AND deleted_at < NOW() - INTERVAL '1 day'`
+              AND deleted_at < NOW() - make_interval(secs => $1::float8)`
+
+	res, err := l.db.GetDB().Exec(ctx, query, l.trash.Retention.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+// Trash возвращает удалённые записи пользователя, недавно удалённые первыми.
+func (l *LockBoxRepo) Trash(ctx context.Context, userId int) (*[]models.Data, error) {
+	query := `SELECT ` + lockColumns + ` FROM lockbox
+              WHERE user_id = $1 AND deleted_at IS NOT NULL
+              ORDER BY deleted_at DESC`
+	rows, err := l.db.GetDB().Query(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dataList := []models.Data{}
+	for rows.Next() {
+		data, err := scanLock(rows)
+		if err != nil {
+			return nil, err
+		}
+		dataList = append(dataList, *data)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return &dataList, nil
+}
+
+// Undelete возвращает запись из корзины.
+func (l *LockBoxRepo) Undelete(ctx context.Context, id, userId int) error {
+	query := `UPDATE lockbox SET deleted_at = NULL, updated_at = NOW()
+              WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`
+	res, err := l.db.GetDB().Exec(ctx, query, id, userId)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrLockBoxExists
+		}
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrLockBoxNotFound
+	}
+	return nil
+}
+
+// EmptyTrash окончательно удаляет все записи из корзины пользователя.
+// Их история удаляется вместе с ними каскадом.
+func (l *LockBoxRepo) EmptyTrash(ctx context.Context, userId int) (int64, error) {
+	query := `DELETE FROM lockbox WHERE user_id = $1 AND deleted_at IS NOT NULL`
+	res, err := l.db.GetDB().Exec(ctx, query, userId)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+const lockColumns = `id, name, COALESCE(name_index, ''), url, username, password, description, user_id, created_at, updated_at, deleted_at, revision`
+
+func scanLock(row pgx.Row) (*models.Data, error) {
+	var data models.Data
+	err := row.Scan(&data.Id, &data.Name, &data.NameIndex, &data.Url, &data.Login, &data.Password, &data.Description,
+		&data.UserID, &data.CreatedAt, &data.UpdatedAt, &data.DeletedAt, &data.Revision)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLockBoxNotFound
+		}
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (l *LockBoxRepo) GetByID(ctx context.Context, id, userId int) (*models.Data, error) {
+	query := `SELECT ` + lockColumns + ` FROM lockbox WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	return scanLock(l.db.GetDB().QueryRow(ctx, query, id, userId))
+}
+
+func (l *LockBoxRepo) GetByIndex(ctx context.Context, index string, userId int) (*models.Data, error) {
+	query := `SELECT ` + lockColumns + ` FROM lockbox WHERE name_index = $1 AND user_id = $2 AND deleted_at IS NULL`
+	return scanLock(l.db.GetDB().QueryRow(ctx, query, index, userId))
+}
+
+// UpdateByID обновляет только переданные поля; имя и индекс меняются вместе.
+// Прежняя версия записи сохраняется в историю. Запись меняется, только если
+// её версия — data.Revision, иначе ErrLockBoxConflict; после обновления в
+// data.Revision — новая версия.
+func (l *LockBoxRepo) UpdateByID(ctx context.Context, data *models.Data) error {
+	tx, err := l.db.GetDB().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if !data.NoHistory {
+		where := `id = $1 AND user_id = $2 AND deleted_at IS NULL`
+		if err := l.saveRevision(ctx, tx, where, data.Id, data.UserID); err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE lockbox
+              SET name = COALESCE(NULLIF($3, ''), name),
+                  name_index = COALESCE(NULLIF($4, ''), name_index),
+                  url = COALESCE(NULLIF($5, ''), url),
+                  username = COALESCE(NULLIF($6, ''), username),
+                  password = COALESCE(NULLIF($7, ''), password),
+                  description = COALESCE(NULLIF($8, ''), description),
+                  updated_at = NOW()
+              WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL AND revision = $9
+              RETURNING revision`
+	err = tx.QueryRow(ctx, query, data.Id, data.UserID, data.Name, data.NameIndex,
+		data.Url, data.Login, data.Password, data.Description, data.Revision).Scan(&data.Revision)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := l.GetByID(ctx, data.Id, data.UserID); err == nil {
+			return domain.ErrLockBoxConflict
+		}
+		return domain.ErrLockBoxNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// History возвращает прежние версии записи, новые первыми.
+func (l *LockBoxRepo) History(ctx context.Context, id, userId int) ([]models.Revision, error) {
+	query := `SELECT ` + revisionColumns + ` FROM lockbox_revisions
+              WHERE lockbox_id = $1 AND user_id = $2
+              ORDER BY revision_id DESC`
+	rows, err := l.db.GetDB().Query(ctx, query, id, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.Revision{}
+	for rows.Next() {
+		var r models.Revision
+		if err := rows.Scan(&r.RevisionId, &r.LockBoxId, &r.Name, &r.NameIndex, &r.Url, &r.Login, &r.Password,
+			&r.Description, &r.UpdatedAt, &r.ReplacedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+// Restore возвращает записи содержимое версии revisionId. Текущее содержимое
+// само становится версией, так что восстановление тоже можно отменить.
+func (l *LockBoxRepo) Restore(ctx context.Context, id, revisionId, userId int) error {
+	tx, err := l.db.GetDB().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM lockbox WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`,
+		id, userId).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrLockBoxNotFound
+	}
+
+	query := `SELECT ` + revisionColumns + ` FROM lockbox_revisions
+              WHERE revision_id = $1 AND lockbox_id = $2 AND user_id = $3`
+	var r models.Revision
+	err = tx.QueryRow(ctx, query, revisionId, id, userId).Scan(&r.RevisionId, &r.LockBoxId, &r.Name, &r.NameIndex,
+		&r.Url, &r.Login, &r.Password, &r.Description, &r.UpdatedAt, &r.ReplacedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrRevisionNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := l.saveRevision(ctx, tx, `id = $1 AND user_id = $2`, id, userId); err != nil {
+		return err
+	}
+
+	update := `UPDATE lockbox
+               SET name = $3, name_index = NULLIF($4, ''), url = $5, username = $6, password = $7,
+                   description = $8, updated_at = NOW()
+               WHERE id = $1 AND user_id = $2`
+	_, err = tx.Exec(ctx, update, id, userId, r.Name, r.NameIndex, r.Url, r.Login, r.Password, r.Description)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrLockBoxExists
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// PurgeRevisions удаляет версии старше срока хранения.
+func (l *LockBoxRepo) PurgeRevisions(ctx context.Context) (int64, error) {
+	if l.history.MaxAge <= 0 {
+		return 0, nil
+	}
+	query := `DELETE FROM lockbox_revisions WHERE replaced_at < NOW() - make_interval(secs => $1::float8)`
+	res, err := l.db.GetDB().Exec(ctx, query, l.history.MaxAge.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+func (l *LockBoxRepo) DeleteByID(ctx context.Context, id, userId int) error {
+	query := `UPDATE lockbox SET deleted_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	res, err := l.db.GetDB().Exec(ctx, query, id, userId)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrLockBoxNotFound
+	}
+	return nil
+}
+
+// Lookup находит запись по слепому индексу, а без него по имени, в том
+// числе удалённую в корзину.
+func (l *LockBoxRepo) Lookup(ctx context.Context, name, index string, userId int) (*models.Data, error) {
+	query := `SELECT ` + lockColumns + ` FROM lockbox
+              WHERE user_id = $1 AND CASE WHEN $3 <> '' THEN name_index = $3 ELSE name = $2 END`
+	return scanLock(l.db.GetDB().QueryRow(ctx, query, userId, name, index))
+}
+
+// UpdateIfRevision заменяет содержимое записи целиком, если её версия всё
+// ещё data.Revision, и возвращает запись из корзины. Иначе — ErrLockBoxConflict.
+// После замены в data.Revision — новая версия.
+func (l *LockBoxRepo) UpdateIfRevision(ctx context.Context, data *models.Data) error {
+	tx, err := l.db.GetDB().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if !data.NoHistory {
+		where := `id = $1 AND user_id = $2 AND revision = $3`
+		if err := l.saveRevision(ctx, tx, where, data.Id, data.UserID, data.Revision); err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE lockbox
+              SET name = $4, name_index = NULLIF($5, ''), url = $6, username = $7, password = $8,
+                  description = $9, updated_at = NOW(), deleted_at = NULL
+              WHERE id = $1 AND user_id = $2 AND revision = $3
+              RETURNING revision`
+	err = tx.QueryRow(ctx, query, data.Id, data.UserID, data.Revision, data.Name, data.NameIndex,
+		data.Url, data.Login, data.Password, data.Description).Scan(&data.Revision)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrLockBoxConflict
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrLockBoxExists
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteIfRevision удаляет запись в корзину, если её версия всё ещё revision.
+func (l *LockBoxRepo) DeleteIfRevision(ctx context.Context, id, revision, userId int) error {
+	query := `UPDATE lockbox SET deleted_at = NOW()
+              WHERE id = $1 AND user_id = $2 AND revision = $3 AND deleted_at IS NULL`
+	res, err := l.db.GetDB().Exec(ctx, query, id, userId, revision)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrLockBoxConflict
+	}
+	return nil
+}
+
+// changeColumns — запись в виде изменения ленты синхронизации.
+const changeColumns = `change_seq, deleted_at IS NOT NULL, id, name, COALESCE(name_index, ''), COALESCE(url, ''),
+                       COALESCE(username, ''), COALESCE(password, ''), COALESCE(description, ''), created_at, updated_at, deleted_at,
+                       revision`
+
+func scanChange(row pgx.Row) (*models.Change, error) {
+	var c models.Change
+	err := row.Scan(&c.Seq, &c.Deleted, &c.Data.Id, &c.Data.Name, &c.Data.NameIndex, &c.Data.Url, &c.Data.Login,
+		&c.Data.Password, &c.Data.Description, &c.Data.CreatedAt, &c.Data.UpdatedAt, &c.Data.DeletedAt, &c.Data.Revision)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Changes возвращает до limit изменений записей пользователя с номером больше
+// since. Если надгробия после since уже удалены или курсор получен не от
+// этого сервера, возвращаются все записи с Reset — тоже страницами по
+// limit, по возрастанию id начиная после after. Чтение идёт в одном
+// снимке, чтобы курсор соответствовал отданным изменениям.
+func (l *LockBoxRepo) Changes(ctx context.Context, userId int, since int64, after int, limit int) (*models.ChangeSet, error) {
+	tx, err := l.db.GetDB().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var seq, prunedSeq int64
+	err = tx.QueryRow(ctx, `SELECT seq, pruned_seq FROM lockbox_sync_state WHERE user_id = $1`, userId).Scan(&seq, &prunedSeq)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	set := &models.ChangeSet{Cursor: seq, Changes: []models.Change{}}
+
+	var rows pgx.Rows
+	if since < prunedSeq || since > seq {
+		set.Reset = true
+		rows, err = tx.Query(ctx, `SELECT `+changeColumns+` FROM lockbox
+                                   WHERE user_id = $1 AND deleted_at IS NULL AND id > $2
+                                   ORDER BY id
+                                   LIMIT $3`, userId, after, limit+1)
+	} else {
+		rows, err = tx.Query(ctx, `SELECT `+changeColumns+` FROM lockbox
+                                   WHERE user_id = $1 AND change_seq > $2
+                                   UNION ALL
+                                   SELECT change_seq, TRUE, lockbox_id, name, COALESCE(name_index, ''), '', '', '', '',
+                                          deleted_at, deleted_at, deleted_at, 0
+                                   FROM lockbox_tombstones
+                                   WHERE user_id = $1 AND change_seq > $2
+                                   ORDER BY change_seq
+                                   LIMIT $3`, userId, since, limit+1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		change, err := scanChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		set.Changes = append(set.Changes, *change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(set.Changes) > limit {
+		set.Changes = set.Changes[:limit]
+		set.More = true
+		if set.Reset {
+			set.After = set.Changes[limit-1].Data.Id
+		} else {
+			set.Cursor = set.Changes[limit-1].Seq
+		}
+	}
+	return set, nil
+}
+
+// PurgeTombstones удаляет надгробия старше срока хранения корзины и
+// запоминает наибольший удалённый номер: клиенты, отставшие дальше него,
+// получат полную синхронизацию.
+func (l *LockBoxRepo) PurgeTombstones(ctx context.Context) (int64, error) {
+	if l.trash.Retention <= 0 {
+		return 0, nil
+	}
+	query := `WITH pruned AS (
+                  DELETE FROM lockbox_tombstones
+                  WHERE deleted_at < NOW() - make_interval(secs => $1::float8)
+                  RETURNING user_id, change_seq
+              ), latest AS (
+                  SELECT user_id, MAX(change_seq) AS change_seq FROM pruned GROUP BY user_id
+              )
+              UPDATE lockbox_sync_state s
+              SET pruned_seq = GREATEST(s.pruned_seq, latest.change_seq)
+              FROM latest
+              WHERE s.user_id = latest.user_id`
+	res, err := l.db.GetDB().Exec(ctx, query, l.trash.Retention.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+// GetIdempotencyKey возвращает версию, которую получила запись, когда
+// изменение с ключом key было применено; ok = false, если не применялось.
+func (l *LockBoxRepo) GetIdempotencyKey(ctx context.Context, userId int, key string) (int, bool, error) {
+	var revision int
+	err := l.db.GetDB().QueryRow(ctx, `SELECT revision FROM idempotency_keys WHERE user_id = $1 AND key = $2`,
+		userId, key).Scan(&revision)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return revision, true, nil
+}
+
+// SaveIdempotencyKey запоминает результат применённого изменения.
+func (l *LockBoxRepo) SaveIdempotencyKey(ctx context.Context, userId int, key string, revision int) error {
+	query := `INSERT INTO idempotency_keys (user_id, key, revision) VALUES ($1, $2, $3)
+              ON CONFLICT (user_id, key) DO NOTHING`
+	_, err := l.db.GetDB().Exec(ctx, query, userId, key, revision)
+	return err
+}
 
-	res, err := l.db.GetDB().Exec(ctx, query)
+// PurgeIdempotencyKeys удаляет ключи старше models.IdempotencyKeyTTL.
+func (l *LockBoxRepo) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE created_at < NOW() - make_interval(secs => $1::float8)`
+	res, err := l.db.GetDB().Exec(ctx, query, models.IdempotencyKeyTTL.Seconds())
 	if err != nil {
 		return 0, err
 	}
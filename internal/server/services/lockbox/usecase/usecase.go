@@ -2,9 +2,13 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"gophKeeper/internal/server/domain"
+	auditmodels "gophKeeper/internal/server/services/audit/models"
+	audit "gophKeeper/internal/server/services/audit/usecase"
 	"gophKeeper/internal/server/services/lockbox/models"
 	"gophKeeper/internal/server/services/lockbox/repository"
+	"strconv"
 )
 
 type ILockBoxUsecase interface {
@@ -15,37 +19,90 @@ type ILockBoxUsecase interface {
 	GetAllLocks(ctx context.Context, userId int) (*[]models.Data, error)
 	ExistsLock(ctx context.Context, name string, userId int) (bool, error)
 	CreateOrUpdateLock(ctx context.Context, data *models.Data) (int, error)
+	GetLockByID(ctx context.Context, id, userId int) (*models.Data, error)
+	GetLockByIndex(ctx context.Context, index string, userId int) (*models.Data, error)
+	UpdateLockByID(ctx context.Context, data *models.Data) error
+	DeleteLockByID(ctx context.Context, id, userId int) error
+	UpdateLockIfRevision(ctx context.Context, data *models.Data) error
+	DeleteLockIfRevision(ctx context.Context, name string, id, revision, userId int) error
+	GetHistory(ctx context.Context, id, userId int) ([]models.Revision, error)
+	RestoreRevision(ctx context.Context, id, revisionId, userId int) error
+	GetTrash(ctx context.Context, userId int) (*[]models.Data, error)
+	RestoreFromTrash(ctx context.Context, id, userId int) error
+	EmptyTrash(ctx context.Context, userId int) (int64, error)
+	GetChanges(ctx context.Context, userId int, since int64, after int, limit int) (*models.ChangeSet, error)
+	PushChanges(ctx context.Context, userId int, changes []models.Change) (*models.PushResult, error)
 }
 
 type LockBoxUsecase struct {
-	repo repository.ILockBoxRepo
+	repo  repository.ILockBoxRepo
+	audit audit.Recorder
 }
 
-func NewLockBoxUsecase(repo repository.ILockBoxRepo) ILockBoxUsecase {
-	return &LockBoxUsecase{repo: repo}
+func NewLockBoxUsecase(repo repository.ILockBoxRepo, recorder audit.Recorder) ILockBoxUsecase {
+	return &LockBoxUsecase{repo: repo, audit: recorder}
 }
 
+// record пишет обращение к записи в журнал аудита. В журнал попадает только
+// id записи, имя и содержимое туда не пишутся.
+func (u *LockBoxUsecase) record(ctx context.Context, action string, userId, id int, err error) {
+	event := auditmodels.Event{UserId: userId, Action: action, Success: err == nil}
+	if id > 0 {
+		event.Target = strconv.Itoa(id)
+	}
+	u.audit.Record(ctx, event)
+}
+
+// lockID находит id записи по имени, чтобы не писать имя в журнал.
+func (u *LockBoxUsecase) lockID(ctx context.Context, name string, userId int) int {
+	lock, err := u.repo.Get(ctx, name, userId)
+	if err != nil {
+		return 0
+	}
+	return lock.Id
+}
+
+// UpdateLock меняет запись по имени, если data.Revision — её текущая версия.
 func (u *LockBoxUsecase) UpdateLock(ctx context.Context, data *models.Data) error {
-	return u.repo.Update(ctx, data)
+	err := u.repo.Update(ctx, data)
+	u.record(ctx, auditmodels.ActionLockBoxUpdate, data.UserID, u.lockID(ctx, data.Name, data.UserID), err)
+	return err
 }
 func (u *LockBoxUsecase) DeleteLock(ctx context.Context, name string, userId int) error {
 	if name == "" {
 		return domain.ErrNameEmpty
 	}
-	return u.repo.Delete(ctx, name, userId)
+	id := u.lockID(ctx, name, userId)
+	err := u.repo.Delete(ctx, name, userId)
+	u.record(ctx, auditmodels.ActionLockBoxDelete, userId, id, err)
+	return err
 }
 func (u *LockBoxUsecase) GetLockByName(ctx context.Context, name string, userId int) (*models.Data, error) {
-	return u.repo.Get(ctx, name, userId)
+	lock, err := u.repo.Get(ctx, name, userId)
+	u.recordRead(ctx, userId, 0, lock, err)
+	return lock, err
 }
+
+// recordRead пишет чтение записи; при ошибке id берётся из запроса, если он известен.
+func (u *LockBoxUsecase) recordRead(ctx context.Context, userId, id int, lock *models.Data, err error) {
+	if err == nil && lock != nil {
+		id = lock.Id
+	}
+	u.record(ctx, auditmodels.ActionLockBoxRead, userId, id, err)
+}
+
 func (u *LockBoxUsecase) CreateLock(ctx context.Context, data *models.Data) (int, error) {
 
 	if data.Name == "" && (data.UserID == 0 || (data.Login == "" && data.Url == "" && data.Description == "" && data.Password == "")) {
 		return 0, domain.ErrNoDataToCreate
 	}
-	return u.repo.Create(ctx, data)
+	id, err := u.repo.Create(ctx, data)
+	u.record(ctx, auditmodels.ActionLockBoxCreate, data.UserID, id, err)
+	return id, err
 }
 func (u *LockBoxUsecase) GetAllLocks(ctx context.Context, userId int) (*[]models.Data, error) {
 	locks, err := u.repo.GetAll(ctx, userId)
+	u.record(ctx, auditmodels.ActionLockBoxList, userId, 0, err)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +119,21 @@ func (u *LockBoxUsecase) ExistsLock(ctx context.Context, name string, userId int
 	}
 	return u.repo.Exists(ctx, name, userId)
 }
+
+// CreateOrUpdateLock сохраняет запись, присланную синхронизацией. Версии
+// при этом не сохраняются, см. models.Data.NoHistory. Существующая запись
+// меняется, только если data.Revision — её текущая версия, иначе
+// ErrLockBoxConflict: вслепую запись не перезаписывается.
 func (u *LockBoxUsecase) CreateOrUpdateLock(ctx context.Context, data *models.Data) (int, error) {
+	data.NoHistory = true
+	return u.upsertLock(ctx, data)
+}
+
+// upsertLock создаёт запись или обновляет существующую с тем же именем.
+func (u *LockBoxUsecase) upsertLock(ctx context.Context, data *models.Data) (int, error) {
+	if data.NameIndex != "" {
+		return u.createOrUpdateLockByIndex(ctx, data)
+	}
 
 	exists, err := u.ExistsLock(ctx, data.Name, data.UserID)
 	if err != nil {
@@ -72,13 +143,294 @@ func (u *LockBoxUsecase) CreateOrUpdateLock(ctx context.Context, data *models.Da
 		return u.CreateLock(ctx, data)
 	}
 
-	if err := u.UpdateLock(ctx, data); err != nil {
+	if err := u.repo.Update(ctx, data); err != nil {
+		u.record(ctx, auditmodels.ActionLockBoxUpdate, data.UserID, 0, err)
 		return 0, err
 	}
 
-	updated, err := u.GetLockByName(ctx, data.Name, data.UserID)
+	updated, err := u.repo.Get(ctx, data.Name, data.UserID)
 	if err != nil {
 		return 0, err
 	}
+	u.record(ctx, auditmodels.ActionLockBoxUpdate, data.UserID, updated.Id, nil)
 	return updated.Id, nil
 }
+
+// createOrUpdateLockByIndex — вариант upsertLock для записей с
+// зашифрованным именем: сравнивать зашифрованные имена бессмысленно.
+func (u *LockBoxUsecase) createOrUpdateLockByIndex(ctx context.Context, data *models.Data) (int, error) {
+	existing, err := u.repo.GetByIndex(ctx, data.NameIndex, data.UserID)
+	if errors.Is(err, domain.ErrLockBoxNotFound) {
+		return u.CreateLock(ctx, data)
+	}
+	if err != nil {
+		return 0, err
+	}
+	data.Id = existing.Id
+	err = u.repo.UpdateByID(ctx, data)
+	u.record(ctx, auditmodels.ActionLockBoxUpdate, data.UserID, existing.Id, err)
+	if err != nil {
+		return 0, err
+	}
+	return existing.Id, nil
+}
+
+func (u *LockBoxUsecase) GetLockByID(ctx context.Context, id, userId int) (*models.Data, error) {
+	lock, err := u.repo.GetByID(ctx, id, userId)
+	u.recordRead(ctx, userId, id, lock, err)
+	return lock, err
+}
+
+func (u *LockBoxUsecase) GetLockByIndex(ctx context.Context, index string, userId int) (*models.Data, error) {
+	if index == "" {
+		return nil, domain.ErrNameEmpty
+	}
+	lock, err := u.repo.GetByIndex(ctx, index, userId)
+	u.recordRead(ctx, userId, 0, lock, err)
+	return lock, err
+}
+
+// UpdateLockByID меняет запись по id, если data.Revision — её текущая версия.
+func (u *LockBoxUsecase) UpdateLockByID(ctx context.Context, data *models.Data) error {
+	err := u.repo.UpdateByID(ctx, data)
+	u.record(ctx, auditmodels.ActionLockBoxUpdate, data.UserID, data.Id, err)
+	return err
+}
+
+func (u *LockBoxUsecase) DeleteLockByID(ctx context.Context, id, userId int) error {
+	err := u.repo.DeleteByID(ctx, id, userId)
+	u.record(ctx, auditmodels.ActionLockBoxDelete, userId, id, err)
+	return err
+}
+
+// UpdateLockIfRevision меняет запись с именем (или слепым индексом) из
+// data, только если это запись data.Id версии data.Revision — условие
+// If-Match. Иначе — ErrLockBoxConflict. Нулевые Id и Revision — условие «*»:
+// подходит любая существующая запись. Новая версия возвращается в data.Revision.
+func (u *LockBoxUsecase) UpdateLockIfRevision(ctx context.Context, data *models.Data) error {
+	lock, err := u.repo.Lookup(ctx, data.Name, data.NameIndex, data.UserID)
+	switch {
+	case err != nil:
+	case lock.DeletedAt != nil:
+		err = domain.ErrLockBoxNotFound
+	case data.Id != 0 && lock.Id != data.Id:
+		err = domain.ErrLockBoxConflict
+	default:
+		// «*» подходит к любой версии: клиент явно согласен перезаписать запись.
+		if data.Id == 0 {
+			data.Revision = lock.Revision
+		}
+		data.Id = lock.Id
+		err = u.repo.UpdateByID(ctx, data)
+	}
+	u.record(ctx, auditmodels.ActionLockBoxUpdate, data.UserID, data.Id, err)
+	return err
+}
+
+// DeleteLockIfRevision удаляет в корзину запись id, только если у неё версия
+// revision, а непустое name — её имя. Иначе — ErrLockBoxConflict. Нулевые id
+// и revision при непустом name — условие «*».
+func (u *LockBoxUsecase) DeleteLockIfRevision(ctx context.Context, name string, id, revision, userId int) error {
+	var err error
+	if name != "" {
+		var lock *models.Data
+		lock, err = u.repo.Lookup(ctx, name, "", userId)
+		switch {
+		case err != nil:
+		case lock.DeletedAt != nil:
+			err = domain.ErrLockBoxNotFound
+		case id != 0 && lock.Id != id:
+			err = domain.ErrLockBoxConflict
+		default:
+			id = lock.Id
+		}
+	}
+	if err == nil {
+		if revision == 0 {
+			err = u.repo.DeleteByID(ctx, id, userId)
+		} else {
+			err = u.repo.DeleteIfRevision(ctx, id, revision, userId)
+		}
+	}
+	u.record(ctx, auditmodels.ActionLockBoxDelete, userId, id, err)
+	return err
+}
+
+// GetHistory возвращает прежние версии записи, новые первыми.
+func (u *LockBoxUsecase) GetHistory(ctx context.Context, id, userId int) ([]models.Revision, error) {
+	if _, err := u.repo.GetByID(ctx, id, userId); err != nil {
+		return nil, err
+	}
+	revisions, err := u.repo.History(ctx, id, userId)
+	u.record(ctx, auditmodels.ActionLockBoxHistory, userId, id, err)
+	return revisions, err
+}
+
+// RestoreRevision возвращает записи содержимое одной из прежних версий.
+func (u *LockBoxUsecase) RestoreRevision(ctx context.Context, id, revisionId, userId int) error {
+	err := u.repo.Restore(ctx, id, revisionId, userId)
+	u.record(ctx, auditmodels.ActionLockBoxRestore, userId, id, err)
+	return err
+}
+
+// GetTrash возвращает удалённые записи, которые ещё можно восстановить.
+func (u *LockBoxUsecase) GetTrash(ctx context.Context, userId int) (*[]models.Data, error) {
+	locks, err := u.repo.Trash(ctx, userId)
+	u.record(ctx, auditmodels.ActionLockBoxTrash, userId, 0, err)
+	return locks, err
+}
+
+// RestoreFromTrash возвращает удалённую запись в хранилище.
+func (u *LockBoxUsecase) RestoreFromTrash(ctx context.Context, id, userId int) error {
+	err := u.repo.Undelete(ctx, id, userId)
+	u.record(ctx, auditmodels.ActionLockBoxUndelete, userId, id, err)
+	return err
+}
+
+// EmptyTrash окончательно удаляет записи из корзины и возвращает их число.
+func (u *LockBoxUsecase) EmptyTrash(ctx context.Context, userId int) (int64, error) {
+	purged, err := u.repo.EmptyTrash(ctx, userId)
+	u.record(ctx, auditmodels.ActionLockBoxPurge, userId, 0, err)
+	return purged, err
+}
+
+// GetChanges возвращает страницу ленты изменений после курсора since, а при
+// полной синхронизации — страницу списка записей после id after.
+// Опрос без изменений в журнал аудита не пишется: клиенты опрашивают ленту
+// постоянно.
+func (u *LockBoxUsecase) GetChanges(ctx context.Context, userId int, since int64, after int, limit int) (*models.ChangeSet, error) {
+	if since < 0 || after < 0 {
+		return nil, domain.ErrInvalidCursor
+	}
+	if limit <= 0 {
+		limit = models.DefaultChangesPage
+	}
+	if limit > models.MaxChangesPage {
+		limit = models.MaxChangesPage
+	}
+	set, err := u.repo.Changes(ctx, userId, since, after, limit)
+	if err != nil || len(set.Changes) > 0 {
+		u.record(ctx, auditmodels.ActionLockBoxList, userId, 0, err)
+	}
+	return set, err
+}
+
+// PushChanges применяет пакет изменений, сделанных клиентом без связи или
+// между синхронизациями. Каждое изменение несёт версию, от которой клиент
+// начинал правку: если запись с тех пор изменили с другого устройства,
+// изменение не применяется и попадает в Conflicts вместе с текущей версией.
+// Конфликты не прерывают пакет, ошибка прерывает. Повторять пакет безопасно:
+// изменение с уже применённым ключом идемпотентности возвращает прежнюю
+// версию, а не применяется заново; конфликт ключ не запоминает.
+// Клиент шлёт только изменённые записи, поэтому их прежние версии
+// сохраняются в истории.
+func (u *LockBoxUsecase) PushChanges(ctx context.Context, userId int, changes []models.Change) (*models.PushResult, error) {
+	result := &models.PushResult{Revisions: make([]int, len(changes))}
+	if len(changes) > models.MaxPushBatch {
+		return result, domain.ErrSyncBatchTooLarge
+	}
+	for _, change := range changes {
+		if len(change.IdempotencyKey) > models.MaxIdempotencyKey {
+			return result, domain.ErrInvalidIdempotencyKey
+		}
+	}
+	for i, change := range changes {
+		// Повтор уже применённого изменения: клиент не получил ответ.
+		if change.IdempotencyKey != "" {
+			revision, ok, err := u.repo.GetIdempotencyKey(ctx, userId, change.IdempotencyKey)
+			if err != nil {
+				return result, err
+			}
+			if ok {
+				result.Revisions[i] = revision
+				result.Applied++
+				continue
+			}
+		}
+
+		data := change.Data
+		data.UserID = userId
+		var err error
+		if change.Deleted {
+			err = u.deletePushed(ctx, &data)
+		} else {
+			err = u.putPushed(ctx, &data)
+		}
+		if errors.Is(err, domain.ErrLockBoxConflict) {
+			result.Conflicts = append(result.Conflicts, u.conflict(ctx, i, &change.Data, userId))
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		if !change.Deleted {
+			result.Revisions[i] = data.Revision
+		}
+		result.Applied++
+
+		if change.IdempotencyKey != "" {
+			if err := u.repo.SaveIdempotencyKey(ctx, userId, change.IdempotencyKey, result.Revisions[i]); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// putPushed сохраняет присланную запись, если она не менялась после версии,
+// от которой начиналась правка. Версия 0 — запись новая: совпадение с
+// существующей записью тоже конфликт.
+func (u *LockBoxUsecase) putPushed(ctx context.Context, data *models.Data) error {
+	existing, err := u.repo.Lookup(ctx, data.Name, data.NameIndex, data.UserID)
+	if errors.Is(err, domain.ErrLockBoxNotFound) {
+		// Правка записи, которую окончательно удалили с другого устройства.
+		if data.Revision > 0 {
+			return domain.ErrLockBoxConflict
+		}
+		_, err = u.CreateLock(ctx, data)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Revision != data.Revision {
+		u.record(ctx, auditmodels.ActionLockBoxUpdate, data.UserID, existing.Id, domain.ErrLockBoxConflict)
+		return domain.ErrLockBoxConflict
+	}
+	data.Id = existing.Id
+	err = u.repo.UpdateIfRevision(ctx, data)
+	u.record(ctx, auditmodels.ActionLockBoxUpdate, data.UserID, existing.Id, err)
+	return err
+}
+
+// deletePushed удаляет запись по имени или слепому индексу, если она не
+// менялась после версии, которую видел клиент. Записи, которой на сервере
+// нет или которая уже в корзине, удалять нечего.
+func (u *LockBoxUsecase) deletePushed(ctx context.Context, data *models.Data) error {
+	existing, err := u.repo.Lookup(ctx, data.Name, data.NameIndex, data.UserID)
+	if errors.Is(err, domain.ErrLockBoxNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.DeletedAt != nil {
+		return nil
+	}
+	err = domain.ErrLockBoxConflict
+	if existing.Revision == data.Revision {
+		err = u.repo.DeleteIfRevision(ctx, existing.Id, data.Revision, data.UserID)
+	}
+	u.record(ctx, auditmodels.ActionLockBoxDelete, data.UserID, existing.Id, err)
+	return err
+}
+
+// conflict описывает отклонённое изменение текущей версией записи. От
+// окончательно удалённой записи остаются только имя и индекс из изменения.
+func (u *LockBoxUsecase) conflict(ctx context.Context, index int, sent *models.Data, userId int) models.Conflict {
+	current, err := u.repo.Lookup(ctx, sent.Name, sent.NameIndex, userId)
+	if err != nil {
+		return models.Conflict{Index: index, Deleted: true, Data: models.Data{Name: sent.Name, NameIndex: sent.NameIndex}}
+	}
+	return models.Conflict{Index: index, Deleted: current.DeletedAt != nil, Data: *current}
+}
@@ -54,3 +54,86 @@ func (u *LockBoxUsecaseMock) CreateOrUpdateLock(ctx context.Context, data *model
 	args := u.Called(ctx, data)
 	return args.Int(0), args.Error(1)
 }
+
+func (u *LockBoxUsecaseMock) GetLockByID(ctx context.Context, id, userId int) (*models.Data, error) {
+	args := u.Called(ctx, id, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Data), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *LockBoxUsecaseMock) GetLockByIndex(ctx context.Context, index string, userId int) (*models.Data, error) {
+	args := u.Called(ctx, index, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Data), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *LockBoxUsecaseMock) UpdateLockByID(ctx context.Context, data *models.Data) error {
+	args := u.Called(ctx, data)
+	return args.Error(0)
+}
+
+func (u *LockBoxUsecaseMock) DeleteLockByID(ctx context.Context, id, userId int) error {
+	args := u.Called(ctx, id, userId)
+	return args.Error(0)
+}
+
+func (u *LockBoxUsecaseMock) UpdateLockIfRevision(ctx context.Context, data *models.Data) error {
+	args := u.Called(ctx, data)
+	return args.Error(0)
+}
+
+func (u *LockBoxUsecaseMock) DeleteLockIfRevision(ctx context.Context, name string, id, revision, userId int) error {
+	args := u.Called(ctx, name, id, revision, userId)
+	return args.Error(0)
+}
+
+func (u *LockBoxUsecaseMock) GetHistory(ctx context.Context, id, userId int) ([]models.Revision, error) {
+	args := u.Called(ctx, id, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Revision), args.Error(1)
+}
+
+func (u *LockBoxUsecaseMock) RestoreRevision(ctx context.Context, id, revisionId, userId int) error {
+	args := u.Called(ctx, id, revisionId, userId)
+	return args.Error(0)
+}
+
+func (u *LockBoxUsecaseMock) GetTrash(ctx context.Context, userId int) (*[]models.Data, error) {
+	args := u.Called(ctx, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*[]models.Data), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *LockBoxUsecaseMock) RestoreFromTrash(ctx context.Context, id, userId int) error {
+	args := u.Called(ctx, id, userId)
+	return args.Error(0)
+}
+
+func (u *LockBoxUsecaseMock) EmptyTrash(ctx context.Context, userId int) (int64, error) {
+	args := u.Called(ctx, userId)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (u *LockBoxUsecaseMock) GetChanges(ctx context.Context, userId int, since int64, after int, limit int) (*models.ChangeSet, error) {
+	args := u.Called(ctx, userId, since, after, limit)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.ChangeSet), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *LockBoxUsecaseMock) PushChanges(ctx context.Context, userId int, changes []models.Change) (*models.PushResult, error) {
+	args := u.Called(ctx, userId, changes)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.PushResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
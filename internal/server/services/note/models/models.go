@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Note — произвольная текстовая заметка. Body приходит с клиента уже зашифрованным.
+type Note struct {
+	Id        int        `json:"id"`
+	Name      string     `json:"name"`
+	Body      string     `json:"body"`
+	UserID    int        `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
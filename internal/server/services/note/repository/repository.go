@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"gophKeeper/internal/server/db"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/note/models"
+)
+
+type INoteRepo interface {
+	Create(ctx context.Context, note *models.Note) (int, error)
+	Get(ctx context.Context, name string, userId int) (*models.Note, error)
+	GetAll(ctx context.Context, userId int) (*[]models.Note, error)
+	Update(ctx context.Context, note *models.Note) error
+	Upsert(ctx context.Context, note *models.Note) (int, error)
+	Delete(ctx context.Context, name string, userId int) error
+}
+
+type NoteRepo struct {
+	db db.IDatabase
+}
+
+func NewNoteRepo(db db.IDatabase) INoteRepo {
+	return &NoteRepo{
+		db: db,
+	}
+}
+
+// Create добавляет заметку. Ранее удалённая заметка с тем же именем восстанавливается.
+func (r *NoteRepo) Create(ctx context.Context, note *models.Note) (int, error) {
+	query := `INSERT INTO note (name, body, user_id)
+              VALUES ($1, $2, $3)
+              ON CONFLICT (name, user_id) DO UPDATE
+                  SET body = EXCLUDED.body, deleted_at = NULL, updated_at = NOW()
+                  WHERE note.deleted_at IS NOT NULL
+              RETURNING id`
+
+	var id int
+	err := r.db.GetDB().QueryRow(ctx, query, note.Name, note.Body, note.UserID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrNoteExists
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *NoteRepo) Get(ctx context.Context, name string, userId int) (*models.Note, error) {
+	query := `SELECT id, body, created_at, updated_at, deleted_at
+              FROM note
+              WHERE user_id = $1 AND name = $2 AND deleted_at IS NULL`
+	var note models.Note
+
+	err := r.db.GetDB().QueryRow(ctx, query, userId, name).Scan(&note.Id, &note.Body, &note.CreatedAt, &note.UpdatedAt, &note.DeletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNoteNotFound
+		}
+		return nil, err
+	}
+	note.Name = name
+	note.UserID = userId
+	return &note, nil
+}
+
+func (r *NoteRepo) GetAll(ctx context.Context, userId int) (*[]models.Note, error) {
+	query := `SELECT id, name, body, created_at, updated_at, deleted_at
+              FROM note
+              WHERE user_id = $1 AND deleted_at IS NULL`
+	rows, err := r.db.GetDB().Query(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []models.Note{}
+	for rows.Next() {
+		var note models.Note
+		if err := rows.Scan(&note.Id, &note.Name, &note.Body, &note.CreatedAt, &note.UpdatedAt, &note.DeletedAt); err != nil {
+			return nil, err
+		}
+		note.UserID = userId
+		notes = append(notes, note)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return &notes, nil
+}
+
+func (r *NoteRepo) Update(ctx context.Context, note *models.Note) error {
+	query := `UPDATE note
+              SET body = $3, updated_at = NOW()
+              WHERE name = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	res, err := r.db.GetDB().Exec(ctx, query, note.Name, note.UserID, note.Body)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrNoteNotFound
+	}
+	return nil
+}
+
+// Upsert используется синхронизацией: создаёт заметку или перезаписывает существующую.
+func (r *NoteRepo) Upsert(ctx context.Context, note *models.Note) (int, error) {
+	query := `INSERT INTO note (name, body, user_id)
+              VALUES ($1, $2, $3)
+              ON CONFLICT (name, user_id) DO UPDATE
+                  SET body = EXCLUDED.body, deleted_at = NULL, updated_at = NOW()
+              RETURNING id`
+
+	var id int
+	err := r.db.GetDB().QueryRow(ctx, query, note.Name, note.Body, note.UserID).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *NoteRepo) Delete(ctx context.Context, name string, userId int) error {
+	query := `UPDATE note
+              SET deleted_at = NOW()
+              WHERE name = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	res, err := r.db.GetDB().Exec(ctx, query, name, userId)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrNoteNotFound
+	}
+	return nil
+}
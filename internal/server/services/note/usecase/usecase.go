@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/note/models"
+	"gophKeeper/internal/server/services/note/repository"
+)
+
+type INoteUsecase interface {
+	CreateNote(ctx context.Context, note *models.Note) (int, error)
+	GetNoteByName(ctx context.Context, name string, userId int) (*models.Note, error)
+	GetAllNotes(ctx context.Context, userId int) (*[]models.Note, error)
+	UpdateNote(ctx context.Context, note *models.Note) error
+	CreateOrUpdateNote(ctx context.Context, note *models.Note) (int, error)
+	DeleteNote(ctx context.Context, name string, userId int) error
+}
+
+type NoteUsecase struct {
+	repo repository.INoteRepo
+}
+
+func NewNoteUsecase(repo repository.INoteRepo) INoteUsecase {
+	return &NoteUsecase{repo: repo}
+}
+
+func (u *NoteUsecase) CreateNote(ctx context.Context, note *models.Note) (int, error) {
+	if note.Name == "" {
+		return 0, domain.ErrNameEmpty
+	}
+	if note.UserID == 0 || note.Body == "" {
+		return 0, domain.ErrNoDataToCreate
+	}
+	return u.repo.Create(ctx, note)
+}
+
+func (u *NoteUsecase) GetNoteByName(ctx context.Context, name string, userId int) (*models.Note, error) {
+	if name == "" {
+		return nil, domain.ErrNameEmpty
+	}
+	return u.repo.Get(ctx, name, userId)
+}
+
+func (u *NoteUsecase) GetAllNotes(ctx context.Context, userId int) (*[]models.Note, error) {
+	return u.repo.GetAll(ctx, userId)
+}
+
+func (u *NoteUsecase) UpdateNote(ctx context.Context, note *models.Note) error {
+	if note.Name == "" {
+		return domain.ErrNameEmpty
+	}
+	if note.Body == "" {
+		return domain.ErrNoDataToCreate
+	}
+	return u.repo.Update(ctx, note)
+}
+
+func (u *NoteUsecase) CreateOrUpdateNote(ctx context.Context, note *models.Note) (int, error) {
+	if note.Name == "" {
+		return 0, domain.ErrNameEmpty
+	}
+	if note.Body == "" {
+		return 0, domain.ErrNoDataToCreate
+	}
+	return u.repo.Upsert(ctx, note)
+}
+
+func (u *NoteUsecase) DeleteNote(ctx context.Context, name string, userId int) error {
+	if name == "" {
+		return domain.ErrNameEmpty
+	}
+	return u.repo.Delete(ctx, name, userId)
+}
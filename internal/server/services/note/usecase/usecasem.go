@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/services/note/models"
+)
+
+type NoteUsecaseMock struct {
+	mock.Mock
+}
+
+func NewNoteUsecaseMock() *NoteUsecaseMock {
+	return &NoteUsecaseMock{}
+}
+
+func (u *NoteUsecaseMock) CreateNote(ctx context.Context, note *models.Note) (int, error) {
+	args := u.Called(ctx, note)
+	return args.Int(0), args.Error(1)
+}
+
+func (u *NoteUsecaseMock) GetNoteByName(ctx context.Context, name string, userId int) (*models.Note, error) {
+	args := u.Called(ctx, name, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Note), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *NoteUsecaseMock) GetAllNotes(ctx context.Context, userId int) (*[]models.Note, error) {
+	args := u.Called(ctx, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*[]models.Note), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (u *NoteUsecaseMock) UpdateNote(ctx context.Context, note *models.Note) error {
+	args := u.Called(ctx, note)
+	return args.Error(0)
+}
+
+func (u *NoteUsecaseMock) CreateOrUpdateNote(ctx context.Context, note *models.Note) (int, error) {
+	args := u.Called(ctx, note)
+	return args.Int(0), args.Error(1)
+}
+
+func (u *NoteUsecaseMock) DeleteNote(ctx context.Context, name string, userId int) error {
+	args := u.Called(ctx, name, userId)
+	return args.Error(0)
+}
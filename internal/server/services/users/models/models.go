@@ -3,9 +3,58 @@ package models
 import "time"
 
 type User struct {
-	UserId    int       `json:"user_id"`
-	Username  string    `json:"username"`
-	Password  string    `json:"password,omitempty"`
-	UserType  string    `json:"user_type"` // тип user_type ('admin', attendee');
-	CreatedAt time.Time `json:"-"`
+	UserId   int    `json:"user_id"`
+	Username string `json:"username"`
+	UserType string `json:"user_type"` // тип user_type ('admin', attendee');
+	// SRPSalt и SRPVerifier заменяют пароль: сервер пароль не получает.
+	SRPSalt     string    `json:"srp_salt,omitempty"`
+	SRPVerifier string    `json:"srp_verifier,omitempty"`
+	CreatedAt   time.Time `json:"-"`
+}
+
+// KDFParams — параметры Argon2id, из которых клиент выводит ключ шифрования
+// хранилища. Сервер хранит их, но сам ключ и мастер-пароль никогда не получает.
+// Check — зашифрованная выведенным ключом контрольная строка для проверки мастер-пароля.
+type KDFParams struct {
+	Salt    string `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	Check   string `json:"check"`
+}
+
+// Valid отклоняет пустые и заведомо слабые параметры Argon2id.
+func (p *KDFParams) Valid() bool {
+	return p.Salt != "" && p.Check != "" && p.Time >= 1 && p.Memory >= 19*1024 && p.Threads >= 1
+}
+
+// PasswordProof подтверждает, что запрос отправил владелец аккаунта, а не
+// тот, кто завладел его токеном. Обычно это доказательство SRP: Session из
+// /api/auth/srp/init и M1, посчитанный по текущему паролю. Аккаунты, ещё не
+// перешедшие на SRP, передают сам пароль.
+type PasswordProof struct {
+	Session  string `json:"session"`
+	M1       string `json:"m1"`
+	Password string `json:"password"`
+}
+
+// PasswordChange заменяет пароль: сервер получает только новый верификатор SRP.
+type PasswordChange struct {
+	Proof       PasswordProof `json:"proof"`
+	SRPSalt     string        `json:"srp_salt"`
+	SRPVerifier string        `json:"srp_verifier"`
+}
+
+// UsernameChange переименовывает аккаунт. Верификатор SRP вычисляется из
+// имени, поэтому вместе с именем клиент присылает новый верификатор.
+type UsernameChange struct {
+	Proof       PasswordProof `json:"proof"`
+	Username    string        `json:"username"`
+	SRPSalt     string        `json:"srp_salt"`
+	SRPVerifier string        `json:"srp_verifier"`
+}
+
+// AccountDelete — запрос на удаление аккаунта со всеми данными.
+type AccountDelete struct {
+	Proof PasswordProof `json:"proof"`
 }
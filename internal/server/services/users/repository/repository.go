@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"golang.org/x/crypto/bcrypt"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gophKeeper/internal/server/db"
 	"gophKeeper/internal/server/domain"
 	"gophKeeper/internal/server/services/users/models"
@@ -13,8 +15,11 @@ type IUserRepository interface {
 	GetAll(ctx context.Context) ([]*models.User, error)
 	Create(ctx context.Context, user *models.User) error
 	GetByID(ctx context.Context, userId int) (*models.User, error)
-	Update(user *models.User) error
-	Delete(userId int) error
+	SetPassword(ctx context.Context, userId int, salt, verifier string) error
+	Rename(ctx context.Context, userId int, username, salt, verifier string) error
+	Delete(ctx context.Context, userId int) error
+	GetKDFParams(ctx context.Context, userId int) (*models.KDFParams, error)
+	SetKDFParams(ctx context.Context, userId int, params *models.KDFParams) error
 }
 
 type userRepository struct {
@@ -28,27 +33,20 @@ func NewUserRepository(db db.IDatabase) IUserRepository {
 }
 
 func (ur *userRepository) Create(ctx context.Context, user *models.User) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return err
-	}
-	user.Password = string(hashedPassword)
-
 	query := `
-        INSERT INTO users (username, password_hash, user_type)
-        VALUES ($1,  $2, $3) RETURNING user_id`
+        INSERT INTO users (username, srp_salt, srp_verifier, user_type)
+        VALUES ($1, $2, $3, $4) RETURNING user_id`
 
-	return ur.db.GetDB().QueryRow(ctx, query, user.Username, user.Password, user.UserType).Scan(&user.UserId)
+	return ur.db.GetDB().QueryRow(ctx, query, user.Username, user.SRPSalt, user.SRPVerifier, user.UserType).Scan(&user.UserId)
 }
 
 func (ur *userRepository) GetByID(ctx context.Context, userId int) (*models.User, error) {
 	var user models.User
-	query := `SELECT user_id, username, email, user_type FROM "users" WHERE user_id = $1`
-	err := ur.db.GetDB().QueryRow(ctx, query, userId).Scan(&user.UserId, &user.Username, &user.UserType)
+	query := `SELECT user_id, username, user_type, created_at FROM "users" WHERE user_id = $1`
+	err := ur.db.GetDB().QueryRow(ctx, query, userId).Scan(&user.UserId, &user.Username, &user.UserType, &user.CreatedAt)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			// Если нет строк, это означает, что логин и/или пароль неверны
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
@@ -57,14 +55,48 @@ func (ur *userRepository) GetByID(ctx context.Context, userId int) (*models.User
 	return &user, nil
 }
 
-func (ur *userRepository) Update(user *models.User) error {
-	//UPDATE users SET field1 = @f1, field2 = @f2
-	//WHERE Id = @Id
+// SetPassword сохраняет новый верификатор SRP. Хеш пароля, оставшийся от
+// входа до SRP, удаляется: иначе старый пароль продолжал бы действовать.
+func (ur *userRepository) SetPassword(ctx context.Context, userId int, salt, verifier string) error {
+	query := `UPDATE "users" SET srp_salt = $2, srp_verifier = $3, password_hash = NULL WHERE user_id = $1`
+	res, err := ur.db.GetDB().Exec(ctx, query, userId, salt, verifier)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
 	return nil
 }
 
-func (ur *userRepository) Delete(id int) error {
-	//DELETE FROM users WHERE Id = @Id
+// Rename меняет имя вместе с верификатором, который от имени зависит.
+func (ur *userRepository) Rename(ctx context.Context, userId int, username, salt, verifier string) error {
+	query := `UPDATE "users" SET username = $2, srp_salt = $3, srp_verifier = $4, password_hash = NULL
+              WHERE user_id = $1`
+	res, err := ur.db.GetDB().Exec(ctx, query, userId, username, salt, verifier)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrUsernameTaken
+		}
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// Delete удаляет пользователя; записи, сессии и метаданные файлов удаляются
+// каскадно внешними ключами.
+func (ur *userRepository) Delete(ctx context.Context, userId int) error {
+	res, err := ur.db.GetDB().Exec(ctx, `DELETE FROM "users" WHERE user_id = $1`, userId)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
 	return nil
 }
 
@@ -72,7 +104,7 @@ func (ur *userRepository) GetAll(ctx context.Context) ([]*models.User, error) {
 	var users []*models.User
 
 	query := `
-		SELECT user_id, username, password_hash, user_type, created_at
+		SELECT user_id, username, user_type, created_at
 		FROM "users"
 	`
 
@@ -87,7 +119,6 @@ func (ur *userRepository) GetAll(ctx context.Context) ([]*models.User, error) {
 		err := rows.Scan(
 			&user.UserId,
 			&user.Username,
-			&user.Password,
 			&user.UserType,
 			&user.CreatedAt,
 		)
@@ -103,3 +134,44 @@ func (ur *userRepository) GetAll(ctx context.Context) ([]*models.User, error) {
 
 	return users, nil
 }
+
+func (ur *userRepository) GetKDFParams(ctx context.Context, userId int) (*models.KDFParams, error) {
+	var params models.KDFParams
+	var salt, check sql.NullString
+	var timeCost, memory, threads sql.NullInt32
+
+	query := `SELECT kdf_salt, kdf_time, kdf_memory, kdf_threads, kdf_check FROM "users" WHERE user_id = $1`
+	err := ur.db.GetDB().QueryRow(ctx, query, userId).Scan(&salt, &timeCost, &memory, &threads, &check)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if !salt.Valid {
+		return nil, domain.ErrKDFNotSet
+	}
+
+	params.Salt = salt.String
+	params.Time = uint32(timeCost.Int32)
+	params.Memory = uint32(memory.Int32)
+	params.Threads = uint8(threads.Int32)
+	params.Check = check.String
+	return &params, nil
+}
+
+// SetKDFParams сохраняет параметры только один раз: их замена сделала бы
+// нерасшифровываемыми все уже сохранённые данные пользователя.
+func (ur *userRepository) SetKDFParams(ctx context.Context, userId int, params *models.KDFParams) error {
+	query := `UPDATE "users"
+              SET kdf_salt = $2, kdf_time = $3, kdf_memory = $4, kdf_threads = $5, kdf_check = $6
+              WHERE user_id = $1 AND kdf_salt IS NULL`
+	res, err := ur.db.GetDB().Exec(ctx, query, userId, params.Salt, params.Time, params.Memory, params.Threads, params.Check)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrKDFAlreadySet
+	}
+	return nil
+}
@@ -3,26 +3,45 @@ package usecase
 import (
 	"context"
 	"gophKeeper/internal/server/domain"
+	authmodels "gophKeeper/internal/server/services/auth/models"
+	"gophKeeper/internal/server/services/binary/storage"
 	"gophKeeper/internal/server/services/users/models"
 	"gophKeeper/internal/server/services/users/repository"
 	"gophKeeper/util"
+	"log/slog"
+	"strings"
 )
 
 type IUserUsecase interface {
 	GetUsers(ctx context.Context) ([]*models.User, error)
 	CreateUser(ctx context.Context, user *models.User) error
 	GetUserByID(ctx context.Context, id int) (*models.User, error)
-	UpdateUser(user *models.User) error
-	DeleteUser(id int) error
+	ChangePassword(ctx context.Context, userId, sessionId int, req *models.PasswordChange) error
+	RenameUser(ctx context.Context, userId, sessionId int, req *models.UsernameChange) error
+	DeleteUser(ctx context.Context, userId int, proof *models.PasswordProof) error
+	GetKDFParams(ctx context.Context, userId int) (*models.KDFParams, error)
+	SetKDFParams(ctx context.Context, userId int, params *models.KDFParams) error
+}
+
+// Authenticator проверяет пароль так же, как при входе, вместе с защитой от
+// перебора. Реализуется usecase авторизации.
+type Authenticator interface {
+	CheckUser(ctx context.Context, user *authmodels.AuthUser) (*authmodels.InfoUser, error)
+	FinishSRP(ctx context.Context, req *authmodels.SRPVerify) (*authmodels.InfoUser, string, error)
+	RevokeOtherSessions(ctx context.Context, userId, currentId int) (int, error)
 }
 
 type UserUsecase struct {
-	repo repository.IUserRepository
+	repo  repository.IUserRepository
+	auth  Authenticator
+	blobs storage.BlobStore
 }
 
-func NewUserUsecase(repo repository.IUserRepository) IUserUsecase {
+func NewUserUsecase(repo repository.IUserRepository, auth Authenticator, blobs storage.BlobStore) IUserUsecase {
 	return &UserUsecase{
-		repo: repo,
+		repo:  repo,
+		auth:  auth,
+		blobs: blobs,
 	}
 }
 
@@ -37,6 +56,11 @@ func (us *UserUsecase) CreateUser(ctx context.Context, user *models.User) error
 	if !util.IsValidUserTypeForRegistration(user.UserType) {
 		return domain.ErrInvalidUserType
 	}
+	// Новые аккаунты создаются только с верификатором SRP.
+	verifier := authmodels.SRPVerifier{Salt: user.SRPSalt, Verifier: user.SRPVerifier}
+	if !verifier.Valid() {
+		return domain.ErrInvalidInput
+	}
 	return us.repo.Create(ctx, user)
 }
 
@@ -44,10 +68,94 @@ func (us *UserUsecase) GetUserByID(ctx context.Context, id int) (*models.User, e
 	return us.repo.GetByID(ctx, id)
 }
 
-func (us *UserUsecase) UpdateUser(user *models.User) error {
-	return us.repo.Update(user)
+// verifyPassword проверяет, что доказательство пароля принадлежит userId.
+// Неверный пароль учитывается защитой от перебора как неудачный вход.
+func (us *UserUsecase) verifyPassword(ctx context.Context, userId int, proof *models.PasswordProof) error {
+	var info *authmodels.InfoUser
+	var err error
+	switch {
+	case proof.Session != "":
+		info, _, err = us.auth.FinishSRP(ctx, &authmodels.SRPVerify{Session: proof.Session, M1: proof.M1})
+	case proof.Password != "":
+		user, getErr := us.repo.GetByID(ctx, userId)
+		if getErr != nil {
+			return getErr
+		}
+		info, err = us.auth.CheckUser(ctx, &authmodels.AuthUser{Username: user.Username, Password: proof.Password})
+	default:
+		return domain.ErrInvalidInput
+	}
+	if err != nil {
+		return err
+	}
+	if info.UserId != userId {
+		return domain.ErrInvalidCredentials
+	}
+	return nil
 }
 
-func (us *UserUsecase) DeleteUser(id int) error {
-	return us.repo.Delete(id)
+// ChangePassword заменяет верификатор и завершает вход на остальных
+// устройствах: кто знал старый пароль, не должен остаться в аккаунте.
+// Хранилище шифруется ключом из мастер-пароля, и его перешифровывает клиент.
+func (us *UserUsecase) ChangePassword(ctx context.Context, userId, sessionId int, req *models.PasswordChange) error {
+	verifier := authmodels.SRPVerifier{Salt: req.SRPSalt, Verifier: req.SRPVerifier}
+	if !verifier.Valid() {
+		return domain.ErrInvalidInput
+	}
+	if err := us.verifyPassword(ctx, userId, &req.Proof); err != nil {
+		return err
+	}
+	if err := us.repo.SetPassword(ctx, userId, req.SRPSalt, req.SRPVerifier); err != nil {
+		return err
+	}
+	_, err := us.auth.RevokeOtherSessions(ctx, userId, sessionId)
+	return err
+}
+
+// RenameUser меняет имя для входа. Остальные сессии завершаются, как и при
+// смене пароля: их токены выданы на прежнее имя.
+func (us *UserUsecase) RenameUser(ctx context.Context, userId, sessionId int, req *models.UsernameChange) error {
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Username == "" {
+		return domain.ErrNameEmpty
+	}
+	verifier := authmodels.SRPVerifier{Salt: req.SRPSalt, Verifier: req.SRPVerifier}
+	if !verifier.Valid() {
+		return domain.ErrInvalidInput
+	}
+	if err := us.verifyPassword(ctx, userId, &req.Proof); err != nil {
+		return err
+	}
+	if err := us.repo.Rename(ctx, userId, req.Username, req.SRPSalt, req.SRPVerifier); err != nil {
+		return err
+	}
+	_, err := us.auth.RevokeOtherSessions(ctx, userId, sessionId)
+	return err
+}
+
+// DeleteUser удаляет аккаунт со всеми записями и файлами. Содержимое файлов
+// удаляется после строки пользователя: если хранилище недоступно, остаются
+// только зашифрованные чанки без владельца, а не аккаунт без файлов.
+func (us *UserUsecase) DeleteUser(ctx context.Context, userId int, proof *models.PasswordProof) error {
+	if err := us.verifyPassword(ctx, userId, proof); err != nil {
+		return err
+	}
+	if err := us.repo.Delete(ctx, userId); err != nil {
+		return err
+	}
+	if err := us.blobs.Delete(ctx, storage.UserKey(userId)); err != nil {
+		slog.Error("Failed to delete user files", "userId", userId, "error", err)
+	}
+	return nil
+}
+
+func (us *UserUsecase) GetKDFParams(ctx context.Context, userId int) (*models.KDFParams, error) {
+	return us.repo.GetKDFParams(ctx, userId)
+}
+
+func (us *UserUsecase) SetKDFParams(ctx context.Context, userId int, params *models.KDFParams) error {
+	if !params.Valid() {
+		return domain.ErrInvalidKDF
+	}
+	return us.repo.SetKDFParams(ctx, userId, params)
 }
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"gophKeeper/internal/server/domain"
+	authmodels "gophKeeper/internal/server/services/auth/models"
+	"gophKeeper/internal/server/services/binary/storage"
+	"gophKeeper/internal/server/services/users/models"
+	"gophKeeper/internal/server/services/users/repository"
+	"gophKeeper/pkg/srp"
+	"strings"
+	"testing"
+)
+
+type fakeUserRepo struct {
+	repository.IUserRepository
+	users    map[int]*models.User
+	verifier string
+	deleted  bool
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, userId int) (*models.User, error) {
+	user, ok := r.users[userId]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepo) SetPassword(ctx context.Context, userId int, salt, verifier string) error {
+	r.verifier = verifier
+	return nil
+}
+
+func (r *fakeUserRepo) Delete(ctx context.Context, userId int) error {
+	r.deleted = true
+	return nil
+}
+
+// fakeAuth принимает пароль "secret" и SRP-сессии из sessions.
+type fakeAuth struct {
+	sessions map[string]int
+	revoked  int
+}
+
+func (a *fakeAuth) CheckUser(ctx context.Context, user *authmodels.AuthUser) (*authmodels.InfoUser, error) {
+	if user.Password != "secret" {
+		return nil, domain.ErrInvalidCredentials
+	}
+	return &authmodels.InfoUser{UserId: 1, Username: user.Username}, nil
+}
+
+func (a *fakeAuth) FinishSRP(ctx context.Context, req *authmodels.SRPVerify) (*authmodels.InfoUser, string, error) {
+	userId, ok := a.sessions[req.Session]
+	if !ok {
+		return nil, "", domain.ErrSRPSessionExpired
+	}
+	return &authmodels.InfoUser{UserId: userId}, "", nil
+}
+
+func (a *fakeAuth) RevokeOtherSessions(ctx context.Context, userId, currentId int) (int, error) {
+	a.revoked++
+	return 1, nil
+}
+
+type fakeBlobs struct {
+	storage.BlobStore
+	deleted []string
+}
+
+func (b *fakeBlobs) Delete(ctx context.Context, prefix string) error {
+	b.deleted = append(b.deleted, prefix)
+	return nil
+}
+
+func TestChangePassword(t *testing.T) {
+	repo := &fakeUserRepo{users: map[int]*models.User{1: {UserId: 1, Username: "alice"}}}
+	auth := &fakeAuth{sessions: map[string]int{"alice": 1, "bob": 2}}
+	uc := NewUserUsecase(repo, auth, &fakeBlobs{})
+	ctx := context.Background()
+	change := func(proof models.PasswordProof) *models.PasswordChange {
+		return &models.PasswordChange{Proof: proof, SRPSalt: strings.Repeat("00", srp.SaltSize), SRPVerifier: "aabb"}
+	}
+
+	if err := uc.ChangePassword(ctx, 1, 7, change(models.PasswordProof{Session: "bob", M1: "00"})); err != domain.ErrInvalidCredentials {
+		t.Errorf("Доказательство чужого пароля должно отклоняться, получено: %v", err)
+	}
+	if err := uc.ChangePassword(ctx, 1, 7, change(models.PasswordProof{})); err != domain.ErrInvalidInput {
+		t.Errorf("Без доказательства ожидалась ErrInvalidInput, получено: %v", err)
+	}
+	if err := uc.ChangePassword(ctx, 1, 7, change(models.PasswordProof{Password: "wrong"})); err != domain.ErrInvalidCredentials {
+		t.Errorf("Ожидалась ErrInvalidCredentials, получено: %v", err)
+	}
+	if repo.verifier != "" || auth.revoked != 0 {
+		t.Fatal("Пароль изменён без подтверждения")
+	}
+
+	if err := uc.ChangePassword(ctx, 1, 7, change(models.PasswordProof{Session: "alice", M1: "00"})); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if err := uc.ChangePassword(ctx, 1, 7, change(models.PasswordProof{Password: "secret"})); err != nil {
+		t.Fatalf("ChangePassword по паролю: %v", err)
+	}
+	if repo.verifier != "aabb" || auth.revoked != 2 {
+		t.Errorf("verifier=%q revoked=%d", repo.verifier, auth.revoked)
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	repo := &fakeUserRepo{users: map[int]*models.User{1: {UserId: 1, Username: "alice"}}}
+	blobs := &fakeBlobs{}
+	uc := NewUserUsecase(repo, &fakeAuth{}, blobs)
+
+	if err := uc.DeleteUser(context.Background(), 1, &models.PasswordProof{Password: "wrong"}); err != domain.ErrInvalidCredentials {
+		t.Fatalf("Ожидалась ErrInvalidCredentials, получено: %v", err)
+	}
+	if err := uc.DeleteUser(context.Background(), 1, &models.PasswordProof{Password: "secret"}); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if !repo.deleted || len(blobs.deleted) != 1 || blobs.deleted[0] != "1" {
+		t.Errorf("deleted=%v blobs=%v", repo.deleted, blobs.deleted)
+	}
+}
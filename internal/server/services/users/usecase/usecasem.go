@@ -37,12 +37,30 @@ func (us *UserUsecaseMock) GetUserByID(ctx context.Context, id int) (*models.Use
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (us *UserUsecaseMock) UpdateUser(user *models.User) error {
-	args := us.Called(user)
+func (us *UserUsecaseMock) ChangePassword(ctx context.Context, userId, sessionId int, req *models.PasswordChange) error {
+	args := us.Called(ctx, userId, sessionId, req)
 	return args.Error(0)
 }
 
-func (us *UserUsecaseMock) DeleteUser(id int) error {
-	args := us.Called(id)
+func (us *UserUsecaseMock) RenameUser(ctx context.Context, userId, sessionId int, req *models.UsernameChange) error {
+	args := us.Called(ctx, userId, sessionId, req)
+	return args.Error(0)
+}
+
+func (us *UserUsecaseMock) DeleteUser(ctx context.Context, userId int, proof *models.PasswordProof) error {
+	args := us.Called(ctx, userId, proof)
+	return args.Error(0)
+}
+
+func (us *UserUsecaseMock) GetKDFParams(ctx context.Context, userId int) (*models.KDFParams, error) {
+	args := us.Called(ctx, userId)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.KDFParams), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (us *UserUsecaseMock) SetKDFParams(ctx context.Context, userId int, params *models.KDFParams) error {
+	args := us.Called(ctx, userId, params)
 	return args.Error(0)
 }
@@ -0,0 +1,53 @@
+package models
+
+import usersmodels "gophKeeper/internal/server/services/users/models"
+
+// RotateRequest — все секреты пользователя, перешифрованные новым ключом.
+// Применяется одной транзакцией: либо всё хранилище переходит на новый ключ,
+// либо ничего не меняется. OldCheck защищает от параллельной смены ключа.
+type RotateRequest struct {
+	OldCheck  string                `json:"old_check" binding:"required"`
+	KDF       usersmodels.KDFParams `json:"kdf" binding:"required"`
+	LockBoxes []LockBox             `json:"lock_boxes"`
+	BankCards []BankCard            `json:"bank_cards"`
+	Notes     []Note                `json:"notes"`
+	Binaries  []Binary              `json:"binaries"`
+}
+
+// LockBox адресуется по id: имя может меняться вместе с ключом, если оно
+// зашифровано.
+type LockBox struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	NameIndex   string `json:"name_index"`
+	Url         string `json:"url"`
+	Login       string `json:"login"`
+	Password    string `json:"password"`
+	Description string `json:"description"`
+}
+
+// BankCard, как и LockBox, адресуется по id: название может быть
+// зашифровано и меняется вместе с ключом.
+type BankCard struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	NameIndex string `json:"name_index"`
+	Number    string `json:"number"`
+	Holder    string `json:"holder"`
+	Expiry    string `json:"expiry"`
+	CVV       string `json:"cvv"`
+	PIN       string `json:"pin"`
+	Notes     string `json:"notes"`
+}
+
+type Note struct {
+	Name string `json:"name"`
+	Body string `json:"body"`
+}
+
+// Binary ссылается на незавершённую загрузку с перешифрованным содержимым,
+// которая заменит текущую версию файла с тем же именем.
+type Binary struct {
+	Name     string `json:"name"`
+	UploadID int    `json:"upload_id"`
+}
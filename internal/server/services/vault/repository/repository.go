@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"gophKeeper/internal/server/db"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/vault/models"
+)
+
+type IVaultRepo interface {
+	Rotate(ctx context.Context, userId int, req *models.RotateRequest) (replaced []int, err error)
+}
+
+type VaultRepo struct {
+	db db.IDatabase
+}
+
+func NewVaultRepo(db db.IDatabase) IVaultRepo {
+	return &VaultRepo{
+		db: db,
+	}
+}
+
+// Rotate заменяет содержимое всех секретов пользователя и параметры KDF в одной
+// транзакции. Удалённые записи очищаются: после смены ключа их уже не расшифровать.
+// Возвращает id заменённых версий файлов, чтобы вызывающий удалил их содержимое.
+func (r *VaultRepo) Rotate(ctx context.Context, userId int, req *models.RotateRequest) ([]int, error) {
+	tx, err := r.db.GetDB().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var check *string
+	err = tx.QueryRow(ctx, `SELECT kdf_check FROM users WHERE user_id = $1 FOR UPDATE`, userId).Scan(&check)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if check == nil || *check != req.OldCheck {
+		return nil, domain.ErrStaleKey
+	}
+
+	for _, table := range []string{"lockbox", "bank_card", "note"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE user_id = $1 AND deleted_at IS NOT NULL`, userId); err != nil {
+			return nil, err
+		}
+	}
+	// История записей зашифрована старым ключом и после смены стала бы нечитаемой.
+	if _, err := tx.Exec(ctx, `DELETE FROM lockbox_revisions WHERE user_id = $1`, userId); err != nil {
+		return nil, err
+	}
+	// То же с именами в надгробиях: клиенты, не заставшие удаление, получат
+	// полную синхронизацию вместо ленты изменений.
+	if _, err := tx.Exec(ctx, `DELETE FROM lockbox_tombstones WHERE user_id = $1`, userId); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE lockbox_sync_state SET pruned_seq = seq WHERE user_id = $1`, userId); err != nil {
+		return nil, err
+	}
+
+	for _, box := range req.LockBoxes {
+		err := execOne(ctx, tx, `UPDATE lockbox SET name = $3, name_index = NULLIF($4, ''), url = $5, username = $6, password = $7,
+                                 description = $8, updated_at = NOW()
+                                 WHERE user_id = $1 AND id = $2`,
+			userId, box.Id, box.Name, box.NameIndex, box.Url, box.Login, box.Password, box.Description)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, card := range req.BankCards {
+		err := execOne(ctx, tx, `UPDATE bank_card SET name = $3, name_index = NULLIF($4, ''), number = $5, holder = $6, expiry = $7,
+                                 cvv = $8, pin = $9, notes = $10, updated_at = NOW()
+                                 WHERE user_id = $1 AND id = $2`,
+			userId, card.Id, card.Name, card.NameIndex, card.Number, card.Holder, card.Expiry, card.CVV, card.PIN, card.Notes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, note := range req.Notes {
+		err := execOne(ctx, tx, `UPDATE note SET body = $3, updated_at = NOW() WHERE user_id = $1 AND name = $2`,
+			userId, note.Name, note.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var replaced []int
+	for _, bin := range req.Binaries {
+		var ready bool
+		err := tx.QueryRow(ctx, `SELECT f.chunk_count = (SELECT COUNT(*) FROM binary_chunk c WHERE c.file_id = f.id)
+                                 FROM binary_file f
+                                 WHERE f.id = $1 AND f.user_id = $2 AND f.name = $3 AND NOT f.completed`,
+			bin.UploadID, userId, bin.Name).Scan(&ready)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, domain.ErrInvalidUpload
+			}
+			return nil, err
+		}
+		if !ready {
+			return nil, domain.ErrUploadIncomplete
+		}
+
+		rows, err := tx.Query(ctx, `DELETE FROM binary_file WHERE name = $1 AND user_id = $2 AND completed RETURNING id`, bin.Name, userId)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return nil, err
+		}
+		replaced = append(replaced, ids...)
+
+		if _, err := tx.Exec(ctx, `UPDATE binary_file SET completed = TRUE, updated_at = NOW() WHERE id = $1`, bin.UploadID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Секрет, не попавший в пакет, после смены ключа стал бы нечитаемым.
+	var missing bool
+	err = tx.QueryRow(ctx, `SELECT (SELECT COUNT(*) FROM lockbox WHERE user_id = $1) <> $2
+                                OR (SELECT COUNT(*) FROM bank_card WHERE user_id = $1) <> $3
+                                OR (SELECT COUNT(*) FROM note WHERE user_id = $1) <> $4
+                                OR (SELECT COUNT(*) FROM binary_file WHERE user_id = $1 AND completed) <> $5`,
+		userId, len(req.LockBoxes), len(req.BankCards), len(req.Notes), len(req.Binaries)).Scan(&missing)
+	if err != nil {
+		return nil, err
+	}
+	if missing {
+		return nil, domain.ErrRotationIncomplete
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE users SET kdf_salt = $2, kdf_time = $3, kdf_memory = $4, kdf_threads = $5, kdf_check = $6
+                           WHERE user_id = $1`,
+		userId, req.KDF.Salt, req.KDF.Time, req.KDF.Memory, req.KDF.Threads, req.KDF.Check)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
+
+// execOne выполняет UPDATE одной записи; отсутствие записи означает,
+// что пакет не соответствует содержимому хранилища.
+func execOne(ctx context.Context, tx pgx.Tx, query string, args ...any) error {
+	res, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() != 1 {
+		return domain.ErrRotationIncomplete
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"gophKeeper/internal/server/domain"
+	"gophKeeper/internal/server/services/binary/storage"
+	"gophKeeper/internal/server/services/vault/models"
+	"gophKeeper/internal/server/services/vault/repository"
+	"log"
+)
+
+type IVaultUsecase interface {
+	RotateKey(ctx context.Context, userId int, req *models.RotateRequest) error
+}
+
+type VaultUsecase struct {
+	repo  repository.IVaultRepo
+	store storage.BlobStore
+}
+
+func NewVaultUsecase(repo repository.IVaultRepo, store storage.BlobStore) IVaultUsecase {
+	return &VaultUsecase{repo: repo, store: store}
+}
+
+func (u *VaultUsecase) RotateKey(ctx context.Context, userId int, req *models.RotateRequest) error {
+	if !req.KDF.Valid() {
+		return domain.ErrInvalidKDF
+	}
+
+	replaced, err := u.repo.Rotate(ctx, userId, req)
+	if err != nil {
+		return err
+	}
+	for _, id := range replaced {
+		if err := u.store.Delete(ctx, storage.FileKey(userId, id)); err != nil {
+			log.Println("failed to delete replaced binary content:", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"gophKeeper/internal/server/services/vault/models"
+)
+
+type VaultUsecaseMock struct {
+	mock.Mock
+}
+
+func NewVaultUsecaseMock() *VaultUsecaseMock {
+	return &VaultUsecaseMock{}
+}
+
+func (u *VaultUsecaseMock) RotateKey(ctx context.Context, userId int, req *models.RotateRequest) error {
+	args := u.Called(ctx, userId, req)
+	return args.Error(0)
+}
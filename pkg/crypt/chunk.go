@@ -0,0 +1,19 @@
+package crypt
+
+// EncryptChunk шифрует один кусок бинарного файла. Файлы шифруются по частям,
+// поэтому ни клиенту, ни серверу не нужно держать весь файл в памяти.
+func EncryptChunk(chunk []byte, encryptor Encryptor) ([]byte, error) {
+	encrypted, err := encryptor.Encrypt(string(chunk))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(encrypted), nil
+}
+
+func DecryptChunk(chunk []byte, encryptor Encryptor) ([]byte, error) {
+	decrypted, err := encryptor.Decrypt(string(chunk))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(decrypted), nil
+}
@@ -17,12 +17,15 @@ type Encryptor interface {
 	Decrypt(string) (string, error)
 }
 
+// AESCBCEncryptor — прежний формат (AES-CBC + HMAC). Новые данные им не
+// шифруются, он нужен только для чтения ранее сохранённых значений.
 type AESCBCEncryptor struct {
 	Key string
 }
 
+// New возвращает шифратор AES-256-GCM, который умеет читать и старый формат AES-CBC.
 func New(key string) Encryptor {
-	return &AESCBCEncryptor{Key: key}
+	return newAESGCMEncryptor(key)
 }
 
 func createHMAC(key, message []byte) []byte {
@@ -47,10 +50,18 @@ func pad(data []byte, blockSize int) []byte {
 }
 
 func unpad(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("ошибка: некорректный паддинг")
+	}
 	padding := int(data[len(data)-1])
-	if padding > len(data) {
+	if padding == 0 || padding > len(data) {
 		return nil, fmt.Errorf("ошибка: некорректный паддинг")
 	}
+	for _, b := range data[len(data)-padding:] {
+		if int(b) != padding {
+			return nil, fmt.Errorf("ошибка: некорректный паддинг")
+		}
+	}
 	return data[:len(data)-padding], nil
 }
 
@@ -95,7 +106,7 @@ func (e *AESCBCEncryptor) Decrypt(encryptedText string) (string, error) {
 	}
 
 	blockSize := block.BlockSize()
-	if len(data) < blockSize+sha256.Size {
+	if len(data) < 2*blockSize+sha256.Size || (len(data)-sha256.Size)%blockSize != 0 {
 		return "", fmt.Errorf("ошибка: повреждённые данные")
 	}
 
@@ -207,7 +218,9 @@ func DecryptLockBox(lockBox *models.LockBox, encryptor Encryptor) (*models.LockB
 	}
 
 	decryptedLockBox := &models.LockBox{
+		ID:          lockBox.ID,
 		Name:        lockBox.Name,
+		NameIndex:   lockBox.NameIndex,
 		Description: decryptedInput.Description,
 		Login:       decryptedInput.Login,
 		URL:         decryptedInput.URL,
@@ -216,6 +229,7 @@ func DecryptLockBox(lockBox *models.LockBox, encryptor Encryptor) (*models.LockB
 		UpdatedAt:   lockBox.UpdatedAt,
 		SyncedAt:    lockBox.SyncedAt,
 		DeletedAt:   lockBox.DeletedAt,
+		Revision:    lockBox.Revision,
 	}
 
 	return decryptedLockBox, nil
@@ -235,7 +249,9 @@ func EncryptLockBox(lockBox *models.LockBox, encryptor Encryptor) (*models.LockB
 	}
 
 	encryptedLockBox := &models.LockBox{
+		ID:          lockBox.ID,
 		Name:        lockBox.Name,
+		NameIndex:   lockBox.NameIndex,
 		Description: encryptedInput.Description,
 		Login:       encryptedInput.Login,
 		URL:         encryptedInput.URL,
@@ -244,6 +260,75 @@ func EncryptLockBox(lockBox *models.LockBox, encryptor Encryptor) (*models.LockB
 		UpdatedAt:   lockBox.UpdatedAt,
 		SyncedAt:    lockBox.SyncedAt,
 		DeletedAt:   lockBox.DeletedAt,
+		Revision:    lockBox.Revision,
 	}
 	return encryptedLockBox, nil
 }
+
+// encryptFields шифрует непустые значения по указателям.
+func encryptFields(encryptor Encryptor, fields ...*string) error {
+	for _, field := range fields {
+		if *field == "" {
+			continue
+		}
+		encrypted, err := encryptor.Encrypt(*field)
+		if err != nil {
+			return err
+		}
+		*field = encrypted
+	}
+	return nil
+}
+
+// decryptFields расшифровывает непустые значения по указателям.
+func decryptFields(encryptor Encryptor, fields ...*string) error {
+	for _, field := range fields {
+		if *field == "" {
+			continue
+		}
+		decrypted, err := encryptor.Decrypt(*field)
+		if err != nil {
+			return err
+		}
+		*field = decrypted
+	}
+	return nil
+}
+
+// EncryptBankCard шифрует все реквизиты карты, кроме названия.
+func EncryptBankCard(card *models.BankCardInput, encryptor Encryptor) (*models.BankCardInput, error) {
+	encryptedCard := *card
+	err := encryptFields(encryptor, &encryptedCard.Number, &encryptedCard.Holder, &encryptedCard.Expiry,
+		&encryptedCard.CVV, &encryptedCard.PIN, &encryptedCard.Notes)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedCard, nil
+}
+
+func DecryptBankCard(card *models.BankCard, encryptor Encryptor) (*models.BankCard, error) {
+	decryptedCard := *card
+	err := decryptFields(encryptor, &decryptedCard.Number, &decryptedCard.Holder, &decryptedCard.Expiry,
+		&decryptedCard.CVV, &decryptedCard.PIN, &decryptedCard.Notes)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptedCard, nil
+}
+
+// EncryptNote шифрует тело заметки; название остаётся открытым, как и у LockBox.
+func EncryptNote(note *models.Note, encryptor Encryptor) (*models.Note, error) {
+	encryptedNote := *note
+	if err := encryptFields(encryptor, &encryptedNote.Body); err != nil {
+		return nil, err
+	}
+	return &encryptedNote, nil
+}
+
+func DecryptNote(note *models.Note, encryptor Encryptor) (*models.Note, error) {
+	decryptedNote := *note
+	if err := decryptFields(encryptor, &decryptedNote.Body); err != nil {
+		return nil, err
+	}
+	return &decryptedNote, nil
+}
@@ -2,6 +2,9 @@
 package crypt
 
 import (
+	"encoding/base64"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -39,40 +42,40 @@ func TestEncryptDecryptStruct(t *testing.T) {
 	}
 	original := input
 
-	err := EncryptStruct(&input, encryptor)
+	encrypted, err := EncryptStruct(&input, encryptor)
 	if err != nil {
 		t.Fatalf("EncryptStruct failed: %v", err)
 	}
 
-	if input.Description == original.Description {
+	if encrypted.Description == original.Description {
 		t.Error("Description was not encrypted")
 	}
-	if input.Login == original.Login {
+	if encrypted.Login == original.Login {
 		t.Error("Login was not encrypted")
 	}
-	if input.URL == original.URL {
+	if encrypted.URL == original.URL {
 		t.Error("URL was not encrypted")
 	}
-	if input.Password == original.Password {
+	if encrypted.Password == original.Password {
 		t.Error("Password was not encrypted")
 	}
 
-	err = DecryptStruct(&input, encryptor)
+	decrypted, err := DecryptStruct(encrypted, encryptor)
 	if err != nil {
 		t.Fatalf("DecryptStruct failed: %v", err)
 	}
 
-	if input.Description != original.Description {
-		t.Errorf("After decryption, Description mismatch: got %q, want %q", input.Description, original.Description)
+	if decrypted.Description != original.Description {
+		t.Errorf("After decryption, Description mismatch: got %q, want %q", decrypted.Description, original.Description)
 	}
-	if input.Login != original.Login {
-		t.Errorf("After decryption, Login mismatch: got %q, want %q", input.Login, original.Login)
+	if decrypted.Login != original.Login {
+		t.Errorf("After decryption, Login mismatch: got %q, want %q", decrypted.Login, original.Login)
 	}
-	if input.URL != original.URL {
-		t.Errorf("After decryption, URL mismatch: got %q, want %q", input.URL, original.URL)
+	if decrypted.URL != original.URL {
+		t.Errorf("After decryption, URL mismatch: got %q, want %q", decrypted.URL, original.URL)
 	}
-	if input.Password != original.Password {
-		t.Errorf("After decryption, Password mismatch: got %q, want %q", input.Password, original.Password)
+	if decrypted.Password != original.Password {
+		t.Errorf("After decryption, Password mismatch: got %q, want %q", decrypted.Password, original.Password)
 	}
 }
 
@@ -90,35 +93,182 @@ func TestEncryptDecryptLockBox(t *testing.T) {
 	}
 	original := box
 
-	err := EncryptLockBox(&box, encryptor)
+	encrypted, err := EncryptLockBox(&box, encryptor)
 	if err != nil {
 		t.Fatalf("EncryptLockBox failed: %v", err)
 	}
 
-	if box.Description == original.Description {
+	if encrypted.Description == original.Description {
 		t.Error("LockBox Description was not encrypted")
 	}
-	if box.URL == original.URL {
+	if encrypted.URL == original.URL {
 		t.Error("LockBox URL was not encrypted")
 	}
-	if box.Password == original.Password {
+	if encrypted.Password == original.Password {
 		t.Error("LockBox Password was not encrypted")
 	}
 
-	err = DecryptLockBox(&box, encryptor)
+	decrypted, err := DecryptLockBox(encrypted, encryptor)
 	if err != nil {
 		t.Fatalf("DecryptLockBox failed: %v", err)
 	}
-	if box.Description != original.Description {
-		t.Errorf("After decryption, Description mismatch: got %q, want %q", box.Description, original.Description)
+	if decrypted.Description != original.Description {
+		t.Errorf("After decryption, Description mismatch: got %q, want %q", decrypted.Description, original.Description)
 	}
-	if box.Login != original.Login {
-		t.Errorf("After decryption, Login mismatch: got %q, want %q", box.Login, original.Login)
+	if decrypted.Login != original.Login {
+		t.Errorf("After decryption, Login mismatch: got %q, want %q", decrypted.Login, original.Login)
 	}
-	if box.URL != original.URL {
-		t.Errorf("After decryption, URL mismatch: got %q, want %q", box.URL, original.URL)
+	if decrypted.URL != original.URL {
+		t.Errorf("After decryption, URL mismatch: got %q, want %q", decrypted.URL, original.URL)
 	}
-	if box.Password != original.Password {
-		t.Errorf("After decryption, Password mismatch: got %q, want %q", box.Password, original.Password)
+	if decrypted.Password != original.Password {
+		t.Errorf("After decryption, Password mismatch: got %q, want %q", decrypted.Password, original.Password)
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	params, err := NewKDFParams()
+	if err != nil {
+		t.Fatalf("NewKDFParams failed: %v", err)
+	}
+	// Уменьшаем стоимость, чтобы тест выполнялся быстро.
+	params.Memory = 1024
+	params.Time = 1
+
+	key := DeriveKey("master password", params)
+	if len(key) != 32 {
+		t.Fatalf("Derived key length = %d, want 32", len(key))
+	}
+	if string(key) != string(DeriveKey("master password", params)) {
+		t.Error("DeriveKey is not deterministic")
+	}
+
+	check, err := NewKeyCheck(NewFromPassword("master password", params))
+	if err != nil {
+		t.Fatalf("NewKeyCheck failed: %v", err)
+	}
+	if !VerifyKeyCheck(check, NewFromPassword("master password", params)) {
+		t.Error("Key check rejected the correct password")
+	}
+	if VerifyKeyCheck(check, NewFromPassword("wrong password", params)) {
+		t.Error("Key check accepted a wrong password")
+	}
+
+	other, err := NewKDFParams()
+	if err != nil {
+		t.Fatalf("NewKDFParams failed: %v", err)
+	}
+	other.Memory, other.Time = params.Memory, params.Time
+	if string(key) == string(DeriveKey("master password", other)) {
+		t.Error("Different salts produced the same key")
+	}
+}
+
+func TestLocked(t *testing.T) {
+	if _, err := Locked().Encrypt("data"); err != ErrLocked {
+		t.Errorf("Encrypt error = %v, want ErrLocked", err)
+	}
+	if _, err := Locked().Decrypt("data"); err != ErrLocked {
+		t.Errorf("Decrypt error = %v, want ErrLocked", err)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	encryptor := New("superSecretKey19")
+
+	encrypted, err := encryptor.Encrypt("Hello, World!")
+	if err != nil {
+		t.Fatalf("Encryption failed: %v", err)
+	}
+	if IsLegacy(encrypted) {
+		t.Fatalf("New ciphertext is in legacy format: %q", encrypted)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, envelopeMarker))
+	if err != nil {
+		t.Fatalf("Envelope is not base64: %v", err)
+	}
+	if data[0] != envelopeVersion || data[1] != algAESGCM {
+		t.Errorf("Unexpected envelope header: %v", data[:2])
+	}
+
+	// Подмена любого байта, включая заголовок, должна обнаруживаться.
+	for _, i := range []int{1, headerSize, len(data) - 1} {
+		tampered := append([]byte(nil), data...)
+		tampered[i] ^= 0xff
+		if _, err := encryptor.Decrypt(envelopeMarker + base64.StdEncoding.EncodeToString(tampered)); err == nil {
+			t.Errorf("Tampered byte %d was not detected", i)
+		}
+	}
+
+	if _, err := New("anotherSecretKey").Decrypt(encrypted); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("Decrypt with another key error = %v, want ErrKeyMismatch", err)
+	}
+}
+
+func TestLegacyCBC(t *testing.T) {
+	legacy := &AESCBCEncryptor{Key: "superSecretKey19"}
+	old, err := legacy.Encrypt("legacy secret")
+	if err != nil {
+		t.Fatalf("Legacy encryption failed: %v", err)
+	}
+	if !IsLegacy(old) {
+		t.Fatalf("CBC ciphertext is not recognized as legacy")
+	}
+
+	encryptor := New("superSecretKey19")
+	decrypted, err := encryptor.Decrypt(old)
+	if err != nil {
+		t.Fatalf("Legacy decryption failed: %v", err)
+	}
+	if decrypted != "legacy secret" {
+		t.Errorf("Legacy decryption mismatch: got %q", decrypted)
+	}
+
+	// Повторная запись переводит значение в новый формат.
+	reencrypted, err := encryptor.Encrypt(decrypted)
+	if err != nil {
+		t.Fatalf("Re-encryption failed: %v", err)
+	}
+	if IsLegacy(reencrypted) {
+		t.Error("Re-encrypted value is still in legacy format")
+	}
+}
+
+func TestUnpad(t *testing.T) {
+	if _, err := unpad([]byte{'a', 'b', 1, 2}); err == nil {
+		t.Error("Inconsistent padding bytes were accepted")
+	}
+	if _, err := unpad([]byte{'a', 0}); err == nil {
+		t.Error("Zero padding was accepted")
+	}
+	got, err := unpad([]byte{'a', 'b', 2, 2})
+	if err != nil || string(got) != "ab" {
+		t.Errorf("unpad = %q, %v; want \"ab\"", got, err)
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	encryptor := New("superSecretKey19")
+
+	index, err := BlindIndex("GitHub", encryptor)
+	if err != nil {
+		t.Fatalf("BlindIndex failed: %v", err)
+	}
+	same, _ := BlindIndex("  github ", encryptor)
+	if index != same {
+		t.Errorf("Index depends on case or spaces: %q != %q", index, same)
+	}
+	other, _ := BlindIndex("gitlab", encryptor)
+	if index == other {
+		t.Error("Different names have the same index")
+	}
+	foreign, _ := BlindIndex("github", New("anotherSecretKey"))
+	if index == foreign {
+		t.Error("Index does not depend on the key")
+	}
+
+	if _, err := BlindIndex("github", Locked()); err != ErrLocked {
+		t.Errorf("BlindIndex error = %v, want ErrLocked", err)
 	}
 }
@@ -0,0 +1,121 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Формат конверта: "$" + base64(версия | алгоритм | id ключа | nonce | шифротекст с тегом).
+// Символ "$" не входит в алфавит base64, поэтому конверт не спутать со старым
+// форматом AES-CBC, который записывался как чистый base64.
+const (
+	envelopeMarker  = "$"
+	envelopeVersion = 1
+	algAESGCM       = 1
+	keyIDSize       = 4
+	headerSize      = 2 + keyIDSize
+)
+
+var (
+	ErrKeyMismatch     = errors.New("данные зашифрованы другим ключом")
+	ErrUnknownEnvelope = errors.New("неизвестная версия или алгоритм шифрования")
+)
+
+// AESGCMEncryptor шифрует AES-256-GCM. Заголовок конверта аутентифицируется
+// вместе с данными. Старые шифротексты AES-CBC по-прежнему расшифровываются,
+// а при следующей записи значение сохраняется уже в новом формате.
+type AESGCMEncryptor struct {
+	aead     cipher.AEAD
+	keyID    [keyIDSize]byte
+	indexKey []byte
+	legacy   *AESCBCEncryptor
+}
+
+// deriveSubkey разделяет ключи по назначению, чтобы один и тот же ключ
+// не использовался и для GCM, и для идентификатора ключа.
+func deriveSubkey(key, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// KeyID возвращает идентификатор ключа, который записывается в заголовок конверта.
+func KeyID(key string) uint32 {
+	return binary.BigEndian.Uint32(deriveSubkey(key, "gophKeeper key id"))
+}
+
+func newAESGCMEncryptor(key string) *AESGCMEncryptor {
+	// Подключ всегда 32 байта, поэтому aes.NewCipher и cipher.NewGCM не возвращают ошибок.
+	block, _ := aes.NewCipher(deriveSubkey(key, "gophKeeper aes-256-gcm"))
+	aead, _ := cipher.NewGCM(block)
+
+	e := &AESGCMEncryptor{
+		aead:     aead,
+		indexKey: deriveSubkey(key, "gophKeeper blind index"),
+		legacy:   &AESCBCEncryptor{Key: key},
+	}
+	binary.BigEndian.PutUint32(e.keyID[:], KeyID(key))
+	return e
+}
+
+// IsLegacy сообщает, что значение записано в старом формате AES-CBC.
+func IsLegacy(ciphertext string) bool {
+	return ciphertext != "" && !strings.HasPrefix(ciphertext, envelopeMarker)
+}
+
+func (e *AESGCMEncryptor) Encrypt(plaintext string) (string, error) {
+	header := make([]byte, headerSize+e.aead.NonceSize())
+	header[0] = envelopeVersion
+	header[1] = algAESGCM
+	copy(header[2:headerSize], e.keyID[:])
+
+	nonce := header[headerSize:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("ошибка генерации nonce: %w", err)
+	}
+
+	// Заголовок с nonce аутентифицируется как additionalData, а по контракту
+	// cipher.AEAD dst не должен с ним пересекаться: конверт собирается в
+	// отдельном буфере.
+	envelope := make([]byte, len(header), len(header)+len(plaintext)+e.aead.Overhead())
+	copy(envelope, header)
+	envelope = e.aead.Seal(envelope, nonce, []byte(plaintext), header)
+	return envelopeMarker + base64.StdEncoding.EncodeToString(envelope), nil
+}
+
+func (e *AESGCMEncryptor) Decrypt(encryptedText string) (string, error) {
+	if IsLegacy(encryptedText) {
+		return e.legacy.Decrypt(encryptedText)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encryptedText, envelopeMarker))
+	if err != nil {
+		return "", fmt.Errorf("ошибка декодирования Base64: %w", err)
+	}
+	if len(data) < headerSize+e.aead.NonceSize()+e.aead.Overhead() {
+		return "", fmt.Errorf("ошибка: повреждённые данные")
+	}
+	if data[0] != envelopeVersion || data[1] != algAESGCM {
+		return "", ErrUnknownEnvelope
+	}
+	if !hmac.Equal(data[2:headerSize], e.keyID[:]) {
+		return "", ErrKeyMismatch
+	}
+
+	header := data[:headerSize+e.aead.NonceSize()]
+	nonce := data[headerSize : headerSize+e.aead.NonceSize()]
+	plaintext, err := e.aead.Open(nil, nonce, data[len(header):], header)
+	if err != nil {
+		return "", fmt.Errorf("ошибка: данные повреждены или подменены")
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,48 @@
+package crypt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// indexer реализуют шифраторы, ключ которых подходит для слепого индекса.
+type indexer interface {
+	blindIndex(name string) string
+}
+
+func (e *AESGCMEncryptor) blindIndex(name string) string {
+	mac := hmac.New(sha256.New, e.indexKey)
+	mac.Write([]byte(name))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// normalizeName приводит имя к виду, в котором сравниваются имена записей:
+// "GitHub " и "github" — одна и та же запись.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// BlindIndex возвращает HMAC нормализованного имени. По нему сервер находит
+// запись и проверяет уникальность, не видя самого имени.
+func BlindIndex(name string, encryptor Encryptor) (string, error) {
+	ix, ok := encryptor.(indexer)
+	if !ok {
+		return "", ErrLocked
+	}
+	return ix.blindIndex(normalizeName(name)), nil
+}
+
+// EncryptName шифрует имя записи и вычисляет для него слепой индекс.
+func EncryptName(name string, encryptor Encryptor) (encrypted, index string, err error) {
+	index, err = BlindIndex(name, encryptor)
+	if err != nil {
+		return "", "", err
+	}
+	encrypted, err = encryptor.Encrypt(name)
+	if err != nil {
+		return "", "", err
+	}
+	return encrypted, index, nil
+}
@@ -0,0 +1,70 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"gophKeeper/internal/client/services/lockbox/models"
+	"io"
+)
+
+// Параметры Argon2id по умолчанию (рекомендации OWASP с запасом по памяти).
+const (
+	kdfTime    = 3
+	kdfMemory  = 64 * 1024
+	kdfThreads = 4
+	kdfSaltLen = 16
+	kdfKeyLen  = 32
+)
+
+// keyCheck шифруется выведенным ключом и хранится на сервере: по нему клиент
+// отличает неверный мастер-пароль от повреждённых данных.
+const keyCheck = "gophKeeper key check"
+
+var ErrLocked = errors.New("хранилище заблокировано: введите мастер-пароль")
+
+// NewKDFParams генерирует случайную соль и параметры по умолчанию для нового хранилища.
+func NewKDFParams() (*models.KDFParams, error) {
+	salt := make([]byte, kdfSaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("ошибка генерации соли: %w", err)
+	}
+	return &models.KDFParams{Salt: salt, Time: kdfTime, Memory: kdfMemory, Threads: kdfThreads}, nil
+}
+
+// DeriveKey выводит 256-битный ключ из мастер-пароля по Argon2id.
+func DeriveKey(password string, params *models.KDFParams) []byte {
+	return argon2.IDKey([]byte(password), params.Salt, params.Time, params.Memory, params.Threads, kdfKeyLen)
+}
+
+// NewFromPassword возвращает шифратор с ключом, выведенным из мастер-пароля.
+func NewFromPassword(password string, params *models.KDFParams) Encryptor {
+	return New(string(DeriveKey(password, params)))
+}
+
+// NewKeyCheck шифрует контрольную строку, которую затем проверяет VerifyKeyCheck.
+func NewKeyCheck(encryptor Encryptor) (string, error) {
+	return encryptor.Encrypt(keyCheck)
+}
+
+func VerifyKeyCheck(check string, encryptor Encryptor) bool {
+	plain, err := encryptor.Decrypt(check)
+	return err == nil && plain == keyCheck
+}
+
+type lockedEncryptor struct{}
+
+// Locked возвращает шифратор-заглушку, который используется до ввода мастер-пароля:
+// ключ существует только в памяти клиента и до разблокировки его нет.
+func Locked() Encryptor {
+	return lockedEncryptor{}
+}
+
+func (lockedEncryptor) Encrypt(string) (string, error) {
+	return "", ErrLocked
+}
+
+func (lockedEncryptor) Decrypt(string) (string, error) {
+	return "", ErrLocked
+}
@@ -0,0 +1,207 @@
+// Package jwks описывает открытые ключи подписи токенов в формате JWK Set
+// (RFC 7517) и выбирает ключ проверки по заголовку kid. Поддерживаются RSA
+// (RS256) и Ed25519 (EdDSA).
+package jwks
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrUnsupportedKey = errors.New("jwks: поддерживаются только ключи RSA и Ed25519")
+	ErrUnknownKey     = errors.New("jwks: неизвестный kid")
+	ErrAlgMismatch    = errors.New("jwks: алгоритм токена не совпадает с ключом")
+	ErrNoPEM          = errors.New("jwks: в файле нет PEM-блока")
+)
+
+// Key — открытый ключ в формате JWK.
+type Key struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// Set — ответ эндпоинта JWKS.
+type Set struct {
+	Keys []Key `json:"keys"`
+}
+
+// Method возвращает алгоритм подписи для ключа.
+func Method(key crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// KeyID вычисляет kid как base64url от SHA-256 SubjectPublicKeyInfo: один и
+// тот же ключ получает один и тот же kid на любом сервере.
+func KeyID(key crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), nil
+}
+
+// NewKey кодирует открытый ключ в JWK.
+func NewKey(key crypto.PublicKey) (Key, error) {
+	method, err := Method(key)
+	if err != nil {
+		return Key{}, err
+	}
+	kid, err := KeyID(key)
+	if err != nil {
+		return Key{}, err
+	}
+	jwk := Key{Kid: kid, Alg: method.Alg(), Use: "sig"}
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(k)
+	}
+	return jwk, nil
+}
+
+// PublicKey декодирует JWK обратно в открытый ключ.
+func (k Key) PublicKey() (crypto.PublicKey, error) {
+	switch {
+	case k.Kty == "RSA":
+		n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+		e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+		if err1 != nil || err2 != nil || len(e) == 0 || len(e) > 4 {
+			return nil, ErrUnsupportedKey
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// Keyfunc выбирает ключ проверки по kid из заголовка токена и отклоняет
+// токены, алгоритм которых не соответствует ключу (в том числе HS256).
+func Keyfunc(lookup func(kid string) (crypto.PublicKey, error)) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+		method, err := Method(key)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != method.Alg() {
+			return nil, ErrAlgMismatch
+		}
+		return key, nil
+	}
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrNoPEM
+	}
+	return block, nil
+}
+
+// LoadPrivateKey читает закрытый ключ RSA или Ed25519 в PEM (PKCS#8 или PKCS#1).
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// LoadPublicKey читает открытый ключ в PEM (PKIX).
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := Method(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GenerateKeyFile создаёт ключ Ed25519 в PEM (PKCS#8). Нужен для разработки:
+// в продакшене ключи выпускаются заранее.
+func GenerateKeyFile(path string) (crypto.Signer, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,4 @@
+// Package pb содержит код, сгенерированный из proto/ для gRPC API.
+package pb
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative gophkeeper/v1/auth.proto gophkeeper/v1/users.proto gophkeeper/v1/lockbox.proto
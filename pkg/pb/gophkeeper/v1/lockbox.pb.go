@@ -0,0 +1,642 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.1
+// 	protoc        v5.29.3
+// source: gophkeeper/v1/lockbox.proto
+
+package gophkeeperv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// LockBox — запись в том виде, в каком её хранит сервер: поля зашифрованы
+// клиентом, имя зашифровано, если задан name_index.
+type LockBox struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	NameIndex   string                 `protobuf:"bytes,3,opt,name=name_index,json=nameIndex,proto3" json:"name_index,omitempty"`
+	Url         string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	Login       string                 `protobuf:"bytes,5,opt,name=login,proto3" json:"login,omitempty"`
+	Password    string                 `protobuf:"bytes,6,opt,name=password,proto3" json:"password,omitempty"`
+	Description string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DeletedAt   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// revision — версия записи; в запросе на изменение — версия, от которой
+	// клиент начинал правку.
+	Revision      int64 `protobuf:"varint,11,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockBox) Reset() {
+	*x = LockBox{}
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockBox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockBox) ProtoMessage() {}
+
+func (x *LockBox) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockBox.ProtoReflect.Descriptor instead.
+func (*LockBox) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_v1_lockbox_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LockBox) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *LockBox) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LockBox) GetNameIndex() string {
+	if x != nil {
+		return x.NameIndex
+	}
+	return ""
+}
+
+func (x *LockBox) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *LockBox) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *LockBox) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *LockBox) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *LockBox) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *LockBox) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *LockBox) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *LockBox) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+type LockBoxKey struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Key:
+	//
+	//	*LockBoxKey_Name
+	//	*LockBoxKey_NameIndex
+	//	*LockBoxKey_Id
+	Key           isLockBoxKey_Key `protobuf_oneof:"key"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockBoxKey) Reset() {
+	*x = LockBoxKey{}
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockBoxKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockBoxKey) ProtoMessage() {}
+
+func (x *LockBoxKey) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockBoxKey.ProtoReflect.Descriptor instead.
+func (*LockBoxKey) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_v1_lockbox_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LockBoxKey) GetKey() isLockBoxKey_Key {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *LockBoxKey) GetName() string {
+	if x != nil {
+		if x, ok := x.Key.(*LockBoxKey_Name); ok {
+			return x.Name
+		}
+	}
+	return ""
+}
+
+func (x *LockBoxKey) GetNameIndex() string {
+	if x != nil {
+		if x, ok := x.Key.(*LockBoxKey_NameIndex); ok {
+			return x.NameIndex
+		}
+	}
+	return ""
+}
+
+func (x *LockBoxKey) GetId() int64 {
+	if x != nil {
+		if x, ok := x.Key.(*LockBoxKey_Id); ok {
+			return x.Id
+		}
+	}
+	return 0
+}
+
+type isLockBoxKey_Key interface {
+	isLockBoxKey_Key()
+}
+
+type LockBoxKey_Name struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3,oneof"`
+}
+
+type LockBoxKey_NameIndex struct {
+	NameIndex string `protobuf:"bytes,2,opt,name=name_index,json=nameIndex,proto3,oneof"`
+}
+
+type LockBoxKey_Id struct {
+	Id int64 `protobuf:"varint,3,opt,name=id,proto3,oneof"`
+}
+
+func (*LockBoxKey_Name) isLockBoxKey_Key() {}
+
+func (*LockBoxKey_NameIndex) isLockBoxKey_Key() {}
+
+func (*LockBoxKey_Id) isLockBoxKey_Key() {}
+
+type LockBoxID struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockBoxID) Reset() {
+	*x = LockBoxID{}
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockBoxID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockBoxID) ProtoMessage() {}
+
+func (x *LockBoxID) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockBoxID.ProtoReflect.Descriptor instead.
+func (*LockBoxID) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_v1_lockbox_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LockBoxID) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type LockBoxList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LockBoxes     []*LockBox             `protobuf:"bytes,1,rep,name=lock_boxes,json=lockBoxes,proto3" json:"lock_boxes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockBoxList) Reset() {
+	*x = LockBoxList{}
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockBoxList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockBoxList) ProtoMessage() {}
+
+func (x *LockBoxList) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockBoxList.ProtoReflect.Descriptor instead.
+func (*LockBoxList) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_v1_lockbox_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LockBoxList) GetLockBoxes() []*LockBox {
+	if x != nil {
+		return x.LockBoxes
+	}
+	return nil
+}
+
+type SyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LockBox       *LockBox               `protobuf:"bytes,1,opt,name=lock_box,json=lockBox,proto3" json:"lock_box,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncRequest) Reset() {
+	*x = SyncRequest{}
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRequest) ProtoMessage() {}
+
+func (x *SyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRequest.ProtoReflect.Descriptor instead.
+func (*SyncRequest) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_v1_lockbox_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SyncRequest) GetLockBox() *LockBox {
+	if x != nil {
+		return x.LockBox
+	}
+	return nil
+}
+
+type SyncResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*SyncResponse_Ack
+	//	*SyncResponse_LockBox
+	Event         isSyncResponse_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncResponse) Reset() {
+	*x = SyncResponse{}
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncResponse) ProtoMessage() {}
+
+func (x *SyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_v1_lockbox_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncResponse.ProtoReflect.Descriptor instead.
+func (*SyncResponse) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_v1_lockbox_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SyncResponse) GetEvent() isSyncResponse_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetAck() *LockBoxID {
+	if x != nil {
+		if x, ok := x.Event.(*SyncResponse_Ack); ok {
+			return x.Ack
+		}
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetLockBox() *LockBox {
+	if x != nil {
+		if x, ok := x.Event.(*SyncResponse_LockBox); ok {
+			return x.LockBox
+		}
+	}
+	return nil
+}
+
+type isSyncResponse_Event interface {
+	isSyncResponse_Event()
+}
+
+type SyncResponse_Ack struct {
+	// ack — id записи, сохранённой из очередного SyncRequest.
+	Ack *LockBoxID `protobuf:"bytes,1,opt,name=ack,proto3,oneof"`
+}
+
+type SyncResponse_LockBox struct {
+	LockBox *LockBox `protobuf:"bytes,2,opt,name=lock_box,json=lockBox,proto3,oneof"`
+}
+
+func (*SyncResponse_Ack) isSyncResponse_Event() {}
+
+func (*SyncResponse_LockBox) isSyncResponse_Event() {}
+
+var File_gophkeeper_v1_lockbox_proto protoreflect.FileDescriptor
+
+var file_gophkeeper_v1_lockbox_proto_rawDesc = []byte{
+	0x0a, 0x1b, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x2f,
+	0x6c, 0x6f, 0x63, 0x6b, 0x62, 0x6f, 0x78, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0d, 0x67,
+	0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x1a, 0x1b, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d,
+	0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xff, 0x02, 0x0a, 0x07, 0x4c,
+	0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x61,
+	0x6d, 0x65, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x6e, 0x61, 0x6d, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x6f, 0x67, 0x69, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x69,
+	0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x20, 0x0a,
+	0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x75, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64,
+	0x5f, 0x61, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x5c, 0x0a, 0x0a,
+	0x4c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x4b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x1f, 0x0a, 0x0a, 0x6e, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x49, 0x6e, 0x64, 0x65,
+	0x78, 0x12, 0x10, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52,
+	0x02, 0x69, 0x64, 0x42, 0x05, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x1b, 0x0a, 0x09, 0x4c, 0x6f,
+	0x63, 0x6b, 0x42, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x22, 0x44, 0x0a, 0x0b, 0x4c, 0x6f, 0x63, 0x6b, 0x42,
+	0x6f, 0x78, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x35, 0x0a, 0x0a, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x62,
+	0x6f, 0x78, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x70,
+	0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x42,
+	0x6f, 0x78, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x65, 0x73, 0x22, 0x40, 0x0a,
+	0x0b, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x31, 0x0a, 0x08,
+	0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x62, 0x6f, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c,
+	0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x22,
+	0x7a, 0x0a, 0x0c, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x2c, 0x0a, 0x03, 0x61, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x67,
+	0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x63,
+	0x6b, 0x42, 0x6f, 0x78, 0x49, 0x44, 0x48, 0x00, 0x52, 0x03, 0x61, 0x63, 0x6b, 0x12, 0x33, 0x0a,
+	0x08, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x62, 0x6f, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x16, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x48, 0x00, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x6b, 0x42,
+	0x6f, 0x78, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x32, 0xc2, 0x03, 0x0a, 0x0e,
+	0x4c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3a,
+	0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x6b,
+	0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78,
+	0x1a, 0x18, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x38, 0x0a, 0x03, 0x47, 0x65,
+	0x74, 0x12, 0x19, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x4b, 0x65, 0x79, 0x1a, 0x16, 0x2e, 0x67,
+	0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x63,
+	0x6b, 0x42, 0x6f, 0x78, 0x12, 0x3a, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x16, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1a, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x38, 0x0a, 0x06, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x70,
+	0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x42,
+	0x6f, 0x78, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x3b, 0x0a, 0x06, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x12, 0x19, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x4b, 0x65, 0x79, 0x1a,
+	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x42, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x4f, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x70, 0x68,
+	0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x42, 0x6f,
+	0x78, 0x1a, 0x18, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x6f, 0x63, 0x6b, 0x42, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x43, 0x0a, 0x04, 0x53,
+	0x79, 0x6e, 0x63, 0x12, 0x1a, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1b, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01,
+	0x42, 0x2e, 0x5a, 0x2c, 0x67, 0x6f, 0x70, 0x68, 0x4b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2f, 0x70,
+	0x6b, 0x67, 0x2f, 0x70, 0x62, 0x2f, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72,
+	0x2f, 0x76, 0x31, 0x3b, 0x67, 0x6f, 0x70, 0x68, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x76, 0x31,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gophkeeper_v1_lockbox_proto_rawDescOnce sync.Once
+	file_gophkeeper_v1_lockbox_proto_rawDescData = file_gophkeeper_v1_lockbox_proto_rawDesc
+)
+
+func file_gophkeeper_v1_lockbox_proto_rawDescGZIP() []byte {
+	file_gophkeeper_v1_lockbox_proto_rawDescOnce.Do(func() {
+		file_gophkeeper_v1_lockbox_proto_rawDescData = protoimpl.X.CompressGZIP(file_gophkeeper_v1_lockbox_proto_rawDescData)
+	})
+	return file_gophkeeper_v1_lockbox_proto_rawDescData
+}
+
+var file_gophkeeper_v1_lockbox_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_gophkeeper_v1_lockbox_proto_goTypes = []any{
+	(*LockBox)(nil),               // 0: gophkeeper.v1.LockBox
+	(*LockBoxKey)(nil),            // 1: gophkeeper.v1.LockBoxKey
+	(*LockBoxID)(nil),             // 2: gophkeeper.v1.LockBoxID
+	(*LockBoxList)(nil),           // 3: gophkeeper.v1.LockBoxList
+	(*SyncRequest)(nil),           // 4: gophkeeper.v1.SyncRequest
+	(*SyncResponse)(nil),          // 5: gophkeeper.v1.SyncResponse
+	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 7: google.protobuf.Empty
+}
+var file_gophkeeper_v1_lockbox_proto_depIdxs = []int32{
+	6,  // 0: gophkeeper.v1.LockBox.created_at:type_name -> google.protobuf.Timestamp
+	6,  // 1: gophkeeper.v1.LockBox.updated_at:type_name -> google.protobuf.Timestamp
+	6,  // 2: gophkeeper.v1.LockBox.deleted_at:type_name -> google.protobuf.Timestamp
+	0,  // 3: gophkeeper.v1.LockBoxList.lock_boxes:type_name -> gophkeeper.v1.LockBox
+	0,  // 4: gophkeeper.v1.SyncRequest.lock_box:type_name -> gophkeeper.v1.LockBox
+	2,  // 5: gophkeeper.v1.SyncResponse.ack:type_name -> gophkeeper.v1.LockBoxID
+	0,  // 6: gophkeeper.v1.SyncResponse.lock_box:type_name -> gophkeeper.v1.LockBox
+	0,  // 7: gophkeeper.v1.LockBoxService.Create:input_type -> gophkeeper.v1.LockBox
+	1,  // 8: gophkeeper.v1.LockBoxService.Get:input_type -> gophkeeper.v1.LockBoxKey
+	7,  // 9: gophkeeper.v1.LockBoxService.List:input_type -> google.protobuf.Empty
+	0,  // 10: gophkeeper.v1.LockBoxService.Update:input_type -> gophkeeper.v1.LockBox
+	1,  // 11: gophkeeper.v1.LockBoxService.Delete:input_type -> gophkeeper.v1.LockBoxKey
+	0,  // 12: gophkeeper.v1.LockBoxService.CreateOrUpdate:input_type -> gophkeeper.v1.LockBox
+	4,  // 13: gophkeeper.v1.LockBoxService.Sync:input_type -> gophkeeper.v1.SyncRequest
+	2,  // 14: gophkeeper.v1.LockBoxService.Create:output_type -> gophkeeper.v1.LockBoxID
+	0,  // 15: gophkeeper.v1.LockBoxService.Get:output_type -> gophkeeper.v1.LockBox
+	3,  // 16: gophkeeper.v1.LockBoxService.List:output_type -> gophkeeper.v1.LockBoxList
+	7,  // 17: gophkeeper.v1.LockBoxService.Update:output_type -> google.protobuf.Empty
+	7,  // 18: gophkeeper.v1.LockBoxService.Delete:output_type -> google.protobuf.Empty
+	2,  // 19: gophkeeper.v1.LockBoxService.CreateOrUpdate:output_type -> gophkeeper.v1.LockBoxID
+	5,  // 20: gophkeeper.v1.LockBoxService.Sync:output_type -> gophkeeper.v1.SyncResponse
+	14, // [14:21] is the sub-list for method output_type
+	7,  // [7:14] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_gophkeeper_v1_lockbox_proto_init() }
+func file_gophkeeper_v1_lockbox_proto_init() {
+	if File_gophkeeper_v1_lockbox_proto != nil {
+		return
+	}
+	file_gophkeeper_v1_lockbox_proto_msgTypes[1].OneofWrappers = []any{
+		(*LockBoxKey_Name)(nil),
+		(*LockBoxKey_NameIndex)(nil),
+		(*LockBoxKey_Id)(nil),
+	}
+	file_gophkeeper_v1_lockbox_proto_msgTypes[5].OneofWrappers = []any{
+		(*SyncResponse_Ack)(nil),
+		(*SyncResponse_LockBox)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gophkeeper_v1_lockbox_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gophkeeper_v1_lockbox_proto_goTypes,
+		DependencyIndexes: file_gophkeeper_v1_lockbox_proto_depIdxs,
+		MessageInfos:      file_gophkeeper_v1_lockbox_proto_msgTypes,
+	}.Build()
+	File_gophkeeper_v1_lockbox_proto = out.File
+	file_gophkeeper_v1_lockbox_proto_rawDesc = nil
+	file_gophkeeper_v1_lockbox_proto_goTypes = nil
+	file_gophkeeper_v1_lockbox_proto_depIdxs = nil
+}
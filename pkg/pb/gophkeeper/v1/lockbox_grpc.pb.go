@@ -0,0 +1,359 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: gophkeeper/v1/lockbox.proto
+
+package gophkeeperv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LockBoxService_Create_FullMethodName         = "/gophkeeper.v1.LockBoxService/Create"
+	LockBoxService_Get_FullMethodName            = "/gophkeeper.v1.LockBoxService/Get"
+	LockBoxService_List_FullMethodName           = "/gophkeeper.v1.LockBoxService/List"
+	LockBoxService_Update_FullMethodName         = "/gophkeeper.v1.LockBoxService/Update"
+	LockBoxService_Delete_FullMethodName         = "/gophkeeper.v1.LockBoxService/Delete"
+	LockBoxService_CreateOrUpdate_FullMethodName = "/gophkeeper.v1.LockBoxService/CreateOrUpdate"
+	LockBoxService_Sync_FullMethodName           = "/gophkeeper.v1.LockBoxService/Sync"
+)
+
+// LockBoxServiceClient is the client API for LockBoxService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LockBoxServiceClient interface {
+	Create(ctx context.Context, in *LockBox, opts ...grpc.CallOption) (*LockBoxID, error)
+	Get(ctx context.Context, in *LockBoxKey, opts ...grpc.CallOption) (*LockBox, error)
+	List(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*LockBoxList, error)
+	// Update находит запись по id, а если он не задан — по имени. Запись
+	// меняется, только если revision — её текущая версия, иначе ABORTED с
+	// текущей версией записи в деталях статуса.
+	Update(ctx context.Context, in *LockBox, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Delete(ctx context.Context, in *LockBoxKey, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// CreateOrUpdate проверяет revision существующей записи, как Update.
+	CreateOrUpdate(ctx context.Context, in *LockBox, opts ...grpc.CallOption) (*LockBoxID, error)
+	// Sync принимает локальные изменения клиента, подтверждая каждое, а после
+	// того как клиент закрыл отправку, передаёт все записи пользователя.
+	// Изменение устаревшей версии завершает поток с ABORTED, как Update.
+	Sync(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SyncRequest, SyncResponse], error)
+}
+
+type lockBoxServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLockBoxServiceClient(cc grpc.ClientConnInterface) LockBoxServiceClient {
+	return &lockBoxServiceClient{cc}
+}
+
+func (c *lockBoxServiceClient) Create(ctx context.Context, in *LockBox, opts ...grpc.CallOption) (*LockBoxID, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LockBoxID)
+	err := c.cc.Invoke(ctx, LockBoxService_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lockBoxServiceClient) Get(ctx context.Context, in *LockBoxKey, opts ...grpc.CallOption) (*LockBox, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LockBox)
+	err := c.cc.Invoke(ctx, LockBoxService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lockBoxServiceClient) List(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*LockBoxList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LockBoxList)
+	err := c.cc.Invoke(ctx, LockBoxService_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lockBoxServiceClient) Update(ctx context.Context, in *LockBox, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, LockBoxService_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lockBoxServiceClient) Delete(ctx context.Context, in *LockBoxKey, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, LockBoxService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lockBoxServiceClient) CreateOrUpdate(ctx context.Context, in *LockBox, opts ...grpc.CallOption) (*LockBoxID, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LockBoxID)
+	err := c.cc.Invoke(ctx, LockBoxService_CreateOrUpdate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lockBoxServiceClient) Sync(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SyncRequest, SyncResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LockBoxService_ServiceDesc.Streams[0], LockBoxService_Sync_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SyncRequest, SyncResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LockBoxService_SyncClient = grpc.BidiStreamingClient[SyncRequest, SyncResponse]
+
+// LockBoxServiceServer is the server API for LockBoxService service.
+// All implementations must embed UnimplementedLockBoxServiceServer
+// for forward compatibility.
+type LockBoxServiceServer interface {
+	Create(context.Context, *LockBox) (*LockBoxID, error)
+	Get(context.Context, *LockBoxKey) (*LockBox, error)
+	List(context.Context, *emptypb.Empty) (*LockBoxList, error)
+	// Update находит запись по id, а если он не задан — по имени. Запись
+	// меняется, только если revision — её текущая версия, иначе ABORTED с
+	// текущей версией записи в деталях статуса.
+	Update(context.Context, *LockBox) (*emptypb.Empty, error)
+	Delete(context.Context, *LockBoxKey) (*emptypb.Empty, error)
+	// CreateOrUpdate проверяет revision существующей записи, как Update.
+	CreateOrUpdate(context.Context, *LockBox) (*LockBoxID, error)
+	// Sync принимает локальные изменения клиента, подтверждая каждое, а после
+	// того как клиент закрыл отправку, передаёт все записи пользователя.
+	// Изменение устаревшей версии завершает поток с ABORTED, как Update.
+	Sync(grpc.BidiStreamingServer[SyncRequest, SyncResponse]) error
+	mustEmbedUnimplementedLockBoxServiceServer()
+}
+
+// UnimplementedLockBoxServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLockBoxServiceServer struct{}
+
+func (UnimplementedLockBoxServiceServer) Create(context.Context, *LockBox) (*LockBoxID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedLockBoxServiceServer) Get(context.Context, *LockBoxKey) (*LockBox, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedLockBoxServiceServer) List(context.Context, *emptypb.Empty) (*LockBoxList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedLockBoxServiceServer) Update(context.Context, *LockBox) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedLockBoxServiceServer) Delete(context.Context, *LockBoxKey) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedLockBoxServiceServer) CreateOrUpdate(context.Context, *LockBox) (*LockBoxID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrUpdate not implemented")
+}
+func (UnimplementedLockBoxServiceServer) Sync(grpc.BidiStreamingServer[SyncRequest, SyncResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Sync not implemented")
+}
+func (UnimplementedLockBoxServiceServer) mustEmbedUnimplementedLockBoxServiceServer() {}
+func (UnimplementedLockBoxServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeLockBoxServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LockBoxServiceServer will
+// result in compilation errors.
+type UnsafeLockBoxServiceServer interface {
+	mustEmbedUnimplementedLockBoxServiceServer()
+}
+
+func RegisterLockBoxServiceServer(s grpc.ServiceRegistrar, srv LockBoxServiceServer) {
+	// If the following call pancis, it indicates UnimplementedLockBoxServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LockBoxService_ServiceDesc, srv)
+}
+
+func _LockBoxService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockBox)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LockBoxServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LockBoxService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LockBoxServiceServer).Create(ctx, req.(*LockBox))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LockBoxService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockBoxKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LockBoxServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LockBoxService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LockBoxServiceServer).Get(ctx, req.(*LockBoxKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LockBoxService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LockBoxServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LockBoxService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LockBoxServiceServer).List(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LockBoxService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockBox)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LockBoxServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LockBoxService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LockBoxServiceServer).Update(ctx, req.(*LockBox))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LockBoxService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockBoxKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LockBoxServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LockBoxService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LockBoxServiceServer).Delete(ctx, req.(*LockBoxKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LockBoxService_CreateOrUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockBox)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LockBoxServiceServer).CreateOrUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LockBoxService_CreateOrUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LockBoxServiceServer).CreateOrUpdate(ctx, req.(*LockBox))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LockBoxService_Sync_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LockBoxServiceServer).Sync(&grpc.GenericServerStream[SyncRequest, SyncResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LockBoxService_SyncServer = grpc.BidiStreamingServer[SyncRequest, SyncResponse]
+
+// LockBoxService_ServiceDesc is the grpc.ServiceDesc for LockBoxService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LockBoxService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gophkeeper.v1.LockBoxService",
+	HandlerType: (*LockBoxServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _LockBoxService_Create_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _LockBoxService_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _LockBoxService_List_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _LockBoxService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _LockBoxService_Delete_Handler,
+		},
+		{
+			MethodName: "CreateOrUpdate",
+			Handler:    _LockBoxService_CreateOrUpdate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Sync",
+			Handler:       _LockBoxService_Sync_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "gophkeeper/v1/lockbox.proto",
+}
@@ -0,0 +1,231 @@
+// Package srp реализует обмен SRP-6a (RFC 5054, группа 2048 бит, SHA-256).
+// Сервер хранит только верификатор и проверяет знание пароля, не получая его.
+// Пароль аккаунта не заменяет мастер-пароль хранилища: клиент требует, чтобы
+// они различались.
+package srp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"math/big"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var (
+	ErrInvalidPublicKey = errors.New("srp: недопустимый публичный ключ")
+	ErrProofMismatch    = errors.New("srp: доказательство не совпало")
+)
+
+// Группа 2048 бит из RFC 5054, приложение A.
+const groupHex = "AC6BDB41324A9A9BF166DE5E1389582FAF72B6651987EE07FC3192943DB56050" +
+	"A37329CBB4A099ED8193E0757767A13DD52312AB4B03310DCD7F48A9DA04FD50" +
+	"E8083969EDB767B0CF6095179A163AB3661A05FBD5FAAAE82918A9962F0B93B8" +
+	"55F97993EC975EEAA80D740ADBF4FF747359D041D5C33EA71D281E446B14773B" +
+	"CA97B43A23FB801676BD207A436C6481F1D2B9078717461A5B9D32E688F87748" +
+	"544523B524B0D57D5EA77A2775D2ECFA032CFBDBF52FB3786160279004E57AE6" +
+	"AF874E7303CE53299CCC041C7BC308D82A5698F3A8D0C38271AE35F8E9DBFBB6" +
+	"94B5C803D89F7AE435DE236D525F54759B65E372FCD68EF20FA7111F9E4AFF73"
+
+const (
+	SaltSize = 16
+
+	// Параметры Argon2id для x: перебор паролей по утёкшему верификатору
+	// должен стоить столько же, сколько перебор по ключу хранилища.
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+)
+
+var (
+	N    *big.Int
+	g    = big.NewInt(2)
+	k    *big.Int
+	nLen int
+)
+
+func init() {
+	N, _ = new(big.Int).SetString(groupHex, 16)
+	nLen = len(N.Bytes())
+	k = hashInt(pad(N.Bytes()), pad(g.Bytes()))
+}
+
+// pad дополняет число нулями слева до длины N, как требует RFC 5054.
+func pad(b []byte) []byte {
+	if len(b) >= nLen {
+		return b
+	}
+	out := make([]byte, nLen)
+	copy(out[nLen-len(b):], b)
+	return out
+}
+
+func hash(parts ...[]byte) []byte {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+func hashInt(parts ...[]byte) *big.Int {
+	return new(big.Int).SetBytes(hash(parts...))
+}
+
+func randomInt() (*big.Int, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(buf), nil
+}
+
+// NewSalt генерирует соль для верификатора. Она не должна совпадать с солью
+// ключа хранилища, иначе x и ключ будут выведены одинаково.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// computeX выводит закрытое значение x из пароля. Вместо простого хеша из
+// RFC 5054 используется Argon2id.
+func computeX(username, password string, salt []byte) *big.Int {
+	stretched := argon2.IDKey([]byte("gophKeeper srp:"+username+":"+password), salt, argonTime, argonMemory, argonThreads, 32)
+	return hashInt(salt, stretched)
+}
+
+// Verifier вычисляет v = g^x mod N, который сервер хранит вместо пароля.
+func Verifier(username, password string, salt []byte) []byte {
+	return new(big.Int).Exp(g, computeX(username, password, salt), N).Bytes()
+}
+
+// clientProof — M1 = H(H(N) xor H(g) | H(I) | s | A | B | K).
+func clientProof(username string, salt, A, B, key []byte) []byte {
+	hn, hg := hash(N.Bytes()), hash(pad(g.Bytes()))
+	for i := range hn {
+		hn[i] ^= hg[i]
+	}
+	return hash(hn, hash([]byte(username)), salt, A, B, key)
+}
+
+// serverProof — M2 = H(A | M1 | K).
+func serverProof(A, m1, key []byte) []byte {
+	return hash(A, m1, key)
+}
+
+// Client — сторона клиента в одном обмене.
+type Client struct {
+	username string
+	password string
+	a        *big.Int
+	A        []byte
+	m2       []byte
+}
+
+func NewClient(username, password string) (*Client, error) {
+	a, err := randomInt()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		username: username,
+		password: password,
+		a:        a,
+		A:        pad(new(big.Int).Exp(g, a, N).Bytes()),
+	}, nil
+}
+
+// Proof принимает соль и B сервера и возвращает M1.
+func (c *Client) Proof(salt, serverB []byte) ([]byte, error) {
+	B := new(big.Int).SetBytes(serverB)
+	if new(big.Int).Mod(B, N).Sign() == 0 {
+		return nil, ErrInvalidPublicKey
+	}
+	bPad := pad(B.Bytes())
+	u := hashInt(c.A, bPad)
+	if u.Sign() == 0 {
+		return nil, ErrInvalidPublicKey
+	}
+
+	// S = (B - k*g^x) ^ (a + u*x) mod N
+	x := computeX(c.username, c.password, salt)
+	base := new(big.Int).Sub(B, new(big.Int).Mul(k, new(big.Int).Exp(g, x, N)))
+	base.Mod(base, N)
+	exp := new(big.Int).Add(c.a, new(big.Int).Mul(u, x))
+	S := new(big.Int).Exp(base, exp, N)
+	key := hash(pad(S.Bytes()))
+
+	m1 := clientProof(c.username, salt, c.A, bPad, key)
+	c.m2 = serverProof(c.A, m1, key)
+	return m1, nil
+}
+
+// VerifyServer проверяет M2: сервер доказывает, что знает верификатор.
+func (c *Client) VerifyServer(m2 []byte) error {
+	if c.m2 == nil || subtle.ConstantTimeCompare(c.m2, m2) != 1 {
+		return ErrProofMismatch
+	}
+	return nil
+}
+
+// Server — сторона сервера в одном обмене. Хранится между двумя запросами.
+type Server struct {
+	username string
+	salt     []byte
+	v        *big.Int
+	A        []byte
+	b        *big.Int
+	B        []byte
+}
+
+func NewServer(username string, salt, verifier, clientA []byte) (*Server, error) {
+	A := new(big.Int).SetBytes(clientA)
+	if new(big.Int).Mod(A, N).Sign() == 0 {
+		return nil, ErrInvalidPublicKey
+	}
+	b, err := randomInt()
+	if err != nil {
+		return nil, err
+	}
+	v := new(big.Int).SetBytes(verifier)
+
+	// B = k*v + g^b mod N
+	B := new(big.Int).Mul(k, v)
+	B.Add(B, new(big.Int).Exp(g, b, N))
+	B.Mod(B, N)
+
+	return &Server{
+		username: username,
+		salt:     salt,
+		v:        v,
+		A:        pad(A.Bytes()),
+		b:        b,
+		B:        pad(B.Bytes()),
+	}, nil
+}
+
+// Verify проверяет M1 клиента и возвращает M2.
+func (s *Server) Verify(m1 []byte) ([]byte, error) {
+	u := hashInt(s.A, s.B)
+	if u.Sign() == 0 {
+		return nil, ErrInvalidPublicKey
+	}
+
+	// S = (A * v^u) ^ b mod N
+	A := new(big.Int).SetBytes(s.A)
+	base := new(big.Int).Mul(A, new(big.Int).Exp(s.v, u, N))
+	base.Mod(base, N)
+	S := new(big.Int).Exp(base, s.b, N)
+	key := hash(pad(S.Bytes()))
+
+	expected := clientProof(s.username, s.salt, s.A, s.B, key)
+	if subtle.ConstantTimeCompare(expected, m1) != 1 {
+		return nil, ErrProofMismatch
+	}
+	return serverProof(s.A, m1, key), nil
+}
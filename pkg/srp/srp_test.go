@@ -0,0 +1,62 @@
+package srp
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	if !N.ProbablyPrime(20) {
+		t.Fatal("N is not prime")
+	}
+	q := new(big.Int).Rsh(N, 1)
+	if !q.ProbablyPrime(20) {
+		t.Fatal("N is not a safe prime")
+	}
+}
+
+func exchange(t *testing.T, password string) error {
+	t.Helper()
+	salt, err := NewSalt()
+	if err != nil {
+		t.Fatalf("NewSalt failed: %v", err)
+	}
+	verifier := Verifier("alice", "correct horse", salt)
+
+	client, err := NewClient("alice", password)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	server, err := NewServer("alice", salt, verifier, client.A)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	m1, err := client.Proof(salt, server.B)
+	if err != nil {
+		t.Fatalf("Proof failed: %v", err)
+	}
+	m2, err := server.Verify(m1)
+	if err != nil {
+		return err
+	}
+	return client.VerifyServer(m2)
+}
+
+func TestExchange(t *testing.T) {
+	if err := exchange(t, "correct horse"); err != nil {
+		t.Fatalf("Exchange with the right password failed: %v", err)
+	}
+	if err := exchange(t, "wrong horse"); err != ErrProofMismatch {
+		t.Fatalf("Exchange error = %v, want ErrProofMismatch", err)
+	}
+}
+
+func TestInvalidPublicKey(t *testing.T) {
+	if _, err := NewServer("alice", []byte("salt"), []byte{1}, N.Bytes()); err != ErrInvalidPublicKey {
+		t.Errorf("NewServer error = %v, want ErrInvalidPublicKey", err)
+	}
+	client, _ := NewClient("alice", "pw")
+	if _, err := client.Proof([]byte("salt"), []byte{0}); err != ErrInvalidPublicKey {
+		t.Errorf("Proof error = %v, want ErrInvalidPublicKey", err)
+	}
+}
@@ -0,0 +1,224 @@
+// Package tlsutil собирает tls.Config для сервера и клиента: взаимный TLS,
+// самоподписанный сертификат для разработки и привязку к ключу сервера (SPKI pin).
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoCertificates = errors.New("tls: в файле CA нет сертификатов")
+	ErrPinMismatch    = errors.New("tls: ключ сервера не совпадает ни с одним из закреплённых")
+)
+
+// pinPrefix — необязательный префикс пина, как в HPKP: "sha256/<base64>".
+const pinPrefix = "sha256/"
+
+// SelfSigned выпускает самоподписанный сертификат ECDSA P-256 на год для
+// перечисленных имён и IP-адресов. Годится только для разработки.
+func SelfSigned(hosts []string) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"gophKeeper"}, CommonName: "gophKeeper dev"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// EnsureSelfSigned создаёт самоподписанную пару в certFile и keyFile, если
+// сертификата ещё нет. Существующие файлы не трогает.
+func EnsureSelfSigned(certFile, keyFile string, hosts []string) error {
+	if _, err := os.Stat(certFile); err == nil {
+		return nil
+	}
+	certPEM, keyPEM, err := SelfSigned(hosts)
+	if err != nil {
+		return err
+	}
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(certFile, certPEM, 0o644)
+}
+
+// SPKIPin возвращает пин сертификата: base64 от SHA-256 его SubjectPublicKeyInfo.
+// Пин не меняется при перевыпуске сертификата на тот же ключ.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// CertFilePin читает PEM-сертификат и возвращает его пин.
+func CertFilePin(certFile string) (string, error) {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return "", err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", ErrNoCertificates
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	return SPKIPin(cert), nil
+}
+
+func loadPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, ErrNoCertificates
+	}
+	return pool, nil
+}
+
+// ServerConfig загружает сертификат сервера. Если задан clientCAFile, сервер
+// требует клиентский сертификат, подписанный этим CA (взаимный TLS).
+func ServerConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if clientCAFile != "" {
+		pool, err := loadPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// ClientOptions — настройки TLS клиента. Пустые поля означают значения по
+// умолчанию: системные корневые сертификаты, без пинов и без сертификата клиента.
+type ClientOptions struct {
+	CAFile   string
+	Pins     []string
+	CertFile string
+	KeyFile  string
+}
+
+// ClientConfig собирает tls.Config клиента. Если заданы пины, а CA нет,
+// цепочка не проверяется вовсе и доверие держится только на пине листового
+// сертификата — так можно подключиться к самоподписанному серверу. С CA пин
+// должен совпасть с любым сертификатом проверенной цепочки.
+func ClientConfig(opts ClientOptions) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.CAFile != "" {
+		pool, err := loadPool(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	pins := make([]string, 0, len(opts.Pins))
+	for _, pin := range opts.Pins {
+		if pin = strings.TrimPrefix(strings.TrimSpace(pin), pinPrefix); pin != "" {
+			pins = append(pins, pin)
+		}
+	}
+	if len(pins) == 0 {
+		return cfg, nil
+	}
+
+	pinOnly := opts.CAFile == ""
+	cfg.InsecureSkipVerify = pinOnly
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		if pinOnly {
+			if len(cs.PeerCertificates) == 0 {
+				return ErrPinMismatch
+			}
+			return matchPin(cs.PeerCertificates[0], pins)
+		}
+		for _, chain := range cs.VerifiedChains {
+			for _, cert := range chain {
+				if matchPin(cert, pins) == nil {
+					return nil
+				}
+			}
+		}
+		return ErrPinMismatch
+	}
+	return cfg, nil
+}
+
+func matchPin(cert *x509.Certificate, pins []string) error {
+	got := SPKIPin(cert)
+	for _, pin := range pins {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(pin)) == 1 {
+			return nil
+		}
+	}
+	return ErrPinMismatch
+}
@@ -0,0 +1,116 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTLSServer поднимает HTTPS-сервер с самоподписанным сертификатом из dir.
+func newTLSServer(t *testing.T, dir, clientCA string) (*httptest.Server, string) {
+	t.Helper()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	if err := EnsureSelfSigned(certFile, keyFile, []string{"127.0.0.1", "localhost"}); err != nil {
+		t.Fatalf("EnsureSelfSigned failed: %v", err)
+	}
+	cfg, err := ServerConfig(certFile, keyFile, clientCA)
+	if err != nil {
+		t.Fatalf("ServerConfig failed: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	srv.TLS = cfg
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv, certFile
+}
+
+func get(t *testing.T, url string, opts ClientOptions) error {
+	t.Helper()
+	cfg, err := ClientConfig(opts)
+	if err != nil {
+		t.Fatalf("ClientConfig failed: %v", err)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func TestPinning(t *testing.T) {
+	srv, certFile := newTLSServer(t, t.TempDir(), "")
+	pin, err := CertFilePin(certFile)
+	if err != nil {
+		t.Fatalf("CertFilePin failed: %v", err)
+	}
+
+	if err := get(t, srv.URL, ClientOptions{}); err == nil {
+		t.Error("Expected self-signed certificate to be rejected without CA or pin")
+	}
+	if err := get(t, srv.URL, ClientOptions{CAFile: certFile}); err != nil {
+		t.Errorf("Expected CA bundle to be trusted, got %v", err)
+	}
+	if err := get(t, srv.URL, ClientOptions{Pins: []string{pinPrefix + pin}}); err != nil {
+		t.Errorf("Expected pinned key to be trusted, got %v", err)
+	}
+	if err := get(t, srv.URL, ClientOptions{CAFile: certFile, Pins: []string{pin}}); err != nil {
+		t.Errorf("Expected CA and pin together to be trusted, got %v", err)
+	}
+
+	other, _, err := SelfSigned([]string{"localhost"})
+	if err != nil {
+		t.Fatalf("SelfSigned failed: %v", err)
+	}
+	otherFile := filepath.Join(t.TempDir(), "other.crt")
+	writeFile(t, otherFile, other)
+	otherPin, _ := CertFilePin(otherFile)
+	if err := get(t, srv.URL, ClientOptions{Pins: []string{otherPin}}); err == nil {
+		t.Error("Expected foreign pin to be rejected")
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, clientKey := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	if err := EnsureSelfSigned(clientCert, clientKey, nil); err != nil {
+		t.Fatalf("EnsureSelfSigned failed: %v", err)
+	}
+	srv, certFile := newTLSServer(t, dir, clientCert)
+
+	if err := get(t, srv.URL, ClientOptions{CAFile: certFile}); err == nil {
+		t.Error("Expected connection without client certificate to be rejected")
+	}
+	opts := ClientOptions{CAFile: certFile, CertFile: clientCert, KeyFile: clientKey}
+	if err := get(t, srv.URL, opts); err != nil {
+		t.Errorf("Expected client certificate to be accepted, got %v", err)
+	}
+}
+
+func TestServerConfigRequiresClientCert(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	if err := EnsureSelfSigned(certFile, keyFile, nil); err != nil {
+		t.Fatalf("EnsureSelfSigned failed: %v", err)
+	}
+	cfg, err := ServerConfig(certFile, keyFile, certFile)
+	if err != nil {
+		t.Fatalf("ServerConfig failed: %v", err)
+	}
+	if cfg.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("Expected RequireAndVerifyClientCert, got %v", cfg.ClientAuth)
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+// Package totp реализует одноразовые пароли по времени (RFC 6238) в варианте,
+// который понимают приложения-аутентификаторы: HMAC-SHA1, 6 цифр, шаг 30 секунд.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits — длина кода.
+	Digits = 6
+	// Period — шаг времени в секундах.
+	Period = 30
+	// Skew — сколько соседних шагов принимается из-за расхождения часов.
+	Skew = 1
+	// secretSize — длина секрета в байтах (160 бит, как рекомендует RFC 4226).
+	secretSize = 20
+)
+
+var ErrInvalidSecret = errors.New("totp: секрет должен быть в base32")
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret создаёт случайный секрет в base32 без выравнивания.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// URI возвращает ссылку otpauth://, которую приложение-аутентификатор
+// принимает как QR-код или вручную.
+func URI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(Period))
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Step возвращает номер шага времени для момента t.
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
+
+func code(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1_000_000)
+}
+
+// Code вычисляет код для момента t.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, Step(t)), nil
+}
+
+// Validate проверяет код с допуском Skew шагов и возвращает шаг, которому он
+// соответствует. Вызывающий запоминает шаг, чтобы код нельзя было повторить.
+func Validate(secret, value string, t time.Time) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil || len(value) != Digits {
+		return 0, false
+	}
+	now := Step(t)
+	for step := now - Skew; step <= now+Skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(code(key, step)), []byte(value)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
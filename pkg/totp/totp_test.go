@@ -0,0 +1,67 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret — ключ SHA1 из приложения B RFC 6238.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeRFCVectors(t *testing.T) {
+	// В RFC коды из 8 цифр, здесь сравниваются последние 6.
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		got, err := Code(rfcSecret, time.Unix(unix, 0))
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		if got != want {
+			t.Errorf("T=%d: ожидался код %s, получили %s", unix, want, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+
+	current, _ := Code(secret, now)
+	if step, ok := Validate(secret, current, now); !ok || step != Step(now) {
+		t.Errorf("Текущий код должен приниматься, шаг %d", step)
+	}
+	previous, _ := Code(secret, now.Add(-Period*time.Second))
+	if _, ok := Validate(secret, previous, now); !ok {
+		t.Error("Код предыдущего шага должен приниматься из-за расхождения часов")
+	}
+	stale, _ := Code(secret, now.Add(-3*Period*time.Second))
+	if _, ok := Validate(secret, stale, now); ok {
+		t.Error("Устаревший код не должен приниматься")
+	}
+	if _, ok := Validate("not base32!", current, now); ok {
+		t.Error("Код с некорректным секретом не должен приниматься")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("gophKeeper", "alice", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/gophKeeper:alice?") {
+		t.Errorf("Неверный префикс URI: %s", uri)
+	}
+	for _, part := range []string{"secret=JBSWY3DPEHPK3PXP", "issuer=gophKeeper", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("URI %s не содержит %s", uri, part)
+		}
+	}
+}